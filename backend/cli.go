@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	backup_app "sofa-commander/backend/internal/features/backup/application"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/serverlock"
+)
+
+// backupDirs are the directories of the files a backup holds besides the sessions.
+var backupDirs = []string{"config", "data"}
+
+const usage = `Usage: %s [command]
+
+Without a command, the server is started. Commands:
+  migrate, --migrate-only  apply the pending session store migrations, which the server applies on startup,
+                           and rewrite sessions stored in an older session format
+  backup [-o file]         write a backup archive of the sessions, config and data
+  verify file              check the integrity of a backup archive
+  restore [-force] file    restore a backup archive into a fresh instance; the server must be stopped
+`
+
+// runCommand runs a maintenance command instead of the server and returns its exit code. The commands
+// use the session store selected by SESSION_STORE and the files of the working directory, like the server.
+func runCommand(name string, args []string) int {
+	fail := func(err error) int {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	newService := func() (backup_app.BackupService, error) {
+		sessionRepository, err := infrastructure.NewSessionRepository(os.Getenv("SESSION_STORE"), os.Getenv("SESSION_STORE_DSN"))
+		if err != nil {
+			return nil, err
+		}
+		// The CLI cannot pause a running server; back up through the admin API while it runs
+		return backup_app.NewBackupService(sessionRepository, backupDirs, func() func() { return func() {} }), nil
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	switch name {
+	case "migrate", "--migrate-only":
+		report, err := infrastructure.MigrateSessionStore(os.Getenv("SESSION_STORE"), os.Getenv("SESSION_STORE_DSN"))
+		if report != nil {
+			for _, m := range report.Applied {
+				fmt.Printf("Applied migration %d_%s\n", m.Version, m.Name)
+			}
+		}
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Printf("Session store is at schema version %d; upgraded %d sessions to session format %d\n", report.SchemaVersion, report.UpgradedSessions, domain.SessionSchemaVersion)
+	case "backup":
+		output := flags.String("o", fmt.Sprintf("sofa-commander-backup-%s.tar.gz", time.Now().Format("20060102-150405")), "archive to write")
+		if flags.Parse(args) != nil {
+			return 2
+		}
+		service, err := newService()
+		if err != nil {
+			return fail(err)
+		}
+		f, err := os.Create(*output)
+		if err != nil {
+			return fail(err)
+		}
+		manifest, err := service.Create(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(*output)
+			return fail(err)
+		}
+		fmt.Printf("Backed up %d sessions and %d files to %s\n", manifest.Sessions, len(manifest.Entries)-1, *output)
+	case "verify":
+		if flags.Parse(args) != nil || flags.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, usage, os.Args[0])
+			return 2
+		}
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return fail(err)
+		}
+		defer f.Close()
+		report := backup_app.NewBackupService(nil, backupDirs, nil).Verify(f)
+		if !report.Valid {
+			return fail(fmt.Errorf("backup is invalid:\n  %s", strings.Join(report.Problems, "\n  ")))
+		}
+		fmt.Printf("Backup of %s is valid: %d sessions, %d files\n", report.Manifest.CreatedAt.Format(time.RFC3339), report.Manifest.Sessions, len(report.Manifest.Entries)-1)
+	case "restore":
+		force := flags.Bool("force", false, "restore into an instance that already has sessions")
+		if flags.Parse(args) != nil || flags.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, usage, os.Args[0])
+			return 2
+		}
+		// Sessions the running server holds in memory would overwrite the restored ones
+		release, err := serverlock.Acquire(serverlock.Path)
+		if errors.Is(err, serverlock.ErrRunning) {
+			return fail(fmt.Errorf("%w; stop it before restoring a backup", err))
+		}
+		if err != nil {
+			return fail(err)
+		}
+		defer release()
+		service, err := newService()
+		if err != nil {
+			return fail(err)
+		}
+		report, err := service.Restore(flags.Arg(0), *force)
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Printf("Restored %d sessions and %d files\n", report.Sessions, report.Files)
+	default:
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		return 2
+	}
+	return 0
+}
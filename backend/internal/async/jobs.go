@@ -0,0 +1,115 @@
+package async
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sofa-commander/backend/internal/chaos"
+)
+
+// Async job statuses.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobCompleted = "completed"
+	jobFailed    = "failed"
+)
+
+const (
+	// defaultAsyncWorkers is the number of async jobs run at the same time unless configured otherwise.
+	defaultAsyncWorkers = 4
+	// maxQueuedJobs bounds the jobs waiting for a worker; further async requests are rejected.
+	maxQueuedJobs = 100
+)
+
+// asyncJob is the view of an operation the client asked to run asynchronously and polls for.
+type asyncJob struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	Status     string     `json:"status"`
+	HTTPStatus int        `json:"http_status,omitempty"` // Status code the synchronous request would have had
+	Result     any        `json:"result,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// asyncJobs runs async jobs as pending operations, workers of them at the same time.
+type asyncJobs struct {
+	workers chan struct{} // Held by the running jobs
+	waiting chan struct{} // Held by the queued and running jobs
+}
+
+func newAsyncJobs(workers int) *asyncJobs {
+	if workers <= 0 {
+		workers = defaultAsyncWorkers
+	}
+	return &asyncJobs{workers: make(chan struct{}, workers), waiting: make(chan struct{}, workers+maxQueuedJobs)}
+}
+
+// job returns the view of an operation run as an async job.
+func (p *pendingOperations) job(op *pendingOperation) asyncJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	job := asyncJob{ID: op.id, Operation: op.operation, Status: jobQueued, CreatedAt: op.createdAt}
+	if !op.startedAt.IsZero() {
+		startedAt := op.startedAt
+		job.Status, job.StartedAt = jobRunning, &startedAt
+	}
+	if !op.finishedAt.IsZero() {
+		finishedAt := op.finishedAt
+		job.HTTPStatus, job.Result, job.FinishedAt = op.status, op.body, &finishedAt
+		job.Status = jobCompleted
+		if op.status >= http.StatusBadRequest {
+			job.Status = jobFailed
+		}
+	}
+	return job
+}
+
+// wantsAsync reports whether the client asked for the operation to run as an async job, with the
+// `Prefer: respond-async` header or the `async=true` query parameter.
+func wantsAsync(c *gin.Context) bool {
+	return c.Query("async") == "true" || strings.Contains(c.GetHeader("Prefer"), "respond-async")
+}
+
+// respondAsync queues an operation as an async job and responds with 202 and the job to poll.
+func (r *Runner) respondAsync(c *gin.Context, operation string, run func(ctx context.Context) (int, any)) {
+	select {
+	case r.jobs.waiting <- struct{}{}:
+	default:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to queue job: too many queued jobs"})
+		return
+	}
+	// Jobs outlive the request, but keep the faults it asked for in chaos testing
+	requestCtx := c.Request.Context()
+	op, err := r.pending.startWhenFree(c, operation, r.jobs.workers, func(ctx context.Context) (int, any) {
+		defer func() { <-r.jobs.waiting }()
+		return run(chaos.Carry(ctx, requestCtx))
+	})
+	if err != nil {
+		<-r.jobs.waiting
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue job: " + err.Error()})
+		return
+	}
+	op.detach()
+	r.pending.track(op)
+	location := "/api/refine/jobs/" + op.id
+	c.Header("Location", location)
+	c.JSON(http.StatusAccepted, gin.H{"job_id": op.id, "status": r.pending.job(op).Status, "status_url": location})
+}
+
+// GetJobHandler returns the status of an async job of the request's user, with the operation's response
+// once it finished.
+func (r *Runner) GetJobHandler(c *gin.Context) {
+	op, ok := r.pending.get(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, r.pending.job(op))
+}
@@ -0,0 +1,201 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+func newTestRunner(t *testing.T, budgets map[string]configdomain.LatencyBudget) *Runner {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app_config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	service := config.NewAppConfigService(path, filepath.Join(dir, "versions.jsonl"))
+	if err := service.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		appConfig.LatencyBudgets = budgets
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return NewRunner(service, 1)
+}
+
+func newTestRouter(r *Runner, run func(ctx context.Context) (int, any)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/op", func(c *gin.Context) { r.RespondWithinBudget(c, "analyze", run) })
+	router.GET("/api/refine/jobs/:id", r.GetJobHandler)
+	router.GET("/api/refine/pending/:id", r.PendingResultHandler)
+	return router
+}
+
+func serve(router *gin.Engine, method, target, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// pollJob polls an async job until it finished.
+func pollJob(t *testing.T, router *gin.Engine, id, userID string) asyncJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := serve(router, http.MethodGet, "/api/refine/jobs/"+id, userID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET job = %d, want 200: %s", w.Code, w.Body)
+		}
+		var job asyncJob
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not finish, status %q", id, job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAsyncJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        func(ctx context.Context) (int, any)
+		wantStatus string
+		wantHTTP   int
+	}{
+		{
+			name:       "completed",
+			run:        func(ctx context.Context) (int, any) { return http.StatusOK, gin.H{"ok": true} },
+			wantStatus: jobCompleted,
+			wantHTTP:   http.StatusOK,
+		},
+		{
+			name:       "failed",
+			run:        func(ctx context.Context) (int, any) { return http.StatusBadGateway, gin.H{"error": "AI unavailable"} },
+			wantStatus: jobFailed,
+			wantHTTP:   http.StatusBadGateway,
+		},
+		{
+			name:       "panicked",
+			run:        func(ctx context.Context) (int, any) { panic("boom") },
+			wantStatus: jobFailed,
+			wantHTTP:   http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(newTestRunner(t, nil), tt.run)
+
+			w := serve(router, http.MethodPost, "/op?async=true", "alice")
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("POST = %d, want 202: %s", w.Code, w.Body)
+			}
+			var accepted struct {
+				JobID     string `json:"job_id"`
+				StatusURL string `json:"status_url"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+				t.Fatal(err)
+			}
+			if accepted.StatusURL != "/api/refine/jobs/"+accepted.JobID || w.Header().Get("Location") != accepted.StatusURL {
+				t.Errorf("status_url = %q, Location = %q, want the job's URL", accepted.StatusURL, w.Header().Get("Location"))
+			}
+
+			job := pollJob(t, router, accepted.JobID, "alice")
+			if job.Status != tt.wantStatus || job.HTTPStatus != tt.wantHTTP {
+				t.Errorf("job = %s/%d, want %s/%d", job.Status, job.HTTPStatus, tt.wantStatus, tt.wantHTTP)
+			}
+			if w := serve(router, http.MethodGet, "/api/refine/jobs/"+accepted.JobID, "bob"); w.Code != http.StatusNotFound {
+				t.Errorf("GET job of another user = %d, want 404", w.Code)
+			}
+		})
+	}
+}
+
+func TestAsyncJobIDs(t *testing.T) {
+	router := newTestRouter(newTestRunner(t, nil), func(ctx context.Context) (int, any) { return http.StatusOK, nil })
+	seen := map[string]bool{}
+	for range 3 {
+		req := httptest.NewRequest(http.MethodPost, "/op", nil)
+		req.Header.Set("Prefer", "respond-async")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var accepted struct {
+			JobID string `json:"job_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+			t.Fatal(err)
+		}
+		if len(accepted.JobID) != len("op-")+32 || seen[accepted.JobID] {
+			t.Errorf("job ID %q is not a fresh random ID", accepted.JobID)
+		}
+		seen[accepted.JobID] = true
+	}
+}
+
+func TestRespondWithinBudget(t *testing.T) {
+	t.Run("no budget", func(t *testing.T) {
+		router := newTestRouter(newTestRunner(t, nil), func(ctx context.Context) (int, any) { return http.StatusCreated, gin.H{"ok": true} })
+		if w := serve(router, http.MethodPost, "/op", ""); w.Code != http.StatusCreated {
+			t.Errorf("POST = %d, want the operation's 201", w.Code)
+		}
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		release := make(chan struct{})
+		budgets := map[string]configdomain.LatencyBudget{"analyze": {Seconds: 1}}
+		router := newTestRouter(newTestRunner(t, budgets), func(ctx context.Context) (int, any) {
+			<-release
+			return http.StatusOK, gin.H{"ok": true}
+		})
+
+		w := serve(router, http.MethodPost, "/op", "alice")
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("POST = %d, want 202: %s", w.Code, w.Body)
+		}
+		var pending struct {
+			Pending     bool   `json:"pending"`
+			OperationID string `json:"operation_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &pending); err != nil {
+			t.Fatal(err)
+		}
+		if !pending.Pending {
+			t.Fatalf("POST = %s, want a pending result", w.Body)
+		}
+		target := "/api/refine/pending/" + pending.OperationID + "?wait=0"
+		if w := serve(router, http.MethodGet, target, "bob"); w.Code != http.StatusNotFound {
+			t.Errorf("GET pending of another user = %d, want 404", w.Code)
+		}
+		if w := serve(router, http.MethodGet, target, "alice"); w.Code != http.StatusAccepted {
+			t.Errorf("GET pending while running = %d, want 202", w.Code)
+		}
+
+		close(release)
+		if w := serve(router, http.MethodGet, "/api/refine/pending/"+pending.OperationID+"?wait=5", "alice"); w.Code != http.StatusOK {
+			t.Errorf("GET pending once finished = %d, want 200: %s", w.Code, w.Body)
+		}
+		if w := serve(router, http.MethodGet, target, "alice"); w.Code != http.StatusNotFound {
+			t.Errorf("GET collected result = %d, want 404", w.Code)
+		}
+	})
+}
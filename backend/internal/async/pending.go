@@ -0,0 +1,232 @@
+// Package async runs operations that may outlast their request: ones exceeding their latency budget
+// keep running as pending operations the client keeps waiting on, and clients asking for async
+// processing get an async job to poll.
+package async
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultPendingWait is how long a pending result request waits without a `wait` parameter.
+	defaultPendingWait = 30 * time.Second
+	maxPendingWait     = 2 * time.Minute
+	// pendingRetention is how long finished results stay available for clients that stopped waiting.
+	pendingRetention = time.Hour
+)
+
+// Runner runs the operations of requests within their latency budgets, and as async jobs on request.
+type Runner struct {
+	appConfigService config.AppConfigService
+	pending          *pendingOperations
+	jobs             *asyncJobs
+}
+
+// NewRunner creates a Runner running async jobs on asyncWorkers workers, or on the default number of
+// workers when it is not positive.
+func NewRunner(appConfigService config.AppConfigService, asyncWorkers int) *Runner {
+	return &Runner{appConfigService: appConfigService, pending: newPendingOperations(), jobs: newAsyncJobs(asyncWorkers)}
+}
+
+// pendingOperation is an operation that outlived its latency budget and keeps running in the background.
+type pendingOperation struct {
+	id         string
+	operation  string
+	owner      string // X-User-ID of the request that started it
+	done       chan struct{}
+	detach     func() bool // Stops the request's context from cancelling the operation
+	status     int
+	body       any
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// pendingOperations tracks operations whose requests stopped waiting for them.
+type pendingOperations struct {
+	mu  sync.Mutex
+	ops map[string]*pendingOperation
+}
+
+func newPendingOperations() *pendingOperations {
+	return &pendingOperations{ops: make(map[string]*pendingOperation)}
+}
+
+// start runs an operation in the background for the request's user. The operation is cancelled when
+// ctx ends, until it is detached from it.
+func (p *pendingOperations) start(c *gin.Context, operation string, run func(ctx context.Context) (int, any)) (*pendingOperation, error) {
+	return p.startWhenFree(c, operation, nil, run)
+}
+
+// startWhenFree is start for an operation that first waits for one of the slots, if any, holding it
+// while it runs.
+func (p *pendingOperations) startWhenFree(c *gin.Context, operation string, slots chan struct{}, run func(ctx context.Context) (int, any)) (*pendingOperation, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate operation ID: %w", err)
+	}
+	ctx := c.Request.Context()
+	opCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	op := &pendingOperation{
+		id:        "op-" + hex.EncodeToString(b),
+		operation: operation,
+		owner:     c.GetHeader("X-User-ID"),
+		done:      make(chan struct{}),
+		detach:    context.AfterFunc(ctx, cancel),
+		createdAt: time.Now(),
+	}
+	go func() {
+		defer cancel()
+		if slots != nil {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+		}
+		p.mu.Lock()
+		op.startedAt = time.Now()
+		p.mu.Unlock()
+		status, body := safeRun(opCtx, operation, run)
+		p.mu.Lock()
+		op.status, op.body, op.finishedAt = status, body, time.Now()
+		p.mu.Unlock()
+		close(op.done)
+	}()
+	return op, nil
+}
+
+// safeRun runs an operation, turning a panic into a 500 result so its waiters are not left hanging.
+func safeRun(ctx context.Context, operation string, run func(ctx context.Context) (int, any)) (status int, body any) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.From(ctx).Error().Str("operation", operation).Interface("panic", r).Msg("Operation panicked")
+			status, body = http.StatusInternalServerError, gin.H{"error": "Internal error in " + operation}
+		}
+	}()
+	return run(ctx)
+}
+
+// track keeps an operation available under its ID, dropping results nobody collected in time.
+func (p *pendingOperations) track(op *pendingOperation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, existing := range p.ops {
+		if !existing.finishedAt.IsZero() && time.Since(existing.finishedAt) > pendingRetention {
+			delete(p.ops, id)
+		}
+	}
+	p.ops[op.id] = op
+}
+
+// get returns an operation started by the request's user. Operations of other users are not found, so
+// their results do not leak.
+func (p *pendingOperations) get(c *gin.Context, id string) (*pendingOperation, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	op, ok := p.ops[id]
+	if !ok || op.owner != c.GetHeader("X-User-ID") {
+		return nil, false
+	}
+	return op, true
+}
+
+func (p *pendingOperations) remove(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.ops, id)
+}
+
+// wait waits for an operation to finish, reporting whether it did.
+func (op *pendingOperation) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-op.done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// pendingResponse tells the client the operation is still running and where to keep waiting for it.
+func (op *pendingOperation) pendingResponse() gin.H {
+	return gin.H{
+		"pending":      true,
+		"operation_id": op.id,
+		"operation":    op.operation,
+		"message":      "The AI is taking longer than usual. You can keep waiting for the result.",
+		"continue_url": "/api/refine/pending/" + op.id,
+	}
+}
+
+// RespondWithinBudget runs an operation and responds with its result. When the operation takes longer
+// than its latency budget, it keeps running and the response is a pending result instead, which the
+// client can keep waiting on with PendingResultHandler. Clients asking for async processing get an async
+// job right away. The operation is cancelled when the client disconnects while waiting for it.
+func (r *Runner) RespondWithinBudget(c *gin.Context, operation string, run func(ctx context.Context) (int, any)) {
+	if wantsAsync(c) {
+		r.respondAsync(c, operation, run)
+		return
+	}
+
+	var wait time.Duration
+	if appConfig, err := r.appConfigService.LoadAppConfig(); err != nil {
+		logging.From(c.Request.Context()).Warn().Err(err).Msg("Ignoring latency budget, failed to load app config")
+	} else {
+		wait = configdomain.LatencyBudgetFor(appConfig.LatencyBudgets, operation).Wait()
+	}
+	if wait <= 0 {
+		c.JSON(run(c.Request.Context()))
+		return
+	}
+
+	op, err := r.pending.start(c, operation, run)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start " + operation + ": " + err.Error()})
+		return
+	}
+	if op.wait(wait) {
+		c.JSON(op.status, op.body)
+		return
+	}
+	if c.Request.Context().Err() != nil {
+		logging.From(c.Request.Context()).Warn().Str("operation", operation).Msg("Client disconnected, cancelled the operation")
+		return
+	}
+	op.detach() // The pending operation outlives this request
+	logging.From(c.Request.Context()).Warn().Str("operation", operation).Str("pending_id", op.id).Msg("Operation exceeded its latency budget, returning a pending result")
+	r.pending.track(op)
+	c.JSON(http.StatusAccepted, op.pendingResponse())
+}
+
+// PendingResultHandler waits for an operation that exceeded its latency budget, up to the `wait` query
+// parameter in seconds. It responds with the operation's result once finished, or with the pending
+// result again.
+func (r *Runner) PendingResultHandler(c *gin.Context) {
+	op, ok := r.pending.get(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending operation not found"})
+		return
+	}
+	wait := defaultPendingWait
+	if seconds, err := strconv.Atoi(c.Query("wait")); err == nil && seconds >= 0 {
+		wait = min(time.Duration(seconds)*time.Second, maxPendingWait)
+	}
+	if !op.wait(wait) {
+		c.JSON(http.StatusAccepted, op.pendingResponse())
+		return
+	}
+	r.pending.remove(op.id)
+	c.JSON(op.status, op.body)
+}
@@ -0,0 +1,234 @@
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fault types requested with the X-Chaos-Fault header.
+const (
+	FaultRateLimit   = "rate_limit"   // Provider calls fail with 429 Too Many Requests
+	FaultServerError = "server_error" // Provider calls fail with 500 Internal Server Error
+	FaultMalformed   = "malformed"    // The text of provider responses is cut in half
+)
+
+var enabled atomic.Bool
+
+// Enable turns on fault injection, e.g. from the CHAOS_TESTING env var. It is meant for staging only:
+// while enabled, any client can slow down or break the AI calls of its own requests.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether fault injection is turned on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// faults are the faults injected into the provider calls of a request.
+type faults struct {
+	latency time.Duration
+	fault   string
+	// Provider calls left to fail; negative fails every call
+	remaining atomic.Int64
+}
+
+type contextKey struct{}
+
+// Middleware reads the faults to inject into the provider calls of a request from its headers:
+// X-Chaos-Latency delays each call by a duration such as "2s", X-Chaos-Fault fails or corrupts the
+// calls with one of the fault types, and X-Chaos-Fault-Count limits the fault to the first calls, so
+// retries can recover. Requests with invalid chaos headers are rejected with 400 Bad Request.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+		f, err := parseFaults(c.Request.Header)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid chaos headers: " + err.Error()})
+			return
+		}
+		if f != nil {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, f))
+		}
+		c.Next()
+	}
+}
+
+// parseFaults returns the faults requested by the headers, or nil when none is.
+func parseFaults(header http.Header) (*faults, error) {
+	latency, fault, count := header.Get("X-Chaos-Latency"), header.Get("X-Chaos-Fault"), header.Get("X-Chaos-Fault-Count")
+	if latency == "" && fault == "" {
+		return nil, nil
+	}
+	f := &faults{fault: fault}
+	if latency != "" {
+		d, err := time.ParseDuration(latency)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("X-Chaos-Latency %q is not a duration", latency)
+		}
+		f.latency = d
+	}
+	switch fault {
+	case "", FaultRateLimit, FaultServerError, FaultMalformed:
+	default:
+		return nil, fmt.Errorf("X-Chaos-Fault %q, expected %q, %q or %q", fault, FaultRateLimit, FaultServerError, FaultMalformed)
+	}
+	f.remaining.Store(-1)
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("X-Chaos-Fault-Count %q is not a count", count)
+		}
+		f.remaining.Store(int64(n))
+	}
+	return f, nil
+}
+
+// Carry returns ctx with the faults of the request context from, for work that outlives the request,
+// such as async jobs.
+func Carry(ctx, from context.Context) context.Context {
+	if f, ok := from.Value(contextKey{}).(*faults); ok {
+		return context.WithValue(ctx, contextKey{}, f)
+	}
+	return ctx
+}
+
+// take reports whether the next provider call should get the fault.
+func (f *faults) take() bool {
+	for {
+		n := f.remaining.Load()
+		if n == 0 {
+			return false
+		}
+		if n < 0 || f.remaining.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// transport injects the faults of the request context into the requests it sends.
+type transport struct {
+	base http.RoundTripper
+}
+
+// Transport wraps the transport of the HTTP client calling an AI provider, so the faults requested for
+// a request are injected into its provider calls. base nil stands for http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+// RoundTrip sends the request after the injected latency, or answers it with the injected fault.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, ok := req.Context().Value(contextKey{}).(*faults)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	if f.latency > 0 {
+		timer := time.NewTimer(f.latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+	if f.fault == "" || !f.take() {
+		return t.base.RoundTrip(req)
+	}
+
+	switch f.fault {
+	case FaultRateLimit:
+		return errorResponse(req, http.StatusTooManyRequests, "rate_limit_exceeded", "Rate limit injected by chaos testing"), nil
+	case FaultServerError:
+		return errorResponse(req, http.StatusInternalServerError, "server_error", "Server error injected by chaos testing"), nil
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode >= 300 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = truncateText(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
+
+// errorResponse returns a provider error response in the format of the OpenAI API.
+func errorResponse(req *http.Request, status int, code, message string) *http.Response {
+	body, _ := json.Marshal(map[string]any{"error": map[string]any{"message": message, "type": code, "code": code}})
+	header := http.Header{"Content-Type": {"application/json"}}
+	if status == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// textFields are the fields holding generated text in provider responses: message text values, chat
+// completion contents and plain completion texts.
+var textFields = map[string]bool{"value": true, "content": true, "text": true}
+
+// truncateText cuts the generated text of a JSON response in half, as an answer cut off mid-way.
+// Responses that are not JSON objects are returned as they are.
+func truncateText(body []byte) []byte {
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Keeps IDs and timestamps as they are
+	if err := decoder.Decode(&doc); err != nil {
+		return body
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, field := range v {
+				if s, ok := field.(string); ok && textFields[k] {
+					r := []rune(s)
+					v[k] = string(r[:len(r)/2])
+					continue
+				}
+				walk(field)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(doc)
+	truncated, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return truncated
+}
@@ -0,0 +1,157 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseFaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantNil       bool
+		wantLatency   time.Duration
+		wantFault     string
+		wantRemaining int64
+		wantErr       bool
+	}{
+		{name: "no chaos headers", wantNil: true},
+		{name: "fault count alone", headers: map[string]string{"X-Chaos-Fault-Count": "2"}, wantNil: true},
+		{name: "latency", headers: map[string]string{"X-Chaos-Latency": "2s"}, wantLatency: 2 * time.Second, wantRemaining: -1},
+		{name: "fault on every call", headers: map[string]string{"X-Chaos-Fault": FaultRateLimit}, wantFault: FaultRateLimit, wantRemaining: -1},
+		{name: "fault on the first calls", headers: map[string]string{"X-Chaos-Fault": FaultServerError, "X-Chaos-Fault-Count": "2"}, wantFault: FaultServerError, wantRemaining: 2},
+		{name: "invalid latency", headers: map[string]string{"X-Chaos-Latency": "soon"}, wantErr: true},
+		{name: "negative latency", headers: map[string]string{"X-Chaos-Latency": "-1s"}, wantErr: true},
+		{name: "unknown fault", headers: map[string]string{"X-Chaos-Fault": "timeout"}, wantErr: true},
+		{name: "invalid count", headers: map[string]string{"X-Chaos-Fault": FaultMalformed, "X-Chaos-Fault-Count": "-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.headers {
+				header.Set(name, value)
+			}
+			f, err := parseFaults(header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (f == nil) != tt.wantNil {
+				t.Fatalf("parseFaults() = %v, want nil %v", f, tt.wantNil)
+			}
+			if f == nil {
+				return
+			}
+			if f.latency != tt.wantLatency || f.fault != tt.wantFault || f.remaining.Load() != tt.wantRemaining {
+				t.Errorf("parseFaults() = latency %v, fault %q, remaining %d, want %v, %q, %d", f.latency, f.fault, f.remaining.Load(), tt.wantLatency, tt.wantFault, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"msg_1","created_at":1700000000,"content":[{"text":{"value":"abcdef"}}]}`)
+	}))
+	defer provider.Close()
+
+	tests := []struct {
+		name       string
+		fault      string
+		count      string
+		wantStatus []int // Of consecutive calls
+		wantBody   string
+	}{
+		{name: "no fault", wantStatus: []int{200}, wantBody: `"value":"abcdef"`},
+		{name: "rate limit", fault: FaultRateLimit, wantStatus: []int{429, 429}},
+		{name: "server error on the first call", fault: FaultServerError, count: "1", wantStatus: []int{500, 200}},
+		{name: "malformed", fault: FaultMalformed, wantStatus: []int{200}, wantBody: `"value":"abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("X-Chaos-Latency", "1ms")
+			if tt.fault != "" {
+				header.Set("X-Chaos-Fault", tt.fault)
+			}
+			if tt.count != "" {
+				header.Set("X-Chaos-Fault-Count", tt.count)
+			}
+			f, err := parseFaults(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.WithValue(context.Background(), contextKey{}, f)
+			client := &http.Client{Transport: Transport(nil)}
+			for i, want := range tt.wantStatus {
+				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, provider.URL, nil)
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("call %d failed: %v", i+1, err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != want {
+					t.Errorf("call %d status = %d, want %d", i+1, resp.StatusCode, want)
+				}
+				if tt.wantBody != "" && !strings.Contains(string(body), tt.wantBody) {
+					t.Errorf("call %d body = %s, want it to contain %s", i+1, body, tt.wantBody)
+				}
+				if want == 200 && !strings.Contains(string(body), `"created_at":1700000000`) {
+					t.Errorf("call %d body = %s, want the non-text fields unchanged", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer Enable(false)
+	tests := []struct {
+		name       string
+		enabled    bool
+		fault      string
+		wantStatus int
+		wantFaults bool
+	}{
+		{name: "disabled", fault: FaultRateLimit, wantStatus: http.StatusOK},
+		{name: "disabled with invalid headers", fault: "unknown", wantStatus: http.StatusOK},
+		{name: "enabled", enabled: true, fault: FaultRateLimit, wantStatus: http.StatusOK, wantFaults: true},
+		{name: "enabled without faults", enabled: true, wantStatus: http.StatusOK},
+		{name: "enabled with invalid headers", enabled: true, fault: "unknown", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Enable(tt.enabled)
+			router := gin.New()
+			router.Use(Middleware())
+			gotFaults := false
+			router.GET("/", func(c *gin.Context) {
+				_, gotFaults = Carry(context.Background(), c.Request.Context()).Value(contextKey{}).(*faults)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.fault != "" {
+				req.Header.Set("X-Chaos-Fault", tt.fault)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotFaults != tt.wantFaults {
+				t.Errorf("faults injected = %v, want %v", gotFaults, tt.wantFaults)
+			}
+		})
+	}
+}
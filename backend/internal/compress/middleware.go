@@ -0,0 +1,168 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// minSize is the smallest response body worth compressing.
+const minSize = 1024
+
+// compressibleTypes are the content type prefixes that are compressed.
+var compressibleTypes = []string{"application/json", "text/", "application/javascript", "application/xml"}
+
+// Middleware compresses response bodies with brotli or gzip, whichever the client prefers of those it
+// accepts. Small bodies, already encoded bodies and WebSocket upgrades are passed through unchanged.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiate(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		c.Header("Vary", "Accept-Encoding")
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, status: http.StatusOK}
+		c.Writer = w
+		defer w.close()
+		c.Next()
+	}
+}
+
+// negotiate picks the encoding to use for an Accept-Encoding header, preferring brotli over gzip.
+func negotiate(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	switch {
+	case accepted["br"]:
+		return "br"
+	case accepted["gzip"]:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the start of a response until it knows whether the body is worth compressing.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	decided  bool
+	encoder  io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.decide()
+}
+
+func (w *compressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < minSize {
+			return len(data), nil
+		}
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if err := w.flushBuffer(); err != nil {
+		return
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide writes the response header, compressing the body if it is large enough and of a compressible type.
+func (w *compressWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	header := w.Header()
+	if len(w.buf) >= minSize && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		if w.encoding == "br" {
+			w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			w.encoder = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// flushBuffer decides on compression and writes out what has been buffered so far.
+func (w *compressWriter) flushBuffer() error {
+	w.decide()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close writes out any buffered body and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if err := w.flushBuffer(); err != nil {
+		return
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
+
+// compressible reports whether a content type is worth compressing.
+func compressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
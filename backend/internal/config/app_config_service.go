@@ -2,74 +2,226 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/jsonfile"
+	"sofa-commander/backend/internal/jsonl"
+	"sofa-commander/backend/internal/logging"
 )
 
+// ErrFormatExampleNotFound is returned when a phase has no format example for a role.
+var ErrFormatExampleNotFound = errors.New("format example not found")
+
 // AppConfigService defines the interface for application configuration management.
 type AppConfigService interface {
 	LoadAppConfig() (*domain.AppConfig, error)
 	SaveAppConfig(config *domain.AppConfig) error
+	UpdateAppConfig(update func(config *domain.AppConfig) error) error
+	UpdateAppConfigBy(author string, update func(config *domain.AppConfig) error) error
+	ListVersions() ([]domain.AppConfigVersion, error)
+	GetVersion(version int) (*domain.AppConfigVersion, error)
+	Rollback(version int, author string) (*domain.AppConfigVersion, error)
+	AddRoleExemplar(role string, exemplar domain.RoleExemplar) (*domain.RoleExemplar, error)
+	DeleteRoleExemplar(role, exemplarID string) error
+	AddPhaseFormatExample(phase string, example domain.PhaseFormatExample) error
+	UpdatePhaseFormatExample(phase, role string, example domain.PhaseFormatExample) error
+	DeletePhaseFormatExample(phase, role string) error
 }
 
 // appConfigService is the implementation of AppConfigService.
 type appConfigService struct {
 	configPath string
+	versions   *jsonl.Store[domain.AppConfigVersion] // The saved versions, appended to a JSON Lines file
+	mu         sync.Mutex                            // Serializes writes, including read-modify-write updates
+	versionsMu sync.Mutex                            // Serializes appending versions
 }
 
-// NewAppConfigService creates a new instance of appConfigService.
-func NewAppConfigService(configPath string) AppConfigService {
-	return &appConfigService{configPath: configPath}
+// NewAppConfigService creates a new instance of appConfigService keeping the version history of the
+// configuration at versionsPath.
+func NewAppConfigService(configPath, versionsPath string) AppConfigService {
+	return &appConfigService{configPath: configPath, versions: jsonl.NewStore[domain.AppConfigVersion](versionsPath, "app config version")}
 }
 
 // LoadAppConfig loads the application configuration from the configured JSON file.
 func (s *appConfigService) LoadAppConfig() (*domain.AppConfig, error) {
-	fmt.Println("[DEBUG] LoadAppConfig called, configPath:", s.configPath)
 	absPath, err := filepath.Abs(s.configPath)
-	fmt.Println("[DEBUG] Absolute config path:", absPath, "err:", err)
 	if err != nil {
-		fmt.Println("[ERROR] Failed to get absolute path:", err)
 		return nil, fmt.Errorf("failed to get absolute path for %s: %w", s.configPath, err)
 	}
 
 	data, err := ioutil.ReadFile(absPath)
-	fmt.Println("[DEBUG] ReadFile result, bytes:", len(data), "err:", err)
 	if err != nil {
-		fmt.Println("[ERROR] Failed to read app config file:", err)
+		logging.Default().Error().Err(err).Str("path", absPath).Msg("Failed to read app config file")
 		return nil, fmt.Errorf("failed to read app config file %s: %w", absPath, err)
 	}
 
 	var appConfig domain.AppConfig
-	err = json.Unmarshal(data, &appConfig)
-	fmt.Println("[DEBUG] Unmarshal result, err:", err)
-	if err != nil {
-		fmt.Println("[ERROR] Failed to unmarshal app config:", err)
+	if err := json.Unmarshal(data, &appConfig); err != nil {
+		logging.Default().Error().Err(err).Str("path", absPath).Msg("Failed to unmarshal app config")
 		return nil, fmt.Errorf("failed to unmarshal app config from %s: %w", absPath, err)
 	}
 
-	fmt.Println("[DEBUG] LoadAppConfig success")
+	logging.Default().Debug().Str("path", absPath).Int("bytes", len(data)).Msg("Loaded app config")
 	return &appConfig, nil
 }
 
-// SaveAppConfig saves the application configuration to the configured JSON file.
+// SaveAppConfig saves the application configuration to the configured JSON file, and records it as a
+// new version.
 func (s *appConfigService) SaveAppConfig(appConfig *domain.AppConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.save(appConfig, "", "")
+	return err
+}
+
+// save writes the configuration and records it as a version by the author, returning the version. The
+// configuration found before the first recorded save is recorded first, so it can be rolled back to.
+// Callers must hold mu.
+func (s *appConfigService) save(appConfig *domain.AppConfig, author, note string) (*domain.AppConfigVersion, error) {
+	if err := s.recordInitialVersion(); err != nil {
+		return nil, err
+	}
+	if err := s.writeAppConfig(appConfig); err != nil {
+		return nil, err
+	}
+	return s.recordVersion(appConfig, author, note)
+}
+
+// writeAppConfig writes the configuration to the configured JSON file, through a temporary file so
+// readers never see a partly written configuration.
+func (s *appConfigService) writeAppConfig(appConfig *domain.AppConfig) error {
 	absPath, err := filepath.Abs(s.configPath)
 	if err != nil {
 		return fmt.Errorf("failed to get absolute path for %s: %w", s.configPath, err)
 	}
+	return jsonfile.NewStore[*domain.AppConfig](absPath, "app config").Store(appConfig)
+}
+
+// UpdateAppConfig atomically loads the configuration, applies the update and saves it.
+func (s *appConfigService) UpdateAppConfig(update func(config *domain.AppConfig) error) error {
+	return s.UpdateAppConfigBy("", update)
+}
 
-	data, err := json.MarshalIndent(appConfig, "", "  ")
+// UpdateAppConfigBy is UpdateAppConfig recording the author of the new version, e.g. the X-User-ID of
+// the request.
+func (s *appConfigService) UpdateAppConfigBy(author string, update func(config *domain.AppConfig) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appConfig, err := s.LoadAppConfig()
 	if err != nil {
-		return fmt.Errorf("failed to marshal app config: %w", err)
+		return err
+	}
+	if err := update(appConfig); err != nil {
+		return err
+	}
+	_, err = s.save(appConfig, author, "")
+	return err
+}
+
+// AddRoleExemplar appends a few-shot exemplar to a role and saves the configuration.
+func (s *appConfigService) AddRoleExemplar(role string, exemplar domain.RoleExemplar) (*domain.RoleExemplar, error) {
+	if strings.TrimSpace(exemplar.Question) == "" {
+		return nil, fmt.Errorf("exemplar question is required")
 	}
+	exemplar.ID = fmt.Sprintf("ex-%d", time.Now().UnixNano())
 
-	err = ioutil.WriteFile(absPath, data, 0644)
+	err := s.UpdateAppConfig(func(appConfig *domain.AppConfig) error {
+		if _, ok := appConfig.RolePrompts[role]; !ok {
+			return fmt.Errorf("role %s is not defined in role_prompts", role)
+		}
+		if appConfig.RoleExemplars == nil {
+			appConfig.RoleExemplars = make(map[string][]domain.RoleExemplar)
+		}
+		appConfig.RoleExemplars[role] = append(appConfig.RoleExemplars[role], exemplar)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to write app config to file %s: %w", absPath, err)
+		return nil, err
 	}
+	return &exemplar, nil
+}
+
+// DeleteRoleExemplar removes an exemplar from a role and saves the configuration.
+func (s *appConfigService) DeleteRoleExemplar(role, exemplarID string) error {
+	return s.UpdateAppConfig(func(appConfig *domain.AppConfig) error {
+		exemplars := appConfig.RoleExemplars[role]
+		for i := range exemplars {
+			if exemplars[i].ID == exemplarID {
+				appConfig.RoleExemplars[role] = append(exemplars[:i], exemplars[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("exemplar %s not found for role %s", exemplarID, role)
+	})
+}
+
+// AddPhaseFormatExample adds the format example of a role to a phase and saves the configuration. A phase
+// has one example per role.
+func (s *appConfigService) AddPhaseFormatExample(phase string, example domain.PhaseFormatExample) error {
+	return s.UpdateAppConfig(func(appConfig *domain.AppConfig) error {
+		if err := checkFormatExample(appConfig, phase, example); err != nil {
+			return err
+		}
+		if slices.ContainsFunc(appConfig.PhaseFormatExamples[phase], func(e domain.PhaseFormatExample) bool { return e.Role == example.Role }) {
+			return fmt.Errorf("phase %s already has a format example for role %s", phase, example.Role)
+		}
+		if appConfig.PhaseFormatExamples == nil {
+			appConfig.PhaseFormatExamples = make(map[string][]domain.PhaseFormatExample)
+		}
+		appConfig.PhaseFormatExamples[phase] = append(appConfig.PhaseFormatExamples[phase], example)
+		return nil
+	})
+}
 
+// UpdatePhaseFormatExample replaces the format example of a role in a phase and saves the configuration.
+func (s *appConfigService) UpdatePhaseFormatExample(phase, role string, example domain.PhaseFormatExample) error {
+	example.Role = role
+	return s.UpdateAppConfig(func(appConfig *domain.AppConfig) error {
+		i := slices.IndexFunc(appConfig.PhaseFormatExamples[phase], func(e domain.PhaseFormatExample) bool { return e.Role == role })
+		if i < 0 {
+			return fmt.Errorf("%w: phase %s, role %s", ErrFormatExampleNotFound, phase, role)
+		}
+		if err := checkFormatExample(appConfig, phase, example); err != nil {
+			return err
+		}
+		appConfig.PhaseFormatExamples[phase][i] = example
+		return nil
+	})
+}
+
+// DeletePhaseFormatExample removes the format example of a role from a phase and saves the configuration.
+func (s *appConfigService) DeletePhaseFormatExample(phase, role string) error {
+	return s.UpdateAppConfig(func(appConfig *domain.AppConfig) error {
+		examples := appConfig.PhaseFormatExamples[phase]
+		remaining := slices.DeleteFunc(slices.Clone(examples), func(e domain.PhaseFormatExample) bool { return e.Role == role })
+		if len(remaining) == len(examples) {
+			return fmt.Errorf("%w: phase %s, role %s", ErrFormatExampleNotFound, phase, role)
+		}
+		appConfig.PhaseFormatExamples[phase] = remaining
+		return nil
+	})
+}
+
+// checkFormatExample checks that an example is well-formed, for a defined role and a phase whose
+// examples are shown to the assistant.
+func checkFormatExample(appConfig *domain.AppConfig, phase string, example domain.PhaseFormatExample) error {
+	if keys := domain.FormatExampleKeys(appConfig.Workflow.Resolved()); !slices.Contains(keys, phase) {
+		return fmt.Errorf("phase %s takes no format examples, phases with examples are %s", phase, strings.Join(keys, ", "))
+	}
+	if err := example.Validate(); err != nil {
+		return err
+	}
+	if _, ok := appConfig.RolePrompts[example.Role]; !ok {
+		return fmt.Errorf("role %s is not defined in role_prompts", example.Role)
+	}
 	return nil
 }
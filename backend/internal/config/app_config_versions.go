@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"sofa-commander/backend/internal/features/config/domain"
+)
+
+// ErrVersionNotFound is returned for a version missing from the version history.
+var ErrVersionNotFound = errors.New("app config version not found")
+
+// ListVersions returns the version history of the configuration, oldest first, without the
+// configurations themselves.
+func (s *appConfigService) ListVersions() ([]domain.AppConfigVersion, error) {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	versions, err := s.readVersions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		versions[i].Config = nil
+	}
+	return versions, nil
+}
+
+// GetVersion returns a version of the configuration.
+func (s *appConfigService) GetVersion(version int) (*domain.AppConfigVersion, error) {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	versions, err := s.readVersions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrVersionNotFound, version)
+}
+
+// Rollback saves an earlier version of the configuration as a new version by the author, and returns the
+// new version. Versions are stored without secrets, so the current secrets are kept, as are the current
+// auth settings and reviewers, which are managed through the admin API only.
+func (s *appConfigService) Rollback(version int, author string) (*domain.AppConfigVersion, error) {
+	target, err := s.GetVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, err := s.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	restored := *target.Config
+	restored.KeepSecrets(current)
+	restored.Auth = current.Auth
+	restored.Approval = current.Approval
+	return s.save(&restored, author, fmt.Sprintf("rollback to version %d", version))
+}
+
+// recordInitialVersion records the configuration file as the first version while the history is empty,
+// e.g. a configuration deployed or edited by hand before versions were recorded.
+func (s *appConfigService) recordInitialVersion() error {
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	versions, err := s.readVersions()
+	if err != nil || len(versions) > 0 {
+		return err
+	}
+	appConfig, err := s.LoadAppConfig()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return s.versions.Append(domain.AppConfigVersion{Version: 1, SavedAt: time.Now(), Note: "initial", Config: appConfig.WithoutSecrets()})
+}
+
+// recordVersion appends the saved configuration, without its secrets, to the history, unless it is the
+// same as the latest version, which is returned instead.
+func (s *appConfigService) recordVersion(appConfig *domain.AppConfig, author, note string) (*domain.AppConfigVersion, error) {
+	appConfig = appConfig.WithoutSecrets()
+	s.versionsMu.Lock()
+	defer s.versionsMu.Unlock()
+	versions, err := s.readVersions()
+	if err != nil {
+		return nil, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		previous, err := json.Marshal(latest.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal app config version: %w", err)
+		}
+		saved, err := json.Marshal(appConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal app config: %w", err)
+		}
+		if bytes.Equal(previous, saved) {
+			return &latest, nil
+		}
+		next = latest.Version + 1
+	}
+	version := domain.AppConfigVersion{Version: next, SavedAt: time.Now(), Author: author, Note: note, Config: appConfig}
+	if err := s.versions.Append(version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// readVersions reads the version history, oldest first. Callers must hold versionsMu.
+func (s *appConfigService) readVersions() ([]domain.AppConfigVersion, error) {
+	return s.versions.List(nil)
+}
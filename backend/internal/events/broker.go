@@ -0,0 +1,65 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sofa-commander/backend/internal/jobs"
+	"sofa-commander/backend/internal/logging"
+)
+
+// Broker delivers events to an external message broker.
+type Broker interface {
+	Name() string
+	Send(event Event) error
+}
+
+// QueuedPublisher is a Publisher forwarding events to a Broker through the job queue, so events are
+// delivered at least once: a failed delivery is retried with backoff and survives restarts. Consumers
+// deduplicate redelivered events by their ID.
+type QueuedPublisher struct {
+	broker  Broker
+	queue   jobs.Queue
+	jobType string
+}
+
+// NewQueuedPublisher creates a QueuedPublisher and registers its delivery job.
+func NewQueuedPublisher(broker Broker, queue jobs.Queue) *QueuedPublisher {
+	p := &QueuedPublisher{broker: broker, queue: queue, jobType: "events.publish." + broker.Name()}
+	queue.Register(p.jobType, p.deliver)
+	return p
+}
+
+// Publish enqueues the event for delivery; it is a Handler, subscribe it to "*" to forward everything.
+func (p *QueuedPublisher) Publish(event Event) {
+	if err := p.queue.Enqueue(p.jobType, event); err != nil {
+		logging.Default().Error().Err(err).Str("event_type", event.Type).Str("session_id", event.SessionID).Str("broker", p.broker.Name()).Msg("Failed to enqueue event")
+	}
+}
+
+func (p *QueuedPublisher) deliver(payload json.RawMessage) error {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("invalid event payload: %w", err)
+	}
+	return p.broker.Send(event)
+}
+
+// NewBroker creates the broker of the given kind: "kafka" sends to the topic on the comma-separated
+// Kafka broker addresses, "nats" publishes on "<topic>.<event type>" on the NATS server at address.
+func NewBroker(kind, address, topic string) (Broker, error) {
+	if address == "" {
+		return nil, fmt.Errorf("an address is required for the %s event broker", kind)
+	}
+	if topic == "" {
+		topic = "sofa-commander"
+	}
+	switch kind {
+	case "kafka":
+		return NewKafkaBroker(address, topic), nil
+	case "nats":
+		return NewNATSBroker(address, topic)
+	default:
+		return nil, fmt.Errorf("unsupported event broker %q", kind)
+	}
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/logging"
+)
+
+// Event types published by the refinement flow.
+const (
+	SessionStarted       = "session.started"
+	SessionForked        = "session.forked"
+	QuestionsGenerated   = "session.questions_generated"
+	SuggestionsGenerated = "session.suggestions_generated"
+	SuggestionsAccepted  = "session.suggestions_accepted"
+	PhaseAdvanced        = "session.phase_advanced"
+	RoundUndone          = "session.round_undone"
+	SessionFinalized     = "session.finalized"
+	ReviewRequested      = "session.review_requested"
+	SessionApproved      = "session.approved"
+	ChangesRequested     = "session.changes_requested"
+	RunFailed            = "session.run_failed"
+	RunOverBudget        = "session.run_over_budget"
+	SessionPruned        = "session.pruned"
+	PromptDrifted        = "session.prompt_drifted"
+)
+
+// Event is a domain event describing something that happened to a session.
+type Event struct {
+	ID          string         `json:"id,omitempty"`
+	Type        string         `json:"type"`
+	SessionID   string         `json:"session_id"`
+	WorkspaceID string         `json:"workspace_id,omitempty"`
+	UserID      string         `json:"user_id,omitempty"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Data        map[string]any `json:"data,omitempty"`
+}
+
+// Publisher publishes domain events.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Handler handles a published event.
+type Handler func(event Event)
+
+// Bus is an in-process Publisher dispatching events to subscribed handlers asynchronously.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler // Keyed by event type, "*" receives every event
+}
+
+// NewBus creates a new event bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers a handler for an event type, or for every event with "*".
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish dispatches the event to its subscribers without blocking the caller.
+func (b *Bus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("evt-%d", event.OccurredAt.UnixNano())
+	}
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[event.Type]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.Default().Error().Str("event_type", event.Type).Str("session_id", event.SessionID).Interface("panic", r).Msg("Event handler panicked")
+				}
+			}()
+			h(event)
+		}(h)
+	}
+}
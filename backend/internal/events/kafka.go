@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBroker sends events to a Kafka topic, keyed by session so a session's events stay ordered.
+type kafkaBroker struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaBroker creates a broker writing to the topic on the comma-separated broker addresses.
+func NewKafkaBroker(addresses, topic string) Broker {
+	return &kafkaBroker{writer: &kafka.Writer{
+		Addr:         kafka.TCP(strings.Split(addresses, ",")...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		WriteTimeout: 10 * time.Second,
+	}}
+}
+
+func (b *kafkaBroker) Name() string { return "kafka" }
+
+// Send writes the event and waits for all in-sync replicas to acknowledge it.
+func (b *kafkaBroker) Send(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err = b.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.SessionID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event_id", Value: []byte(event.ID)},
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write event to kafka: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"sofa-commander/backend/internal/jsonl"
+	"sofa-commander/backend/internal/logging"
+)
+
+// Log appends every event it records to a JSON Lines file, keeping a durable trail of session activity,
+// including the rounds pruned from oversized sessions.
+type Log struct {
+	events *jsonl.Store[Event]
+}
+
+// NewLog creates an event log backed by the given JSON Lines file.
+func NewLog(path string) *Log {
+	return &Log{events: jsonl.NewStore[Event](path, "event")}
+}
+
+// Append writes a single event.
+func (l *Log) Append(event Event) error {
+	return l.events.Append(event)
+}
+
+// HandleEvent is a Handler recording events to the log; subscribe it to "*" to record everything.
+func (l *Log) HandleEvent(event Event) {
+	if err := l.Append(event); err != nil {
+		logging.Default().Warn().Err(err).Str("event_type", event.Type).Str("session_id", event.SessionID).Msg("Failed to record event")
+	}
+}
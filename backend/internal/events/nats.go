@@ -0,0 +1,44 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsBroker publishes events on a subject per event type.
+type natsBroker struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+// NewNATSBroker connects to the NATS server at url; events are published on "<subjectPrefix>.<event type>".
+func NewNATSBroker(url, subjectPrefix string) (Broker, error) {
+	conn, err := nats.Connect(url, nats.Name("sofa-commander"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	return &natsBroker{conn: conn, subjectPrefix: subjectPrefix}, nil
+}
+
+func (b *natsBroker) Name() string { return "nats" }
+
+// Send publishes the event and flushes it so a lost connection fails the delivery.
+func (b *natsBroker) Send(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	msg := nats.NewMsg(b.subjectPrefix + "." + event.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID) // Lets JetStream streams deduplicate redeliveries
+	if err := b.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish event to nats: %w", err)
+	}
+	if err := b.conn.FlushTimeout(10 * time.Second); err != nil {
+		return fmt.Errorf("failed to flush event to nats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	jirainfra "sofa-commander/backend/internal/features/jira/infrastructure"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const (
+	maxSearchResults  = 3
+	maxSnippetRunes   = 1500
+	productContextDoc = "產品背景"
+)
+
+// toolExecutor implements the assistant's function tools over the app configuration and Jira.
+type toolExecutor struct {
+	appConfigService config.AppConfigService
+}
+
+// NewToolExecutor creates the executor of the lookup_glossary, search_knowledge_base and fetch_jira_issue tools.
+func NewToolExecutor(appConfigService config.AppConfigService) infrastructure.ToolExecutor {
+	return &toolExecutor{appConfigService: appConfigService}
+}
+
+// Tools returns the tools whose data sources are configured, or none when tools are disabled.
+func (e *toolExecutor) Tools() []openai.Tool {
+	appConfig, err := e.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Disabling assistant tools, failed to load app config")
+		return nil
+	}
+	if !appConfig.AssistantTools.Enabled {
+		return nil
+	}
+
+	var tools []openai.Tool
+	if len(appConfig.Glossary) > 0 {
+		tools = append(tools, functionTool("lookup_glossary", "查詢產品術語表中某個名詞的標準用語與定義。", map[string]any{
+			"term": map[string]any{"type": "string", "description": "要查詢的名詞"},
+		}, "term"))
+	}
+	if len(appConfig.KnowledgeBase) > 0 || appConfig.ProductContext != "" {
+		tools = append(tools, functionTool("search_knowledge_base", "以關鍵字搜尋專案知識庫文件（產品背景、規格、決策紀錄等）。", map[string]any{
+			"query": map[string]any{"type": "string", "description": "搜尋關鍵字，多個關鍵字以空白分隔"},
+		}, "query"))
+	}
+	if appConfig.Jira.BaseURL != "" {
+		tools = append(tools, functionTool("fetch_jira_issue", "取得 Jira issue 的標題、描述與狀態。", map[string]any{
+			"issue_key": map[string]any{"type": "string", "description": "Issue key，例如 PROJ-123"},
+		}, "issue_key"))
+	}
+	return tools
+}
+
+// Execute runs a tool call and returns its JSON output.
+func (e *toolExecutor) Execute(name, arguments string) (string, error) {
+	var args struct {
+		Term     string `json:"term"`
+		Query    string `json:"query"`
+		IssueKey string `json:"issue_key"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments for %s: %w", name, err)
+	}
+	appConfig, err := e.appConfigService.LoadAppConfig()
+	if err != nil {
+		return "", err
+	}
+
+	var result any
+	switch name {
+	case "lookup_glossary":
+		result = lookupGlossary(appConfig.Glossary, args.Term)
+	case "search_knowledge_base":
+		result = searchKnowledgeBase(knowledgeDocuments(appConfig), args.Query)
+	case "fetch_jira_issue":
+		client, err := jirainfra.NewJiraClientFromConfig(appConfig.Jira)
+		if err != nil {
+			return "", err
+		}
+		result, err = client.GetIssue(strings.TrimSpace(args.IssueKey))
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown tool: %s", name)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool output: %w", err)
+	}
+	return string(data), nil
+}
+
+func functionTool(name, description string, properties map[string]any, required ...string) openai.Tool {
+	return openai.Tool{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  map[string]any{"type": "object", "properties": properties, "required": required},
+		},
+	}
+}
+
+// lookupGlossary returns the glossary terms whose term or variants match the queried term.
+func lookupGlossary(glossary []configdomain.GlossaryTerm, term string) []configdomain.GlossaryTerm {
+	needle := strings.ToLower(strings.TrimSpace(term))
+	matches := []configdomain.GlossaryTerm{}
+	if needle == "" {
+		return matches
+	}
+	for _, entry := range glossary {
+		candidates := append([]string{entry.Term}, entry.Variants...)
+		for _, candidate := range candidates {
+			c := strings.ToLower(candidate)
+			if c == "" {
+				continue
+			}
+			if c == needle || strings.Contains(c, needle) || strings.Contains(needle, c) {
+				matches = append(matches, entry)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// knowledgeDocuments returns the searchable documents, with the product context as the first one.
+func knowledgeDocuments(appConfig *configdomain.AppConfig) []configdomain.KnowledgeDocument {
+	docs := make([]configdomain.KnowledgeDocument, 0, len(appConfig.KnowledgeBase)+1)
+	if appConfig.ProductContext != "" {
+		docs = append(docs, configdomain.KnowledgeDocument{Title: productContextDoc, Content: appConfig.ProductContext})
+	}
+	return append(docs, appConfig.KnowledgeBase...)
+}
+
+// searchKnowledgeBase ranks documents by how often the query keywords occur in them.
+func searchKnowledgeBase(docs []configdomain.KnowledgeDocument, query string) []configdomain.KnowledgeDocument {
+	keywords := strings.Fields(strings.ToLower(query))
+	type scored struct {
+		doc   configdomain.KnowledgeDocument
+		score int
+	}
+	var ranked []scored
+	for _, doc := range docs {
+		text := strings.ToLower(doc.Title + "\n" + doc.Content)
+		score := 0
+		for _, kw := range keywords {
+			score += strings.Count(text, kw)
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{doc: doc, score: score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	results := []configdomain.KnowledgeDocument{}
+	for i := 0; i < len(ranked) && i < maxSearchResults; i++ {
+		doc := ranked[i].doc
+		if runes := []rune(doc.Content); len(runes) > maxSnippetRunes {
+			doc.Content = string(runes[:maxSnippetRunes]) + "…"
+		}
+		results = append(results, doc)
+	}
+	return results
+}
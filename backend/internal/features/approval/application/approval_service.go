@@ -0,0 +1,265 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/approval/domain"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	emailapp "sofa-commander/backend/internal/features/email/application"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// ErrNotReviewer is returned when a decision is made by someone who is not a designated reviewer.
+var ErrNotReviewer = fmt.Errorf("not a designated reviewer")
+
+// ErrInvalidReviewers is returned when saving reviewers without an ID or with the same ID twice.
+var ErrInvalidReviewers = fmt.Errorf("invalid reviewers")
+
+// ApprovalService defines the interface for the reviewer sign-off of finalized stories and the
+// review comments on them.
+type ApprovalService interface {
+	GetState(sessionID string) (*domain.ApprovalState, error)
+	Decide(sessionID, reviewerID string, decision refinementdomain.ApprovalStatus, comment string) (*domain.ApprovalState, error)
+	HandleEvent(event events.Event)
+	ListComments(sessionID string) ([]refinementdomain.ReviewComment, error)
+	AddComment(sessionID, author string, req *domain.CommentRequest) (*refinementdomain.ReviewComment, error)
+	DeleteComment(sessionID, commentID string) error
+	SubmitComments(ctx context.Context, sessionID string) (*domain.RevisionResult, error)
+	Reviewers() ([]configdomain.Reviewer, error)
+	SaveReviewers(reviewers []configdomain.Reviewer) error
+}
+
+// approvalService is the implementation of ApprovalService.
+type approvalService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+	publisher         events.Publisher
+}
+
+// NewApprovalService creates a new instance of approvalService.
+func NewApprovalService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService, publisher events.Publisher) ApprovalService {
+	return &approvalService{refinementService: refinementService, appConfigService: appConfigService, publisher: publisher}
+}
+
+// HandleEvent requests a review from the designated reviewers whenever a session is finalized.
+// Finalizing again restarts the review, as earlier decisions were about a different output.
+func (s *approvalService) HandleEvent(event events.Event) {
+	if event.Type != events.SessionFinalized {
+		return
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", event.SessionID).Msg("Skipping review request, failed to load app config")
+		return
+	}
+	reviewers := appConfig.Approval.Reviewers
+	if len(reviewers) == 0 {
+		return
+	}
+	session, err := s.refinementService.UpdateSession(event.SessionID, func(session *refinementdomain.RefinementSession) {
+		session.ApprovalStatus = refinementdomain.ApprovalPending
+		session.Approvals = nil
+	})
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", event.SessionID).Msg("Failed to request review")
+		return
+	}
+	s.publish(events.ReviewRequested, session, map[string]any{"reviewers": reviewerIDs(reviewers)})
+	s.notifyReviewers(appConfig, session)
+}
+
+// GetState returns the approval status of a session.
+func (s *approvalService) GetState(sessionID string) (*domain.ApprovalState, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	return stateOf(session, appConfig.Approval.Reviewers), nil
+}
+
+// Decide records a reviewer's decision and updates the session's approval status: any request for
+// changes wins, and the story is approved once every designated reviewer approved it.
+func (s *approvalService) Decide(sessionID, reviewerID string, decision refinementdomain.ApprovalStatus, comment string) (*domain.ApprovalState, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	reviewers := appConfig.Approval.Reviewers
+	var reviewer *configdomain.Reviewer
+	for i := range reviewers {
+		if reviewers[i].ID == reviewerID {
+			reviewer = &reviewers[i]
+		}
+	}
+	if reviewer == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotReviewer, reviewerID)
+	}
+
+	var updateErr error
+	session, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		if session.FinalizedAt == nil {
+			updateErr = fmt.Errorf("session %s has not been finalized", sessionID)
+			return
+		}
+		if session.ApprovalStatus == "" {
+			session.ApprovalStatus = refinementdomain.ApprovalPending // Finalized before reviewers were designated
+		}
+		decisions := session.Approvals[:0:0]
+		for _, d := range session.Approvals {
+			if d.ReviewerID != reviewerID {
+				decisions = append(decisions, d)
+			}
+		}
+		session.Approvals = append(decisions, refinementdomain.ApprovalDecision{
+			ReviewerID:   reviewer.ID,
+			ReviewerName: reviewer.Name,
+			Decision:     decision,
+			Comment:      comment,
+			DecidedAt:    time.Now(),
+		})
+		session.ApprovalStatus = stateOf(session, reviewers).Status
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updateErr != nil {
+		return nil, updateErr
+	}
+
+	state := stateOf(session, reviewers)
+	switch state.Status {
+	case refinementdomain.ApprovalApproved:
+		s.publish(events.SessionApproved, session, nil)
+	case refinementdomain.ApprovalChangesRequested:
+		if decision == refinementdomain.ApprovalChangesRequested {
+			s.publish(events.ChangesRequested, session, map[string]any{"reviewer_id": reviewerID, "comment": comment})
+		}
+	}
+	return state, nil
+}
+
+// Reviewers returns the designated reviewers.
+func (s *approvalService) Reviewers() ([]configdomain.Reviewer, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	if appConfig.Approval.Reviewers == nil {
+		return []configdomain.Reviewer{}, nil
+	}
+	return appConfig.Approval.Reviewers, nil
+}
+
+// SaveReviewers replaces the designated reviewers. Reviewers are managed through the admin API only, as
+// they decide who can sign off stories.
+func (s *approvalService) SaveReviewers(reviewers []configdomain.Reviewer) error {
+	seen := make(map[string]bool)
+	for _, r := range reviewers {
+		if r.ID == "" {
+			return fmt.Errorf("%w: reviewer %q has no ID", ErrInvalidReviewers, r.Name)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("%w: reviewer %q is listed twice", ErrInvalidReviewers, r.ID)
+		}
+		seen[r.ID] = true
+	}
+	return s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		appConfig.Approval.Reviewers = reviewers
+		return nil
+	})
+}
+
+// stateOf computes the approval state of a session from its decisions.
+func stateOf(session *refinementdomain.RefinementSession, reviewers []configdomain.Reviewer) *domain.ApprovalState {
+	state := &domain.ApprovalState{SessionID: session.ID, Decisions: session.Approvals, Pending: []string{}}
+	if state.Decisions == nil {
+		state.Decisions = []refinementdomain.ApprovalDecision{}
+	}
+	decided := make(map[string]refinementdomain.ApprovalStatus)
+	for _, d := range session.Approvals {
+		decided[d.ReviewerID] = d.Decision
+	}
+	changesRequested := false
+	for _, r := range reviewers {
+		switch decided[r.ID] {
+		case refinementdomain.ApprovalChangesRequested:
+			changesRequested = true
+		case refinementdomain.ApprovalApproved:
+		default:
+			state.Pending = append(state.Pending, r.ID)
+		}
+	}
+	switch {
+	case session.ApprovalStatus == "":
+		state.Status = ""
+	case changesRequested:
+		state.Status = refinementdomain.ApprovalChangesRequested
+	case len(state.Pending) == 0:
+		state.Status = refinementdomain.ApprovalApproved
+	default:
+		state.Status = refinementdomain.ApprovalPending
+	}
+	return state
+}
+
+// notifyReviewers emails the reviewers that a story awaits their sign-off.
+func (s *approvalService) notifyReviewers(appConfig *configdomain.AppConfig, session *refinementdomain.RefinementSession) {
+	mailer, err := emailapp.NewMailer(appConfig.Email)
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Not emailing reviewers")
+		return
+	}
+	subject := "[Sofa Commander] 請審核需求：" + firstLine(session.FinalUserStory)
+	body := "以下需求已完成打磨，請審核並核准或要求修改。\n\n" + session.FinalUserStory + "\n"
+	if link := appConfig.SessionURL(session.ID); link != "" {
+		body += "\n" + link + "\n"
+	}
+	for _, r := range appConfig.Approval.Reviewers {
+		if r.Email == "" {
+			continue
+		}
+		if err := mailer.Send(r.Email, subject, body); err != nil {
+			logging.Default().Warn().Err(err).Str("session_id", session.ID).Str("reviewer_id", r.ID).Msg("Failed to notify reviewer")
+		}
+	}
+}
+
+func (s *approvalService) publish(eventType string, session *refinementdomain.RefinementSession, data map[string]any) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.Publish(events.Event{
+		Type:        eventType,
+		SessionID:   session.ID,
+		WorkspaceID: session.WorkspaceID,
+		UserID:      session.UserID,
+		Data:        data,
+	})
+}
+
+func reviewerIDs(reviewers []configdomain.Reviewer) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, r := range reviewers {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func firstLine(text string) string {
+	for i, r := range text {
+		if r == '\n' {
+			return text[:i]
+		}
+	}
+	return text
+}
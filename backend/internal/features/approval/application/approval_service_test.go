@@ -0,0 +1,163 @@
+package application
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// stubSessions keeps a single session in memory.
+type stubSessions struct {
+	refinementapp.RefinementService
+	session *refinementdomain.RefinementSession
+}
+
+func (s *stubSessions) GetSession(sessionID string) (*refinementdomain.RefinementSession, error) {
+	return s.session, nil
+}
+
+func (s *stubSessions) UpdateSession(sessionID string, update func(session *refinementdomain.RefinementSession)) (*refinementdomain.RefinementSession, error) {
+	update(s.session)
+	return s.session, nil
+}
+
+func newTestConfigService(t *testing.T, reviewers ...configdomain.Reviewer) config.AppConfigService {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app_config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	service := config.NewAppConfigService(path, filepath.Join(dir, "versions.jsonl"))
+	if err := service.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		appConfig.Approval.Reviewers = reviewers
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestDecide(t *testing.T) {
+	reviewers := []configdomain.Reviewer{{ID: "lead", Name: "Tech Lead"}, {ID: "qa", Name: "QA Lead"}}
+	type decision struct {
+		reviewer string
+		decision refinementdomain.ApprovalStatus
+	}
+	tests := []struct {
+		name        string
+		decisions   []decision
+		wantStatus  refinementdomain.ApprovalStatus
+		wantPending []string
+		wantErr     error
+	}{
+		{
+			name:        "one approval",
+			decisions:   []decision{{"lead", refinementdomain.ApprovalApproved}},
+			wantStatus:  refinementdomain.ApprovalPending,
+			wantPending: []string{"qa"},
+		},
+		{
+			name:        "every reviewer approves",
+			decisions:   []decision{{"lead", refinementdomain.ApprovalApproved}, {"qa", refinementdomain.ApprovalApproved}},
+			wantStatus:  refinementdomain.ApprovalApproved,
+			wantPending: []string{},
+		},
+		{
+			name:        "a request for changes wins",
+			decisions:   []decision{{"lead", refinementdomain.ApprovalApproved}, {"qa", refinementdomain.ApprovalChangesRequested}},
+			wantStatus:  refinementdomain.ApprovalChangesRequested,
+			wantPending: []string{},
+		},
+		{
+			name:        "a later decision replaces the reviewer's earlier one",
+			decisions:   []decision{{"qa", refinementdomain.ApprovalChangesRequested}, {"qa", refinementdomain.ApprovalApproved}},
+			wantStatus:  refinementdomain.ApprovalPending,
+			wantPending: []string{"lead"},
+		},
+		{
+			name:      "not a reviewer",
+			decisions: []decision{{"pm", refinementdomain.ApprovalApproved}},
+			wantErr:   ErrNotReviewer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finalizedAt := time.Now()
+			sessions := &stubSessions{session: &refinementdomain.RefinementSession{ID: "s1", FinalizedAt: &finalizedAt, ApprovalStatus: refinementdomain.ApprovalPending}}
+			s := NewApprovalService(sessions, newTestConfigService(t, reviewers...), nil)
+
+			var err error
+			for _, d := range tt.decisions {
+				_, err = s.Decide("s1", d.reviewer, d.decision, "")
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decide() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decide() error = %v", err)
+			}
+			got, _ := s.GetState("s1")
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if len(got.Pending) != len(tt.wantPending) || (len(got.Pending) > 0 && got.Pending[0] != tt.wantPending[0]) {
+				t.Errorf("pending = %v, want %v", got.Pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestDecideBeforeFinalize(t *testing.T) {
+	sessions := &stubSessions{session: &refinementdomain.RefinementSession{ID: "s1"}}
+	s := NewApprovalService(sessions, newTestConfigService(t, configdomain.Reviewer{ID: "lead"}), nil)
+	if _, err := s.Decide("s1", "lead", refinementdomain.ApprovalApproved, ""); err == nil {
+		t.Error("Decide() on a session that is not finalized succeeded")
+	}
+}
+
+func TestSaveReviewers(t *testing.T) {
+	tests := []struct {
+		name      string
+		reviewers []configdomain.Reviewer
+		wantErr   bool
+	}{
+		{name: "reviewers", reviewers: []configdomain.Reviewer{{ID: "lead"}, {ID: "qa", Email: "qa@example.com"}}},
+		{name: "none", reviewers: nil},
+		{name: "reviewer without ID", reviewers: []configdomain.Reviewer{{Name: "Tech Lead"}}, wantErr: true},
+		{name: "duplicate reviewer", reviewers: []configdomain.Reviewer{{ID: "lead"}, {ID: "lead"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewApprovalService(&stubSessions{}, newTestConfigService(t, configdomain.Reviewer{ID: "previous"}), nil)
+			err := s.SaveReviewers(tt.reviewers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SaveReviewers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidReviewers) {
+				t.Errorf("SaveReviewers() error = %v, want ErrInvalidReviewers", err)
+			}
+			got, err := s.Reviewers()
+			if err != nil {
+				t.Fatalf("Reviewers() error = %v", err)
+			}
+			want := len(tt.reviewers)
+			if tt.wantErr {
+				want = 1 // The previous reviewers are kept
+			}
+			if len(got) != want {
+				t.Errorf("Reviewers() = %v, want %d reviewers", got, want)
+			}
+		})
+	}
+}
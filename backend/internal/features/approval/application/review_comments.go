@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/approval/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// ListComments returns the review comments of a session.
+func (s *approvalService) ListComments(sessionID string) ([]refinementdomain.ReviewComment, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	comments := append([]refinementdomain.ReviewComment{}, session.ReviewComments...)
+	return comments, nil
+}
+
+// AddComment adds an inline comment on a sentence of the final story or an AC item.
+func (s *approvalService) AddComment(sessionID, author string, req *domain.CommentRequest) (*refinementdomain.ReviewComment, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+	var comment refinementdomain.ReviewComment
+	var commentErr error
+	_, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		if session.FinalizedAt == nil {
+			commentErr = fmt.Errorf("session %s has not been finalized", sessionID)
+			return
+		}
+		var items []string
+		switch req.Target {
+		case refinementdomain.CommentTargetStory:
+			items = storySentences(session.FinalUserStory)
+		case refinementdomain.CommentTargetAC:
+			items = session.FinalAC
+		default:
+			commentErr = fmt.Errorf("unknown comment target %q, expected %q or %q", req.Target, refinementdomain.CommentTargetStory, refinementdomain.CommentTargetAC)
+			return
+		}
+		if req.Index < 0 || req.Index >= len(items) {
+			commentErr = fmt.Errorf("%s index %d out of range (0-%d)", req.Target, req.Index, len(items)-1)
+			return
+		}
+		now := time.Now()
+		comment = refinementdomain.ReviewComment{
+			ID:        fmt.Sprintf("comment-%d", now.UnixNano()),
+			Author:    author,
+			Target:    req.Target,
+			Index:     req.Index,
+			Quote:     items[req.Index],
+			Body:      body,
+			CreatedAt: now,
+		}
+		session.ReviewComments = append(session.ReviewComments, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if commentErr != nil {
+		return nil, commentErr
+	}
+	return &comment, nil
+}
+
+// DeleteComment removes an unresolved comment.
+func (s *approvalService) DeleteComment(sessionID, commentID string) error {
+	found := false
+	_, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		for i, c := range session.ReviewComments {
+			if c.ID == commentID && c.ResolvedAt == nil {
+				session.ReviewComments = append(session.ReviewComments[:i], session.ReviewComments[i+1:]...)
+				found = true
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("open comment %s not found", commentID)
+	}
+	return nil
+}
+
+// SubmitComments turns the open comments into a modification request, finalizes the session again
+// and marks the comments resolved. Finalizing again restarts the approval.
+func (s *approvalService) SubmitComments(ctx context.Context, sessionID string) (*domain.RevisionResult, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var open []refinementdomain.ReviewComment
+	for _, c := range session.ReviewComments {
+		if c.ResolvedAt == nil {
+			open = append(open, c)
+		}
+	}
+	if len(open) == 0 {
+		return nil, fmt.Errorf("session %s has no open comments", session.ID)
+	}
+
+	userStory, ac, rawAI, err := s.refinementService.Finalize(ctx, sessionID, string(session.Phase), nil, nil, modificationRequest(open))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resolved := make(map[string]bool, len(open))
+	for i := range open {
+		open[i].ResolvedAt = &now
+		resolved[open[i].ID] = true
+	}
+	_, _ = s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		for i := range session.ReviewComments {
+			if resolved[session.ReviewComments[i].ID] {
+				session.ReviewComments[i].ResolvedAt = &now
+			}
+		}
+	})
+
+	result := &domain.RevisionResult{
+		Finalize:         refinementdomain.FinalizeResponse{UserStory: userStory, AC: ac, RawAI: rawAI, LintFindings: []refinementdomain.LintFinding{}},
+		ResolvedComments: open,
+	}
+	if appConfig, err := s.appConfigService.LoadAppConfig(); err == nil {
+		result.Finalize.LintFindings = refinementapp.LintStory(userStory, ac, appConfig.StyleLint)
+	}
+	return result, nil
+}
+
+// modificationRequest formats review comments as the modification suggestion of a finalize.
+func modificationRequest(comments []refinementdomain.ReviewComment) string {
+	var b strings.Builder
+	b.WriteString("審核者對最終輸出提出以下修改意見，請逐一處理，其餘內容保持不變：\n")
+	for _, c := range comments {
+		target := "用戶故事"
+		if c.Target == refinementdomain.CommentTargetAC {
+			target = fmt.Sprintf("驗收標準第 %d 條", c.Index+1)
+		}
+		b.WriteString(fmt.Sprintf("- 針對%s「%s」：%s\n", target, c.Quote, c.Body))
+	}
+	return b.String()
+}
+
+// storySentences splits a story into sentences at Chinese and Western sentence ends and line breaks.
+func storySentences(story string) []string {
+	var sentences []string
+	var current strings.Builder
+	flush := func() {
+		if sentence := strings.TrimSpace(current.String()); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		current.Reset()
+	}
+	for _, r := range story {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+		if strings.ContainsRune("。！？!?", r) || r == '.' {
+			flush()
+		}
+	}
+	flush()
+	return sentences
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// DecisionRequest is the request structure for approving or requesting changes on a story. The reviewer
+// is the authenticated user of the request.
+type DecisionRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// ApprovalState is the approval status of a session and the decisions behind it.
+type ApprovalState struct {
+	SessionID string                              `json:"session_id"`
+	Status    refinementdomain.ApprovalStatus     `json:"status"`
+	Decisions []refinementdomain.ApprovalDecision `json:"decisions"`
+	Pending   []string                            `json:"pending"` // IDs of reviewers yet to decide
+}
+
+// CommentRequest is the request structure for commenting on the final output.
+type CommentRequest struct {
+	Target string `json:"target" binding:"required"` // "story" or "ac"
+	Index  int    `json:"index"`
+	Body   string `json:"body" binding:"required"`
+}
+
+// RevisionResult is the revised final output generated from submitted comments.
+type RevisionResult struct {
+	Finalize         refinementdomain.FinalizeResponse `json:"finalize"`
+	ResolvedComments []refinementdomain.ReviewComment  `json:"resolved_comments"`
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"sofa-commander/backend/internal/features/approval/application"
+	"sofa-commander/backend/internal/features/approval/domain"
+	authdomain "sofa-commander/backend/internal/features/auth/domain"
+	authhttp "sofa-commander/backend/internal/features/auth/presentation/http"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalHandler holds the approval service and the admin token required to manage reviewers.
+type ApprovalHandler struct {
+	approvalService application.ApprovalService
+	adminToken      string
+}
+
+// NewApprovalHandler creates a new ApprovalHandler. Without an admin token, reviewers cannot be managed.
+func NewApprovalHandler(approvalService application.ApprovalService, adminToken string) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+		adminToken:      adminToken,
+	}
+}
+
+func (h *ApprovalHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// GetApprovalHandler returns the approval status of a session.
+func (h *ApprovalHandler) GetApprovalHandler(c *gin.Context) {
+	state, err := h.approvalService.GetState(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// ApproveHandler records a reviewer's approval.
+func (h *ApprovalHandler) ApproveHandler(c *gin.Context) {
+	h.decide(c, refinementdomain.ApprovalApproved)
+}
+
+// RequestChangesHandler records a reviewer's request for changes.
+func (h *ApprovalHandler) RequestChangesHandler(c *gin.Context) {
+	h.decide(c, refinementdomain.ApprovalChangesRequested)
+}
+
+// decide records a decision of the authenticated user. Without authentication the user is unknown, as the
+// X-User-ID header can be set by anyone, so no decision can be made.
+func (h *ApprovalHandler) decide(c *gin.Context, decision refinementdomain.ApprovalStatus) {
+	value, _ := c.Get(authhttp.PrincipalKey)
+	principal, _ := value.(*authdomain.Principal)
+	if principal == nil || principal.UserID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Deciding on a story requires authenticating as a reviewer"})
+		return
+	}
+	var req domain.DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	state, err := h.approvalService.Decide(c.Param("id"), principal.UserID, decision, req.Comment)
+	if errors.Is(err, application.ErrNotReviewer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to record decision: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// ListCommentsHandler returns the review comments of a session.
+func (h *ApprovalHandler) ListCommentsHandler(c *gin.Context) {
+	comments, err := h.approvalService.ListComments(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// AddCommentHandler adds an inline comment on the final story or an AC item.
+func (h *ApprovalHandler) AddCommentHandler(c *gin.Context) {
+	var req domain.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	comment, err := h.approvalService.AddComment(c.Param("id"), c.GetHeader("X-User-ID"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add comment: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// DeleteCommentHandler removes an open comment.
+func (h *ApprovalHandler) DeleteCommentHandler(c *gin.Context) {
+	if err := h.approvalService.DeleteComment(c.Param("id"), c.Param("commentId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+// SubmitCommentsHandler generates a revised final output from the open comments.
+func (h *ApprovalHandler) SubmitCommentsHandler(c *gin.Context) {
+	result, err := h.approvalService.SubmitComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit comments: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetReviewersHandler returns the designated reviewers.
+func (h *ApprovalHandler) GetReviewersHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	reviewers, err := h.approvalService.Reviewers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reviewers: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviewers)
+}
+
+// SaveReviewersHandler replaces the designated reviewers.
+func (h *ApprovalHandler) SaveReviewersHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var reviewers []configdomain.Reviewer
+	if err := c.ShouldBindJSON(&reviewers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := h.approvalService.SaveReviewers(reviewers)
+	if errors.Is(err, application.ErrInvalidReviewers) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reviewers: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviewers)
+}
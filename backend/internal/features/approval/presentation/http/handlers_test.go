@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sofa-commander/backend/internal/features/approval/application"
+	"sofa-commander/backend/internal/features/approval/domain"
+	authdomain "sofa-commander/backend/internal/features/auth/domain"
+	authhttp "sofa-commander/backend/internal/features/auth/presentation/http"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubApprovalService records the reviewer of the decisions and reviewers saved.
+type stubApprovalService struct {
+	application.ApprovalService
+	reviewer  string
+	reviewers []configdomain.Reviewer
+}
+
+func (s *stubApprovalService) Decide(sessionID, reviewerID string, decision refinementdomain.ApprovalStatus, comment string) (*domain.ApprovalState, error) {
+	s.reviewer = reviewerID
+	return &domain.ApprovalState{SessionID: sessionID, Status: decision}, nil
+}
+
+func (s *stubApprovalService) SaveReviewers(reviewers []configdomain.Reviewer) error {
+	s.reviewers = reviewers
+	return nil
+}
+
+func TestDecideReviewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name         string
+		principal    *authdomain.Principal
+		header       string
+		wantStatus   int
+		wantReviewer string
+	}{
+		{name: "authenticated user", principal: &authdomain.Principal{UserID: "lead", Method: authdomain.MethodJWT}, wantStatus: http.StatusOK, wantReviewer: "lead"},
+		{name: "unauthenticated user header", header: "lead", wantStatus: http.StatusUnauthorized},
+		{name: "key not bound to a user", principal: &authdomain.Principal{Method: authdomain.MethodAPIKey}, header: "lead", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubApprovalService{}
+			router := gin.New()
+			router.POST("/sessions/:id/approval/approve", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Set(authhttp.PrincipalKey, tt.principal)
+				}
+			}, NewApprovalHandler(service, "").ApproveHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/sessions/s1/approval/approve", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-User-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if service.reviewer != tt.wantReviewer {
+				t.Errorf("reviewer = %q, want %q", service.reviewer, tt.wantReviewer)
+			}
+		})
+	}
+}
+
+func TestSaveReviewersRequiresAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{name: "admin token", header: "admin", wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubApprovalService{}
+			router := gin.New()
+			router.PUT("/api/admin/approval/reviewers", NewApprovalHandler(service, "admin").SaveReviewersHandler)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/approval/reviewers", strings.NewReader(`[{"id":"lead","name":"Tech Lead"}]`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-Admin-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if saved := len(service.reviewers) == 1; saved != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("reviewers saved = %v, want %v", saved, tt.wantStatus == http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package application
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/features/auth/domain"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// keyPrefix starts every API key, so leaked keys are easy to recognize.
+const keyPrefix = "sc_"
+
+// AuthService defines the interface for authenticating API requests and managing API keys.
+type AuthService interface {
+	Authenticate(apiKey, bearer string) (*domain.Principal, error)
+	ListKeys() ([]configdomain.APIKey, error)
+	IssueKey(req *domain.IssueKeyRequest) (*domain.IssuedKey, error)
+	RevokeKey(id string) error
+	Settings() (*domain.AuthSettings, error)
+	SaveSettings(settings *domain.AuthSettings) error
+}
+
+// UserDirectory reports the users deactivated by the identity provider, whose credentials stop working.
+type UserDirectory interface {
+	Deactivated(userName string) bool
+}
+
+// authService is the implementation of AuthService. The auth config is read on every request, so keys
+// and settings take effect without a restart.
+type authService struct {
+	appConfigService config.AppConfigService
+	users            UserDirectory
+}
+
+// NewAuthService creates a new instance of authService.
+func NewAuthService(appConfigService config.AppConfigService, users UserDirectory) AuthService {
+	return &authService{appConfigService: appConfigService, users: users}
+}
+
+// Authenticate checks the credentials of a request: an API key from the X-API-Key header, or a bearer
+// token that is either an API key or a JWT. While authentication is disabled, every request passes
+// with a nil principal. A config that cannot be read fails every request rather than disabling it.
+// Principals of deactivated users are rejected.
+func (s *authService) Authenticate(apiKey, bearer string) (*domain.Principal, error) {
+	principal, err := s.authenticate(apiKey, bearer)
+	if err == nil && principal != nil && principal.UserID != "" && s.users.Deactivated(principal.UserID) {
+		return nil, fmt.Errorf("%w: user %s is deactivated", domain.ErrUnauthenticated, principal.UserID)
+	}
+	return principal, err
+}
+
+// authenticate checks the credentials of a request.
+func (s *authService) authenticate(apiKey, bearer string) (*domain.Principal, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auth config: %w", err)
+	}
+	auth := appConfig.Auth
+	if !auth.Enabled {
+		return nil, nil
+	}
+	switch {
+	case apiKey != "":
+		return authenticateKey(auth, apiKey)
+	case strings.HasPrefix(bearer, keyPrefix):
+		return authenticateKey(auth, bearer)
+	case bearer != "":
+		return authenticateJWT(auth.JWT, bearer)
+	}
+	return nil, domain.ErrUnauthenticated
+}
+
+// authenticateKey looks up an unrevoked API key by its hash.
+func authenticateKey(auth configdomain.AuthConfig, key string) (*domain.Principal, error) {
+	hash := hashKey(key)
+	for _, k := range auth.APIKeys {
+		if k.RevokedAt == nil && subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hash)) == 1 {
+			return &domain.Principal{UserID: k.UserID, Method: domain.MethodAPIKey, KeyID: k.ID}, nil
+		}
+	}
+	return nil, domain.ErrUnauthenticated
+}
+
+// authenticateJWT verifies a JWT with the configured secret or public key, and its issuer and audience
+// when configured.
+func authenticateJWT(cfg configdomain.JWTConfig, token string) (*domain.Principal, error) {
+	secret := cfg.Secret
+	if env := os.Getenv("JWT_SECRET"); env != "" {
+		secret = env
+	}
+	if secret == "" && cfg.PublicKey == "" {
+		return nil, domain.ErrUnauthenticated
+	}
+	options := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if secret != "" {
+				return []byte(secret), nil
+			}
+		case *jwt.SigningMethodRSA:
+			if cfg.PublicKey != "" {
+				return jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+			}
+		case *jwt.SigningMethodECDSA:
+			if cfg.PublicKey != "" {
+				return jwt.ParseECPublicKeyFromPEM([]byte(cfg.PublicKey))
+			}
+		}
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+
+	claim := cfg.UserClaim
+	if claim == "" {
+		claim = "sub"
+	}
+	userID, _ := claims[claim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: token has no %s claim", domain.ErrUnauthenticated, claim)
+	}
+	return &domain.Principal{UserID: userID, Method: domain.MethodJWT}, nil
+}
+
+// ListKeys returns the issued API keys, revoked ones included.
+func (s *authService) ListKeys() ([]configdomain.APIKey, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	return appConfig.Auth.APIKeys, nil
+}
+
+// IssueKey generates an API key and stores its hash.
+func (s *authService) IssueKey(req *domain.IssueKeyRequest) (*domain.IssuedKey, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := keyPrefix + hex.EncodeToString(secret)
+	issued := &domain.IssuedKey{
+		APIKey: configdomain.APIKey{
+			ID:        fmt.Sprintf("key-%d", time.Now().UnixNano()),
+			Name:      strings.TrimSpace(req.Name),
+			UserID:    req.UserID,
+			Prefix:    key[:len(keyPrefix)+6],
+			Hash:      hashKey(key),
+			CreatedAt: time.Now(),
+		},
+		Key: key,
+	}
+	err := s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		appConfig.Auth.APIKeys = append(appConfig.Auth.APIKeys, issued.APIKey)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RevokeKey revokes an API key. Revoked keys are kept, so the key list shows when they stopped working.
+func (s *authService) RevokeKey(id string) error {
+	return s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		for i := range appConfig.Auth.APIKeys {
+			if appConfig.Auth.APIKeys[i].ID == id {
+				if appConfig.Auth.APIKeys[i].RevokedAt == nil {
+					now := time.Now()
+					appConfig.Auth.APIKeys[i].RevokedAt = &now
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("API key %s not found", id)
+	})
+}
+
+// Settings returns the auth settings, without the JWT secret.
+func (s *authService) Settings() (*domain.AuthSettings, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	settings := &domain.AuthSettings{Enabled: appConfig.Auth.Enabled, JWT: appConfig.Auth.JWT}
+	settings.HasJWTSecret = settings.JWT.Secret != ""
+	settings.JWT.Secret = ""
+	return settings, nil
+}
+
+// SaveSettings replaces the auth settings, keeping the current JWT secret when none is given. The API
+// keys are kept.
+func (s *authService) SaveSettings(settings *domain.AuthSettings) error {
+	return s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		jwtConfig := settings.JWT
+		if jwtConfig.Secret == "" {
+			jwtConfig.Secret = appConfig.Auth.JWT.Secret
+		}
+		appConfig.Auth.Enabled = settings.Enabled
+		appConfig.Auth.JWT = jwtConfig
+		return nil
+	})
+}
+
+// hashKey returns the hex SHA-256 of an API key.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
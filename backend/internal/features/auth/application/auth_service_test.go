@@ -0,0 +1,94 @@
+package application
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"sofa-commander/backend/internal/features/auth/domain"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+func TestAuthenticateJWT(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	expires := time.Now().Add(time.Hour).Unix()
+	hmac := func(secret string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return token
+	}
+	ecdsaToken, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": "ann", "exp": expires}).SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	secretConfig := configdomain.JWTConfig{Secret: "s3cret"}
+	tests := []struct {
+		name     string
+		cfg      configdomain.JWTConfig
+		token    string
+		wantUser string
+		wantErr  bool
+	}{
+		{name: "secret", cfg: secretConfig, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "exp": expires}), wantUser: "ann"},
+		{name: "public key", cfg: configdomain.JWTConfig{PublicKey: publicKey}, token: ecdsaToken, wantUser: "ann"},
+		{name: "user claim", cfg: configdomain.JWTConfig{Secret: "s3cret", UserClaim: "email"}, token: hmac("s3cret", jwt.MapClaims{"sub": "1", "email": "ann@example.com", "exp": expires}), wantUser: "ann@example.com"},
+		{name: "issuer and audience", cfg: configdomain.JWTConfig{Secret: "s3cret", Issuer: "idp", Audience: "sofa"}, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "iss": "idp", "aud": "sofa", "exp": expires}), wantUser: "ann"},
+		{name: "not configured", cfg: configdomain.JWTConfig{}, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "exp": expires}), wantErr: true},
+		{name: "wrong secret", cfg: secretConfig, token: hmac("other", jwt.MapClaims{"sub": "ann", "exp": expires}), wantErr: true},
+		{name: "expired", cfg: secretConfig, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "exp": time.Now().Add(-time.Hour).Unix()}), wantErr: true},
+		{name: "no expiry", cfg: secretConfig, token: hmac("s3cret", jwt.MapClaims{"sub": "ann"}), wantErr: true},
+		{name: "no user claim", cfg: secretConfig, token: hmac("s3cret", jwt.MapClaims{"exp": expires}), wantErr: true},
+		{name: "wrong issuer", cfg: configdomain.JWTConfig{Secret: "s3cret", Issuer: "idp"}, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "iss": "other", "exp": expires}), wantErr: true},
+		{name: "wrong audience", cfg: configdomain.JWTConfig{Secret: "s3cret", Audience: "sofa"}, token: hmac("s3cret", jwt.MapClaims{"sub": "ann", "aud": "other", "exp": expires}), wantErr: true},
+		{name: "HMAC token with a public key configured", cfg: configdomain.JWTConfig{PublicKey: publicKey}, token: hmac(publicKey, jwt.MapClaims{"sub": "ann", "exp": expires}), wantErr: true},
+		{name: "malformed token", cfg: secretConfig, token: "not-a-token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticateJWT(tt.cfg, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("authenticateJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrUnauthenticated) {
+					t.Errorf("authenticateJWT() error = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if principal.UserID != tt.wantUser || principal.Method != domain.MethodJWT {
+				t.Errorf("authenticateJWT() = %+v, want user %q authenticated by JWT", principal, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestAuthenticateJWTSecretFromEnvironment(t *testing.T) {
+	t.Setenv("JWT_SECRET", "from-env")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "ann", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("from-env"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	if _, err := authenticateJWT(configdomain.JWTConfig{Secret: "configured"}, token); err != nil {
+		t.Errorf("authenticateJWT() error = %v, want the JWT_SECRET environment variable to override the configured secret", err)
+	}
+}
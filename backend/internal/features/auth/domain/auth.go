@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// Authentication methods.
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// ErrUnauthenticated is returned for requests without valid credentials.
+var ErrUnauthenticated = errors.New("valid API key or bearer token required")
+
+// Principal is who an authenticated request acts as.
+type Principal struct {
+	UserID string `json:"user_id,omitempty"` // Empty for API keys not bound to a user
+	Method string `json:"method"`
+	KeyID  string `json:"key_id,omitempty"` // Set for API keys
+}
+
+// IssueKeyRequest is the request structure for issuing an API key.
+type IssueKeyRequest struct {
+	Name   string `json:"name" binding:"required"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// IssuedKey is a newly issued API key, the only time the key itself is returned.
+type IssuedKey struct {
+	configdomain.APIKey
+	Key string `json:"key"`
+}
+
+// AuthSettings are the auth settings of the app config, which are managed through the admin API only.
+type AuthSettings struct {
+	Enabled      bool                   `json:"enabled"`
+	JWT          configdomain.JWTConfig `json:"jwt"` // Leave the secret empty on update to keep the current one
+	HasJWTSecret bool                   `json:"has_jwt_secret"`
+}
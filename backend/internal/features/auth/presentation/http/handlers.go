@@ -0,0 +1,155 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"sofa-commander/backend/internal/features/auth/application"
+	"sofa-commander/backend/internal/features/auth/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey is the gin context key of the authenticated request's principal.
+const PrincipalKey = "principal"
+
+// Middleware authenticates the requests to /api routes when authentication is enabled, except for the
+// routes under the exempt prefixes, which check their own tokens. The X-User-ID header of authenticated
+// requests is set to the principal's user, or removed for principals not bound to one, so it cannot be
+// spoofed.
+func Middleware(authService application.AuthService, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, "/api/") || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		bearer, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		principal, err := authService.Authenticate(c.GetHeader("X-API-Key"), strings.TrimSpace(bearer))
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate: " + err.Error()})
+			return
+		}
+		if principal != nil {
+			c.Request.Header.Del("X-User-ID")
+			c.Set(PrincipalKey, principal)
+			if principal.UserID != "" {
+				c.Request.Header.Set("X-User-ID", principal.UserID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// AuthHandler holds the auth service and the admin token required to manage API keys.
+type AuthHandler struct {
+	authService application.AuthService
+	adminToken  string
+}
+
+// NewAuthHandler creates a new AuthHandler. Without an admin token, API keys cannot be managed.
+func NewAuthHandler(authService application.AuthService, adminToken string) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		adminToken:  adminToken,
+	}
+}
+
+func (h *AuthHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// ListKeysHandler returns the issued API keys, without the keys themselves.
+func (h *AuthHandler) ListKeysHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	keys, err := h.authService.ListKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// IssueKeyHandler issues an API key. The response is the only time the key is returned.
+func (h *AuthHandler) IssueKeyHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.IssueKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	issued, err := h.authService.IssueKey(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue API key: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, issued)
+}
+
+// RevokeKeyHandler revokes the API key with the given ID.
+func (h *AuthHandler) RevokeKeyHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	if err := h.authService.RevokeKey(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetSettingsHandler returns the auth settings, without the JWT secret.
+func (h *AuthHandler) GetSettingsHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	settings, err := h.authService.Settings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth settings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// SaveSettingsHandler replaces the auth settings.
+func (h *AuthHandler) SaveSettingsHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var settings domain.AuthSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.authService.SaveSettings(&settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save auth settings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Auth settings saved successfully"})
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sofa-commander/backend/internal/features/auth/application"
+	"sofa-commander/backend/internal/features/auth/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubAuthService authenticates every request as its principal.
+type stubAuthService struct {
+	application.AuthService
+	principal *domain.Principal
+}
+
+func (s stubAuthService) Authenticate(apiKey, bearer string) (*domain.Principal, error) {
+	return s.principal, nil
+}
+
+func TestMiddlewareUserHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		principal *domain.Principal
+		header    string
+		want      string
+	}{
+		{name: "authentication disabled", principal: nil, header: "ann", want: "ann"},
+		{name: "user key", principal: &domain.Principal{UserID: "bob", Method: domain.MethodAPIKey}, header: "ann", want: "bob"},
+		{name: "key not bound to a user", principal: &domain.Principal{Method: domain.MethodAPIKey}, header: "ann", want: ""},
+		{name: "JWT", principal: &domain.Principal{UserID: "bob", Method: domain.MethodJWT}, want: "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Middleware(stubAuthService{principal: tt.principal}))
+			var got string
+			router.GET("/api/me", func(c *gin.Context) { got = c.GetHeader("X-User-ID") })
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+			if tt.header != "" {
+				req.Header.Set("X-User-ID", tt.header)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("X-User-ID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/features/backlog/domain"
+	"sofa-commander/backend/internal/features/backlog/infrastructure"
+	exportapp "sofa-commander/backend/internal/features/export/application"
+	gitlabapp "sofa-commander/backend/internal/features/gitlab/application"
+	jiraapp "sofa-commander/backend/internal/features/jira/application"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+)
+
+// BacklogService defines the interface for grouping sessions into ordered backlogs and sprints.
+type BacklogService interface {
+	ListBacklogs(workspaceID string) ([]domain.Backlog, error)
+	GetBacklog(id string) (*domain.Backlog, error)
+	CreateBacklog(req *domain.BacklogRequest) (*domain.Backlog, error)
+	UpdateBacklog(id string, req *domain.BacklogRequest) (*domain.Backlog, error)
+	DeleteBacklog(id string) error
+	AddSessions(id string, sessionIDs []string) (*domain.Backlog, error)
+	RemoveSession(id, sessionID string) (*domain.Backlog, error)
+	Reorder(id string, sessionIDs []string) (*domain.Backlog, error)
+	Export(id, tracker string) (*domain.ExportResult, error)
+	ImportBaseline(ctx context.Context, workspaceID, format string, data []byte) (*domain.BaselineReport, error)
+}
+
+// backlogService is the implementation of BacklogService.
+type backlogService struct {
+	mu                sync.Mutex // Serializes read-modify-write updates and deletes
+	repo              infrastructure.BacklogRepository
+	refinementService refinementapp.RefinementService
+	jiraService       jiraapp.JiraService
+	gitLabService     gitlabapp.GitLabService
+}
+
+// NewBacklogService creates a new instance of backlogService.
+func NewBacklogService(repo infrastructure.BacklogRepository, refinementService refinementapp.RefinementService, jiraService jiraapp.JiraService, gitLabService gitlabapp.GitLabService) BacklogService {
+	return &backlogService{repo: repo, refinementService: refinementService, jiraService: jiraService, gitLabService: gitLabService}
+}
+
+// ListBacklogs returns the backlogs of a workspace, or all backlogs when the workspace ID is empty.
+func (s *backlogService) ListBacklogs(workspaceID string) ([]domain.Backlog, error) {
+	backlogs, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if workspaceID == "" {
+		return backlogs, nil
+	}
+	result := []domain.Backlog{}
+	for _, b := range backlogs {
+		if b.WorkspaceID == workspaceID {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+// GetBacklog returns a single backlog.
+func (s *backlogService) GetBacklog(id string) (*domain.Backlog, error) {
+	return s.repo.Get(id)
+}
+
+// CreateBacklog creates an empty backlog.
+func (s *backlogService) CreateBacklog(req *domain.BacklogRequest) (*domain.Backlog, error) {
+	kind, err := validKind(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("backlog name is required")
+	}
+	id, err := newBacklogID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	backlog := &domain.Backlog{
+		ID:          id,
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        kind,
+		WorkspaceID: req.WorkspaceID,
+		SessionIDs:  []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Save(backlog); err != nil {
+		return nil, err
+	}
+	return backlog, nil
+}
+
+// UpdateBacklog renames a backlog or changes its kind; its sessions are kept.
+func (s *backlogService) UpdateBacklog(id string, req *domain.BacklogRequest) (*domain.Backlog, error) {
+	kind, err := validKind(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(id, func(backlog *domain.Backlog) error {
+		if strings.TrimSpace(req.Name) != "" {
+			backlog.Name = strings.TrimSpace(req.Name)
+		}
+		if req.Kind != "" {
+			backlog.Kind = kind
+		}
+		return nil
+	})
+}
+
+// DeleteBacklog removes a backlog; its sessions are not affected.
+func (s *backlogService) DeleteBacklog(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repo.Delete(id)
+}
+
+// AddSessions appends sessions to the end of a backlog. Sessions already in it keep their position.
+func (s *backlogService) AddSessions(id string, sessionIDs []string) (*domain.Backlog, error) {
+	for _, sessionID := range sessionIDs {
+		if _, err := s.refinementService.GetSession(sessionID); err != nil {
+			return nil, err
+		}
+	}
+	return s.update(id, func(backlog *domain.Backlog) error {
+		for _, sessionID := range sessionIDs {
+			if !slices.Contains(backlog.SessionIDs, sessionID) {
+				backlog.SessionIDs = append(backlog.SessionIDs, sessionID)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveSession removes a session from a backlog.
+func (s *backlogService) RemoveSession(id, sessionID string) (*domain.Backlog, error) {
+	return s.update(id, func(backlog *domain.Backlog) error {
+		i := slices.Index(backlog.SessionIDs, sessionID)
+		if i < 0 {
+			return fmt.Errorf("session %s is not in backlog %s", sessionID, id)
+		}
+		backlog.SessionIDs = slices.Delete(backlog.SessionIDs, i, i+1)
+		return nil
+	})
+}
+
+// Reorder sets the order of a backlog's sessions. The new order must list exactly the sessions in the backlog.
+func (s *backlogService) Reorder(id string, sessionIDs []string) (*domain.Backlog, error) {
+	return s.update(id, func(backlog *domain.Backlog) error {
+		current := slices.Clone(backlog.SessionIDs)
+		proposed := slices.Clone(sessionIDs)
+		slices.Sort(current)
+		slices.Sort(proposed)
+		if !slices.Equal(current, proposed) {
+			return fmt.Errorf("the new order must list exactly the sessions of backlog %s", id)
+		}
+		backlog.SessionIDs = slices.Clone(sessionIDs)
+		return nil
+	})
+}
+
+// Export exports the refined stories of a backlog to a tracker in backlog order. Sessions that are not
+// finalized are skipped, and a failure on one session does not stop the others.
+func (s *backlogService) Export(id, tracker string) (*domain.ExportResult, error) {
+	if tracker != "jira" && tracker != "gitlab" {
+		return nil, fmt.Errorf("unsupported tracker %q, expected \"jira\" or \"gitlab\"", tracker)
+	}
+	backlog, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ExportResult{BacklogID: backlog.ID, Tracker: tracker, Items: []domain.ExportItem{}}
+	for _, sessionID := range backlog.SessionIDs {
+		item := domain.ExportItem{SessionID: sessionID}
+		session, err := s.refinementService.GetSession(sessionID)
+		switch {
+		case err != nil:
+			item.Error = err.Error()
+		case session.FinalizedAt == nil:
+			item.Skipped = "not finalized"
+		case tracker == "jira":
+			synced, err := s.jiraService.Sync(sessionID)
+			if err != nil {
+				skipBelowThreshold(&item, err)
+			} else {
+				item.IssueKey, item.IssueURL, item.Created = synced.IssueKey, synced.IssueURL, synced.Created
+			}
+		default:
+			exported, err := s.gitLabService.Export(sessionID)
+			if err != nil {
+				skipBelowThreshold(&item, err)
+			} else {
+				item.IssueKey, item.IssueURL, item.Created = strconv.Itoa(exported.IssueIID), exported.IssueURL, exported.Created
+			}
+		}
+		if item.Error != "" {
+			result.Failed++
+		} else if item.Skipped == "" {
+			result.Exported++
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// skipBelowThreshold records the failed export of an item, skipping stories gated by their quality score.
+func skipBelowThreshold(item *domain.ExportItem, err error) {
+	if errors.Is(err, exportapp.ErrBelowQualityThreshold) {
+		item.Skipped = "below quality threshold"
+		return
+	}
+	item.Error = err.Error()
+}
+
+// update loads a backlog, applies a change and saves it, so concurrent changes are not lost.
+func (s *backlogService) update(id string, change func(backlog *domain.Backlog) error) (*domain.Backlog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backlog, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(backlog); err != nil {
+		return nil, err
+	}
+	backlog.UpdatedAt = time.Now()
+	if err := s.repo.Save(backlog); err != nil {
+		return nil, err
+	}
+	return backlog, nil
+}
+
+// validKind returns the backlog kind, defaulting to "backlog".
+func validKind(kind string) (string, error) {
+	switch kind {
+	case "":
+		return domain.KindBacklog, nil
+	case domain.KindBacklog, domain.KindSprint:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unsupported backlog kind %q, expected \"backlog\" or \"sprint\"", kind)
+	}
+}
+
+func newBacklogID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate backlog ID: %w", err)
+	}
+	return "bl-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,223 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"sofa-commander/backend/internal/features/backlog/domain"
+)
+
+const (
+	// maxBaselineStories caps the stories analyzed from one import.
+	maxBaselineStories = 200
+	// baselineWorkers is the number of stories analyzed at the same time.
+	baselineWorkers = 4
+)
+
+// ImportBaseline parses an exported backlog and analyzes the readiness of each story without starting
+// sessions, ranking the stories to refine first. Stories that fail to analyze are listed last.
+func (s *backlogService) ImportBaseline(ctx context.Context, workspaceID, format string, data []byte) (*domain.BaselineReport, error) {
+	stories, err := ParseBacklogExport(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(stories) == 0 {
+		return nil, fmt.Errorf("the import contains no stories")
+	}
+	if len(stories) > maxBaselineStories {
+		return nil, fmt.Errorf("the import contains %d stories, at most %d can be analyzed at once", len(stories), maxBaselineStories)
+	}
+
+	items := make([]domain.BaselineItem, len(stories))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < baselineWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := domain.BaselineItem{ImportedStory: stories[i], MissingInfo: []string{}}
+				analysis, err := s.refinementService.AnalyzeStory(ctx, workspaceID, stories[i].Title, stories[i].Description)
+				if err != nil {
+					item.Error = err.Error()
+				} else {
+					item.Readiness, item.MissingInfo, item.Summary = analysis.Readiness, analysis.MissingInfo, analysis.Summary
+				}
+				items[i] = item
+			}
+		}()
+	}
+	for i := range stories {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if (items[i].Error == "") != (items[j].Error == "") {
+			return items[i].Error == ""
+		}
+		if items[i].Readiness != items[j].Readiness {
+			return items[i].Readiness < items[j].Readiness
+		}
+		return len(items[i].MissingInfo) > len(items[j].MissingInfo)
+	})
+
+	report := &domain.BaselineReport{Stories: len(items), MissingInfo: map[string]int{}, Items: items}
+	var total int
+	for i := range items {
+		items[i].Rank = i + 1
+		if items[i].Error != "" {
+			report.Failed++
+			continue
+		}
+		report.Analyzed++
+		total += items[i].Readiness
+		for _, category := range items[i].MissingInfo {
+			report.MissingInfo[strings.ToLower(category)]++
+		}
+	}
+	if report.Analyzed > 0 {
+		report.AverageReadiness = math.Round(float64(total)/float64(report.Analyzed)*10) / 10
+	}
+	return report, nil
+}
+
+// ParseBacklogExport reads the stories of an exported backlog in CSV or Jira JSON format.
+func ParseBacklogExport(format string, data []byte) ([]domain.ImportedStory, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return parseCSVBacklog(data)
+	case domain.ImportFormatJira:
+		return parseJiraBacklog(data)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q, expected \"csv\" or \"jira\"", format)
+	}
+}
+
+// parseCSVBacklog reads a CSV file with a header row. The title column is "Summary" or "Title", the
+// description column "Description" and the key column "Issue key", "Key" or "ID"; other columns are
+// ignored. Jira's CSV export uses these names.
+func parseCSVBacklog(data []byte) ([]domain.ImportedStory, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	titleCol, descriptionCol, keyCol := -1, -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "summary", "title":
+			if titleCol < 0 {
+				titleCol = i
+			}
+		case "description":
+			if descriptionCol < 0 {
+				descriptionCol = i
+			}
+		case "issue key", "key", "id":
+			if keyCol < 0 {
+				keyCol = i
+			}
+		}
+	}
+	if titleCol < 0 {
+		return nil, fmt.Errorf("the CSV file has no \"Summary\" or \"Title\" column")
+	}
+
+	var stories []domain.ImportedStory
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		story := domain.ImportedStory{Title: strings.TrimSpace(column(record, titleCol))}
+		story.Description = strings.TrimSpace(column(record, descriptionCol))
+		story.Key = strings.TrimSpace(column(record, keyCol))
+		if story.Title == "" && story.Description == "" {
+			continue
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+func column(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// parseJiraBacklog reads the issues of a Jira search API response. Descriptions are plain text (API v2)
+// or Atlassian Document Format (API v3).
+func parseJiraBacklog(data []byte) ([]domain.ImportedStory, error) {
+	var export struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary     string          `json:"summary"`
+				Description json.RawMessage `json:"description"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse Jira export: %w", err)
+	}
+	var stories []domain.ImportedStory
+	for _, issue := range export.Issues {
+		story := domain.ImportedStory{Key: issue.Key, Title: strings.TrimSpace(issue.Fields.Summary)}
+		var text string
+		if err := json.Unmarshal(issue.Fields.Description, &text); err == nil {
+			story.Description = strings.TrimSpace(text)
+		} else {
+			var doc adfNode
+			if err := json.Unmarshal(issue.Fields.Description, &doc); err == nil {
+				var b strings.Builder
+				doc.writeText(&b)
+				story.Description = strings.TrimSpace(b.String())
+			}
+		}
+		if story.Title == "" && story.Description == "" {
+			continue
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+// adfNode is a node of an Atlassian Document Format document.
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+// writeText writes the text of the node, one line per block.
+func (n adfNode) writeText(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+	case "hardBreak":
+		b.WriteString("\n")
+	}
+	for _, child := range n.Content {
+		child.writeText(b)
+	}
+	switch n.Type {
+	case "paragraph", "heading", "listItem", "codeBlock", "blockquote":
+		b.WriteString("\n")
+	}
+}
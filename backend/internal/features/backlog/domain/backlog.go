@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// Kinds of backlogs.
+const (
+	KindBacklog = "backlog"
+	KindSprint  = "sprint"
+)
+
+// Backlog is a named, ordered group of refinement sessions, such as a sprint or a product backlog.
+type Backlog struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"` // "backlog" or "sprint"
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	SessionIDs  []string  `json:"session_ids"` // In priority order
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BacklogRequest is the request structure for creating or renaming a backlog.
+type BacklogRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Kind        string `json:"kind,omitempty"` // Defaults to "backlog"
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+// SessionsRequest lists the sessions to add to a backlog, or the new order of all of its sessions.
+type SessionsRequest struct {
+	SessionIDs []string `json:"session_ids" binding:"required"`
+}
+
+// ExportRequest selects the tracker a backlog's refined stories are exported to.
+type ExportRequest struct {
+	Tracker string `json:"tracker" binding:"required"` // "jira" or "gitlab"
+}
+
+// ExportItem is the outcome of exporting one session of a backlog.
+type ExportItem struct {
+	SessionID string `json:"session_id"`
+	IssueKey  string `json:"issue_key,omitempty"` // Jira issue key or GitLab issue IID
+	IssueURL  string `json:"issue_url,omitempty"`
+	Created   bool   `json:"created"`
+	Skipped   string `json:"skipped,omitempty"` // Why the session was not exported
+	Error     string `json:"error,omitempty"`
+}
+
+// ExportResult is the outcome of exporting a backlog, in backlog order.
+type ExportResult struct {
+	BacklogID string       `json:"backlog_id"`
+	Tracker   string       `json:"tracker"`
+	Exported  int          `json:"exported"`
+	Failed    int          `json:"failed"`
+	Items     []ExportItem `json:"items"`
+}
+
+// Formats of imported backlogs.
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJira = "jira" // Jira search API response or JSON export: {"issues": [{"key", "fields": {...}}]}
+)
+
+// ImportedStory is a story read from an exported backlog.
+type ImportedStory struct {
+	Key         string `json:"key,omitempty"` // Issue key in the source tracker
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// BaselineItem is the analysis of one imported story.
+type BaselineItem struct {
+	ImportedStory
+	Rank        int      `json:"rank"`      // 1 is the story to refine first
+	Readiness   int      `json:"readiness"` // 0 (not ready at all) to 100 (ready for development)
+	MissingInfo []string `json:"missing_info"`
+	Summary     string   `json:"summary,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// BaselineReport is the quality analysis of an imported backlog, least ready stories first.
+type BaselineReport struct {
+	Stories          int            `json:"stories"`
+	Analyzed         int            `json:"analyzed"`
+	Failed           int            `json:"failed"`
+	AverageReadiness float64        `json:"average_readiness"`
+	MissingInfo      map[string]int `json:"missing_info"` // Number of stories missing each category
+	Items            []BaselineItem `json:"items"`
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/backlog/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// BacklogRepository defines the interface for backlog persistence.
+type BacklogRepository interface {
+	List() ([]domain.Backlog, error)
+	Get(id string) (*domain.Backlog, error)
+	Save(backlog *domain.Backlog) error
+	Delete(id string) error
+}
+
+// jsonBacklogRepository stores backlogs in a JSON file.
+type jsonBacklogRepository struct {
+	file *jsonfile.Store[[]domain.Backlog]
+	mu   sync.Mutex
+}
+
+// NewJSONBacklogRepository creates a new repository backed by the given JSON file.
+func NewJSONBacklogRepository(path string) BacklogRepository {
+	return &jsonBacklogRepository{file: jsonfile.NewStore[[]domain.Backlog](path, "backlogs")}
+}
+
+// List returns all backlogs.
+func (r *jsonBacklogRepository) List() ([]domain.Backlog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Get returns the backlog with the given ID.
+func (r *jsonBacklogRepository) Get(id string) (*domain.Backlog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backlogs, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range backlogs {
+		if backlogs[i].ID == id {
+			return &backlogs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backlog %s not found", id)
+}
+
+// Save creates or replaces a backlog.
+func (r *jsonBacklogRepository) Save(backlog *domain.Backlog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backlogs, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range backlogs {
+		if backlogs[i].ID == backlog.ID {
+			backlogs[i] = *backlog
+			replaced = true
+		}
+	}
+	if !replaced {
+		backlogs = append(backlogs, *backlog)
+	}
+	return r.file.Store(backlogs)
+}
+
+// Delete removes a backlog.
+func (r *jsonBacklogRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backlogs, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range backlogs {
+		if backlogs[i].ID == id {
+			return r.file.Store(append(backlogs[:i], backlogs[i+1:]...))
+		}
+	}
+	return fmt.Errorf("backlog %s not found", id)
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"sofa-commander/backend/internal/async"
+	"sofa-commander/backend/internal/features/backlog/application"
+	"sofa-commander/backend/internal/features/backlog/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes bounds the size of an imported backlog.
+const maxImportBytes = 10 << 20
+
+// BacklogHandler holds the backlog service.
+type BacklogHandler struct {
+	backlogService application.BacklogService
+	async          *async.Runner
+}
+
+// NewBacklogHandler creates a new BacklogHandler running its AI operations with the async runner.
+func NewBacklogHandler(backlogService application.BacklogService, asyncRunner *async.Runner) *BacklogHandler {
+	return &BacklogHandler{
+		backlogService: backlogService,
+		async:          asyncRunner,
+	}
+}
+
+// ListBacklogsHandler handles listing backlogs, optionally filtered by the `workspace_id` query parameter.
+func (h *BacklogHandler) ListBacklogsHandler(c *gin.Context) {
+	backlogs, err := h.backlogService.ListBacklogs(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list backlogs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlogs)
+}
+
+// GetBacklogHandler handles fetching a single backlog.
+func (h *BacklogHandler) GetBacklogHandler(c *gin.Context) {
+	backlog, err := h.backlogService.GetBacklog(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlog)
+}
+
+// CreateBacklogHandler handles creating a backlog.
+func (h *BacklogHandler) CreateBacklogHandler(c *gin.Context) {
+	var req domain.BacklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	backlog, err := h.backlogService.CreateBacklog(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create backlog: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, backlog)
+}
+
+// UpdateBacklogHandler handles renaming a backlog.
+func (h *BacklogHandler) UpdateBacklogHandler(c *gin.Context) {
+	var req domain.BacklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	backlog, err := h.backlogService.UpdateBacklog(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update backlog: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlog)
+}
+
+// DeleteBacklogHandler handles deleting a backlog.
+func (h *BacklogHandler) DeleteBacklogHandler(c *gin.Context) {
+	if err := h.backlogService.DeleteBacklog(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Backlog deleted"})
+}
+
+// AddSessionsHandler handles appending sessions to a backlog.
+func (h *BacklogHandler) AddSessionsHandler(c *gin.Context) {
+	var req domain.SessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	backlog, err := h.backlogService.AddSessions(c.Param("id"), req.SessionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add sessions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlog)
+}
+
+// RemoveSessionHandler handles removing a session from a backlog.
+func (h *BacklogHandler) RemoveSessionHandler(c *gin.Context) {
+	backlog, err := h.backlogService.RemoveSession(c.Param("id"), c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to remove session: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlog)
+}
+
+// ReorderHandler handles setting the order of a backlog's sessions.
+func (h *BacklogHandler) ReorderHandler(c *gin.Context) {
+	var req domain.SessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	backlog, err := h.backlogService.Reorder(c.Param("id"), req.SessionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to reorder backlog: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backlog)
+}
+
+// ExportHandler handles exporting all refined stories of a backlog to a tracker.
+func (h *BacklogHandler) ExportHandler(c *gin.Context) {
+	var req domain.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.backlogService.Export(c.Param("id"), req.Tracker)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to export backlog: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportBaselineHandler handles importing an exported backlog, sent as the raw request body, and
+// responds with its readiness report. The `format` query parameter is "csv" or "jira", defaulting from
+// the content type; `workspace_id` selects the AI provider. The analysis runs within its latency
+// budget like the refinement operations, or as an async job on request.
+func (h *BacklogHandler) ImportBaselineHandler(c *gin.Context) {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "The exported backlog exceeds the size limit of 10 MB"})
+		return
+	}
+	if err != nil || len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The request body must contain the exported backlog"})
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = domain.ImportFormatCSV
+		if c.ContentType() == "application/json" {
+			format = domain.ImportFormatJira
+		}
+	}
+	workspaceID := c.Query("workspace_id")
+	h.async.RespondWithinBudget(c, "import_backlog", func(ctx context.Context) (int, any) {
+		report, err := h.backlogService.ImportBaseline(ctx, workspaceID, format, data)
+		if err != nil {
+			return http.StatusBadRequest, gin.H{"error": "Failed to import backlog: " + err.Error()}
+		}
+		return http.StatusOK, report
+	})
+}
@@ -0,0 +1,383 @@
+package application
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/backup/domain"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+// sessionDatabasePatterns match the SQLite session database and its journals, which are backed up
+// through the session repository instead, so backups restore into either session store.
+var sessionDatabasePatterns = []string{"*.db", "*.db-*"}
+
+// lockFilePattern matches lock files, such as the one the running server holds, which are not backed up.
+const lockFilePattern = "*.lock"
+
+// BackupService defines the interface for backing up and restoring the data of an instance: the
+// sessions, and the files of the config and data directories, which hold the config, the story
+// backlogs and memory, and the usage data.
+type BackupService interface {
+	Create(w io.Writer) (*domain.Manifest, error)
+	Verify(r io.Reader) *domain.VerifyReport
+	Restore(archivePath string, force bool) (*domain.RestoreReport, error)
+}
+
+// backupService is the implementation of BackupService.
+type backupService struct {
+	sessions infrastructure.SessionRepository
+	dirs     []string
+	pause    func() func()
+}
+
+// NewBackupService creates a new instance of backupService backing up the sessions of a repository and
+// the files under dirs, relative to the working directory. pause is called to hold changes while a
+// backup is taken, and returns the function resuming them.
+func NewBackupService(sessions infrastructure.SessionRepository, dirs []string, pause func() func()) BackupService {
+	return &backupService{sessions: sessions, dirs: dirs, pause: pause}
+}
+
+// Create writes a backup archive, a gzipped tar of the files, the sessions as JSON lines and a manifest
+// of their checksums, which comes last.
+func (s *backupService) Create(w io.Writer) (*domain.Manifest, error) {
+	resume := s.pause()
+	defer resume()
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifest := &domain.Manifest{Version: domain.FormatVersion, CreatedAt: time.Now(), Entries: []domain.FileEntry{}}
+	add := func(name string, data []byte, mode fs.FileMode) error {
+		sum := sha256.Sum256(data)
+		manifest.Entries = append(manifest.Entries, domain.FileEntry{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		return writeEntry(tw, name, data, mode, manifest.CreatedAt)
+	}
+
+	for _, dir := range s.dirs {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == dir && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() || isSessionDatabase(d.Name()) || isLockFile(d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return add(domain.FilesDir+filepath.ToSlash(p), data, info.Mode().Perm())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", dir, err)
+		}
+	}
+
+	sessions, err := s.sessions.List()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, session := range sessions {
+		data, err := refinementdomain.EncodeSession(session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up session %s: %w", session.ID, err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	manifest.Sessions = len(sessions)
+	if err := add(domain.SessionsPath, buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeEntry(tw, domain.ManifestPath, data, 0644, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeEntry writes a file to a tar archive.
+func writeEntry(tw *tar.Writer, name string, data []byte, mode fs.FileMode, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: int64(mode), Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to backup: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to backup: %w", name, err)
+	}
+	return nil
+}
+
+// Verify checks a backup archive against its manifest: every entry must be listed with its size and
+// checksum, every listed entry present, the files within the backed-up directories and the sessions
+// readable.
+func (s *backupService) Verify(r io.Reader) *domain.VerifyReport {
+	report := &domain.VerifyReport{}
+	problem := func(format string, args ...any) {
+		report.Problems = append(report.Problems, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[string]domain.FileEntry)
+	sessions := -1
+	err := readArchive(r, func(name string, mode fs.FileMode, data []byte) error {
+		if name == domain.ManifestPath {
+			var manifest domain.Manifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				problem("manifest is unreadable: %v", err)
+				return nil
+			}
+			report.Manifest = &manifest
+			return nil
+		}
+		if _, ok := seen[name]; ok {
+			problem("%s appears more than once", name)
+		}
+		sum := sha256.Sum256(data)
+		seen[name] = domain.FileEntry{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+		if name == domain.SessionsPath {
+			n, err := decodeSessions(data, nil)
+			if err != nil {
+				problem("%v", err)
+			}
+			sessions = n
+		} else if _, err := s.restorePath(name); err != nil {
+			problem("%v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		problem("archive is unreadable: %v", err)
+		return report
+	}
+
+	manifest := report.Manifest
+	if manifest == nil {
+		problem("archive has no %s", domain.ManifestPath)
+		return report
+	}
+	if manifest.Version != domain.FormatVersion {
+		problem("unsupported backup version %d", manifest.Version)
+	}
+	listed := make(map[string]bool)
+	for _, entry := range manifest.Entries {
+		listed[entry.Path] = true
+		actual, ok := seen[entry.Path]
+		switch {
+		case !ok:
+			problem("%s is missing", entry.Path)
+		case actual.Size != entry.Size || actual.SHA256 != entry.SHA256:
+			problem("%s does not match its checksum", entry.Path)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(seen)) {
+		if !listed[name] {
+			problem("%s is not in the manifest", name)
+		}
+	}
+	if sessions != -1 && sessions != manifest.Sessions {
+		problem("%s holds %d sessions, the manifest lists %d", domain.SessionsPath, sessions, manifest.Sessions)
+	}
+	report.Valid = len(report.Problems) == 0
+	return report
+}
+
+// Restore restores a verified backup archive, overwriting the backed-up files. Unless forced, the
+// instance must be fresh, without sessions; the sessions of the archive replace stored ones with the
+// same ID.
+func (s *backupService) Restore(archivePath string, force bool) (*domain.RestoreReport, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+	report := s.Verify(f)
+	if !report.Valid {
+		return nil, fmt.Errorf("backup failed verification: %s", strings.Join(report.Problems, "; "))
+	}
+
+	if !force {
+		stored, err := s.sessions.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(stored) > 0 {
+			return nil, fmt.Errorf("instance already has %d sessions; restore with force to overwrite", len(stored))
+		}
+	}
+
+	resume := s.pause()
+	defer resume()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	restored := &domain.RestoreReport{}
+	err = readArchive(f, func(name string, mode fs.FileMode, data []byte) error {
+		switch name {
+		case domain.ManifestPath:
+			return nil
+		case domain.SessionsPath:
+			n, err := decodeSessions(data, s.sessions.Save)
+			restored.Sessions = n
+			return err
+		}
+		target, err := s.restorePath(name)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(target, data, mode); err != nil {
+			return err
+		}
+		restored.Files++
+		return nil
+	})
+	if err != nil {
+		return restored, fmt.Errorf("failed to restore backup: %w", err)
+	}
+	return restored, nil
+}
+
+// restorePath returns the path a file entry of an archive is restored to, which must be within one of
+// the backed-up directories.
+func (s *backupService) restorePath(name string) (string, error) {
+	rel, ok := strings.CutPrefix(name, domain.FilesDir)
+	if ok && filepath.IsLocal(filepath.FromSlash(rel)) {
+		// Cleaned first, so e.g. "config/../secret" is not taken for a file of the config directory
+		rel = path.Clean(rel)
+		for _, dir := range s.dirs {
+			if strings.HasPrefix(rel, filepath.ToSlash(filepath.Clean(dir))+"/") {
+				return filepath.FromSlash(rel), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unexpected entry %s", name)
+}
+
+// readArchive calls fn with every regular file of a gzipped tar archive.
+func readArchive(r io.Reader, fn func(name string, mode fs.FileMode, data []byte) error) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry %s", header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := fn(header.Name, fs.FileMode(header.Mode).Perm(), data); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeSessions decodes the sessions of a sessions entry, passes each to save unless it is nil, and
+// returns their number.
+func decodeSessions(data []byte, save func(session *refinementdomain.RefinementSession) error) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	n := 0
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		session, _, err := refinementdomain.DecodeSession(scanner.Bytes())
+		if err != nil {
+			return n, fmt.Errorf("session %d of %s is unreadable: %w", n+1, domain.SessionsPath, err)
+		}
+		if session.ID == "" {
+			return n, fmt.Errorf("session %d of %s has no ID", n+1, domain.SessionsPath)
+		}
+		if save != nil {
+			if err := save(session); err != nil {
+				return n, err
+			}
+		}
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// writeFileAtomic writes a file through a temporary file in the same directory, so a failed restore
+// leaves no partially written file.
+func writeFileAtomic(target string, data []byte, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// isSessionDatabase reports whether a file is part of a SQLite session database.
+func isSessionDatabase(name string) bool {
+	for _, pattern := range sessionDatabasePatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isLockFile reports whether a file is a lock file.
+func isLockFile(name string) bool {
+	ok, _ := filepath.Match(lockFilePattern, name)
+	return ok
+}
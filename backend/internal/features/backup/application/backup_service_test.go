@@ -0,0 +1,38 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRestorePath(t *testing.T) {
+	s := &backupService{dirs: []string{"config", "./data/"}}
+	tests := []struct {
+		name    string
+		entry   string
+		want    string
+		wantErr bool
+	}{
+		{name: "config file", entry: "files/config/app_config.json", want: filepath.Join("config", "app_config.json")},
+		{name: "nested data file", entry: "files/data/backlogs/b1.json", want: filepath.Join("data", "backlogs", "b1.json")},
+		{name: "path within the directories", entry: "files/config/../data/usage.jsonl", want: filepath.Join("data", "usage.jsonl")},
+		{name: "path leaving the directories", entry: "files/config/../secret", wantErr: true},
+		{name: "path leaving the working directory", entry: "files/config/../../etc/passwd", wantErr: true},
+		{name: "absolute path", entry: "files//etc/passwd", wantErr: true},
+		{name: "directory not backed up", entry: "files/logs/app.log", wantErr: true},
+		{name: "directory name prefix", entry: "files/configuration/app.json", wantErr: true},
+		{name: "backed-up directory itself", entry: "files/config", wantErr: true},
+		{name: "entry outside the files", entry: "manifest.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.restorePath(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restorePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("restorePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
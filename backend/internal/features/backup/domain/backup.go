@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// FormatVersion is the version of the backup archive layout. Archives of other versions cannot be
+// restored.
+const FormatVersion = 1
+
+// Paths of the archive entries besides the backed-up files, which are stored under FilesDir.
+const (
+	ManifestPath = "manifest.json"
+	SessionsPath = "sessions.jsonl"
+	FilesDir     = "files/"
+)
+
+// Manifest describes a backup archive: every other entry is listed with its checksum, so a truncated
+// or tampered archive is detected before anything is restored.
+type Manifest struct {
+	Version   int         `json:"version"`
+	CreatedAt time.Time   `json:"created_at"`
+	Sessions  int         `json:"sessions"` // Number of sessions in SessionsPath
+	Entries   []FileEntry `json:"entries"`
+}
+
+// FileEntry is an entry of a backup archive.
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// VerifyReport is the result of checking the integrity of a backup archive.
+type VerifyReport struct {
+	Valid    bool      `json:"valid"`
+	Manifest *Manifest `json:"manifest,omitempty"`
+	Problems []string  `json:"problems,omitempty"`
+}
+
+// RestoreReport summarizes a restored backup.
+type RestoreReport struct {
+	Files    int `json:"files"`
+	Sessions int `json:"sessions"`
+}
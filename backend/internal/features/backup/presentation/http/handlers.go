@@ -0,0 +1,67 @@
+package http
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+
+	"sofa-commander/backend/internal/features/backup/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler holds the backup service and the admin token required to use it.
+type BackupHandler struct {
+	backupService application.BackupService
+	adminToken    string
+}
+
+// NewBackupHandler creates a new BackupHandler. An empty admin token disables the endpoints.
+func NewBackupHandler(backupService application.BackupService, adminToken string) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+		adminToken:    adminToken,
+	}
+}
+
+func (h *BackupHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// CreateBackupHandler downloads a backup archive of the instance. Store writes wait while it is taken. The
+// archive is written to a temporary file first, so a failed backup is reported rather than truncated.
+func (h *BackupHandler) CreateBackupHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	tmp, err := os.CreateTemp("", "sofa-commander-backup-*.tar.gz")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup: " + err.Error()})
+		return
+	}
+	defer os.Remove(tmp.Name())
+	manifest, err := h.backupService.Create(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup: " + err.Error()})
+		return
+	}
+	c.Header("X-Backup-Sessions", fmt.Sprint(manifest.Sessions))
+	c.FileAttachment(tmp.Name(), fmt.Sprintf("sofa-commander-backup-%s.tar.gz", manifest.CreatedAt.Format("20060102-150405")))
+}
+
+// VerifyBackupHandler checks the integrity of a backup archive sent as the request body.
+func (h *BackupHandler) VerifyBackupHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	c.JSON(http.StatusOK, h.backupService.Verify(c.Request.Body))
+}
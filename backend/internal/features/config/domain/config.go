@@ -1,11 +1,48 @@
 package domain
 
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
 // AppConfig represents the application configuration.
 type AppConfig struct {
 	ProductContext      string                          `json:"product_context"`
 	RolePrompts         map[string]string               `json:"role_prompts"`
 	PhasePrompts        map[string]string               `json:"phase_prompts"`
 	PhaseFormatExamples map[string][]PhaseFormatExample `json:"phase_format_examples"`
+	RoleExemplars       map[string][]RoleExemplar       `json:"role_exemplars,omitempty"`
+	RoleLimits          map[string]RoleLimit            `json:"role_limits,omitempty"`       // Keyed by role name
+	RoleDisplay         map[string]RoleDisplay          `json:"role_display,omitempty"`      // Keyed by role name
+	RoleRules           []RoleRule                      `json:"role_rules,omitempty"`        // Select the roles of sessions started without any
+	SessionTypes        map[string]SessionTypeConfig    `json:"session_types,omitempty"`     // Keyed by session type, e.g. "spike"
+	Workflow            WorkflowConfig                  `json:"workflow,omitempty"`          // Phases sessions go through
+	WorkspacePrompts    map[string]PromptSet            `json:"workspace_prompts,omitempty"` // Per-workspace overrides, keyed by workspace ID
+	StyleLint           StyleLintConfig                 `json:"style_lint,omitempty"`
+	ScoringRubric       ScoringRubric                   `json:"scoring_rubric,omitempty"`
+	Retrospective       RetrospectiveConfig             `json:"retrospective,omitempty"`
+	Glossary            []GlossaryTerm                  `json:"glossary,omitempty"`
+	Regulations         []Regulation                    `json:"regulations,omitempty"` // Compliance concerns the optional compliance phase checks
+	KnowledgeBase       []KnowledgeDocument             `json:"knowledge_base,omitempty"`
+	AssistantTools      AssistantToolsConfig            `json:"assistant_tools,omitempty"`
+	SuggestionEnsemble  SuggestionEnsembleConfig        `json:"suggestion_ensemble,omitempty"`
+	Localization        LocalizationConfig              `json:"localization,omitempty"`
+	ShadowModel         ShadowModelConfig               `json:"shadow_model,omitempty"`
+	LatencyBudgets      map[string]LatencyBudget        `json:"latency_budgets,omitempty"` // Keyed by operation, "*" applies to operations without their own
+	ModelPrices         map[string]ModelPrice           `json:"model_prices,omitempty"`    // Keyed by model, used to estimate the cost of usage
+	OutputFilter        OutputFilterConfig              `json:"output_filter,omitempty"`
+	Experimental        ExperimentalConfig              `json:"experimental,omitempty"`
+	ExportTemplates     map[string]ExportTemplate       `json:"export_templates,omitempty"` // Keyed by template name
+	Jira                JiraConfig                      `json:"jira,omitempty"`
+	GitLab              GitLabConfig                    `json:"gitlab,omitempty"`
+	Email               EmailConfig                     `json:"email,omitempty"`
+	Approval            ApprovalConfig                  `json:"approval,omitempty"`
+	Slack               SlackConfig                     `json:"slack,omitempty"`
+	Auth                AuthConfig                      `json:"auth,omitempty"`
+	Telemetry           TelemetryConfig                 `json:"telemetry,omitempty"`
+	PublicBaseURL       string                          `json:"public_base_url,omitempty"` // Used to link back to sessions from other tools
+	OfflineMode         bool                            `json:"offline_mode,omitempty"`    // Disables AI operations; sessions stay viewable and exportable
 	ModelParams         ModelParams                     `json:"model_params"`
 }
 
@@ -15,7 +52,392 @@ type ModelParams struct {
 	MaxTokens   int     `json:"max_tokens"`
 }
 
+// ModelPrice is the price of a model's tokens, in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Cost returns the cost of a model run in USD.
+func (p ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6
+}
+
 type PhaseFormatExample struct {
 	Role   string   `json:"role"`
 	Prompt []string `json:"prompt"`
 }
+
+// RoleExemplar is a curated Q&A pair from a past high-quality session, used as a few-shot example in a role's prompt.
+type RoleExemplar struct {
+	ID              string `json:"id"`
+	Question        string `json:"question"`
+	Answer          string `json:"answer"`
+	SourceSessionID string `json:"source_session_id,omitempty"`
+}
+
+// PromptSet is the prompt configuration of a team: what a marketplace package shares and what a
+// workspace may override.
+type PromptSet struct {
+	RolePrompts         map[string]string               `json:"role_prompts"`
+	PhasePrompts        map[string]string               `json:"phase_prompts"`
+	PhaseFormatExamples map[string][]PhaseFormatExample `json:"phase_format_examples"`
+	RoleExemplars       map[string][]RoleExemplar       `json:"role_exemplars,omitempty"`
+	RoleLimits          map[string]RoleLimit            `json:"role_limits,omitempty"`
+	Package             string                          `json:"package,omitempty"` // "name@version" when imported from the marketplace
+}
+
+// Prompts returns the global prompt configuration.
+func (c *AppConfig) Prompts() PromptSet {
+	return PromptSet{
+		RolePrompts:         c.RolePrompts,
+		PhasePrompts:        c.PhasePrompts,
+		PhaseFormatExamples: c.PhaseFormatExamples,
+		RoleExemplars:       c.RoleExemplars,
+		RoleLimits:          c.RoleLimits,
+	}
+}
+
+// SetPrompts replaces the global prompt configuration.
+func (c *AppConfig) SetPrompts(p PromptSet) {
+	c.RolePrompts = p.RolePrompts
+	c.PhasePrompts = p.PhasePrompts
+	c.PhaseFormatExamples = p.PhaseFormatExamples
+	c.RoleExemplars = p.RoleExemplars
+	c.RoleLimits = p.RoleLimits
+}
+
+// ForWorkspace returns the config with the workspace's prompt overrides applied, or the config itself
+// when the workspace has none.
+func (c *AppConfig) ForWorkspace(workspaceID string) *AppConfig {
+	prompts, ok := c.WorkspacePrompts[workspaceID]
+	if workspaceID == "" || !ok {
+		return c
+	}
+	resolved := *c
+	resolved.SetPrompts(prompts)
+	return &resolved
+}
+
+// SessionTypeConfig overrides the prompts of a session type other than the default user story.
+type SessionTypeConfig struct {
+	PhasePrompts map[string]string `json:"phase_prompts,omitempty"` // Keyed by "questioning", "questioning_<round>", "suggesting" or "finalize"
+}
+
+// RoleDisplay describes a role to people reading its questions and suggestions.
+type RoleDisplay struct {
+	Name        string            `json:"name,omitempty"`  // Display name, the role key when empty
+	Names       map[string]string `json:"names,omitempty"` // Display name per language, e.g. "zh-TW" or "en"
+	Description string            `json:"description,omitempty"`
+	Color       string            `json:"color,omitempty"` // CSS color, e.g. "#4f46e5"
+	Icon        string            `json:"icon,omitempty"`  // Icon name or image URL, interpreted by the client
+	Order       int               `json:"order,omitempty"` // Roles are listed by ascending order, then in the session's role order
+}
+
+// DisplayName returns the role's display name in a language, falling back to the language without its
+// region, then to Name, then to the role key.
+func (d RoleDisplay) DisplayName(role, language string) string {
+	if name := d.Names[language]; language != "" && name != "" {
+		return name
+	}
+	if base, _, found := strings.Cut(language, "-"); found && d.Names[base] != "" {
+		return d.Names[base]
+	}
+	if d.Name != "" {
+		return d.Name
+	}
+	return role
+}
+
+// RoleRule adds a set of roles to sessions started without selected roles whose story has one of the
+// labels or mentions one of the keywords, e.g. the security role to stories mentioning "payment". A rule
+// without labels and keywords applies to every such session.
+type RoleRule struct {
+	Name     string   `json:"name"`
+	Labels   []string `json:"labels,omitempty"`   // Matched against the tags of the request, ignoring case
+	Keywords []string `json:"keywords,omitempty"` // Matched against the story, ignoring case
+	Roles    []string `json:"roles"`
+}
+
+// RoleLimit bounds how many questions and suggestions a role contributes per round; 0 means unbounded.
+type RoleLimit struct {
+	MinQuestions   int `json:"min_questions,omitempty"`
+	MaxQuestions   int `json:"max_questions,omitempty"`
+	MinSuggestions int `json:"min_suggestions,omitempty"`
+	MaxSuggestions int `json:"max_suggestions,omitempty"`
+}
+
+// RolePromptsWithExemplars returns the role prompts with each role's exemplars appended as few-shot examples.
+func (c *AppConfig) RolePromptsWithExemplars() map[string]string {
+	if len(c.RoleExemplars) == 0 {
+		return c.RolePrompts
+	}
+	result := make(map[string]string, len(c.RolePrompts))
+	for role, prompt := range c.RolePrompts {
+		exemplars := c.RoleExemplars[role]
+		if len(exemplars) == 0 {
+			result[role] = prompt
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(prompt)
+		b.WriteString("\n參考範例問答（來自過往高品質的打磨紀錄，請學習其具體程度）：")
+		for _, ex := range exemplars {
+			b.WriteString("\n  Q: " + ex.Question)
+			if ex.Answer != "" {
+				b.WriteString("\n  A: " + ex.Answer)
+			}
+		}
+		result[role] = b.String()
+	}
+	return result
+}
+
+// StyleLintConfig configures the readability and style checks run on finalized stories.
+// Zero values fall back to the linter defaults.
+type StyleLintConfig struct {
+	Disabled          bool     `json:"disabled,omitempty"`
+	MaxSentenceLength int      `json:"max_sentence_length,omitempty"` // In characters
+	CheckPassiveVoice bool     `json:"check_passive_voice,omitempty"`
+	UserClauses       []string `json:"user_clauses,omitempty"`    // e.g. "As a", "作為"
+	BenefitClauses    []string `json:"benefit_clauses,omitempty"` // e.g. "so that", "以便"
+	UntestableTerms   []string `json:"untestable_terms,omitempty"`
+}
+
+// GlossaryTerm is a canonical product term and the variants that should be replaced by it.
+type GlossaryTerm struct {
+	Term       string   `json:"term"`
+	Variants   []string `json:"variants,omitempty"` // e.g. "basket" for the term "cart"
+	Definition string   `json:"definition,omitempty"`
+}
+
+// OutputFilterConfig blocks terms, e.g. competitor names, profanity or banned phrasing, in the questions,
+// suggestions and final stories the AI generates.
+type OutputFilterConfig struct {
+	BlockedTerms []BlockedTerm `json:"blocked_terms,omitempty"`
+	Regenerate   bool          `json:"regenerate,omitempty"` // Ask the AI once to rewrite offending output before scrubbing what remains
+	Mask         string        `json:"mask,omitempty"`       // Replaces terms without a replacement of their own, "***" by default
+}
+
+// BlockedTerm is a term the AI's output must not contain, matched case-insensitively.
+type BlockedTerm struct {
+	Term        string `json:"term"`
+	Category    string `json:"category,omitempty"`    // e.g. "competitor", "profanity" or "phrasing"
+	Replacement string `json:"replacement,omitempty"` // e.g. "a competitor"; the mask when empty
+}
+
+// Regulation is a compliance concern relevant to the product, e.g. GDPR, PCI DSS or HIPAA.
+type Regulation struct {
+	Name     string `json:"name"`
+	Concerns string `json:"concerns,omitempty"` // What the regulation requires of this product, e.g. "EU customers' personal data"
+}
+
+// KnowledgeDocument is a project document the assistant can search while refining.
+type KnowledgeDocument struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// AssistantToolsConfig enables function tools that ground the assistant in project data.
+// Each tool is only offered when its data source (glossary, knowledge base, Jira) is configured.
+type AssistantToolsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SuggestionEnsembleConfig has suggestions generated by a second model alongside the assistant's model,
+// merged and deduplicated with the models that proposed each suggestion.
+type SuggestionEnsembleConfig struct {
+	Model string `json:"model,omitempty"` // Second model, empty disables the ensemble
+}
+
+// LocalizationConfig configures the localization support of refinement.
+type LocalizationConfig struct {
+	AnalyzeSuggestions bool `json:"analyze_suggestions,omitempty"` // Flag localization impacts in a suggestion group for all sessions
+}
+
+// ShadowModelConfig names a candidate model that silently runs the same rounds as the default model.
+// Its outputs are recorded for comparison and never shown.
+type ShadowModelConfig struct {
+	Model string `json:"model,omitempty"` // Empty disables shadow runs
+}
+
+// RetrospectiveConfig schedules the retrospective insights report.
+type RetrospectiveConfig struct {
+	SprintDays  int    `json:"sprint_days,omitempty"`  // Length of a sprint; a report on the last sprint is generated every SprintDays, 0 disables the schedule
+	WorkspaceID string `json:"workspace_id,omitempty"` // Workspace whose AI provider classifies the questions, and whose sessions are analyzed when set
+}
+
+// DefaultRunTimeout is how long a single AI run may take when its budget sets no timeout.
+const DefaultRunTimeout = 10 * time.Minute
+
+// LatencyBudget bounds how long an AI operation may take. When a run exceeds the budget, it is retried
+// on the fallback model if one is set, and the request stops waiting and returns a pending result that
+// the client can keep waiting on. A run exceeding the timeout is cancelled.
+type LatencyBudget struct {
+	Seconds        int    `json:"seconds,omitempty"`         // 0 disables the budget
+	FallbackModel  string `json:"fallback_model,omitempty"`  // Faster model to switch to when the budget is exceeded
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // Hard limit of a single run, 0 uses DefaultRunTimeout
+}
+
+// LatencyBudgetFor returns the budget of an operation, or the "*" budget when it has none.
+func LatencyBudgetFor(budgets map[string]LatencyBudget, operation string) LatencyBudget {
+	if budget, ok := budgets[operation]; ok {
+		return budget
+	}
+	return budgets["*"]
+}
+
+// Wait returns how long a request waits for an operation before returning a pending result: the
+// budget, plus a second budget for the fallback model when one is set. It is 0 without a budget.
+func (b LatencyBudget) Wait() time.Duration {
+	wait := time.Duration(b.Seconds) * time.Second
+	if b.FallbackModel != "" {
+		wait *= 2
+	}
+	return wait
+}
+
+// RunTimeout returns how long a single run may take before it is cancelled.
+func (b LatencyBudget) RunTimeout() time.Duration {
+	if b.TimeoutSeconds > 0 {
+		return time.Duration(b.TimeoutSeconds) * time.Second
+	}
+	return DefaultRunTimeout
+}
+
+// ScoringRubric defines how finalized stories are scored. Every finalized story is scored against the
+// criteria when at least one is defined.
+type ScoringRubric struct {
+	Criteria        []RubricCriterion `json:"criteria,omitempty"`
+	ExportThreshold float64           `json:"export_threshold,omitempty"` // Minimum total score (0-100) for exports, 0 disables the gate
+}
+
+// RubricCriterion is a weighted criterion of the scoring rubric.
+type RubricCriterion struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Weight      float64 `json:"weight"` // Relative weight, 1 when not given
+}
+
+// ExperimentalConfig enables features that are still being evaluated and may change or be removed.
+type ExperimentalConfig struct {
+	// PrefetchSuggestions generates suggestions in the background when the PM is predicted to ask for them next
+	PrefetchSuggestions bool `json:"prefetch_suggestions"`
+}
+
+// ExportTemplate is an admin-defined Go template rendering a session into an export format.
+type ExportTemplate struct {
+	Format      string `json:"format"`       // "markdown", "jira", "confluence", ...
+	ContentType string `json:"content_type"` // e.g. "text/markdown"
+	Body        string `json:"body"`         // Go text/template over the export data model
+}
+
+// JiraConfig holds the Jira REST API connection used for exporting and syncing stories.
+type JiraConfig struct {
+	BaseURL    string `json:"base_url"` // e.g. https://your-company.atlassian.net
+	Email      string `json:"email"`
+	APIToken   string `json:"api_token,omitempty"` // JIRA_API_TOKEN overrides this when set
+	ProjectKey string `json:"project_key"`
+	IssueType  string `json:"issue_type,omitempty"` // Defaults to "Story"
+	// ACFormat is "description" (the default), or "checklist" to also create a sub-task per AC along with
+	// the issue, so the team can tick them off; later syncs update the description only
+	ACFormat    string `json:"ac_format,omitempty"`
+	SubtaskType string `json:"subtask_type,omitempty"` // Issue type of the checklist sub-tasks, defaults to "Sub-task"
+}
+
+// GitLabConfig holds the GitLab connection used for exporting stories and receiving issue webhooks.
+type GitLabConfig struct {
+	BaseURL       string   `json:"base_url,omitempty"` // Defaults to https://gitlab.com
+	Token         string   `json:"token,omitempty"`    // GITLAB_TOKEN overrides this when set
+	ProjectID     string   `json:"project_id"`         // Numeric ID or URL-encoded path of the export project
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+	TriggerLabel  string   `json:"trigger_label,omitempty"` // Defaults to "needs-refinement"
+	DefaultRoles  []string `json:"default_roles,omitempty"` // Roles for webhook-started sessions, all roles when empty
+}
+
+// RoleNames returns the names of all configured roles in a stable order.
+func (c *AppConfig) RoleNames() []string {
+	names := make([]string, 0, len(c.RolePrompts))
+	for name := range c.RolePrompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SessionURL returns the link to a session in the web UI, or an empty string without a public base URL.
+func (c *AppConfig) SessionURL(sessionID string) string {
+	if c.PublicBaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(c.PublicBaseURL, "/") + "/?session=" + sessionID
+}
+
+// EmailConfig holds the SMTP settings for outgoing mail and the inbound email integration.
+type EmailConfig struct {
+	SMTPHost             string   `json:"smtp_host"`
+	SMTPPort             int      `json:"smtp_port,omitempty"` // Defaults to 587
+	SMTPUsername         string   `json:"smtp_username,omitempty"`
+	SMTPPassword         string   `json:"smtp_password,omitempty"` // SMTP_PASSWORD overrides this when set
+	From                 string   `json:"from"`                    // e.g. refine@company.com
+	InboundSecret        string   `json:"inbound_secret,omitempty"`
+	AllowedSenderDomains []string `json:"allowed_sender_domains,omitempty"` // Any sender when empty
+	DefaultRoles         []string `json:"default_roles,omitempty"`          // Roles for email-started sessions, all roles when empty
+}
+
+// ApprovalConfig designates the reviewers who sign off finalized stories (Definition of Ready).
+// Without reviewers, finalized stories need no approval. Reviewers are managed through the admin API.
+type ApprovalConfig struct {
+	Reviewers []Reviewer `json:"reviewers,omitempty"`
+}
+
+// Reviewer is a designated reviewer, identified by the user ID they authenticate as.
+type Reviewer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Role  string `json:"role,omitempty"` // e.g. "Tech Lead", "QA Lead"
+	Email string `json:"email,omitempty"`
+}
+
+// SlackConfig holds the Slack bot used to send direct messages.
+type SlackConfig struct {
+	BotToken string `json:"bot_token,omitempty"` // SLACK_BOT_TOKEN overrides this when set
+}
+
+// TelemetryConfig opts in to reporting anonymized, aggregate usage to the maintainers: request and error
+// counts per API route and event counts per type, never content, IDs or names. GET /api/telemetry shows
+// the next report.
+type TelemetryConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Endpoint      string `json:"endpoint,omitempty"`       // URL the reports are posted to
+	IntervalHours int    `json:"interval_hours,omitempty"` // Defaults to 24
+}
+
+// AuthConfig holds the authentication of the /api routes. Requests authenticate with an API key, in the
+// X-API-Key header or as a bearer token, or with a JWT bearer token.
+type AuthConfig struct {
+	Enabled bool      `json:"enabled"`
+	APIKeys []APIKey  `json:"api_keys,omitempty"` // Issued and revoked through the admin API only
+	JWT     JWTConfig `json:"jwt,omitempty"`
+}
+
+// APIKey is an issued API key. Only its hash is stored; the key itself is shown once when issued.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	UserID    string     `json:"user_id,omitempty"` // Requests with the key act as this user
+	Prefix    string     `json:"prefix"`            // Start of the key, to recognize it
+	Hash      string     `json:"hash"`              // Hex SHA-256 of the key
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// JWTConfig holds how JWT bearer tokens are verified: HMAC-signed tokens with the secret, RSA or ECDSA
+// signed tokens with the PEM public key. Tokens act as the user of their user claim.
+type JWTConfig struct {
+	Secret    string `json:"secret,omitempty"`     // JWT_SECRET overrides this when set
+	PublicKey string `json:"public_key,omitempty"` // PEM
+	Issuer    string `json:"issuer,omitempty"`     // Required issuer, unless empty
+	Audience  string `json:"audience,omitempty"`   // Required audience, unless empty
+	UserClaim string `json:"user_claim,omitempty"` // "sub" by default
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// AppConfigVersion is a saved version of the application configuration. Every save that changes the
+// configuration appends a version, so earlier ones can be inspected and rolled back to.
+type AppConfigVersion struct {
+	Version int        `json:"version"` // Starting at 1, the configuration found before the first recorded save
+	SavedAt time.Time  `json:"saved_at"`
+	Author  string     `json:"author,omitempty"` // X-User-ID of the request that saved it, empty for internal updates
+	Note    string     `json:"note,omitempty"`   // e.g. "rollback to version 3"
+	Config  *AppConfig `json:"config,omitempty"` // Left out of version listings
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Validate checks that a format example has the shape the parser expects of question and suggestion
+// output: a role and at least one non-blank prompt.
+func (e PhaseFormatExample) Validate() error {
+	if strings.TrimSpace(e.Role) == "" {
+		return fmt.Errorf("format example has no role")
+	}
+	if len(e.Prompt) == 0 {
+		return fmt.Errorf("format example of role %s has no prompt", e.Role)
+	}
+	if slices.ContainsFunc(e.Prompt, func(prompt string) bool { return strings.TrimSpace(prompt) == "" }) {
+		return fmt.Errorf("format example of role %s has a blank prompt", e.Role)
+	}
+	return nil
+}
+
+// FormatExampleKeys returns the phase prompt keys whose format examples are shown to the assistant: the
+// questioning and suggesting prompts, and the prompts of the workflow's question and suggestion phases.
+// Their output is parsed as items of a role and its prompts.
+func FormatExampleKeys(phases []WorkflowPhase) []string {
+	keys := []string{"questioning", "suggesting"}
+	for _, phase := range phases {
+		if phase.Output == WorkflowOutputQuestions || phase.Output == WorkflowOutputSuggestions {
+			if !slices.Contains(keys, phase.PromptKey) {
+				keys = append(keys, phase.PromptKey)
+			}
+		}
+	}
+	return keys
+}
+
+// LintFormatExamples checks the phase format examples of a prompt set against the output the parser
+// expects: every example needs a role and at least one non-blank prompt, or it returns an error.
+// Examples the assistant is never shown, of roles not defined in the role prompts or of phases without
+// question or suggestion output, are returned as warnings.
+func (p PromptSet) LintFormatExamples(phases []WorkflowPhase) ([]string, error) {
+	keys := FormatExampleKeys(phases)
+	phaseKeys := make([]string, 0, len(p.PhaseFormatExamples))
+	for key := range p.PhaseFormatExamples {
+		phaseKeys = append(phaseKeys, key)
+	}
+	slices.Sort(phaseKeys)
+
+	var warnings []string
+	for _, key := range phaseKeys {
+		if !slices.Contains(keys, key) {
+			warnings = append(warnings, fmt.Sprintf("format examples of phase %s are never used, phases with examples are %s", key, strings.Join(keys, ", ")))
+		}
+		for i, example := range p.PhaseFormatExamples[key] {
+			if err := example.Validate(); err != nil {
+				return warnings, fmt.Errorf("phase %s, example %d: %w", key, i+1, err)
+			}
+			if _, ok := p.RolePrompts[example.Role]; !ok {
+				warnings = append(warnings, fmt.Sprintf("format example %d of phase %s is for role %s, which is not defined in role_prompts", i+1, key, example.Role))
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// LintFormatExamples checks the format examples of the global prompts and of every workspace's prompt
+// overrides, as PromptSet.LintFormatExamples does. Warnings and errors of a workspace name it.
+func (c *AppConfig) LintFormatExamples() ([]string, error) {
+	phases := c.Workflow.Resolved()
+	warnings, err := c.Prompts().LintFormatExamples(phases)
+	if err != nil {
+		return warnings, err
+	}
+	workspaceIDs := make([]string, 0, len(c.WorkspacePrompts))
+	for id := range c.WorkspacePrompts {
+		workspaceIDs = append(workspaceIDs, id)
+	}
+	slices.Sort(workspaceIDs)
+	for _, id := range workspaceIDs {
+		workspaceWarnings, err := c.WorkspacePrompts[id].LintFormatExamples(phases)
+		for _, warning := range workspaceWarnings {
+			warnings = append(warnings, "workspace "+id+": "+warning)
+		}
+		if err != nil {
+			return warnings, fmt.Errorf("workspace %s: %w", id, err)
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "sort"
+
+// AppConfigResponse is the API view of the configuration: its secrets and API keys are never returned,
+// only which secrets are set.
+type AppConfigResponse struct {
+	AppConfig
+	SecretsSet []string `json:"secrets_set"` // JSON paths of the secrets that are set, e.g. "jira.api_token"
+}
+
+// secrets returns the secret fields of the config by their JSON path. API keys are stored hashed and
+// managed through the admin API, so they are not among them.
+func (c *AppConfig) secrets() map[string]*string {
+	return map[string]*string{
+		"jira.api_token":        &c.Jira.APIToken,
+		"gitlab.token":          &c.GitLab.Token,
+		"gitlab.webhook_secret": &c.GitLab.WebhookSecret,
+		"email.smtp_password":   &c.Email.SMTPPassword,
+		"email.inbound_secret":  &c.Email.InboundSecret,
+		"slack.bot_token":       &c.Slack.BotToken,
+		"auth.jwt.secret":       &c.Auth.JWT.Secret,
+	}
+}
+
+// WithoutSecrets returns a copy of the config with its secrets cleared and its API keys left out.
+func (c *AppConfig) WithoutSecrets() *AppConfig {
+	redacted := *c
+	for _, secret := range redacted.secrets() {
+		*secret = ""
+	}
+	redacted.Auth.APIKeys = nil
+	return &redacted
+}
+
+// ToResponse converts the config to its API view.
+func (c *AppConfig) ToResponse() AppConfigResponse {
+	set := []string{}
+	for path, secret := range c.secrets() {
+		if *secret != "" {
+			set = append(set, path)
+		}
+	}
+	sort.Strings(set)
+	return AppConfigResponse{AppConfig: *c.WithoutSecrets(), SecretsSet: set}
+}
+
+// KeepSecrets fills the secrets left empty in the config with the ones of the stored config, so a config
+// read from the API, which has none, can be saved back without dropping them.
+func (c *AppConfig) KeepSecrets(stored *AppConfig) {
+	storedSecrets := stored.secrets()
+	for path, secret := range c.secrets() {
+		if *secret == "" {
+			*secret = *storedSecrets[path]
+		}
+	}
+}
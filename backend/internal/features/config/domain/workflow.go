@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Outputs a workflow phase asks the assistant for.
+const (
+	WorkflowOutputQuestions   = "questions"   // Questions per role, which the PM answers
+	WorkflowOutputSuggestions = "suggestions" // Suggestions per role, which the PM accepts
+	WorkflowOutputStructured  = "structured"  // A JSON object under the phase's schema, e.g. an estimate
+	WorkflowOutputFinal       = "final"       // The final user story and AC
+)
+
+// Names of the built-in phases, which keep their own endpoints. Other phases are run through the
+// generic phase endpoint.
+const (
+	WorkflowQuestioning = "QUESTIONING"
+	WorkflowSuggesting  = "SUGGESTING"
+	WorkflowFinalizing  = "FINALIZING"
+)
+
+// WorkflowConfig defines the phases refinement sessions go through. Without phases, sessions follow
+// the default QUESTIONING → SUGGESTING → FINALIZING workflow.
+type WorkflowConfig struct {
+	Phases []WorkflowPhase `json:"phases,omitempty"` // In order; sessions start in the first one
+}
+
+// WorkflowPhase is a phase of a refinement workflow.
+type WorkflowPhase struct {
+	Name        string         `json:"name"`             // e.g. "ESTIMATION"
+	PromptKey   string         `json:"prompt_key"`       // Key of the phase's prompt in the phase prompts
+	Output      string         `json:"output"`           // One of the WorkflowOutput constants
+	Schema      map[string]any `json:"schema,omitempty"` // JSON schema of structured output, an object at the root
+	Transitions []string       `json:"transitions"`      // Phases a session may move to from this one
+}
+
+// DefaultWorkflow returns the workflow sessions follow unless one is configured.
+func DefaultWorkflow() []WorkflowPhase {
+	all := []string{WorkflowQuestioning, WorkflowSuggesting, WorkflowFinalizing}
+	return []WorkflowPhase{
+		{Name: WorkflowQuestioning, PromptKey: "questioning", Output: WorkflowOutputQuestions, Transitions: all},
+		{Name: WorkflowSuggesting, PromptKey: "suggesting", Output: WorkflowOutputSuggestions, Transitions: all},
+		{Name: WorkflowFinalizing, PromptKey: "finalizing", Output: WorkflowOutputFinal, Transitions: []string{WorkflowFinalizing}},
+	}
+}
+
+// Resolved returns the configured phases, or the default workflow.
+func (w WorkflowConfig) Resolved() []WorkflowPhase {
+	if len(w.Phases) == 0 {
+		return DefaultWorkflow()
+	}
+	return w.Phases
+}
+
+// Validate checks that a workflow can drive sessions: phase names are unique, transitions lead to
+// phases of the workflow, sessions start in the QUESTIONING phase and the FINALIZING phase finalizes
+// them. The built-in phases keep the output of their endpoints.
+func (w WorkflowConfig) Validate() error {
+	if len(w.Phases) == 0 {
+		return nil
+	}
+	if w.Phases[0].Name != WorkflowQuestioning {
+		return fmt.Errorf("workflow must start with the %s phase, not %s", WorkflowQuestioning, w.Phases[0].Name)
+	}
+	builtin := map[string]string{
+		WorkflowQuestioning: WorkflowOutputQuestions,
+		WorkflowSuggesting:  WorkflowOutputSuggestions,
+		WorkflowFinalizing:  WorkflowOutputFinal,
+	}
+	names := make(map[string]bool)
+	final := false
+	for _, phase := range w.Phases {
+		if phase.Name == "" {
+			return fmt.Errorf("workflow phase without a name")
+		}
+		if names[phase.Name] {
+			return fmt.Errorf("workflow phase %s is defined twice", phase.Name)
+		}
+		names[phase.Name] = true
+		switch phase.Output {
+		case WorkflowOutputQuestions, WorkflowOutputSuggestions, WorkflowOutputFinal:
+		case WorkflowOutputStructured:
+			if phase.Schema == nil || phase.Schema["type"] != "object" {
+				return fmt.Errorf("workflow phase %s needs a schema with an object at the root", phase.Name)
+			}
+		default:
+			return fmt.Errorf("workflow phase %s has unsupported output %q", phase.Name, phase.Output)
+		}
+		if output, ok := builtin[phase.Name]; ok && phase.Output != output {
+			return fmt.Errorf("workflow phase %s must have output %q", phase.Name, output)
+		}
+		if phase.Output == WorkflowOutputFinal {
+			if phase.Name != WorkflowFinalizing {
+				return fmt.Errorf("only the %s phase may have output %q", WorkflowFinalizing, WorkflowOutputFinal)
+			}
+			final = true
+		}
+	}
+	if !final {
+		return fmt.Errorf("workflow must include the %s phase", WorkflowFinalizing)
+	}
+	for _, phase := range w.Phases {
+		for _, next := range phase.Transitions {
+			if !names[next] {
+				return fmt.Errorf("workflow phase %s moves to undefined phase %s", phase.Name, next)
+			}
+		}
+	}
+	return nil
+}
+
+// FindWorkflowPhase returns the phase with the given name of a workflow.
+func FindWorkflowPhase(phases []WorkflowPhase, name string) (WorkflowPhase, bool) {
+	i := slices.IndexFunc(phases, func(p WorkflowPhase) bool { return p.Name == name })
+	if i < 0 {
+		return WorkflowPhase{}, false
+	}
+	return phases[i], true
+}
@@ -1,11 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"sofa-commander/backend/internal/config"
 	"sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/httpcache"
 )
 
 // AppConfigHandler holds the app config service.
@@ -20,14 +23,14 @@ func NewAppConfigHandler(appConfigService config.AppConfigService) *AppConfigHan
 	}
 }
 
-// GetAppConfigHandler handles fetching the application configuration.
+// GetAppConfigHandler handles fetching the application configuration, without its secrets.
 func (h *AppConfigHandler) GetAppConfigHandler(c *gin.Context) {
 	appConfig, err := h.appConfigService.LoadAppConfig()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, appConfig)
+	httpcache.JSON(c, appConfig.ToResponse())
 }
 
 // SaveAppConfigHandler handles saving the application configuration.
@@ -37,11 +40,184 @@ func (h *AppConfigHandler) SaveAppConfigHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := appConfig.Workflow.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Malformed format examples would only degrade the AI's output, so they are rejected; examples the
+	// assistant is never shown are saved with a warning.
+	warnings, err := appConfig.LintFormatExamples()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "warnings": warnings})
+		return
+	}
 
-	if err := h.appConfigService.SaveAppConfig(&appConfig); err != nil {
+	// Authentication and reviewers are managed through the admin API; the stored settings are kept, so a
+	// config edited in the frontend cannot disable authentication, change its keys or make its author a
+	// reviewer. Secrets left empty, as they are in the config the API returns, keep their stored value.
+	err = h.appConfigService.UpdateAppConfigBy(c.GetHeader("X-User-ID"), func(stored *domain.AppConfig) error {
+		appConfig.KeepSecrets(stored)
+		appConfig.Auth = stored.Auth
+		appConfig.Approval = stored.Approval
+		*stored = appConfig
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save app config: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "App config saved successfully"})
+	response := gin.H{"message": "App config saved successfully"}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListAppConfigVersionsHandler handles listing the saved versions of the application configuration.
+func (h *AppConfigHandler) ListAppConfigVersionsHandler(c *gin.Context) {
+	versions, err := h.appConfigService.ListVersions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list app config versions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetAppConfigVersionHandler handles fetching a saved version of the application configuration.
+func (h *AppConfigHandler) GetAppConfigVersionHandler(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version: " + c.Param("version")})
+		return
+	}
+	appConfigVersion, err := h.appConfigService.GetVersion(version)
+	if errors.Is(err, config.ErrVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get app config version: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, appConfigVersion)
+}
+
+// RollbackAppConfigHandler handles restoring a saved version of the application configuration, which is
+// saved as a new version.
+func (h *AppConfigHandler) RollbackAppConfigHandler(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version: " + c.Param("version")})
+		return
+	}
+	restored, err := h.appConfigService.Rollback(version, c.GetHeader("X-User-ID"))
+	if errors.Is(err, config.ErrVersionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back app config: " + err.Error()})
+		return
+	}
+	restored.Config = nil
+	c.JSON(http.StatusOK, gin.H{"message": "App config rolled back successfully", "version": restored})
+}
+
+// ListRoleExemplarsHandler handles listing the few-shot exemplars of a role.
+func (h *AppConfigHandler) ListRoleExemplarsHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	exemplars := appConfig.RoleExemplars[c.Param("role")]
+	if exemplars == nil {
+		exemplars = []domain.RoleExemplar{}
+	}
+	c.JSON(http.StatusOK, exemplars)
+}
+
+// AddRoleExemplarHandler handles adding a few-shot exemplar to a role.
+func (h *AppConfigHandler) AddRoleExemplarHandler(c *gin.Context) {
+	var exemplar domain.RoleExemplar
+	if err := c.ShouldBindJSON(&exemplar); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := h.appConfigService.AddRoleExemplar(c.Param("role"), exemplar)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add exemplar: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// DeleteRoleExemplarHandler handles removing a few-shot exemplar from a role.
+func (h *AppConfigHandler) DeleteRoleExemplarHandler(c *gin.Context) {
+	if err := h.appConfigService.DeleteRoleExemplar(c.Param("role"), c.Param("exemplarId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Exemplar deleted successfully"})
+}
+
+// ListPhaseFormatExamplesHandler handles listing the format examples of a phase.
+func (h *AppConfigHandler) ListPhaseFormatExamplesHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	examples := appConfig.PhaseFormatExamples[c.Param("phase")]
+	if examples == nil {
+		examples = []domain.PhaseFormatExample{}
+	}
+	c.JSON(http.StatusOK, examples)
+}
+
+// AddPhaseFormatExampleHandler handles adding the format example of a role to a phase.
+func (h *AppConfigHandler) AddPhaseFormatExampleHandler(c *gin.Context) {
+	var example domain.PhaseFormatExample
+	if err := c.ShouldBindJSON(&example); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.appConfigService.AddPhaseFormatExample(c.Param("phase"), example); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to add format example: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, example)
+}
+
+// UpdatePhaseFormatExampleHandler handles replacing the format example of a role in a phase.
+func (h *AppConfigHandler) UpdatePhaseFormatExampleHandler(c *gin.Context) {
+	var example domain.PhaseFormatExample
+	if err := c.ShouldBindJSON(&example); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	example.Role = c.Param("role")
+	if err := h.appConfigService.UpdatePhaseFormatExample(c.Param("phase"), example.Role, example); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, config.ErrFormatExampleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": "Failed to update format example: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, example)
+}
+
+// DeletePhaseFormatExampleHandler handles removing the format example of a role from a phase.
+func (h *AppConfigHandler) DeletePhaseFormatExampleHandler(c *gin.Context) {
+	if err := h.appConfigService.DeletePhaseFormatExample(c.Param("phase"), c.Param("role")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, config.ErrFormatExampleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Format example deleted successfully"})
 }
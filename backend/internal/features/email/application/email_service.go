@@ -0,0 +1,146 @@
+package application
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"strings"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/email/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/mail"
+)
+
+var (
+	// ErrInvalidInboundSecret is returned when the inbound webhook secret does not match.
+	ErrInvalidInboundSecret = fmt.Errorf("invalid inbound email secret")
+	// ErrSenderNotAllowed is returned when the sender's domain is not allowed.
+	ErrSenderNotAllowed = fmt.Errorf("sender is not allowed to start refinements")
+)
+
+// EmailService defines the interface for the inbound email integration.
+type EmailService interface {
+	HandleInbound(secret string, email *domain.InboundEmail) error
+}
+
+// emailService is the implementation of EmailService.
+type emailService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+}
+
+// NewEmailService creates a new instance of emailService.
+func NewEmailService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService) EmailService {
+	return &emailService{refinementService: refinementService, appConfigService: appConfigService}
+}
+
+// HandleInbound starts a session from an inbound email in the background and replies to the
+// sender with the first round of questions.
+func (s *emailService) HandleInbound(secret string, email *domain.InboundEmail) error {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	cfg := appConfig.Email
+	if cfg.InboundSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(cfg.InboundSecret)) != 1 {
+		return ErrInvalidInboundSecret
+	}
+	sender, err := netmail.ParseAddress(email.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", email.From, err)
+	}
+	if !senderAllowed(sender.Address, cfg.AllowedSenderDomains) {
+		return ErrSenderNotAllowed
+	}
+	story := strings.TrimSpace(email.Subject + "\n\n" + email.Text)
+	if story == "" {
+		return fmt.Errorf("email has no content")
+	}
+	mailer, err := NewMailer(cfg)
+	if err != nil {
+		return err
+	}
+
+	roles := cfg.DefaultRoles
+	if len(roles) == 0 {
+		roles = appConfig.RoleNames()
+	}
+	req := &refinementdomain.RefinementRequest{InitialUserStory: story, SelectedRoles: roles, UserID: sender.Address}
+
+	go func() {
+		session, err := refinementapp.StartWithConfig(context.Background(), s.refinementService, req, appConfig)
+		if err != nil {
+			logging.Default().Error().Err(err).Str("sender", sender.Address).Msg("Failed to start refinement from email")
+			if err := mailer.Send(sender.Address, "Re: "+email.Subject, "很抱歉，無法開始需求打磨："+err.Error()); err != nil {
+				logging.Default().Warn().Err(err).Str("sender", sender.Address).Msg("Failed to send failure reply")
+			}
+			return
+		}
+		if _, err := s.refinementService.UpdateSession(session.ID, func(session *refinementdomain.RefinementSession) {
+			session.RequesterEmail = sender.Address
+		}); err != nil {
+			logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Failed to record requester email")
+		}
+		if err := mailer.Send(sender.Address, "Re: "+email.Subject, questionsReply(session, appConfig.SessionURL(session.ID))); err != nil {
+			logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Failed to reply with questions")
+		}
+	}()
+	return nil
+}
+
+// NewMailer creates a mailer from the email configuration.
+func NewMailer(cfg configdomain.EmailConfig) (mail.Mailer, error) {
+	password := cfg.SMTPPassword
+	if envPassword := os.Getenv("SMTP_PASSWORD"); envPassword != "" {
+		password = envPassword
+	}
+	return mail.NewSMTPMailer(mail.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: password,
+		From:     cfg.From,
+	})
+}
+
+// questionsReply formats the first round of questions as a plain-text email.
+func questionsReply(session *refinementdomain.RefinementSession, link string) string {
+	var b strings.Builder
+	b.WriteString("您好，我們已開始打磨這個需求，以下是各角色的第一輪問題：\n\n")
+	for _, q := range session.Questions {
+		b.WriteString("【" + q.Role + "】\n")
+		for i, p := range q.Prompt {
+			b.WriteString(fmt.Sprintf("%d. %s\n", i+1, p))
+		}
+		b.WriteString("\n")
+	}
+	if link != "" {
+		b.WriteString("請到以下連結回答問題並繼續打磨：\n" + link + "\n")
+	} else {
+		b.WriteString("Session ID：" + session.ID + "\n")
+	}
+	return b.String()
+}
+
+func senderAllowed(address string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return false
+	}
+	senderDomain := strings.ToLower(address[at+1:])
+	for _, d := range domains {
+		if strings.ToLower(d) == senderDomain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+package domain
+
+// InboundEmail is an email received from an email service's inbound webhook
+// (SendGrid Inbound Parse, Mailgun routes or a plain JSON post).
+type InboundEmail struct {
+	From    string `json:"from" form:"from"`
+	Subject string `json:"subject" form:"subject"`
+	Text    string `json:"text" form:"text"`
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"sofa-commander/backend/internal/features/email/application"
+	"sofa-commander/backend/internal/features/email/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler holds the email service.
+type EmailHandler struct {
+	emailService application.EmailService
+}
+
+// NewEmailHandler creates a new EmailHandler.
+func NewEmailHandler(emailService application.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+	}
+}
+
+// InboundHandler handles inbound email webhooks. Form posts from SendGrid and Mailgun as well as
+// JSON bodies are accepted; the shared secret is passed as the `token` query parameter.
+func (h *EmailHandler) InboundHandler(c *gin.Context) {
+	var email domain.InboundEmail
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		if err := c.ShouldBindJSON(&email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		email.From = firstNonEmpty(c.PostForm("from"), c.PostForm("sender"))
+		email.Subject = c.PostForm("subject")
+		email.Text = firstNonEmpty(c.PostForm("text"), c.PostForm("stripped-text"), c.PostForm("body-plain"))
+	}
+
+	err := h.emailService.HandleInbound(c.Query("token"), &email)
+	switch {
+	case errors.Is(err, application.ErrInvalidInboundSecret):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrSenderNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to handle inbound email: " + err.Error()})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"message": "Refinement session starting"})
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
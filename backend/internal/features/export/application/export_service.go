@@ -0,0 +1,154 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/export/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// ErrBelowQualityThreshold is returned when exporting a story scored below the rubric's export threshold.
+var ErrBelowQualityThreshold = fmt.Errorf("story quality score is below the export threshold")
+
+// ExportService defines the interface for rendering sessions with export templates.
+type ExportService interface {
+	Render(sessionID, templateName string) (*domain.ExportResult, error)
+	CheckQuality(session *refinementdomain.RefinementSession) error
+	ListTemplates() (map[string]configdomain.ExportTemplate, error)
+	SaveTemplate(name string, tmpl configdomain.ExportTemplate) error
+	DeleteTemplate(name string) error
+}
+
+// exportService is the implementation of ExportService.
+type exportService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+}
+
+// NewExportService creates a new instance of exportService.
+func NewExportService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService) ExportService {
+	return &exportService{refinementService: refinementService, appConfigService: appConfigService}
+}
+
+// Render renders a session with the named template; admin-defined templates override built-in ones.
+func (s *exportService) Render(sessionID, templateName string) (*domain.ExportResult, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckQuality(session); err != nil {
+		return nil, err
+	}
+	tmpl, err := s.lookup(templateName)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseTemplate(templateName, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse export template %s: %w", templateName, err)
+	}
+
+	data := domain.ExportData{
+		Session:         session,
+		UserStory:       session.FinalUserStory,
+		AC:              session.FinalAC,
+		AccessibilityAC: session.AccessibilityAC(),
+		ApprovalStatus:  session.ApprovalStatus,
+		Approvals:       session.Approvals,
+		Rounds:          session.Transcript(),
+		GeneratedAt:     time.Now(),
+	}
+	if data.UserStory == "" {
+		data.UserStory = session.UserStory
+	}
+
+	var b strings.Builder
+	if err := parsed.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render export template %s: %w", templateName, err)
+	}
+	return &domain.ExportResult{Template: templateName, Format: tmpl.Format, ContentType: tmpl.ContentType, Body: b.String()}, nil
+}
+
+// CheckQuality gates exports of stories scored below the rubric's export threshold, returning
+// ErrBelowQualityThreshold for them. Every tracker export checks it before touching the tracker.
+// Stories that have not been scored are not gated.
+func (s *exportService) CheckQuality(session *refinementdomain.RefinementSession) error {
+	if session.QualityScore == nil {
+		return nil
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	threshold := appConfig.ScoringRubric.ExportThreshold
+	if threshold > 0 && session.QualityScore.Total < threshold {
+		return fmt.Errorf("%w: %.1f < %.1f", ErrBelowQualityThreshold, session.QualityScore.Total, threshold)
+	}
+	return nil
+}
+
+// ListTemplates returns the built-in templates merged with the admin-defined ones.
+func (s *exportService) ListTemplates() (map[string]configdomain.ExportTemplate, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]configdomain.ExportTemplate, len(builtinTemplates)+len(appConfig.ExportTemplates))
+	for name, tmpl := range builtinTemplates {
+		result[name] = tmpl
+	}
+	for name, tmpl := range appConfig.ExportTemplates {
+		result[name] = tmpl
+	}
+	return result, nil
+}
+
+// SaveTemplate validates and stores an admin-defined template.
+func (s *exportService) SaveTemplate(name string, tmpl configdomain.ExportTemplate) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if tmpl.ContentType == "" {
+		tmpl.ContentType = "text/plain; charset=utf-8"
+	}
+	if _, err := parseTemplate(name, tmpl); err != nil {
+		return fmt.Errorf("invalid export template: %w", err)
+	}
+	return s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		if appConfig.ExportTemplates == nil {
+			appConfig.ExportTemplates = make(map[string]configdomain.ExportTemplate)
+		}
+		appConfig.ExportTemplates[name] = tmpl
+		return nil
+	})
+}
+
+// DeleteTemplate removes an admin-defined template, restoring the built-in one if any.
+func (s *exportService) DeleteTemplate(name string) error {
+	return s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		if _, ok := appConfig.ExportTemplates[name]; !ok {
+			return fmt.Errorf("export template %s not found", name)
+		}
+		delete(appConfig.ExportTemplates, name)
+		return nil
+	})
+}
+
+func (s *exportService) lookup(name string) (configdomain.ExportTemplate, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return configdomain.ExportTemplate{}, err
+	}
+	if tmpl, ok := appConfig.ExportTemplates[name]; ok {
+		return tmpl, nil
+	}
+	if tmpl, ok := builtinTemplates[name]; ok {
+		return tmpl, nil
+	}
+	return configdomain.ExportTemplate{}, fmt.Errorf("export template %s not found", name)
+}
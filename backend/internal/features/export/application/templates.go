@@ -0,0 +1,142 @@
+package application
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// templateFuncs are available to every export template.
+var templateFuncs = template.FuncMap{
+	"inc":  func(i int) int { return i + 1 },
+	"join": strings.Join,
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+	// quote continues a Markdown blockquote over the lines of a multi-line text
+	"quote": func(prefix, text string) string { return strings.ReplaceAll(text, "\n", "\n"+prefix) },
+}
+
+// builtinTemplates are used when no admin-defined template with the same name exists.
+var builtinTemplates = map[string]configdomain.ExportTemplate{
+	"markdown": {
+		Format:      "markdown",
+		ContentType: "text/markdown; charset=utf-8",
+		Body: `# User Story
+
+{{.UserStory}}
+
+## Acceptance Criteria
+{{range $i, $ac := .AC}}
+{{inc $i}}. {{$ac}}{{end}}
+{{if .AccessibilityAC}}
+## Accessibility Acceptance Criteria
+{{range $i, $ac := .AccessibilityAC}}
+{{inc $i}}. {{$ac}}{{end}}
+{{end}}{{if .ApprovalStatus}}
+## Approval
+
+Status: **{{.ApprovalStatus}}**
+{{range .Approvals}}
+- {{.ReviewerName}} ({{.ReviewerID}}): {{.Decision}}{{if .Comment}} — {{.Comment}}{{end}}{{end}}
+{{end}}`,
+	},
+	"transcript": {
+		Format:      "markdown",
+		ContentType: "text/markdown; charset=utf-8",
+		Body: `# Refinement Transcript{{with .Session.UserStory}}
+
+## Initial User Story
+
+{{.}}{{end}}
+{{range .Rounds}}
+## Round {{.Round}}
+{{if .Answers}}
+### Questions
+{{range .Answers}}
+- **{{.Role}}**: {{.Question}}
+  > {{quote "  > " .Answer}}{{end}}
+{{end}}{{if .AcceptedSuggestions}}
+### Accepted Suggestions
+{{range .AcceptedSuggestions}}
+- **{{.Role}}**: {{.Prompt}}{{end}}
+{{end}}{{end}}{{if .Session.FinalizedAt}}
+## Final User Story
+
+{{.UserStory}}
+
+## Acceptance Criteria
+{{range $i, $ac := .AC}}
+{{inc $i}}. {{$ac}}{{end}}
+{{if .AccessibilityAC}}
+## Accessibility Acceptance Criteria
+{{range $i, $ac := .AccessibilityAC}}
+{{inc $i}}. {{$ac}}{{end}}
+{{end}}{{end}}
+_Generated {{date "2006-01-02 15:04" .GeneratedAt}}_
+`,
+	},
+	"jira": {
+		Format:      "jira",
+		ContentType: "text/plain; charset=utf-8",
+		Body: `h2. User Story
+{{.UserStory}}
+
+h2. Acceptance Criteria
+{{range .AC}}# {{.}}
+{{end}}{{if .AccessibilityAC}}
+h2. Accessibility Acceptance Criteria
+{{range .AccessibilityAC}}# {{.}}
+{{end}}{{end}}{{if .ApprovalStatus}}
+h2. Approval
+Status: *{{.ApprovalStatus}}*
+{{range .Approvals}}* {{.ReviewerName}} ({{.ReviewerID}}): {{.Decision}}{{if .Comment}} — {{.Comment}}{{end}}
+{{end}}{{end}}`,
+	},
+	"confluence": {
+		Format:      "confluence",
+		ContentType: "text/html; charset=utf-8",
+		Body: `<h2>User Story</h2>
+<p>{{.UserStory}}</p>
+<h2>Acceptance Criteria</h2>
+<ol>{{range .AC}}
+<li>{{.}}</li>{{end}}
+</ol>
+{{if .AccessibilityAC}}<h2>Accessibility Acceptance Criteria</h2>
+<ol>{{range .AccessibilityAC}}
+<li>{{.}}</li>{{end}}
+</ol>
+{{end}}{{if .ApprovalStatus}}<h2>Approval</h2>
+<p>Status: <strong>{{.ApprovalStatus}}</strong></p>
+<ul>{{range .Approvals}}
+<li>{{.ReviewerName}} ({{.ReviewerID}}): {{.Decision}}{{if .Comment}} — {{.Comment}}{{end}}</li>{{end}}
+</ul>
+{{end}}`,
+	},
+}
+
+// executor is a parsed export template.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// parseTemplate parses an export template body with the export template functions. HTML templates are
+// parsed with html/template, so the story and comments they render are escaped.
+func parseTemplate(name string, tmpl configdomain.ExportTemplate) (executor, error) {
+	if strings.HasPrefix(tmpl.ContentType, "text/html") {
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(tmpl.Body)
+	}
+	return template.New(name).Funcs(templateFuncs).Parse(tmpl.Body)
+}
+
+// IssueTitle returns the first line of the story, shortened to fit the title of a tracker issue.
+func IssueTitle(userStory string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(userStory), "\n", 2)[0])
+	runes := []rune(title)
+	if len(runes) > 120 {
+		title = string(runes[:120]) + "…"
+	}
+	return title
+}
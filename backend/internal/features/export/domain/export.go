@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// ExportData is the data model export templates are rendered against.
+type ExportData struct {
+	Session   *refinementdomain.RefinementSession
+	UserStory string   // Finalized user story, falling back to the current one
+	AC        []string // Finalized acceptance criteria
+	// AccessibilityAC are the WCAG-based criteria of the accessibility phase, exported as their own section
+	AccessibilityAC []string
+	// ApprovalStatus is the reviewer sign-off status, empty when no approval is required
+	ApprovalStatus refinementdomain.ApprovalStatus
+	Approvals      []refinementdomain.ApprovalDecision
+	// Rounds are the answered questions and accepted suggestions of the session by round, for transcripts
+	Rounds      []refinementdomain.TranscriptRound
+	GeneratedAt time.Time
+}
+
+// ExportResult is a rendered export document.
+type ExportResult struct {
+	Template    string `json:"template"`
+	Format      string `json:"format"`
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/export/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler holds the export service.
+type ExportHandler struct {
+	exportService application.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(exportService application.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportSessionHandler renders a session with the template given in the `template` query parameter.
+func (h *ExportHandler) ExportSessionHandler(c *gin.Context) {
+	result, err := h.exportService.Render(c.Param("id"), c.DefaultQuery("template", "markdown"))
+	if err != nil {
+		if errors.Is(err, application.ErrBelowQualityThreshold) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export session: " + err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, result.ContentType, []byte(result.Body))
+}
+
+// TranscriptHandler renders the full transcript of a session, from the initial story through each
+// round's answers and accepted suggestions to the final output, as a Markdown download.
+func (h *ExportHandler) TranscriptHandler(c *gin.Context) {
+	result, err := h.exportService.Render(c.Param("id"), "transcript")
+	if err != nil {
+		if errors.Is(err, application.ErrBelowQualityThreshold) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export transcript: " + err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-transcript.md"`, c.Param("id")))
+	c.Data(http.StatusOK, result.ContentType, []byte(result.Body))
+}
+
+// ListTemplatesHandler handles listing the available export templates.
+func (h *ExportHandler) ListTemplatesHandler(c *gin.Context) {
+	templates, err := h.exportService.ListTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list export templates: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// SaveTemplateHandler handles creating or replacing an export template.
+func (h *ExportHandler) SaveTemplateHandler(c *gin.Context) {
+	var tmpl configdomain.ExportTemplate
+	if err := c.ShouldBindJSON(&tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.exportService.SaveTemplate(c.Param("name"), tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to save export template: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Export template saved successfully"})
+}
+
+// DeleteTemplateHandler handles deleting an export template.
+func (h *ExportHandler) DeleteTemplateHandler(c *gin.Context) {
+	if err := h.exportService.DeleteTemplate(c.Param("name")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Export template deleted successfully"})
+}
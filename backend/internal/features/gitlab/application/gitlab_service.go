@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	exportapp "sofa-commander/backend/internal/features/export/application"
+	"sofa-commander/backend/internal/features/gitlab/domain"
+	"sofa-commander/backend/internal/features/gitlab/infrastructure"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// defaultTriggerLabel starts a refinement when added to a GitLab issue.
+const defaultTriggerLabel = "needs-refinement"
+
+// ErrInvalidWebhookToken is returned when the webhook secret does not match.
+var ErrInvalidWebhookToken = fmt.Errorf("invalid gitlab webhook token")
+
+// GitLabService defines the interface for the GitLab integration.
+type GitLabService interface {
+	Export(sessionID string) (*domain.ExportResult, error)
+	HandleIssueHook(token string, payload *domain.IssueHookPayload) (bool, error)
+}
+
+// gitLabService is the implementation of GitLabService.
+type gitLabService struct {
+	refinementService refinementapp.RefinementService
+	exportService     exportapp.ExportService
+	appConfigService  config.AppConfigService
+}
+
+// NewGitLabService creates a new instance of gitLabService.
+func NewGitLabService(refinementService refinementapp.RefinementService, exportService exportapp.ExportService, appConfigService config.AppConfigService) GitLabService {
+	return &gitLabService{
+		refinementService: refinementService,
+		exportService:     exportService,
+		appConfigService:  appConfigService,
+	}
+}
+
+// Export creates a GitLab issue from the finalized story, or updates the one it was created from.
+func (s *gitLabService) Export(sessionID string) (*domain.ExportResult, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	if err := s.exportService.CheckQuality(session); err != nil {
+		return nil, err
+	}
+	appConfig, client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	description, err := s.exportService.Render(sessionID, "markdown")
+	if err != nil {
+		return nil, err
+	}
+	title := exportapp.IssueTitle(session.FinalUserStory)
+
+	if session.GitLabIssueIID != 0 {
+		issueURL, err := client.UpdateIssue(session.GitLabProjectID, session.GitLabIssueIID, title, description.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.ExportResult{ProjectID: session.GitLabProjectID, IssueIID: session.GitLabIssueIID, IssueURL: issueURL}, nil
+	}
+
+	projectID := appConfig.GitLab.ProjectID
+	if projectID == "" {
+		return nil, fmt.Errorf("gitlab project_id must be configured")
+	}
+	iid, issueURL, err := client.CreateIssue(projectID, title, description.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		session.GitLabProjectID = projectID
+		session.GitLabIssueIID = iid
+	}); err != nil {
+		return nil, err
+	}
+	return &domain.ExportResult{ProjectID: projectID, IssueIID: iid, IssueURL: issueURL, Created: true}, nil
+}
+
+// HandleIssueHook starts a refinement session when the trigger label is added to an issue.
+// The session is started in the background and its link posted back as an issue comment.
+func (s *gitLabService) HandleIssueHook(token string, payload *domain.IssueHookPayload) (bool, error) {
+	appConfig, client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+	if appConfig.GitLab.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(appConfig.GitLab.WebhookSecret)) != 1 {
+		return false, ErrInvalidWebhookToken
+	}
+	if payload.ObjectKind != "issue" || !labelAdded(payload, triggerLabel(appConfig)) {
+		return false, nil
+	}
+
+	projectID := strconv.Itoa(payload.Project.ID)
+	issue := payload.ObjectAttributes
+	roles := appConfig.GitLab.DefaultRoles
+	if len(roles) == 0 {
+		roles = appConfig.RoleNames()
+	}
+	req := &refinementdomain.RefinementRequest{
+		InitialUserStory: strings.TrimSpace(issue.Title + "\n\n" + issue.Description),
+		SelectedRoles:    roles,
+	}
+
+	go func() {
+		session, err := refinementapp.StartWithConfig(context.Background(), s.refinementService, req, appConfig)
+		if err != nil {
+			logging.Default().Error().Err(err).Str("gitlab_project_id", projectID).Int("gitlab_issue_iid", issue.IID).Msg("Failed to start refinement for gitlab issue")
+			return
+		}
+		if _, err := s.refinementService.UpdateSession(session.ID, func(session *refinementdomain.RefinementSession) {
+			session.GitLabProjectID = projectID
+			session.GitLabIssueIID = issue.IID
+		}); err != nil {
+			logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Failed to link session to gitlab issue")
+		}
+
+		note := fmt.Sprintf("Refinement session `%s` has started.", session.ID)
+		if link := appConfig.SessionURL(session.ID); link != "" {
+			note = fmt.Sprintf("Refinement session started: %s", link)
+		}
+		if err := client.CreateNote(projectID, issue.IID, note); err != nil {
+			logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Failed to post session link to gitlab")
+		}
+	}()
+	return true, nil
+}
+
+func (s *gitLabService) client() (*configdomain.AppConfig, infrastructure.GitLabClient, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	token := appConfig.GitLab.Token
+	if envToken := os.Getenv("GITLAB_TOKEN"); envToken != "" {
+		token = envToken
+	}
+	client, err := infrastructure.NewGitLabClient(appConfig.GitLab.BaseURL, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return appConfig, client, nil
+}
+
+func triggerLabel(appConfig *configdomain.AppConfig) string {
+	if appConfig.GitLab.TriggerLabel != "" {
+		return appConfig.GitLab.TriggerLabel
+	}
+	return defaultTriggerLabel
+}
+
+// labelAdded reports whether this event added the label, or opened an issue already carrying it.
+func labelAdded(payload *domain.IssueHookPayload, label string) bool {
+	if changes := payload.Changes.Labels; changes != nil {
+		return domain.HasLabel(changes.Current, label) && !domain.HasLabel(changes.Previous, label)
+	}
+	return payload.ObjectAttributes.Action == "open" && domain.HasLabel(payload.Labels, label)
+}
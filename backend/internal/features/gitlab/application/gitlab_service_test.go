@@ -0,0 +1,54 @@
+package application
+
+import (
+	"encoding/json"
+	"testing"
+
+	"sofa-commander/backend/internal/features/gitlab/domain"
+)
+
+func TestLabelAdded(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bool
+	}{
+		{
+			name:    "label added",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"update"},"changes":{"labels":{"previous":[{"title":"bug"}],"current":[{"title":"bug"},{"title":"refine"}]}}}`,
+			want:    true,
+		},
+		{
+			name:    "label kept while other labels change",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"update"},"changes":{"labels":{"previous":[{"title":"refine"}],"current":[{"title":"refine"},{"title":"bug"}]}}}`,
+		},
+		{
+			name:    "label removed",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"update"},"changes":{"labels":{"previous":[{"title":"refine"}],"current":[]}}}`,
+		},
+		{
+			name:    "issue opened with the label",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"open"},"labels":[{"title":"refine"}]}`,
+			want:    true,
+		},
+		{
+			name:    "issue updated carrying the label",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"update"},"labels":[{"title":"refine"}]}`,
+		},
+		{
+			name:    "issue opened without the label",
+			payload: `{"object_kind":"issue","object_attributes":{"action":"open"},"labels":[{"title":"bug"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload domain.IssueHookPayload
+			if err := json.Unmarshal([]byte(tt.payload), &payload); err != nil {
+				t.Fatalf("invalid payload: %v", err)
+			}
+			if got := labelAdded(&payload, "refine"); got != tt.want {
+				t.Errorf("labelAdded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
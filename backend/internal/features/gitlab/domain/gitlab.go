@@ -0,0 +1,46 @@
+package domain
+
+// IssueHookPayload is the subset of a GitLab "Issue Hook" webhook payload we use.
+type IssueHookPayload struct {
+	ObjectKind string `json:"object_kind"`
+	Project    struct {
+		ID int `json:"id"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID         int    `json:"iid"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		Action      string `json:"action"`
+	} `json:"object_attributes"`
+	Labels  []Label `json:"labels"`
+	Changes struct {
+		Labels *struct {
+			Previous []Label `json:"previous"`
+			Current  []Label `json:"current"`
+		} `json:"labels"`
+	} `json:"changes"`
+}
+
+// Label is a GitLab issue label.
+type Label struct {
+	Title string `json:"title"`
+}
+
+// HasLabel reports whether the label list contains the given title.
+func HasLabel(labels []Label, title string) bool {
+	for _, l := range labels {
+		if l.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportResult describes the GitLab issue a session was exported to.
+type ExportResult struct {
+	ProjectID string `json:"project_id"`
+	IssueIID  int    `json:"issue_iid"`
+	IssueURL  string `json:"issue_url"`
+	Created   bool   `json:"created"`
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLabClient defines the interface for the GitLab REST API calls we use.
+type GitLabClient interface {
+	CreateIssue(projectID, title, description string) (int, string, error)
+	UpdateIssue(projectID string, issueIID int, title, description string) (string, error)
+	CreateNote(projectID string, issueIID int, body string) error
+}
+
+// gitLabClient is a GitLab REST API v4 client authenticated with a private token.
+type gitLabClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitLabClient creates a new GitLab client.
+func NewGitLabClient(baseURL, token string) (GitLabClient, error) {
+	if token == "" {
+		return nil, fmt.Errorf("gitlab token must be configured")
+	}
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	return &gitLabClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type issueResponse struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+// CreateIssue creates an issue and returns its IID and web URL.
+func (c *gitLabClient) CreateIssue(projectID, title, description string) (int, string, error) {
+	var resp issueResponse
+	body := map[string]string{"title": title, "description": description}
+	if err := c.do(http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/issues", body, &resp); err != nil {
+		return 0, "", fmt.Errorf("failed to create gitlab issue: %w", err)
+	}
+	return resp.IID, resp.WebURL, nil
+}
+
+// UpdateIssue replaces the title and description of an issue and returns its web URL.
+func (c *gitLabClient) UpdateIssue(projectID string, issueIID int, title, description string) (string, error) {
+	var resp issueResponse
+	body := map[string]string{"title": title, "description": description}
+	if err := c.do(http.MethodPut, fmt.Sprintf("/projects/%s/issues/%d", url.PathEscape(projectID), issueIID), body, &resp); err != nil {
+		return "", fmt.Errorf("failed to update gitlab issue %d: %w", issueIID, err)
+	}
+	return resp.WebURL, nil
+}
+
+// CreateNote posts a comment on an issue.
+func (c *gitLabClient) CreateNote(projectID string, issueIID int, body string) error {
+	if err := c.do(http.MethodPost, fmt.Sprintf("/projects/%s/issues/%d/notes", url.PathEscape(projectID), issueIID), map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to comment on gitlab issue %d: %w", issueIID, err)
+	}
+	return nil
+}
+
+func (c *gitLabClient) do(method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest(method, c.baseURL+"/api/v4"+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gitlab returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	exportapp "sofa-commander/backend/internal/features/export/application"
+	"sofa-commander/backend/internal/features/gitlab/application"
+	"sofa-commander/backend/internal/features/gitlab/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GitLabHandler holds the gitlab service.
+type GitLabHandler struct {
+	gitLabService application.GitLabService
+}
+
+// NewGitLabHandler creates a new GitLabHandler.
+func NewGitLabHandler(gitLabService application.GitLabService) *GitLabHandler {
+	return &GitLabHandler{
+		gitLabService: gitLabService,
+	}
+}
+
+// ExportHandler handles exporting a finalized session as a GitLab issue.
+func (h *GitLabHandler) ExportHandler(c *gin.Context) {
+	result, err := h.gitLabService.Export(c.Param("id"))
+	if errors.Is(err, exportapp.ErrBelowQualityThreshold) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export to GitLab: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// WebhookHandler handles GitLab issue webhooks, starting a refinement when the trigger label is added.
+func (h *GitLabHandler) WebhookHandler(c *gin.Context) {
+	var payload domain.IssueHookPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	started, err := h.gitLabService.HandleIssueHook(c.GetHeader("X-Gitlab-Token"), &payload)
+	if errors.Is(err, application.ErrInvalidWebhookToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle GitLab webhook: " + err.Error()})
+		return
+	}
+	if !started {
+		c.JSON(http.StatusOK, gin.H{"message": "Ignored"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Refinement session starting"})
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	exportapp "sofa-commander/backend/internal/features/export/application"
+	"sofa-commander/backend/internal/features/jira/domain"
+	"sofa-commander/backend/internal/features/jira/infrastructure"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// JiraService defines the interface for keeping sessions in sync with Jira issues.
+type JiraService interface {
+	Sync(sessionID string) (*domain.SyncResult, error)
+	Link(sessionID, issueKey string) (*domain.SyncResult, error)
+	PullComments(ctx context.Context, sessionID string) (*domain.PullCommentsResult, error)
+	HandleEvent(event events.Event)
+}
+
+// jiraService is the implementation of JiraService.
+type jiraService struct {
+	refinementService refinementapp.RefinementService
+	exportService     exportapp.ExportService
+	appConfigService  config.AppConfigService
+}
+
+// NewJiraService creates a new instance of jiraService.
+func NewJiraService(refinementService refinementapp.RefinementService, exportService exportapp.ExportService, appConfigService config.AppConfigService) JiraService {
+	return &jiraService{
+		refinementService: refinementService,
+		exportService:     exportService,
+		appConfigService:  appConfigService,
+	}
+}
+
+// Sync creates the session's Jira issue on first call and updates it afterwards.
+func (s *jiraService) Sync(sessionID string) (*domain.SyncResult, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	if err := s.exportService.CheckQuality(session); err != nil {
+		return nil, err
+	}
+	client, cfg, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	description, err := s.exportService.Render(sessionID, "jira")
+	if err != nil {
+		return nil, err
+	}
+	summary := exportapp.IssueTitle(session.FinalUserStory)
+
+	issueKey := session.JiraIssueKey
+	created := false
+	if issueKey == "" {
+		issueKey, err = client.CreateIssue(cfg.ProjectKey, cfg.IssueType, summary, description.Body)
+		created = true
+	} else {
+		err = client.UpdateIssue(issueKey, summary, description.Body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checklistKeys []string
+	if created && cfg.ACFormat == "checklist" {
+		for _, criterion := range session.FinalAC {
+			key, err := client.CreateSubtask(cfg.ProjectKey, cfg.SubtaskType, issueKey, exportapp.IssueTitle(criterion), criterion)
+			if err != nil {
+				logging.Default().Warn().Err(err).Str("issue_key", issueKey).Msg("Failed to create checklist sub-task of jira issue")
+				continue
+			}
+			checklistKeys = append(checklistKeys, key)
+		}
+	}
+
+	now := time.Now()
+	if _, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		session.JiraIssueKey = issueKey
+		session.JiraSyncedAt = &now
+	}); err != nil {
+		return nil, err
+	}
+	return &domain.SyncResult{IssueKey: issueKey, IssueURL: client.IssueURL(issueKey), Created: created, SyncedAt: now, ChecklistKeys: checklistKeys}, nil
+}
+
+// Link links a session to an existing issue and pushes the finalized output to it if available.
+func (s *jiraService) Link(sessionID, issueKey string) (*domain.SyncResult, error) {
+	session, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		session.JiraIssueKey = issueKey
+	})
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt != nil {
+		return s.Sync(sessionID)
+	}
+	client, _, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SyncResult{IssueKey: issueKey, IssueURL: client.IssueURL(issueKey)}, nil
+}
+
+// PullComments adds issue comments posted since the last pull to the session as additional context.
+func (s *jiraService) PullComments(ctx context.Context, sessionID string) (*domain.PullCommentsResult, error) {
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.JiraIssueKey == "" {
+		return nil, fmt.Errorf("session %s is not linked to a jira issue", sessionID)
+	}
+	client, _, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := client.ListComments(session.JiraIssueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Comments are compared against the creation time of the latest pulled one, both from Jira's clock,
+	// so skew between Jira and this server cannot skip or repeat comments.
+	var fresh []domain.Comment
+	pulledAt := session.JiraCommentsPulledAt
+	for _, comment := range comments {
+		if session.JiraCommentsPulledAt == nil || comment.Created.After(*session.JiraCommentsPulledAt) {
+			fresh = append(fresh, comment)
+			if pulledAt == nil || comment.Created.After(*pulledAt) {
+				created := comment.Created
+				pulledAt = &created
+			}
+		}
+	}
+	if len(fresh) == 0 {
+		return &domain.PullCommentsResult{IssueKey: session.JiraIssueKey, Comments: []domain.Comment{}}, nil
+	}
+	var b strings.Builder
+	for _, comment := range fresh {
+		b.WriteString(fmt.Sprintf("- %s：%s\n", comment.Author, comment.Body))
+	}
+	if err := s.refinementService.AddContext(ctx, sessionID, "Jira 評論 "+session.JiraIssueKey, b.String()); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.refinementService.UpdateSession(sessionID, func(session *refinementdomain.RefinementSession) {
+		session.JiraCommentsPulledAt = pulledAt
+	}); err != nil {
+		return nil, err
+	}
+	return &domain.PullCommentsResult{IssueKey: session.JiraIssueKey, Comments: fresh}, nil
+}
+
+// HandleEvent re-syncs linked issues when their session is finalized again.
+func (s *jiraService) HandleEvent(event events.Event) {
+	if event.Type != events.SessionFinalized {
+		return
+	}
+	session, err := s.refinementService.GetSession(event.SessionID)
+	if err != nil || session.JiraIssueKey == "" {
+		return
+	}
+	if _, err := s.Sync(event.SessionID); err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", event.SessionID).Str("jira_issue", session.JiraIssueKey).Msg("Failed to sync session to jira")
+	}
+}
+
+// client returns a Jira client and the Jira config with the defaults of unset issue types applied.
+func (s *jiraService) client() (infrastructure.JiraClient, configdomain.JiraConfig, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, configdomain.JiraConfig{}, err
+	}
+	cfg := appConfig.Jira
+	if cfg.ProjectKey == "" {
+		return nil, cfg, fmt.Errorf("jira project_key must be configured")
+	}
+	if cfg.IssueType == "" {
+		cfg.IssueType = "Story"
+	}
+	if cfg.SubtaskType == "" {
+		cfg.SubtaskType = "Sub-task"
+	}
+	client, err := infrastructure.NewJiraClientFromConfig(cfg)
+	if err != nil {
+		return nil, cfg, err
+	}
+	return client, cfg, nil
+}
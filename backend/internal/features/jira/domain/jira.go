@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Comment is a comment on a Jira issue.
+type Comment struct {
+	ID      string    `json:"id"`
+	Author  string    `json:"author"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created"`
+}
+
+// Issue is a Jira issue as read through the API.
+type Issue struct {
+	Key         string `json:"key"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	URL         string `json:"url"`
+}
+
+// LinkRequest is the request structure for linking a session to an existing Jira issue.
+type LinkRequest struct {
+	IssueKey string `json:"issue_key" binding:"required"`
+}
+
+// SyncResult describes the Jira issue a session is synced with.
+type SyncResult struct {
+	IssueKey string    `json:"issue_key"`
+	IssueURL string    `json:"issue_url"`
+	Created  bool      `json:"created"` // True when the issue was created by this sync
+	SyncedAt time.Time `json:"synced_at"`
+	// ChecklistKeys are the sub-tasks created for the AC along with the issue, with the checklist AC format
+	ChecklistKeys []string `json:"checklist_keys,omitempty"`
+}
+
+// PullCommentsResult describes the comments pulled into a session.
+type PullCommentsResult struct {
+	IssueKey string    `json:"issue_key"`
+	Comments []Comment `json:"comments"`
+}
@@ -0,0 +1,223 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/jira/domain"
+)
+
+// JiraClient defines the interface for the Jira REST API calls we use.
+type JiraClient interface {
+	CreateIssue(projectKey, issueType, summary, description string) (string, error)
+	CreateSubtask(projectKey, issueType, parentKey, summary, description string) (string, error)
+	UpdateIssue(issueKey, summary, description string) error
+	ListComments(issueKey string) ([]domain.Comment, error)
+	GetIssue(issueKey string) (*domain.Issue, error)
+	IssueURL(issueKey string) string
+}
+
+// issueKeyPattern matches Jira issue keys, e.g. "PROJ-123".
+var issueKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-\d+$`)
+
+// issuePath returns the API path of an issue, rejecting anything but an issue key, as keys also come
+// from the assistant's tool calls.
+func issuePath(issueKey string) (string, error) {
+	if !issueKeyPattern.MatchString(issueKey) {
+		return "", fmt.Errorf("invalid jira issue key %q", issueKey)
+	}
+	return "/rest/api/2/issue/" + url.PathEscape(issueKey), nil
+}
+
+// jiraClient is a Jira Cloud REST API v2 client using basic auth with an API token.
+type jiraClient struct {
+	baseURL    string
+	email      string
+	apiToken   string
+	httpClient *http.Client
+}
+
+// NewJiraClient creates a new Jira client.
+func NewJiraClient(baseURL, email, apiToken string) (JiraClient, error) {
+	if baseURL == "" || email == "" || apiToken == "" {
+		return nil, fmt.Errorf("jira base_url, email and api_token must be configured")
+	}
+	return &jiraClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		email:      email,
+		apiToken:   apiToken,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// NewJiraClientFromConfig creates a Jira client from the app configuration. The JIRA_API_TOKEN
+// environment variable overrides the configured API token.
+func NewJiraClientFromConfig(cfg configdomain.JiraConfig) (JiraClient, error) {
+	if token := os.Getenv("JIRA_API_TOKEN"); token != "" {
+		cfg.APIToken = token
+	}
+	return NewJiraClient(cfg.BaseURL, cfg.Email, cfg.APIToken)
+}
+
+// CreateIssue creates an issue and returns its key.
+func (c *jiraClient) CreateIssue(projectKey, issueType, summary, description string) (string, error) {
+	body := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": projectKey},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     summary,
+			"description": description,
+		},
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", body, &resp); err != nil {
+		return "", fmt.Errorf("failed to create jira issue: %w", err)
+	}
+	return resp.Key, nil
+}
+
+// CreateSubtask creates a sub-task of an issue and returns its key.
+func (c *jiraClient) CreateSubtask(projectKey, issueType, parentKey, summary, description string) (string, error) {
+	body := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": projectKey},
+			"parent":      map[string]string{"key": parentKey},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     summary,
+			"description": description,
+		},
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", body, &resp); err != nil {
+		return "", fmt.Errorf("failed to create sub-task of jira issue %s: %w", parentKey, err)
+	}
+	return resp.Key, nil
+}
+
+// UpdateIssue replaces the summary and description of an issue.
+func (c *jiraClient) UpdateIssue(issueKey, summary, description string) error {
+	body := map[string]any{
+		"fields": map[string]any{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+	path, err := issuePath(issueKey)
+	if err != nil {
+		return err
+	}
+	if err := c.do(http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("failed to update jira issue %s: %w", issueKey, err)
+	}
+	return nil
+}
+
+// ListComments returns the comments of an issue, oldest first.
+func (c *jiraClient) ListComments(issueKey string) ([]domain.Comment, error) {
+	var resp struct {
+		Comments []struct {
+			ID     string `json:"id"`
+			Author struct {
+				DisplayName string `json:"displayName"`
+			} `json:"author"`
+			Body    string `json:"body"`
+			Created string `json:"created"`
+		} `json:"comments"`
+	}
+	path, err := issuePath(issueKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(http.MethodGet, path+"/comment", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list comments of jira issue %s: %w", issueKey, err)
+	}
+	comments := make([]domain.Comment, 0, len(resp.Comments))
+	for _, rc := range resp.Comments {
+		created, err := time.Parse("2006-01-02T15:04:05.000-0700", rc.Created)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse created time of comment %s on jira issue %s: %w", rc.ID, issueKey, err)
+		}
+		comments = append(comments, domain.Comment{ID: rc.ID, Author: rc.Author.DisplayName, Body: rc.Body, Created: created})
+	}
+	return comments, nil
+}
+
+// GetIssue returns the summary, description and status of an issue.
+func (c *jiraClient) GetIssue(issueKey string) (*domain.Issue, error) {
+	var resp struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+			Status      struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	path, err := issuePath(issueKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.do(http.MethodGet, path+"?fields=summary,description,status", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get jira issue %s: %w", issueKey, err)
+	}
+	return &domain.Issue{
+		Key:         resp.Key,
+		Summary:     resp.Fields.Summary,
+		Description: resp.Fields.Description,
+		Status:      resp.Fields.Status.Name,
+		URL:         c.IssueURL(resp.Key),
+	}, nil
+}
+
+// IssueURL returns the browser URL of an issue.
+func (c *jiraClient) IssueURL(issueKey string) string {
+	return c.baseURL + "/browse/" + issueKey
+}
+
+func (c *jiraClient) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("jira returned status %d: %s", resp.StatusCode, string(data))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	exportapp "sofa-commander/backend/internal/features/export/application"
+	"sofa-commander/backend/internal/features/jira/application"
+	"sofa-commander/backend/internal/features/jira/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JiraHandler holds the jira service.
+type JiraHandler struct {
+	jiraService application.JiraService
+}
+
+// NewJiraHandler creates a new JiraHandler.
+func NewJiraHandler(jiraService application.JiraService) *JiraHandler {
+	return &JiraHandler{
+		jiraService: jiraService,
+	}
+}
+
+// SyncHandler handles creating or updating the session's Jira issue.
+func (h *JiraHandler) SyncHandler(c *gin.Context) {
+	result, err := h.jiraService.Sync(c.Param("id"))
+	if errors.Is(err, exportapp.ErrBelowQualityThreshold) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync with Jira: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// LinkHandler handles linking a session to an existing Jira issue.
+func (h *JiraHandler) LinkHandler(c *gin.Context) {
+	var req domain.LinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.jiraService.Link(c.Param("id"), req.IssueKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Jira issue: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// PullCommentsHandler handles pulling Jira comments into the session as additional context.
+func (h *JiraHandler) PullCommentsHandler(c *gin.Context) {
+	result, err := h.jiraService.PullComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pull Jira comments: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/marketplace/domain"
+	"sofa-commander/backend/internal/features/marketplace/infrastructure"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+)
+
+// MarketplaceService defines the interface for sharing prompt configurations between workspaces.
+type MarketplaceService interface {
+	ListPackages() ([]domain.Package, error)
+	ListVersions(name string) ([]domain.Package, error)
+	Publish(req *domain.PublishRequest, publishedBy string) (*domain.Package, error)
+	Import(name string, req *domain.ImportRequest) (*domain.Package, error)
+}
+
+// marketplaceService is the implementation of MarketplaceService.
+type marketplaceService struct {
+	repository       infrastructure.PackageRepository
+	appConfigService config.AppConfigService
+	workspaceService workspaceapp.WorkspaceService
+}
+
+// NewMarketplaceService creates a new instance of marketplaceService.
+func NewMarketplaceService(repository infrastructure.PackageRepository, appConfigService config.AppConfigService, workspaceService workspaceapp.WorkspaceService) MarketplaceService {
+	return &marketplaceService{repository: repository, appConfigService: appConfigService, workspaceService: workspaceService}
+}
+
+// ListPackages returns the latest version of every package.
+func (s *marketplaceService) ListPackages() ([]domain.Package, error) {
+	packages, err := s.repository.List()
+	if err != nil {
+		return nil, err
+	}
+	latest := []domain.Package{}
+	index := make(map[string]int)
+	for _, p := range packages {
+		if i, ok := index[p.Name]; ok {
+			if p.Version > latest[i].Version {
+				latest[i] = p
+			}
+			continue
+		}
+		index[p.Name] = len(latest)
+		latest = append(latest, p)
+	}
+	return latest, nil
+}
+
+// ListVersions returns every version of a package, oldest first.
+func (s *marketplaceService) ListVersions(name string) ([]domain.Package, error) {
+	packages, err := s.repository.List()
+	if err != nil {
+		return nil, err
+	}
+	versions := []domain.Package{}
+	for _, p := range packages {
+		if p.Name == name {
+			versions = append(versions, p)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("package %s not found", name)
+	}
+	return versions, nil
+}
+
+// Publish publishes the prompts of a workspace (its overrides, or the global prompts it uses) as a
+// new version of the named package.
+func (s *marketplaceService) Publish(req *domain.PublishRequest, publishedBy string) (*domain.Package, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || strings.ContainsAny(name, "@/ ") {
+		return nil, fmt.Errorf("invalid package name %q", req.Name)
+	}
+	if err := s.checkWorkspace(req.WorkspaceID); err != nil {
+		return nil, err
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	prompts := appConfig.ForWorkspace(req.WorkspaceID).Prompts()
+	if len(prompts.RolePrompts) == 0 {
+		return nil, fmt.Errorf("there are no role prompts to publish")
+	}
+
+	pkg := &domain.Package{
+		Name:              name,
+		Description:       req.Description,
+		PublishedBy:       publishedBy,
+		SourceWorkspaceID: req.WorkspaceID,
+		PublishedAt:       time.Now(),
+		Prompts:           prompts,
+	}
+	if err := s.repository.Add(pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Import applies a package version to a workspace's prompt overrides, or to the global prompts.
+func (s *marketplaceService) Import(name string, req *domain.ImportRequest) (*domain.Package, error) {
+	if err := s.checkWorkspace(req.WorkspaceID); err != nil {
+		return nil, err
+	}
+	versions, err := s.ListVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	var pkg *domain.Package
+	for i := range versions {
+		if (req.Version == 0 && (pkg == nil || versions[i].Version > pkg.Version)) || versions[i].Version == req.Version {
+			pkg = &versions[i]
+		}
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("version %d of package %s not found", req.Version, name)
+	}
+
+	prompts := pkg.Prompts
+	prompts.Package = fmt.Sprintf("%s@%d", pkg.Name, pkg.Version)
+	err = s.appConfigService.UpdateAppConfig(func(appConfig *configdomain.AppConfig) error {
+		if req.WorkspaceID == "" {
+			appConfig.SetPrompts(prompts)
+			return nil
+		}
+		if appConfig.WorkspacePrompts == nil {
+			appConfig.WorkspacePrompts = make(map[string]configdomain.PromptSet)
+		}
+		appConfig.WorkspacePrompts[req.WorkspaceID] = prompts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func (s *marketplaceService) checkWorkspace(workspaceID string) error {
+	if workspaceID == "" {
+		return nil
+	}
+	_, err := s.workspaceService.GetWorkspace(workspaceID)
+	return err
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// Package is a published, versioned prompt configuration that other workspaces can import.
+type Package struct {
+	Name              string                 `json:"name"`
+	Version           int                    `json:"version"` // Starts at 1 and increases with every publish
+	Description       string                 `json:"description,omitempty"`
+	PublishedBy       string                 `json:"published_by,omitempty"`
+	SourceWorkspaceID string                 `json:"source_workspace_id,omitempty"`
+	PublishedAt       time.Time              `json:"published_at"`
+	Prompts           configdomain.PromptSet `json:"prompts"`
+}
+
+// PublishRequest is the request structure for publishing a workspace's prompts as a package.
+type PublishRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"` // Empty publishes the global prompts
+}
+
+// ImportRequest is the request structure for importing a package into a workspace.
+type ImportRequest struct {
+	WorkspaceID string `json:"workspace_id,omitempty"` // Empty imports into the global prompts
+	Version     int    `json:"version,omitempty"`      // 0 imports the latest version
+}
@@ -0,0 +1,50 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"sofa-commander/backend/internal/features/marketplace/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// PackageRepository defines the interface for prompt package persistence.
+type PackageRepository interface {
+	List() ([]domain.Package, error)
+	Add(pkg *domain.Package) error // Assigns the next version of the package name
+}
+
+// jsonPackageRepository stores every version of every package in a JSON file.
+type jsonPackageRepository struct {
+	file *jsonfile.Store[[]domain.Package]
+	mu   sync.Mutex
+}
+
+// NewJSONPackageRepository creates a new repository backed by the given JSON file.
+func NewJSONPackageRepository(path string) PackageRepository {
+	return &jsonPackageRepository{file: jsonfile.NewStore[[]domain.Package](path, "packages")}
+}
+
+// List returns all versions of all packages in publishing order.
+func (r *jsonPackageRepository) List() ([]domain.Package, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Add stores a package as the next version of its name.
+func (r *jsonPackageRepository) Add(pkg *domain.Package) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	packages, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	pkg.Version = 1
+	for _, p := range packages {
+		if p.Name == pkg.Name && p.Version >= pkg.Version {
+			pkg.Version = p.Version + 1
+		}
+	}
+	return r.file.Store(append(packages, *pkg))
+}
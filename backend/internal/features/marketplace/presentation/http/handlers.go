@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/marketplace/application"
+	"sofa-commander/backend/internal/features/marketplace/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarketplaceHandler holds the marketplace service.
+type MarketplaceHandler struct {
+	marketplaceService application.MarketplaceService
+}
+
+// NewMarketplaceHandler creates a new MarketplaceHandler.
+func NewMarketplaceHandler(marketplaceService application.MarketplaceService) *MarketplaceHandler {
+	return &MarketplaceHandler{
+		marketplaceService: marketplaceService,
+	}
+}
+
+// ListPackagesHandler returns the latest version of every package.
+func (h *MarketplaceHandler) ListPackagesHandler(c *gin.Context) {
+	packages, err := h.marketplaceService.ListPackages()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list packages: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, packages)
+}
+
+// ListVersionsHandler returns every version of a package.
+func (h *MarketplaceHandler) ListVersionsHandler(c *gin.Context) {
+	versions, err := h.marketplaceService.ListVersions(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// PublishHandler publishes a workspace's prompts as a new package version.
+func (h *MarketplaceHandler) PublishHandler(c *gin.Context) {
+	var req domain.PublishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pkg, err := h.marketplaceService.Publish(&req, c.GetHeader("X-User-ID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to publish package: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, pkg)
+}
+
+// ImportHandler imports a package version into a workspace.
+func (h *MarketplaceHandler) ImportHandler(c *gin.Context) {
+	var req domain.ImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pkg, err := h.marketplaceService.Import(c.Param("name"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to import package: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pkg)
+}
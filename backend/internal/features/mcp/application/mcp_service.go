@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/features/mcp/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// ErrUnknownTool is returned when a tool that is not exposed is called.
+var ErrUnknownTool = fmt.Errorf("unknown tool")
+
+// MCPService defines the interface for the refinement tools exposed over MCP.
+type MCPService interface {
+	ListTools() []domain.Tool
+	CallTool(ctx context.Context, name string, arguments json.RawMessage) (*domain.ToolResult, error)
+}
+
+// mcpService is the implementation of MCPService.
+type mcpService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+}
+
+// NewMCPService creates a new instance of mcpService.
+func NewMCPService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService) MCPService {
+	return &mcpService{refinementService: refinementService, appConfigService: appConfigService}
+}
+
+var answersSchema = map[string]interface{}{
+	"type":                 "object",
+	"description":          "Answers keyed by \"<role>_<question>\", using the role and question text returned by the previous call",
+	"additionalProperties": map[string]interface{}{"type": "string"},
+}
+
+var tools = []domain.Tool{
+	{
+		Name:        "start_refinement",
+		Description: "Start refining a user story. Returns the session with the first round of questions from each role.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"story":         map[string]interface{}{"type": "string", "description": "The initial user story"},
+			"roles":         map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Roles to ask questions; defaults to all configured roles"},
+			"workspace_id":  map[string]interface{}{"type": "string"},
+			"target_rounds": map[string]interface{}{"type": "integer", "description": "Intended number of questioning rounds"},
+		}, "story"),
+	},
+	{
+		Name:        "submit_answers",
+		Description: "Answer the current questions and get the next round of questions.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"session_id":      map[string]interface{}{"type": "string"},
+			"answers":         answersSchema,
+			"additional_info": map[string]interface{}{"type": "string"},
+		}, "session_id", "answers"),
+	},
+	{
+		Name:        "get_suggestions",
+		Description: "Answer the current questions and get improvement suggestions from each role instead of more questions.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"session_id":      map[string]interface{}{"type": "string"},
+			"answers":         answersSchema,
+			"additional_info": map[string]interface{}{"type": "string"},
+		}, "session_id", "answers"),
+	},
+	{
+		Name:        "finalize",
+		Description: "Generate the final user story and acceptance criteria for the session.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"session_id":              map[string]interface{}{"type": "string"},
+			"accepted_suggestions":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Keys of the accepted suggestions"},
+			"modification_suggestion": map[string]interface{}{"type": "string"},
+		}, "session_id"),
+	},
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
+
+// ListTools returns the exposed tools.
+func (s *mcpService) ListTools() []domain.Tool {
+	return tools
+}
+
+// CallTool runs a tool. Errors from the refinement itself are returned as an error result.
+func (s *mcpService) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*domain.ToolResult, error) {
+	var args struct {
+		Story                  string            `json:"story"`
+		Roles                  []string          `json:"roles"`
+		WorkspaceID            string            `json:"workspace_id"`
+		TargetRounds           int               `json:"target_rounds"`
+		SessionID              string            `json:"session_id"`
+		Answers                map[string]string `json:"answers"`
+		AdditionalInfo         string            `json:"additional_info"`
+		AcceptedSuggestions    []string          `json:"accepted_suggestions"`
+		ModificationSuggestion string            `json:"modification_suggestion"`
+	}
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if args.SessionID != "" {
+		appConfig = refinementapp.ConfigForSession(s.refinementService, args.SessionID, appConfig)
+	}
+
+	var result interface{}
+	switch name {
+	case "start_refinement":
+		roles := args.Roles
+		if len(roles) == 0 {
+			roles = appConfig.RoleNames()
+		}
+		result, err = refinementapp.StartWithConfig(ctx, s.refinementService, &refinementdomain.RefinementRequest{
+			InitialUserStory: args.Story,
+			SelectedRoles:    roles,
+			WorkspaceID:      args.WorkspaceID,
+			TargetRounds:     args.TargetRounds,
+		}, appConfig)
+	case "submit_answers":
+		result, err = s.refinementService.SubmitAnswersAndContinue(ctx, args.SessionID, args.Answers, args.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	case "get_suggestions":
+		result, err = s.refinementService.SubmitAnswersAndGetSuggestions(ctx, args.SessionID, args.Answers, args.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	case "finalize":
+		var session *refinementdomain.RefinementSession
+		session, err = s.refinementService.GetSession(args.SessionID)
+		if err != nil {
+			break
+		}
+		var userStory string
+		var ac []string
+		userStory, ac, _, err = s.refinementService.Finalize(ctx, args.SessionID, string(session.Phase), nil, args.AcceptedSuggestions, args.ModificationSuggestion)
+		result = refinementdomain.FinalizeResponse{
+			UserStory:    userStory,
+			AC:           ac,
+			LintFindings: refinementapp.LintStory(userStory, ac, appConfig.StyleLint),
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	if err != nil {
+		return &domain.ToolResult{Content: []domain.Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	if session, ok := result.(*refinementdomain.RefinementSession); ok {
+		result = refinementdomain.NewSessionResponse(session).WithRoleDisplay(appConfig.RoleDisplay)
+	}
+
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool result: %w", err)
+	}
+	return &domain.ToolResult{Content: []domain.Content{{Type: "text", Text: string(text)}}}, nil
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"sofa-commander/backend/internal/features/mcp/application"
+	"sofa-commander/backend/internal/features/mcp/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MCPHandler serves the MCP endpoint over the streamable HTTP transport (plain JSON responses).
+type MCPHandler struct {
+	mcpService application.MCPService
+	token      string
+}
+
+// NewMCPHandler creates a new MCPHandler. An empty token disables the endpoint.
+func NewMCPHandler(mcpService application.MCPService, token string) *MCPHandler {
+	return &MCPHandler{
+		mcpService: mcpService,
+		token:      token,
+	}
+}
+
+// MessageHandler handles a JSON-RPC message posted by an MCP client.
+func (h *MCPHandler) MessageHandler(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Valid MCP token required"})
+		return
+	}
+
+	var req domain.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &domain.Error{Code: domain.ErrCodeParse, Message: err.Error()}})
+		return
+	}
+	if req.IsNotification() {
+		c.Status(http.StatusAccepted)
+		return
+	}
+
+	result, rpcErr := h.dispatch(c.Request.Context(), &req)
+	c.JSON(http.StatusOK, domain.Response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func (h *MCPHandler) dispatch(ctx context.Context, req *domain.Request) (interface{}, *domain.Error) {
+	switch req.Method {
+	case "initialize":
+		return gin.H{
+			"protocolVersion": domain.ProtocolVersion,
+			"capabilities":    gin.H{"tools": gin.H{}},
+			"serverInfo":      gin.H{"name": "sofa-commander", "version": "1.0.0"},
+		}, nil
+	case "ping":
+		return gin.H{}, nil
+	case "tools/list":
+		return gin.H{"tools": h.mcpService.ListTools()}, nil
+	case "tools/call":
+		var params domain.CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &domain.Error{Code: domain.ErrCodeInvalidParams, Message: err.Error()}
+		}
+		result, err := h.mcpService.CallTool(ctx, params.Name, params.Arguments)
+		if errors.Is(err, application.ErrUnknownTool) {
+			return nil, &domain.Error{Code: domain.ErrCodeInvalidParams, Message: err.Error()}
+		}
+		if err != nil {
+			return nil, &domain.Error{Code: domain.ErrCodeInternal, Message: err.Error()}
+		}
+		return result, nil
+	default:
+		return nil, &domain.Error{Code: domain.ErrCodeMethodNotFound, Message: "Method not found: " + req.Method}
+	}
+}
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	emailapp "sofa-commander/backend/internal/features/email/application"
+	"sofa-commander/backend/internal/features/notification/domain"
+	"sofa-commander/backend/internal/features/notification/infrastructure"
+	"sofa-commander/backend/internal/jobs"
+	"sofa-commander/backend/internal/logging"
+)
+
+// deliverJobType is the job type delivering one notification over one channel.
+const deliverJobType = "notification.deliver"
+
+// NotificationService defines the interface for user notifications.
+type NotificationService interface {
+	GetPreferences(userID string) (*domain.Preferences, error)
+	SavePreferences(prefs *domain.Preferences) error
+	HandleEvent(event events.Event)
+}
+
+// notificationService is the implementation of NotificationService.
+type notificationService struct {
+	repository       infrastructure.PreferencesRepository
+	appConfigService config.AppConfigService
+	queue            jobs.Queue
+}
+
+// NewNotificationService creates a new instance of notificationService and registers its delivery job.
+func NewNotificationService(repository infrastructure.PreferencesRepository, appConfigService config.AppConfigService, queue jobs.Queue) NotificationService {
+	s := &notificationService{repository: repository, appConfigService: appConfigService, queue: queue}
+	queue.Register(deliverJobType, s.deliver)
+	return s
+}
+
+// GetPreferences returns a user's preferences, defaulting to no channels.
+func (s *notificationService) GetPreferences(userID string) (*domain.Preferences, error) {
+	prefs, err := s.repository.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		prefs = &domain.Preferences{UserID: userID, Channels: []string{}, Events: domain.DefaultEvents}
+	}
+	return prefs, nil
+}
+
+// SavePreferences validates and stores a user's preferences.
+func (s *notificationService) SavePreferences(prefs *domain.Preferences) error {
+	if prefs.UserID == "" {
+		return fmt.Errorf("user id is required")
+	}
+	for _, channel := range prefs.Channels {
+		switch channel {
+		case domain.ChannelEmail:
+			if prefs.Email == "" {
+				return fmt.Errorf("email is required for the email channel")
+			}
+		case domain.ChannelSlack:
+			if prefs.SlackUserID == "" {
+				return fmt.Errorf("slack_user_id is required for the slack channel")
+			}
+		case domain.ChannelWebhook:
+			if prefs.WebhookURL == "" {
+				return fmt.Errorf("webhook_url is required for the webhook channel")
+			}
+			if err := infrastructure.ValidateWebhookURL(prefs.WebhookURL); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown notification channel %q", channel)
+		}
+	}
+	return s.repository.Save(prefs)
+}
+
+// HandleEvent enqueues a notification for every subscribed recipient and channel of an event:
+// the session owner, or the designated reviewers for review requests.
+func (s *notificationService) HandleEvent(event events.Event) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("event_type", event.Type).Str("session_id", event.SessionID).Msg("Skipping notifications, failed to load app config")
+		return
+	}
+	subject, body := message(event)
+	for _, userID := range recipients(event) {
+		prefs, err := s.repository.Get(userID)
+		if err != nil {
+			logging.Default().Warn().Err(err).Str("user_id", userID).Msg("Failed to load notification preferences")
+			continue
+		}
+		if prefs == nil || !prefs.Subscribed(event.Type) {
+			continue
+		}
+		for _, channel := range prefs.Channels {
+			notification := domain.Notification{
+				UserID:     userID,
+				Channel:    channel,
+				EventType:  event.Type,
+				SessionID:  event.SessionID,
+				Subject:    subject,
+				Body:       body,
+				SessionURL: appConfig.SessionURL(event.SessionID),
+			}
+			if err := s.queue.Enqueue(deliverJobType, notification); err != nil {
+				logging.Default().Error().Err(err).Str("user_id", userID).Str("event_type", event.Type).Str("session_id", event.SessionID).Msg("Failed to enqueue notification")
+			}
+		}
+	}
+}
+
+// deliver sends a queued notification; errors make the queue retry it.
+func (s *notificationService) deliver(payload json.RawMessage) error {
+	var notification domain.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return fmt.Errorf("invalid notification payload: %w", err)
+	}
+	prefs, err := s.repository.Get(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if prefs == nil {
+		return nil // The user removed their preferences meanwhile
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	text := notification.Body
+	if notification.SessionURL != "" {
+		text += "\n" + notification.SessionURL
+	}
+
+	switch notification.Channel {
+	case domain.ChannelEmail:
+		mailer, err := emailapp.NewMailer(appConfig.Email)
+		if err != nil {
+			return err
+		}
+		return mailer.Send(prefs.Email, notification.Subject, text)
+	case domain.ChannelSlack:
+		token := appConfig.Slack.BotToken
+		if envToken := os.Getenv("SLACK_BOT_TOKEN"); envToken != "" {
+			token = envToken
+		}
+		return infrastructure.SendSlackDM(token, prefs.SlackUserID, "*"+notification.Subject+"*\n"+text)
+	case domain.ChannelWebhook:
+		return infrastructure.PostWebhook(prefs.WebhookURL, &notification)
+	default:
+		return fmt.Errorf("unknown notification channel %q", notification.Channel)
+	}
+}
+
+// recipients returns the users an event concerns.
+func recipients(event events.Event) []string {
+	if event.Type == events.ReviewRequested {
+		reviewers, _ := event.Data["reviewers"].([]string)
+		return reviewers
+	}
+	if event.UserID == "" {
+		return nil
+	}
+	return []string{event.UserID}
+}
+
+// message returns the subject and body of the notification for an event.
+func message(event events.Event) (string, string) {
+	switch event.Type {
+	case events.SessionFinalized:
+		return "[Sofa Commander] 需求已完成打磨", fmt.Sprintf("你的需求打磨 %s 已產出最終的用戶故事與驗收標準。", event.SessionID)
+	case events.ReviewRequested:
+		return "[Sofa Commander] 請審核需求", fmt.Sprintf("需求打磨 %s 已完成，等待你的審核。", event.SessionID)
+	case events.RunFailed:
+		errText, _ := event.Data["error"].(string)
+		return "[Sofa Commander] AI 執行失敗", fmt.Sprintf("需求打磨 %s 的 AI 執行失敗：%s", event.SessionID, errText)
+	case events.SessionApproved:
+		return "[Sofa Commander] 需求已核准", fmt.Sprintf("需求打磨 %s 已獲所有審核者核准。", event.SessionID)
+	case events.ChangesRequested:
+		comment, _ := event.Data["comment"].(string)
+		return "[Sofa Commander] 審核者要求修改", fmt.Sprintf("需求打磨 %s 被要求修改：%s", event.SessionID, comment)
+	default:
+		return "[Sofa Commander] " + event.Type, fmt.Sprintf("需求打磨 %s：%s", event.SessionID, event.Type)
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "sofa-commander/backend/internal/events"
+
+// Notification channels.
+const (
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelWebhook = "webhook"
+)
+
+// DefaultEvents are the events a user is notified about when they have not chosen any.
+var DefaultEvents = []string{events.SessionFinalized, events.ReviewRequested, events.RunFailed}
+
+// Preferences are a user's notification channels and event subscriptions.
+type Preferences struct {
+	UserID      string   `json:"user_id"`
+	Channels    []string `json:"channels"`                // "email", "slack", "webhook"
+	Events      []string `json:"events,omitempty"`        // Event types, DefaultEvents when empty
+	Email       string   `json:"email,omitempty"`         // Address for the email channel
+	SlackUserID string   `json:"slack_user_id,omitempty"` // Member ID for Slack direct messages
+	WebhookURL  string   `json:"webhook_url,omitempty"`   // Receives the notification as JSON
+}
+
+// Subscribed reports whether the user wants to be notified about an event type.
+func (p *Preferences) Subscribed(eventType string) bool {
+	subscriptions := p.Events
+	if len(subscriptions) == 0 {
+		subscriptions = DefaultEvents
+	}
+	for _, e := range subscriptions {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is a message to deliver to a user over one channel.
+type Notification struct {
+	UserID     string `json:"user_id"`
+	Channel    string `json:"channel"`
+	EventType  string `json:"event_type"`
+	SessionID  string `json:"session_id"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+	SessionURL string `json:"session_url,omitempty"`
+}
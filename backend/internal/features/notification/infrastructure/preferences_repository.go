@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"sync"
+
+	"sofa-commander/backend/internal/features/notification/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// PreferencesRepository defines the interface for notification preference persistence.
+type PreferencesRepository interface {
+	Get(userID string) (*domain.Preferences, error)
+	Save(prefs *domain.Preferences) error
+}
+
+// jsonPreferencesRepository stores the preferences of all users in a JSON file keyed by user ID.
+type jsonPreferencesRepository struct {
+	file *jsonfile.Store[map[string]domain.Preferences]
+	mu   sync.Mutex
+}
+
+// NewJSONPreferencesRepository creates a new repository backed by the given JSON file.
+func NewJSONPreferencesRepository(path string) PreferencesRepository {
+	return &jsonPreferencesRepository{file: jsonfile.NewStore[map[string]domain.Preferences](path, "notification preferences")}
+}
+
+// Get returns a user's preferences, or nil if the user has none.
+func (r *jsonPreferencesRepository) Get(userID string) (*domain.Preferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	prefs, ok := all[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &prefs, nil
+}
+
+// Save creates or replaces a user's preferences.
+func (r *jsonPreferencesRepository) Save(prefs *domain.Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	all[prefs.UserID] = *prefs
+	return r.file.Store(all)
+}
@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"sofa-commander/backend/internal/features/notification/domain"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// webhookClient posts to user-supplied webhooks. It only connects to public addresses, checked when
+// connecting so a host cannot resolve to a public address when validated and a private one when
+// posted to, and only follows redirects to https URLs.
+var webhookClient = &http.Client{
+	Timeout: 15 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: 10 * time.Second, Control: rejectPrivateAddress}).DialContext,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("webhook redirected to a non-https URL")
+		}
+		return nil
+	},
+}
+
+// SendSlackDM sends a direct message to a Slack member through the bot's chat.postMessage call.
+func SendSlackDM(botToken, slackUserID, text string) error {
+	if botToken == "" {
+		return fmt.Errorf("slack bot_token must be configured")
+	}
+	data, err := json.Marshal(map[string]string{"channel": slackUserID, "text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/chat.postMessage", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+botToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack returned error: %s", result.Error)
+	}
+	return nil
+}
+
+// PostWebhook posts a notification as JSON to a user's webhook.
+func PostWebhook(url string, notification *domain.Notification) error {
+	if err := ValidateWebhookURL(url); err != nil {
+		return err
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// ValidateWebhookURL checks that a webhook URL is an https URL whose host resolves to public addresses
+// only, so webhooks cannot reach internal services or cloud metadata endpoints.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook_url: %w", err)
+	}
+	if u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("webhook_url must be an https URL")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("webhook host %s resolves to the non-public address %s", u.Hostname(), addr.IP)
+		}
+	}
+	return nil
+}
+
+// rejectPrivateAddress is a dialer control refusing connections to non-public addresses.
+func rejectPrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("connecting to the non-public address %s is not allowed", host)
+	}
+	return nil
+}
+
+// publicIP reports whether an address is publicly routable: not loopback, private, link-local (which
+// includes cloud metadata endpoints), multicast or unspecified.
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sofa-commander/backend/internal/features/notification/domain"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "public address", url: "https://93.184.216.34/hooks/sofa"},
+		{name: "public IPv6 address", url: "https://[2606:4700:4700::1111]/hook"},
+		{name: "http", url: "http://93.184.216.34/hook", wantErr: true},
+		{name: "no host", url: "https:///hook", wantErr: true},
+		{name: "loopback", url: "https://127.0.0.1:8080/hook", wantErr: true},
+		{name: "private network", url: "https://10.0.0.12/hook", wantErr: true},
+		{name: "cloud metadata", url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "IPv6 loopback", url: "https://[::1]/hook", wantErr: true},
+		{name: "unspecified", url: "https://0.0.0.0/hook", wantErr: true},
+		{name: "not a URL", url: "https://%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWebhookURL(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWebhookURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRejectPrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: "93.184.216.34:443"},
+		{address: "127.0.0.1:443", wantErr: true},
+		{address: "192.168.1.10:443", wantErr: true},
+		{address: "[fe80::1]:443", wantErr: true},
+		{address: "example.com:443", wantErr: true}, // Dialers connect to resolved addresses only
+		{address: "no-port", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if err := rejectPrivateAddress("tcp", tt.address, nil); (err != nil) != tt.wantErr {
+				t.Errorf("rejectPrivateAddress(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostWebhookRefusesLocalServer(t *testing.T) {
+	posted := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posted = true }))
+	defer server.Close()
+
+	if err := PostWebhook(server.URL, &domain.Notification{}); err == nil {
+		t.Error("PostWebhook() to a loopback server succeeded")
+	}
+	if posted {
+		t.Error("the loopback server received the webhook")
+	}
+}
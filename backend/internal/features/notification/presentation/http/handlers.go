@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/notification/application"
+	"sofa-commander/backend/internal/features/notification/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler holds the notification service.
+type NotificationHandler struct {
+	notificationService application.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(notificationService application.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetPreferencesHandler returns the notification preferences of the user in the X-User-ID header.
+func (h *NotificationHandler) GetPreferencesHandler(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-User-ID header is required"})
+		return
+	}
+	prefs, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// SavePreferencesHandler replaces the notification preferences of the user in the X-User-ID header.
+func (h *NotificationHandler) SavePreferencesHandler(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-User-ID header is required"})
+		return
+	}
+	var prefs domain.Preferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	prefs.UserID = userID
+	if err := h.notificationService.SavePreferences(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to save preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
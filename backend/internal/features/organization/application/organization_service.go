@@ -0,0 +1,282 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/features/organization/domain"
+	"sofa-commander/backend/internal/features/organization/infrastructure"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	usageapp "sofa-commander/backend/internal/features/usage/application"
+	usagedomain "sofa-commander/backend/internal/features/usage/domain"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+)
+
+// defaultRollupDays is the period of a rollup when none is given.
+const defaultRollupDays = 30
+
+// OrganizationService defines the interface for organizations, the workspaces grouped under them and
+// their rollup reports.
+type OrganizationService interface {
+	ListOrganizations() ([]domain.Organization, error)
+	GetOrganization(id string) (*domain.Organization, error)
+	CreateOrganization(req *domain.OrganizationRequest) (*domain.Organization, error)
+	UpdateOrganization(id string, req *domain.OrganizationRequest) (*domain.Organization, error)
+	DeleteOrganization(id string) error
+	ListWorkspaces(id string) ([]workspacedomain.Workspace, error)
+	ProvisionWorkspace(id string, req *workspacedomain.WorkspaceRequest) (*workspacedomain.Workspace, error)
+	AssignWorkspace(id, workspaceID string) (*workspacedomain.Workspace, error)
+	RemoveWorkspace(id, workspaceID string) error
+	Rollup(id string, filter domain.RollupFilter) (*domain.Rollup, error)
+}
+
+// organizationService is the implementation of OrganizationService.
+type organizationService struct {
+	repo              infrastructure.OrganizationRepository
+	workspaceService  workspaceapp.WorkspaceService
+	refinementService refinementapp.RefinementService
+	usageService      usageapp.UsageService
+	appConfigService  config.AppConfigService
+}
+
+// NewOrganizationService creates a new instance of organizationService.
+func NewOrganizationService(repo infrastructure.OrganizationRepository, workspaceService workspaceapp.WorkspaceService, refinementService refinementapp.RefinementService, usageService usageapp.UsageService, appConfigService config.AppConfigService) OrganizationService {
+	return &organizationService{repo: repo, workspaceService: workspaceService, refinementService: refinementService, usageService: usageService, appConfigService: appConfigService}
+}
+
+// ListOrganizations returns all organizations.
+func (s *organizationService) ListOrganizations() ([]domain.Organization, error) {
+	return s.repo.List()
+}
+
+// GetOrganization returns a single organization.
+func (s *organizationService) GetOrganization(id string) (*domain.Organization, error) {
+	return s.repo.Get(id)
+}
+
+// CreateOrganization creates an organization.
+func (s *organizationService) CreateOrganization(req *domain.OrganizationRequest) (*domain.Organization, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("organization name is required")
+	}
+	id, err := newOrganizationID()
+	if err != nil {
+		return nil, err
+	}
+	org := &domain.Organization{ID: id, CreatedAt: time.Now()}
+	apply(org, req)
+	if err := s.repo.Save(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// UpdateOrganization replaces the admins of an organization; an empty name keeps the current one.
+func (s *organizationService) UpdateOrganization(id string, req *domain.OrganizationRequest) (*domain.Organization, error) {
+	org, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	apply(org, req)
+	if err := s.repo.Save(org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// DeleteOrganization removes an organization without workspaces; its workspaces have to be removed or
+// moved first, so none is ungrouped by accident.
+func (s *organizationService) DeleteOrganization(id string) error {
+	workspaces, err := s.ListWorkspaces(id)
+	if err != nil {
+		return err
+	}
+	if len(workspaces) > 0 {
+		return fmt.Errorf("organization %s still has %d workspaces", id, len(workspaces))
+	}
+	return s.repo.Delete(id)
+}
+
+// ListWorkspaces returns the workspaces grouped under an organization.
+func (s *organizationService) ListWorkspaces(id string) ([]workspacedomain.Workspace, error) {
+	if _, err := s.repo.Get(id); err != nil {
+		return nil, err
+	}
+	all, err := s.workspaceService.ListWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	workspaces := []workspacedomain.Workspace{}
+	for _, w := range all {
+		if w.OrganizationID == id {
+			workspaces = append(workspaces, w)
+		}
+	}
+	return workspaces, nil
+}
+
+// ProvisionWorkspace creates a workspace grouped under an organization.
+func (s *organizationService) ProvisionWorkspace(id string, req *workspacedomain.WorkspaceRequest) (*workspacedomain.Workspace, error) {
+	if _, err := s.repo.Get(id); err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaceService.CreateWorkspace(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspaceService.SetOrganization(workspace.ID, id)
+}
+
+// AssignWorkspace groups an existing workspace under an organization. Workspaces of another
+// organization have to be removed from it first.
+func (s *organizationService) AssignWorkspace(id, workspaceID string) (*workspacedomain.Workspace, error) {
+	if _, err := s.repo.Get(id); err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaceService.GetWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.OrganizationID != "" && workspace.OrganizationID != id {
+		return nil, fmt.Errorf("workspace %s belongs to organization %s", workspaceID, workspace.OrganizationID)
+	}
+	return s.workspaceService.SetOrganization(workspaceID, id)
+}
+
+// RemoveWorkspace ungroups a workspace from an organization; the workspace itself is kept.
+func (s *organizationService) RemoveWorkspace(id, workspaceID string) error {
+	workspace, err := s.workspaceService.GetWorkspace(workspaceID)
+	if err != nil {
+		return err
+	}
+	if workspace.OrganizationID != id {
+		return fmt.Errorf("workspace %s does not belong to organization %s", workspaceID, id)
+	}
+	_, err = s.workspaceService.SetOrganization(workspaceID, "")
+	return err
+}
+
+// Rollup adds up the sessions, token usage, estimated cost and quality scores of an organization's
+// workspaces over the last days.
+func (s *organizationService) Rollup(id string, filter domain.RollupFilter) (*domain.Rollup, error) {
+	workspaces, err := s.ListWorkspaces(id)
+	if err != nil {
+		return nil, err
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	days := filter.Days
+	if days <= 0 {
+		days = defaultRollupDays
+	}
+	until := time.Now()
+	since := until.AddDate(0, 0, -days)
+
+	rollup := &domain.Rollup{OrganizationID: id, Since: since, Until: until, Workspaces: []domain.WorkspaceRollup{}}
+	index := make(map[string]int, len(workspaces))
+	scores := make([]float64, len(workspaces))
+	for i, w := range workspaces {
+		index[w.ID] = i
+		rollup.Workspaces = append(rollup.Workspaces, domain.WorkspaceRollup{WorkspaceID: w.ID, Name: w.Name})
+
+		attributions, err := s.usageService.ListAttributions(usagedomain.AttributionFilter{WorkspaceID: w.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list usage of workspace %s: %w", w.ID, err)
+		}
+		m := &rollup.Workspaces[i].Metrics
+		for _, a := range attributions {
+			if a.CreatedAt.Before(since) {
+				continue
+			}
+			m.PromptTokens += a.PromptTokens
+			m.CompletionTokens += a.CompletionTokens
+			m.TotalTokens += a.TotalTokens
+			if price, ok := appConfig.ModelPrices[a.Model]; ok {
+				m.EstimatedCost += price.Cost(a.PromptTokens, a.CompletionTokens)
+			} else {
+				m.UnpricedTokens += a.TotalTokens
+			}
+		}
+	}
+
+	for _, session := range s.refinementService.ListSessions() {
+		i, ok := index[session.WorkspaceID]
+		if !ok {
+			continue
+		}
+		m := &rollup.Workspaces[i].Metrics
+		if !session.CreatedAt.Before(since) {
+			m.Sessions++
+		}
+		if session.FinalizedAt == nil || session.FinalizedAt.Before(since) {
+			continue
+		}
+		m.FinalizedSessions++
+		if session.QualityScore != nil {
+			m.ScoredStories++
+			scores[i] += session.QualityScore.Total
+		}
+	}
+
+	var totalScore float64
+	for i := range rollup.Workspaces {
+		m := &rollup.Workspaces[i].Metrics
+		if m.ScoredStories > 0 {
+			m.AverageScore = round(scores[i] / float64(m.ScoredStories))
+		}
+		m.EstimatedCost = math.Round(m.EstimatedCost*100) / 100
+		totalScore += scores[i]
+		add(&rollup.Total, *m)
+	}
+	if rollup.Total.ScoredStories > 0 {
+		rollup.Total.AverageScore = round(totalScore / float64(rollup.Total.ScoredStories))
+	}
+	rollup.Total.EstimatedCost = math.Round(rollup.Total.EstimatedCost*100) / 100
+	return rollup, nil
+}
+
+// add adds the counts of a workspace to the total; the average score is computed separately.
+func add(total *domain.Metrics, m domain.Metrics) {
+	total.Sessions += m.Sessions
+	total.FinalizedSessions += m.FinalizedSessions
+	total.PromptTokens += m.PromptTokens
+	total.CompletionTokens += m.CompletionTokens
+	total.TotalTokens += m.TotalTokens
+	total.EstimatedCost += m.EstimatedCost
+	total.UnpricedTokens += m.UnpricedTokens
+	total.ScoredStories += m.ScoredStories
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func apply(org *domain.Organization, req *domain.OrganizationRequest) {
+	if strings.TrimSpace(req.Name) != "" {
+		org.Name = strings.TrimSpace(req.Name)
+	}
+	org.Admins = []string{}
+	for _, admin := range req.Admins {
+		if admin = strings.TrimSpace(admin); admin != "" && !slices.Contains(org.Admins, admin) {
+			org.Admins = append(org.Admins, admin)
+		}
+	}
+	org.UpdatedAt = time.Now()
+}
+
+func newOrganizationID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate organization ID: %w", err)
+	}
+	return "org-" + hex.EncodeToString(b), nil
+}
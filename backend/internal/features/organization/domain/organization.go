@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Organization groups the workspaces of a business unit. Its admins provision the workspaces and see the
+// rollup of their usage, cost and quality.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Admins    []string  `json:"admins"` // User IDs of the org admins, as in the X-User-ID header
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationRequest is the request structure for creating or updating an organization.
+type OrganizationRequest struct {
+	Name   string   `json:"name"`
+	Admins []string `json:"admins"`
+}
+
+// AssignRequest is the request structure for grouping an existing workspace under an organization.
+type AssignRequest struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+}
+
+// IsAdmin reports whether the user is an admin of the organization.
+func (o *Organization) IsAdmin(userID string) bool {
+	return userID != "" && slices.Contains(o.Admins, userID)
+}
+
+// RollupFilter selects the period of a rollup.
+type RollupFilter struct {
+	Days int `form:"days"` // Defaults to 30
+}
+
+// Rollup is the usage, cost and quality of an organization's workspaces over a period.
+type Rollup struct {
+	OrganizationID string            `json:"organization_id"`
+	Since          time.Time         `json:"since"`
+	Until          time.Time         `json:"until"`
+	Total          Metrics           `json:"total"`
+	Workspaces     []WorkspaceRollup `json:"workspaces"`
+}
+
+// WorkspaceRollup is the rollup of one workspace.
+type WorkspaceRollup struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Metrics
+}
+
+// Metrics are the figures a rollup adds up. Sessions count the sessions started in the period; the
+// scores are those of the stories finalized in it.
+type Metrics struct {
+	Sessions          int     `json:"sessions"`
+	FinalizedSessions int     `json:"finalized_sessions"`
+	PromptTokens      int     `json:"prompt_tokens"`
+	CompletionTokens  int     `json:"completion_tokens"`
+	TotalTokens       int     `json:"total_tokens"`
+	EstimatedCost     float64 `json:"estimated_cost"`  // USD, from the model prices of the app config
+	UnpricedTokens    int     `json:"unpriced_tokens"` // Tokens of models without a price, left out of the cost
+	ScoredStories     int     `json:"scored_stories"`
+	AverageScore      float64 `json:"average_score"`
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/organization/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// OrganizationRepository defines the interface for organization persistence.
+type OrganizationRepository interface {
+	List() ([]domain.Organization, error)
+	Get(id string) (*domain.Organization, error)
+	Save(organization *domain.Organization) error
+	Delete(id string) error
+}
+
+// jsonOrganizationRepository stores organizations in a JSON file.
+type jsonOrganizationRepository struct {
+	file *jsonfile.Store[[]domain.Organization]
+	mu   sync.Mutex
+}
+
+// NewJSONOrganizationRepository creates a new repository backed by the given JSON file.
+func NewJSONOrganizationRepository(path string) OrganizationRepository {
+	return &jsonOrganizationRepository{file: jsonfile.NewStore[[]domain.Organization](path, "organizations")}
+}
+
+// List returns all organizations.
+func (r *jsonOrganizationRepository) List() ([]domain.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Get returns the organization with the given ID.
+func (r *jsonOrganizationRepository) Get(id string) (*domain.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	organizations, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range organizations {
+		if organizations[i].ID == id {
+			return &organizations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("organization %s not found", id)
+}
+
+// Save creates or replaces an organization.
+func (r *jsonOrganizationRepository) Save(organization *domain.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	organizations, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range organizations {
+		if organizations[i].ID == organization.ID {
+			organizations[i] = *organization
+			replaced = true
+		}
+	}
+	if !replaced {
+		organizations = append(organizations, *organization)
+	}
+	return r.file.Store(organizations)
+}
+
+// Delete removes an organization.
+func (r *jsonOrganizationRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	organizations, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range organizations {
+		if organizations[i].ID == id {
+			return r.file.Store(append(organizations[:i], organizations[i+1:]...))
+		}
+	}
+	return fmt.Errorf("organization %s not found", id)
+}
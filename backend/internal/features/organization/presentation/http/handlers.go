@@ -0,0 +1,213 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"sofa-commander/backend/internal/features/organization/application"
+	"sofa-commander/backend/internal/features/organization/domain"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler holds the organization service and the admin token of the platform admins, who
+// create and delete organizations. The other routes of an organization are open to its admins too.
+type OrganizationHandler struct {
+	organizationService application.OrganizationService
+	adminToken          string
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler.
+func NewOrganizationHandler(organizationService application.OrganizationService, adminToken string) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		adminToken:          adminToken,
+	}
+}
+
+// platformAdmin reports whether the request carries the admin token.
+func (h *OrganizationHandler) platformAdmin(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// authorized loads the organization of the request and reports whether a platform admin or one of its
+// admins sent it, responding otherwise.
+func (h *OrganizationHandler) authorized(c *gin.Context) (*domain.Organization, bool) {
+	org, err := h.organizationService.GetOrganization(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !h.platformAdmin(c) && !org.IsAdmin(c.GetHeader("X-User-ID")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Organization admin required"})
+		return nil, false
+	}
+	return org, true
+}
+
+// ListOrganizationsHandler lists all organizations to platform admins, and the organizations the user in
+// the X-User-ID header administers to others.
+func (h *OrganizationHandler) ListOrganizationsHandler(c *gin.Context) {
+	orgs, err := h.organizationService.ListOrganizations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations: " + err.Error()})
+		return
+	}
+	if !h.platformAdmin(c) {
+		userID := c.GetHeader("X-User-ID")
+		administered := []domain.Organization{}
+		for i := range orgs {
+			if orgs[i].IsAdmin(userID) {
+				administered = append(administered, orgs[i])
+			}
+		}
+		orgs = administered
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// GetOrganizationHandler handles fetching a single organization.
+func (h *OrganizationHandler) GetOrganizationHandler(c *gin.Context) {
+	org, ok := h.authorized(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// CreateOrganizationHandler handles creating an organization.
+func (h *OrganizationHandler) CreateOrganizationHandler(c *gin.Context) {
+	if !h.platformAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := h.organizationService.CreateOrganization(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// UpdateOrganizationHandler handles updating an organization.
+func (h *OrganizationHandler) UpdateOrganizationHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	var req domain.OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := h.organizationService.UpdateOrganization(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganizationHandler handles deleting an organization without workspaces.
+func (h *OrganizationHandler) DeleteOrganizationHandler(c *gin.Context) {
+	if !h.platformAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	if err := h.organizationService.DeleteOrganization(c.Param("id")); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to delete organization: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// ListWorkspacesHandler lists the workspaces of an organization.
+func (h *OrganizationHandler) ListWorkspacesHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	workspaces, err := h.organizationService.ListWorkspaces(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list workspaces: " + err.Error()})
+		return
+	}
+	resp := make([]workspacedomain.WorkspaceResponse, 0, len(workspaces))
+	for i := range workspaces {
+		resp = append(resp, workspaces[i].ToResponse())
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ProvisionWorkspaceHandler creates a workspace in an organization.
+func (h *OrganizationHandler) ProvisionWorkspaceHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	var req workspacedomain.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	workspace, err := h.organizationService.ProvisionWorkspace(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to provision workspace: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, workspace.ToResponse())
+}
+
+// AssignWorkspaceHandler groups an existing workspace under an organization.
+func (h *OrganizationHandler) AssignWorkspaceHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	var req domain.AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	workspace, err := h.organizationService.AssignWorkspace(c.Param("id"), req.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to assign workspace: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, workspace.ToResponse())
+}
+
+// RemoveWorkspaceHandler ungroups a workspace from an organization.
+func (h *OrganizationHandler) RemoveWorkspaceHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	if err := h.organizationService.RemoveWorkspace(c.Param("id"), c.Param("workspaceId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace removed from organization successfully"})
+}
+
+// RollupHandler returns the usage, cost and quality rollup of an organization over the last `days` days.
+func (h *OrganizationHandler) RollupHandler(c *gin.Context) {
+	if _, ok := h.authorized(c); !ok {
+		return
+	}
+	var filter domain.RollupFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rollup, err := h.organizationService.Rollup(c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute rollup: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rollup)
+}
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/project/domain"
+	"sofa-commander/backend/internal/features/project/infrastructure"
+)
+
+// ProjectService defines the interface for the project application service.
+type ProjectService interface {
+	ListProjects() ([]domain.Project, error)
+	GetProject(id string) (*domain.Project, error)
+	CreateProject(req *domain.ProjectRequest) (*domain.Project, error)
+	UpdateProject(id string, req *domain.ProjectRequest) (*domain.Project, error)
+	DeleteProject(id string) error
+	ForProject(id string, appConfig *configdomain.AppConfig) (*configdomain.AppConfig, error)
+}
+
+// projectService is the implementation of ProjectService.
+type projectService struct {
+	repo infrastructure.ProjectRepository
+}
+
+// NewProjectService creates a new instance of projectService.
+func NewProjectService(repo infrastructure.ProjectRepository) ProjectService {
+	return &projectService{repo: repo}
+}
+
+// ListProjects returns all projects.
+func (s *projectService) ListProjects() ([]domain.Project, error) {
+	return s.repo.List()
+}
+
+// GetProject returns a single project.
+func (s *projectService) GetProject(id string) (*domain.Project, error) {
+	return s.repo.Get(id)
+}
+
+// CreateProject creates a project.
+func (s *projectService) CreateProject(req *domain.ProjectRequest) (*domain.Project, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("project name is required")
+	}
+	id, err := newProjectID()
+	if err != nil {
+		return nil, err
+	}
+	project := &domain.Project{ID: id, CreatedAt: time.Now()}
+	apply(project, req)
+	if err := s.repo.Save(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// UpdateProject replaces the context and prompts of a project; an empty name keeps the current one.
+func (s *projectService) UpdateProject(id string, req *domain.ProjectRequest) (*domain.Project, error) {
+	project, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	apply(project, req)
+	if err := s.repo.Save(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// DeleteProject removes a project. Its sessions keep their project ID and fall back to the global
+// context and prompts.
+func (s *projectService) DeleteProject(id string) error {
+	return s.repo.Delete(id)
+}
+
+// ForProject returns the app config with the product context and prompts of a project applied, or the
+// config itself without a project.
+func (s *projectService) ForProject(id string, appConfig *configdomain.AppConfig) (*configdomain.AppConfig, error) {
+	if id == "" {
+		return appConfig, nil
+	}
+	project, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return project.Apply(appConfig), nil
+}
+
+func apply(project *domain.Project, req *domain.ProjectRequest) {
+	if strings.TrimSpace(req.Name) != "" {
+		project.Name = strings.TrimSpace(req.Name)
+	}
+	project.Description = req.Description
+	project.ProductContext = req.ProductContext
+	project.PromptSet = req.PromptSet
+	project.UpdatedAt = time.Now()
+}
+
+func newProjectID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate project ID: %w", err)
+	}
+	return "prj-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// Project is a product served by the deployment, with its own product context and prompts. Its sessions
+// are refined with them instead of the global ones of the app config.
+type Project struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	ProductContext string `json:"product_context"`
+	// Prompts replaces the prompts of the app config; the global ones apply where a project sets none
+	configdomain.PromptSet
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProjectRequest is the request structure for creating or updating a project.
+type ProjectRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	ProductContext string `json:"product_context"`
+	configdomain.PromptSet
+}
+
+// Apply returns the app config with the project's product context and prompts in place of the global
+// ones. Settings the project leaves empty keep their global value.
+func (p *Project) Apply(appConfig *configdomain.AppConfig) *configdomain.AppConfig {
+	resolved := *appConfig
+	if p.ProductContext != "" {
+		resolved.ProductContext = p.ProductContext
+	}
+	if len(p.RolePrompts) > 0 {
+		resolved.RolePrompts = p.RolePrompts
+	}
+	if len(p.PhasePrompts) > 0 {
+		resolved.PhasePrompts = p.PhasePrompts
+	}
+	if len(p.PhaseFormatExamples) > 0 {
+		resolved.PhaseFormatExamples = p.PhaseFormatExamples
+	}
+	if len(p.RoleExemplars) > 0 {
+		resolved.RoleExemplars = p.RoleExemplars
+	}
+	if len(p.RoleLimits) > 0 {
+		resolved.RoleLimits = p.RoleLimits
+	}
+	return &resolved
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/project/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// ProjectRepository defines the interface for project persistence.
+type ProjectRepository interface {
+	List() ([]domain.Project, error)
+	Get(id string) (*domain.Project, error)
+	Save(project *domain.Project) error
+	Delete(id string) error
+}
+
+// jsonProjectRepository stores projects in a JSON file.
+type jsonProjectRepository struct {
+	file *jsonfile.Store[[]domain.Project]
+	mu   sync.Mutex
+}
+
+// NewJSONProjectRepository creates a new repository backed by the given JSON file.
+func NewJSONProjectRepository(path string) ProjectRepository {
+	return &jsonProjectRepository{file: jsonfile.NewStore[[]domain.Project](path, "projects")}
+}
+
+// List returns all projects.
+func (r *jsonProjectRepository) List() ([]domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Get returns the project with the given ID.
+func (r *jsonProjectRepository) Get(id string) (*domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projects, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		if projects[i].ID == id {
+			return &projects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("project %s not found", id)
+}
+
+// Save creates or replaces a project.
+func (r *jsonProjectRepository) Save(project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projects, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range projects {
+		if projects[i].ID == project.ID {
+			projects[i] = *project
+			replaced = true
+		}
+	}
+	if !replaced {
+		projects = append(projects, *project)
+	}
+	return r.file.Store(projects)
+}
+
+// Delete removes a project.
+func (r *jsonProjectRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projects, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range projects {
+		if projects[i].ID == id {
+			return r.file.Store(append(projects[:i], projects[i+1:]...))
+		}
+	}
+	return fmt.Errorf("project %s not found", id)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/project/application"
+	"sofa-commander/backend/internal/features/project/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectHandler holds the project service.
+type ProjectHandler struct {
+	projectService application.ProjectService
+}
+
+// NewProjectHandler creates a new ProjectHandler.
+func NewProjectHandler(projectService application.ProjectService) *ProjectHandler {
+	return &ProjectHandler{
+		projectService: projectService,
+	}
+}
+
+// ListProjectsHandler handles listing all projects.
+func (h *ProjectHandler) ListProjectsHandler(c *gin.Context) {
+	projects, err := h.projectService.ListProjects()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list projects: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetProjectHandler handles fetching a single project.
+func (h *ProjectHandler) GetProjectHandler(c *gin.Context) {
+	project, err := h.projectService.GetProject(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// CreateProjectHandler handles creating a project.
+func (h *ProjectHandler) CreateProjectHandler(c *gin.Context) {
+	var req domain.ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	project, err := h.projectService.CreateProject(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// UpdateProjectHandler handles updating a project.
+func (h *ProjectHandler) UpdateProjectHandler(c *gin.Context) {
+	var req domain.ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	project, err := h.projectService.UpdateProject(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProjectHandler handles deleting a project.
+func (h *ProjectHandler) DeleteProjectHandler(c *gin.Context) {
+	if err := h.projectService.DeleteProject(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// vaguePhrases are answers that almost never give a role what it needs.
+var vaguePhrases = []string{"it depends", "tbd", "to be decided", "not sure", "maybe", "看情況", "待定", "再說", "不確定", "之後再討論"}
+
+const answerCheckSystemPrompt = `你是一位需求打磨教練，負責檢查產品經理 (PM) 對角色提問的回答是否具體。
+請判斷回答是否含糊（例如「看情況」、「TBD」、沒有數字或條件），並指出該角色實際需要哪些具體細節。
+僅回傳 JSON：{"vague": true/false, "reason": "判斷理由", "needed_detail": "角色需要的具體細節"}`
+
+// CheckAnswer flags vague answers and suggests the concrete detail the asking role needs.
+func (s *refinementService) CheckAnswer(ctx context.Context, req *domain.CheckAnswerRequest) (*domain.AnswerQualityHint, error) {
+	session, err := s.GetSession(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Answer) == "" {
+		return &domain.AnswerQualityHint{Vague: true, Reason: "回答為空"}, nil
+	}
+
+	hint := &domain.AnswerQualityHint{MatchedPhrases: matchVaguePhrases(req.Answer)}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var history strings.Builder
+	history.WriteString("User Story：" + session.UserStory + "\n")
+	for _, q := range session.Questions {
+		if q.Answer != "" {
+			history.WriteString(fmt.Sprintf("先前回答（%s）：%s\n", q.Role, q.Answer))
+		}
+	}
+	userPrompt := fmt.Sprintf("%s\n角色：%s\n問題：%s\nPM 回答：%s", history.String(), req.Role, req.Question, req.Answer)
+
+	raw, err := client.Complete(ctx, model, answerCheckSystemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check answer: %w", err)
+	}
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "```json") && strings.HasSuffix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```json\n")
+		raw = strings.TrimSuffix(raw, "\n```")
+	}
+	if err := json.Unmarshal([]byte(raw), hint); err != nil {
+		return nil, fmt.Errorf("failed to parse answer check from AI: %w, raw response: %s", err, raw)
+	}
+	if len(hint.MatchedPhrases) > 0 {
+		hint.Vague = true
+	}
+	return hint, nil
+}
+
+// matchVaguePhrases returns the vague phrases contained in the answer.
+func matchVaguePhrases(answer string) []string {
+	lower := strings.ToLower(answer)
+	var matched []string
+	for _, phrase := range vaguePhrases {
+		if strings.Contains(lower, phrase) {
+			matched = append(matched, phrase)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+const endpointStubsSystemPrompt = `You are an API designer. Given a user story and its acceptance criteria, propose the REST endpoints needed to implement it.
+Use resource-oriented paths with OpenAPI path parameters (e.g. /orders/{id}), upper-case HTTP methods and JSON Schema for request and response bodies.
+Propose only endpoints the story actually needs. Omit request_schema for endpoints without a body.
+Return only JSON: [{"method": "POST", "path": "/...", "summary": "...", "request_schema": {...}, "response_status": 201, "response_schema": {...}}]`
+
+// ProposeEndpoints proposes endpoint stubs for the finalized story and AC of a session and stores them,
+// replacing earlier proposals.
+func (s *refinementService) ProposeEndpoints(ctx context.Context, sessionID string) ([]domain.EndpointStub, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	stubs, err := proposeEndpoints(ctx, client, model, session.FinalUserStory, session.FinalAC)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.EndpointStubs = stubs
+	}); err != nil {
+		return nil, err
+	}
+	return stubs, nil
+}
+
+// proposeEndpoints asks the model for the endpoints a story needs.
+func proposeEndpoints(ctx context.Context, client infrastructure.OpenAIClient, model, userStory string, ac []string) ([]domain.EndpointStub, error) {
+	source, err := json.Marshal(map[string]any{"user_story": userStory, "ac": ac})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal finalized output: %w", err)
+	}
+	raw, err := client.Complete(ctx, model, endpointStubsSystemPrompt, string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to propose endpoints: %w", err)
+	}
+	raw = stripCodeFence(raw)
+
+	var stubs []domain.EndpointStub
+	if err := json.Unmarshal([]byte(raw), &stubs); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoints from AI: %w, raw response: %s", err, raw)
+	}
+	result := stubs[:0]
+	for _, stub := range stubs {
+		stub.Method = strings.ToUpper(strings.TrimSpace(stub.Method))
+		stub.Path = strings.TrimSpace(stub.Path)
+		if stub.Method == "" || stub.Path == "" {
+			continue
+		}
+		if !strings.HasPrefix(stub.Path, "/") {
+			stub.Path = "/" + stub.Path
+		}
+		result = append(result, stub)
+	}
+	return result, nil
+}
@@ -0,0 +1,313 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// confidentSimilarity is the similarity above which a block's heading is matched to a question
+// without asking the AI.
+const confidentSimilarity = 0.8
+
+const bulkMatchSystemPrompt = `You match a product manager's answers, written freely in an editor, to the questions they answer.
+You are given the numbered questions and the numbered answer blocks, each with the heading the PM wrote above it.
+Match every block to the question it answers, or to 0 if it answers none. Never match two blocks to the same question.
+Return only JSON: [{"block": 1, "question": 3}]`
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
+	markdownQuestion = regexp.MustCompile(`^(?i)(?:Q\d*[:.：]|\*\*Q\d*[:.：]?\*\*)\s*(.+)$`)
+	answerPrefix     = regexp.MustCompile(`^(?i)(?:A\d*[:.：]|\*\*A\d*[:.：]?\*\*)\s*`)
+	questionNumber   = regexp.MustCompile(`^(?i)Q?(\d+)[.)、]?$`)
+)
+
+// bulkBlock is one answer of a bulk submission with the heading the PM wrote above it.
+type bulkBlock struct {
+	Role    string // Set when the answer is written under a role's section
+	Heading string
+	Answer  string
+}
+
+// bulkQuestion is a question of the current round in submission order.
+type bulkQuestion struct {
+	Key    string // Answer key, "<role>_<prompt>"
+	Role   string
+	Prompt string
+}
+
+// ParseBulkAnswers maps a round's answers written as one Markdown or YAML document to the current
+// questions. Blocks are matched by question number, exact text or close similarity; the rest are
+// matched with AI assistance, and the answers it matched are reported for a second look.
+func (s *refinementService) ParseBulkAnswers(ctx context.Context, sessionID, content, format string) (*domain.BulkAnswers, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = detectBulkFormat(content)
+	}
+	var blocks []bulkBlock
+	switch format {
+	case "markdown":
+		blocks = parseMarkdownAnswers(content, session.Request.SelectedRoles)
+	case "yaml":
+		blocks, err = parseYAMLAnswers(content)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bulk answer format %q, expected \"markdown\" or \"yaml\"", format)
+	}
+
+	var questions []bulkQuestion
+	for _, q := range session.Questions {
+		for _, p := range q.Prompt {
+			questions = append(questions, bulkQuestion{Key: q.Role + "_" + p, Role: q.Role, Prompt: p})
+		}
+	}
+
+	result := &domain.BulkAnswers{Answers: make(map[string]string)}
+	var pending []bulkBlock
+	for _, block := range blocks {
+		if key := matchBlock(block, questions); key != "" {
+			addBulkAnswer(result.Answers, key, block.Answer)
+		} else {
+			pending = append(pending, block)
+		}
+	}
+	if len(pending) > 0 {
+		matched := s.matchBlocksWithAI(ctx, session, pending, questions)
+		for i, block := range pending {
+			if key, ok := matched[i]; ok {
+				addBulkAnswer(result.Answers, key, block.Answer)
+				result.AIMatched = append(result.AIMatched, key)
+			} else {
+				result.Unmatched = append(result.Unmatched, domain.BulkAnswerBlock{Heading: block.Heading, Answer: block.Answer})
+			}
+		}
+	}
+	for _, q := range questions {
+		if _, ok := result.Answers[q.Key]; !ok {
+			result.Unanswered = append(result.Unanswered, q.Key)
+		}
+	}
+	return result, nil
+}
+
+// detectBulkFormat treats documents with Markdown headings or Q: lines as Markdown, anything else as YAML.
+func detectBulkFormat(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if markdownHeading.MatchString(line) || markdownQuestion.MatchString(line) {
+			return "markdown"
+		}
+	}
+	return "yaml"
+}
+
+// parseMarkdownAnswers splits a Markdown document into answers. Each heading or "Q:" line starts an
+// answer; a heading that names a selected role starts that role's section instead.
+func parseMarkdownAnswers(content string, roles []string) []bulkBlock {
+	var blocks []bulkBlock
+	var current *bulkBlock
+	var answer []string
+	role := ""
+	flush := func() {
+		if current != nil {
+			current.Answer = strings.TrimSpace(strings.Join(answer, "\n"))
+			if current.Answer != "" {
+				blocks = append(blocks, *current)
+			}
+		}
+		current, answer = nil, nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		heading := ""
+		if m := markdownHeading.FindStringSubmatch(trimmed); m != nil {
+			heading = strings.TrimSpace(m[1])
+		} else if m := markdownQuestion.FindStringSubmatch(trimmed); m != nil {
+			heading = strings.TrimSpace(m[1])
+		}
+		if heading == "" {
+			if current != nil {
+				answer = append(answer, answerPrefix.ReplaceAllString(trimmed, ""))
+			}
+			continue
+		}
+		flush()
+		if matchesRole(heading, roles) {
+			role = heading
+			continue
+		}
+		current = &bulkBlock{Role: role, Heading: heading}
+	}
+	flush()
+	return blocks
+}
+
+// parseYAMLAnswers reads answers from a YAML mapping of questions (or question numbers) to answers,
+// optionally grouped under role names, or from a list of {question, answer} items.
+func parseYAMLAnswers(content string) ([]bulkBlock, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML answers: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	var blocks []bulkBlock
+	var walk func(node *yaml.Node, role string) error
+	walk = func(node *yaml.Node, role string) error {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if value.Kind == yaml.MappingNode && role == "" {
+					if err := walk(value, key.Value); err != nil {
+						return err
+					}
+					continue
+				}
+				if value.Kind != yaml.ScalarNode {
+					return fmt.Errorf("the answer to %q must be text", key.Value)
+				}
+				if strings.TrimSpace(value.Value) != "" {
+					blocks = append(blocks, bulkBlock{Role: role, Heading: key.Value, Answer: strings.TrimSpace(value.Value)})
+				}
+			}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				var qa struct {
+					Role     string `yaml:"role"`
+					Question string `yaml:"question"`
+					Answer   string `yaml:"answer"`
+				}
+				if err := item.Decode(&qa); err != nil {
+					return fmt.Errorf("failed to parse YAML answer item: %w", err)
+				}
+				if strings.TrimSpace(qa.Answer) != "" {
+					blocks = append(blocks, bulkBlock{Role: qa.Role, Heading: qa.Question, Answer: strings.TrimSpace(qa.Answer)})
+				}
+			}
+		default:
+			return fmt.Errorf("YAML answers must be a mapping or a list")
+		}
+		return nil
+	}
+	if err := walk(doc.Content[0], ""); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// matchBlock returns the key of the question a block answers when the match is unambiguous: the
+// heading is the question's number, its key, its text, or very close to its text.
+func matchBlock(block bulkBlock, questions []bulkQuestion) string {
+	heading := strings.TrimSpace(block.Heading)
+	if m := questionNumber.FindStringSubmatch(heading); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(questions) {
+			return questions[n-1].Key
+		}
+	}
+	// The questions of the block's role are tried first, in case similar questions were asked by several roles.
+	for _, sameRole := range []bool{true, false} {
+		best, bestScore := "", 0.0
+		for _, q := range questions {
+			if sameRole && block.Role != "" && !strings.EqualFold(block.Role, q.Role) {
+				continue
+			}
+			if heading == q.Key || heading == q.Prompt {
+				return q.Key
+			}
+			if score := questionSimilarity(heading, q.Prompt); score > bestScore {
+				best, bestScore = q.Key, score
+			}
+		}
+		if bestScore >= confidentSimilarity {
+			return best
+		}
+	}
+	return ""
+}
+
+// matchBlocksWithAI asks the AI which questions the blocks answer, keyed by block index. Blocks are
+// left unmatched when the AI is unavailable.
+func (s *refinementService) matchBlocksWithAI(ctx context.Context, session *domain.RefinementSession, blocks []bulkBlock, questions []bulkQuestion) map[int]string {
+	matched := make(map[int]string)
+	if len(questions) == 0 {
+		return matched
+	}
+	var b strings.Builder
+	b.WriteString("Questions:\n")
+	for i, q := range questions {
+		fmt.Fprintf(&b, "%d. [%s] %s\n", i+1, q.Role, q.Prompt)
+	}
+	b.WriteString("\nAnswer blocks:\n")
+	for i, block := range blocks {
+		heading := block.Heading
+		if block.Role != "" {
+			heading = "[" + block.Role + "] " + heading
+		}
+		fmt.Fprintf(&b, "%d. Heading: %s\n   Answer: %s\n", i+1, heading, block.Answer)
+	}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Skipping AI matching of bulk answers")
+		return matched
+	}
+	raw, err := client.Complete(ctx, model, bulkMatchSystemPrompt, b.String())
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to match bulk answers with AI")
+		return matched
+	}
+	var pairs []struct {
+		Block    int `json:"block"`
+		Question int `json:"question"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &pairs); err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to parse bulk answer matches from AI")
+		if logging.Prompts() {
+			logging.From(ctx).Debug().Str("output", raw).Msg("Unparsable bulk answer matches")
+		}
+		return matched
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Block < pairs[j].Block })
+	used := make(map[int]bool)
+	for _, pair := range pairs {
+		if pair.Block < 1 || pair.Block > len(blocks) || pair.Question < 1 || pair.Question > len(questions) || used[pair.Question] {
+			continue
+		}
+		used[pair.Question] = true
+		matched[pair.Block-1] = questions[pair.Question-1].Key
+	}
+	return matched
+}
+
+// addBulkAnswer sets an answer, joining it to an earlier answer to the same question.
+func addBulkAnswer(answers map[string]string, key, answer string) {
+	if existing, ok := answers[key]; ok {
+		answer = existing + "\n" + answer
+	}
+	answers[key] = answer
+}
+
+func matchesRole(heading string, roles []string) bool {
+	for _, role := range roles {
+		if strings.EqualFold(heading, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdownAnswers(t *testing.T) {
+	roles := []string{"PM", "QA"}
+	tests := []struct {
+		name    string
+		content string
+		want    []bulkBlock
+	}{
+		{
+			name:    "headings",
+			content: "## Who are the users?\nStore managers.\n\n## What is out of scope?\nRefunds.",
+			want: []bulkBlock{
+				{Heading: "Who are the users?", Answer: "Store managers."},
+				{Heading: "What is out of scope?", Answer: "Refunds."},
+			},
+		},
+		{
+			name:    "question and answer lines",
+			content: "Q1: Who are the users?\nA1: Store managers.\nQ: What is out of scope?\nA: Refunds.\nAnd returns.",
+			want: []bulkBlock{
+				{Heading: "Who are the users?", Answer: "Store managers."},
+				{Heading: "What is out of scope?", Answer: "Refunds.\nAnd returns."},
+			},
+		},
+		{
+			name:    "role sections",
+			content: "# PM\n## Who are the users?\nStore managers.\n# qa\n## Which browsers?\nChrome.",
+			want: []bulkBlock{
+				{Role: "PM", Heading: "Who are the users?", Answer: "Store managers."},
+				{Role: "qa", Heading: "Which browsers?", Answer: "Chrome."},
+			},
+		},
+		{
+			name:    "unanswered questions and preamble are dropped",
+			content: "My answers:\n## Who are the users?\n\n## What is out of scope?\nRefunds.",
+			want: []bulkBlock{
+				{Heading: "What is out of scope?", Answer: "Refunds."},
+			},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseMarkdownAnswers(tt.content, roles)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMarkdownAnswers() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []bulkBlock
+		wantErr bool
+	}{
+		{
+			name:    "mapping",
+			content: "1: Store managers.\n\"What is out of scope?\": Refunds.\n",
+			want: []bulkBlock{
+				{Heading: "1", Answer: "Store managers."},
+				{Heading: "What is out of scope?", Answer: "Refunds."},
+			},
+		},
+		{
+			name:    "mapping grouped by role",
+			content: "PM:\n  \"Who are the users?\": Store managers.\nQA:\n  \"Which browsers?\": \"\"\n",
+			want: []bulkBlock{
+				{Role: "PM", Heading: "Who are the users?", Answer: "Store managers."},
+			},
+		},
+		{
+			name:    "list",
+			content: "- role: QA\n  question: Which browsers?\n  answer: Chrome.\n- question: Who are the users?\n  answer: \" Store managers. \"\n",
+			want: []bulkBlock{
+				{Role: "QA", Heading: "Which browsers?", Answer: "Chrome."},
+				{Heading: "Who are the users?", Answer: "Store managers."},
+			},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "answer that is not text",
+			content: "\"Which browsers?\":\n  - Chrome\n  - Firefox\n",
+			wantErr: true,
+		},
+		{
+			name:    "scalar document",
+			content: "just some text",
+			wantErr: true,
+		},
+		{
+			name:    "invalid YAML",
+			content: "a: [b",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAMLAnswers(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseYAMLAnswers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAMLAnswers() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchBlock(t *testing.T) {
+	questions := []bulkQuestion{
+		{Key: "PM_Who are the target users?", Role: "PM", Prompt: "Who are the target users?"},
+		{Key: "QA_Which browsers must be supported?", Role: "QA", Prompt: "Which browsers must be supported?"},
+		{Key: "QA_Who are the target users?", Role: "QA", Prompt: "Who are the target users?"},
+	}
+	tests := []struct {
+		name  string
+		block bulkBlock
+		want  string
+	}{
+		{name: "number", block: bulkBlock{Heading: "2"}, want: "QA_Which browsers must be supported?"},
+		{name: "Q number", block: bulkBlock{Heading: "Q1."}, want: "PM_Who are the target users?"},
+		{name: "number out of range", block: bulkBlock{Heading: "4"}, want: ""},
+		{name: "key", block: bulkBlock{Heading: "QA_Who are the target users?"}, want: "QA_Who are the target users?"},
+		{name: "exact text", block: bulkBlock{Heading: "Which browsers must be supported?"}, want: "QA_Which browsers must be supported?"},
+		{name: "exact text within role", block: bulkBlock{Role: "qa", Heading: "Who are the target users?"}, want: "QA_Who are the target users?"},
+		{name: "similar text", block: bulkBlock{Heading: "Which browsers must be supported"}, want: "QA_Which browsers must be supported?"},
+		{name: "similar text outside role", block: bulkBlock{Role: "PM", Heading: "Which browsers must be supported"}, want: "QA_Which browsers must be supported?"},
+		{name: "unrelated", block: bulkBlock{Heading: "Deployment window"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchBlock(tt.block, questions); got != tt.want {
+				t.Errorf("matchBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
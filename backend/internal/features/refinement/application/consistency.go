@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const (
+	// consistencyCandidates is the number of most similar earlier stories a story is compared with, so
+	// the prompt stays small however many stories a product has.
+	consistencyCandidates = 5
+	// consistencyMinSimilarity is the similarity below which earlier stories are not compared, as they
+	// are about something else.
+	consistencyMinSimilarity = 0.1
+)
+
+const consistencySystemPrompt = `You check a new user story for contradictions with earlier finalized stories of the same product.
+A contradiction is a statement of the new story that cannot hold together with a statement of an earlier story: conflicting business rules, limits, permissions, states or behaviors of the same thing. Different features, added detail and intended changes that the new story states explicitly are not contradictions.
+Quote the conflicting statements briefly and explain the conflict in one sentence, in the language of the stories.
+Return only JSON: {"conflicts": [{"story": 2, "statement": "...", "conflicts_with": "...", "explanation": "..."}]}
+"story" is the number of the earlier story. Return an empty conflicts array when there is none.`
+
+// consistencyCandidate is an earlier story compared with the checked one.
+type consistencyCandidate struct {
+	session    *domain.RefinementSession
+	similarity float64
+}
+
+// CheckConsistency checks the story of a session for contradictions with the previously finalized
+// stories of the same product. The earlier stories most similar to it by their embeddings are compared
+// by the AI; the others are not about the same thing.
+func (s *refinementService) CheckConsistency(ctx context.Context, sessionID string) (*domain.ConsistencyReport, error) {
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = sessionContext(ctx, session)
+	story := storyText(session)
+	report := &domain.ConsistencyReport{SessionID: sessionID, Conflicts: []domain.StoryConflict{}}
+
+	vector := embed(story)
+	var candidates []consistencyCandidate
+	for _, other := range s.ListSessions() {
+		if other.ID == sessionID || other.WorkspaceID != session.WorkspaceID || other.ProjectID != session.ProjectID || other.FinalizedAt == nil || other.IsTutorial() {
+			continue
+		}
+		if similarity := cosineSimilarity(vector, embed(storyText(other))); similarity >= consistencyMinSimilarity {
+			candidates = append(candidates, consistencyCandidate{session: other, similarity: similarity})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].similarity > candidates[j].similarity })
+	candidates = candidates[:min(len(candidates), consistencyCandidates)]
+	report.Compared = len(candidates)
+	if len(candidates) == 0 {
+		return report, nil
+	}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("New story:\n" + story + "\n")
+	for i, candidate := range candidates {
+		fmt.Fprintf(&b, "\nEarlier story %d:\n%s\n", i+1, storyText(candidate.session))
+	}
+	raw, err := client.Complete(ctx, model, consistencySystemPrompt, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check consistency: %w", err)
+	}
+	var output struct {
+		Conflicts []struct {
+			Story         int    `json:"story"`
+			Statement     string `json:"statement"`
+			ConflictsWith string `json:"conflicts_with"`
+			Explanation   string `json:"explanation"`
+		} `json:"conflicts"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse consistency check from AI: %w, raw response: %s", err, raw)
+	}
+	for _, conflict := range output.Conflicts {
+		if conflict.Story < 1 || conflict.Story > len(candidates) {
+			continue
+		}
+		candidate := candidates[conflict.Story-1]
+		report.Conflicts = append(report.Conflicts, domain.StoryConflict{
+			SessionID:     candidate.session.ID,
+			StoryTitle:    storyTitle(candidate.session),
+			Similarity:    candidate.similarity,
+			Statement:     strings.TrimSpace(conflict.Statement),
+			ConflictsWith: strings.TrimSpace(conflict.ConflictsWith),
+			Explanation:   strings.TrimSpace(conflict.Explanation),
+		})
+	}
+	return report, nil
+}
+
+// storyText returns the current story of a session with its final AC.
+func storyText(session *domain.RefinementSession) string {
+	text := currentStory(session)
+	for i, criterion := range session.FinalAC {
+		text += fmt.Sprintf("\n%d. %s", i+1, criterion)
+	}
+	return text
+}
+
+// cosineSimilarity is the cosine similarity of two embeddings, 0 when either is empty.
+func cosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for key, value := range a {
+		dot += value * b[key]
+		normA += value * value
+	}
+	for _, value := range b {
+		normB += value * value
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
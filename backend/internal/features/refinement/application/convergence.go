@@ -0,0 +1,69 @@
+package application
+
+import (
+	"strings"
+	"unicode"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const (
+	// duplicateSimilarity is the similarity above which a question counts as a repeat of an earlier one.
+	duplicateSimilarity = 0.6
+	// convergedRepeatRatio is the share of repeated questions above which a round is considered converged.
+	convergedRepeatRatio = 0.7
+)
+
+// updateConvergence scores a new questioning round against all earlier rounds, flags the session
+// as converged when most questions are repeats, and records the round's questions.
+func updateConvergence(session *domain.RefinementSession, newQuestions []domain.Question) {
+	var prompts []string
+	for _, q := range newQuestions {
+		prompts = append(prompts, q.Prompt...)
+	}
+
+	if len(session.AskedQuestions) > 0 && len(prompts) > 0 {
+		repeats := 0
+		for _, p := range prompts {
+			for _, asked := range session.AskedQuestions {
+				if questionSimilarity(p, asked) >= duplicateSimilarity {
+					repeats++
+					break
+				}
+			}
+		}
+		session.ConvergenceScore = float64(repeats) / float64(len(prompts))
+		session.Converged = session.ConvergenceScore >= convergedRepeatRatio
+	}
+	session.AskedQuestions = append(session.AskedQuestions, prompts...)
+}
+
+// questionSimilarity returns the Jaccard similarity of the character bigrams of two questions,
+// which works for both Chinese and English text.
+func questionSimilarity(a, b string) float64 {
+	ga, gb := bigrams(a), bigrams(b)
+	if len(ga) == 0 || len(gb) == 0 {
+		return 0
+	}
+	intersection := 0
+	for g := range ga {
+		if gb[g] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(ga)+len(gb)-intersection)
+}
+
+func bigrams(s string) map[string]bool {
+	var runes []rune
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	result := make(map[string]bool)
+	for i := 0; i+1 < len(runes); i++ {
+		result[string(runes[i:i+2])] = true
+	}
+	return result
+}
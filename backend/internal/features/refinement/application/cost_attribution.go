@@ -0,0 +1,80 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"sofa-commander/backend/internal/events"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	usagedomain "sofa-commander/backend/internal/features/usage/domain"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/tracing"
+)
+
+// costTags identifies who a provider request is attributed to.
+type costTags struct {
+	SessionID   string
+	WorkspaceID string
+	UserID      string
+}
+
+// tagsFor returns the cost allocation tags of a session.
+func tagsFor(session *domain.RefinementSession) costTags {
+	return costTags{SessionID: session.ID, WorkspaceID: session.WorkspaceID, UserID: session.UserID}
+}
+
+// metadata returns the tags as provider request metadata.
+func (t costTags) metadata(operation string) map[string]string {
+	return map[string]string{
+		"session_id":   t.SessionID,
+		"workspace_id": t.WorkspaceID,
+		"user_id":      t.UserID,
+		"operation":    operation,
+	}
+}
+
+// runAssistant runs the assistant within the operation's latency budget, with cost allocation metadata
+// and the response schema of its output, and records the run's attribution.
+func (s *refinementService) runAssistant(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, budget configdomain.LatencyBudget, schema *infrastructure.ResponseSchema) error {
+	startedAt := time.Now()
+	ctx, span := tracing.Start(ctx, "refinement.run", attribute.String("refinement.operation", operation), attribute.String("refinement.session.id", tags.SessionID))
+	result, err := s.runWithinBudget(ctx, client, threadID, assistantID, tags, operation, budget, schema)
+	tracing.End(span, err)
+	recordRun(tags.SessionID, operation, startedAt, result, err)
+	if err != nil {
+		if s.publisher != nil {
+			s.publisher.Publish(events.Event{
+				Type:        events.RunFailed,
+				SessionID:   tags.SessionID,
+				WorkspaceID: tags.WorkspaceID,
+				UserID:      tags.UserID,
+				Data:        map[string]any{"operation": operation, "error": err.Error()},
+			})
+		}
+		return err
+	}
+	warnIfContextNearLimit(ctx, result)
+	if s.usageService == nil || tags.WorkspaceID == domain.TutorialWorkspaceID {
+		return nil
+	}
+	err = s.usageService.RecordRun(usagedomain.Attribution{
+		RunID:            result.RunID,
+		ThreadID:         threadID,
+		SessionID:        tags.SessionID,
+		WorkspaceID:      tags.WorkspaceID,
+		UserID:           tags.UserID,
+		Operation:        operation,
+		Model:            result.Model,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.CompletionTokens,
+		TotalTokens:      result.TotalTokens,
+	})
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to record usage attribution")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+// staleLockAfter is how long an operation may hold a session's lock before it is reported as stale.
+// Runs are cancelled after their run timeout, so an operation holding the lock much longer is stuck.
+const staleLockAfter = 2 * configdomain.DefaultRunTimeout
+
+// contextWarningShare is the share of a model's context window a run's prompt may fill before the
+// operation warns that the session's context is nearing the limit.
+const contextWarningShare = 0.8
+
+// defaultContextWindow is the context window, in tokens, of models missing from contextWindows.
+const defaultContextWindow = 128000
+
+// contextWindows holds the context windows of model families by model name prefix, the more specific
+// prefixes first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude", 200000},
+	{"gemini", 1048576},
+}
+
+// contextWindow returns the context window of a model in tokens.
+func contextWindow(model string) int {
+	for _, window := range contextWindows {
+		if strings.HasPrefix(model, window.prefix) {
+			return window.tokens
+		}
+	}
+	return defaultContextWindow
+}
+
+// warnIfContextNearLimit warns the operation when a run's prompt nearly filled the model's context window,
+// after which the provider truncates the thread and the assistant loses the start of the conversation.
+func warnIfContextNearLimit(ctx context.Context, result *infrastructure.RunResult) {
+	if result == nil || result.PromptTokens == 0 {
+		return
+	}
+	window := contextWindow(result.Model)
+	if float64(result.PromptTokens) >= contextWarningShare*float64(window) {
+		addWarning(ctx, domain.WarningContextNearLimit, "對話內容已使用模型上下文的 %d%%（%d / %d tokens），建議精簡內容或開始新的 session", result.PromptTokens*100/window, result.PromptTokens, window)
+	}
+}
+
+// runStats holds the run and parse statistics of each session since the server started, by session ID.
+var runStats sync.Map
+
+// sessionRunStats are the run and parse statistics of a session.
+type sessionRunStats struct {
+	mu               sync.Mutex
+	runs             int
+	failedRuns       int
+	lastOperation    string
+	lastDuration     time.Duration
+	lastPromptTokens int
+	lastAt           time.Time
+	lastError        string
+	parseFailures    int
+}
+
+func statsFor(sessionID string) *sessionRunStats {
+	stats, _ := runStats.LoadOrStore(sessionID, &sessionRunStats{})
+	return stats.(*sessionRunStats)
+}
+
+// recordRun records the outcome of an assistant run started at startedAt.
+func recordRun(sessionID, operation string, startedAt time.Time, result *infrastructure.RunResult, err error) {
+	stats := statsFor(sessionID)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.runs++
+	stats.lastOperation = operation
+	stats.lastDuration = time.Since(startedAt)
+	stats.lastAt = startedAt
+	stats.lastPromptTokens = 0
+	stats.lastError = ""
+	if err != nil {
+		stats.failedRuns++
+		stats.lastError = err.Error()
+	} else if result != nil {
+		stats.lastPromptTokens = result.PromptTokens
+	}
+}
+
+// recordParseFailure records an assistant output of a session that could not be parsed.
+func recordParseFailure(sessionID string) {
+	stats := statsFor(sessionID)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.parseFailures++
+}
+
+// estimateTokens roughly estimates the tokens of a text: about four characters per token for ASCII
+// text and one token per character otherwise, e.g. Chinese.
+func estimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// Diagnostics reports the health of a session: the size of its thread, its recent runs and parse
+// failures, and whether an operation has been holding its lock for suspiciously long. A thread that
+// cannot be read is reported rather than failing the diagnostics.
+func (s *refinementService) Diagnostics(ctx context.Context, sessionID string) (*domain.SessionDiagnostics, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := &domain.SessionDiagnostics{SessionID: session.ID, ThreadID: session.ThreadID}
+
+	if session.ThreadID != "" {
+		if err := s.measureThread(ctx, session, diagnostics); err != nil {
+			diagnostics.ThreadError = err.Error()
+		}
+	}
+
+	if stats, ok := runStats.Load(sessionID); ok {
+		stats := stats.(*sessionRunStats)
+		stats.mu.Lock()
+		diagnostics.Runs = stats.runs
+		diagnostics.FailedRuns = stats.failedRuns
+		diagnostics.ParseFailures = stats.parseFailures
+		if stats.runs > 0 {
+			lastAt := stats.lastAt
+			diagnostics.LastRunOperation = stats.lastOperation
+			diagnostics.LastRunSeconds = stats.lastDuration.Seconds()
+			diagnostics.LastRunPromptTokens = stats.lastPromptTokens
+			diagnostics.LastRunAt = &lastAt
+			diagnostics.LastRunError = stats.lastError
+		}
+		stats.mu.Unlock()
+	}
+
+	if since, ok := lockHeldSince(sessionID); ok {
+		held := time.Since(since)
+		diagnostics.Locked = true
+		diagnostics.LockedSeconds = held.Seconds()
+		diagnostics.StaleLock = held > staleLockAfter
+	}
+	if drift, ok := promptDrifts.Load(sessionID); ok {
+		diagnostics.PromptDrift = drift.(*domain.PromptDrift)
+	}
+	return diagnostics, nil
+}
+
+// measureThread counts the messages of a session's thread and estimates the tokens they add to each
+// run's context.
+func (s *refinementService) measureThread(ctx context.Context, session *domain.RefinementSession, diagnostics *domain.SessionDiagnostics) error {
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return err
+	}
+	messages, err := client.ListThreadMessages(ctx, session.ThreadID)
+	if err != nil {
+		return err
+	}
+	diagnostics.ThreadMessages = len(messages)
+	for _, msg := range messages {
+		for _, content := range msg.Content {
+			if content.Text != nil {
+				diagnostics.EstimatedContextTokens += estimateTokens(content.Text.Value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// ensembleSystemPrompt instructs the ensemble model, which sees the session as a transcript instead of a thread.
+const ensembleSystemPrompt = `You are a multi-role requirement refinement assistant helping a Product Manager refine a user story.
+You are given the product context, the user story and the conversation so far, followed by the instruction for this step.
+Follow the instruction exactly and return only the JSON array it asks for.`
+
+// ensembleRun is a suggesting request to a second model running alongside the assistant run.
+type ensembleRun struct {
+	primaryModel string
+	model        string
+	done         chan struct{}
+	suggestions  []domain.Suggestion
+	err          error
+}
+
+// startEnsemble asks the session's ensemble model for suggestions in parallel with the assistant run.
+// It returns nil when the session has no ensemble model or it is the assistant's own model.
+func (s *refinementService) startEnsemble(ctx context.Context, client infrastructure.OpenAIClient, primaryModel string, session *domain.RefinementSession, instruction string) *ensembleRun {
+	model := session.Request.EnsembleModel
+	if model == "" || model == primaryModel {
+		return nil
+	}
+	run := &ensembleRun{primaryModel: primaryModel, model: model, done: make(chan struct{})}
+	prompt := ensemblePrompt(session, instruction)
+	go func() {
+		defer close(run.done)
+		raw, err := client.Complete(ctx, model, ensembleSystemPrompt, prompt)
+		if err != nil {
+			run.err = err
+			return
+		}
+		if err := json.Unmarshal([]byte(stripCodeFence(raw)), &run.suggestions); err != nil {
+			run.err = fmt.Errorf("failed to parse suggestions: %w, raw response: %s", err, raw)
+		}
+	}()
+	return run
+}
+
+// ensemblePrompt renders the session state the assistant sees through its thread as a transcript.
+func ensemblePrompt(session *domain.RefinementSession, instruction string) string {
+	var b strings.Builder
+	if session.ConfigSnapshot != nil && session.ConfigSnapshot.ProductContext != "" {
+		b.WriteString("Product Context:\n" + session.ConfigSnapshot.ProductContext + "\n\n")
+	}
+	b.WriteString("User Story:\n" + session.UserStory + "\n\n")
+	b.WriteString("Conversation so far:\n")
+	for _, entry := range session.History {
+		b.WriteString(entry + "\n")
+	}
+	for _, q := range session.Questions {
+		for _, p := range q.Prompt {
+			fmt.Fprintf(&b, "- %s asked: %s\n", q.Role, p)
+		}
+		if q.Answer != "" {
+			fmt.Fprintf(&b, "  PM answered: %s\n", q.Answer)
+		}
+	}
+	b.WriteString("\nInstruction:\n" + instruction)
+	return b.String()
+}
+
+// merge waits for the ensemble run and merges its suggestions into the assistant's. Similar prompts of
+// a role are kept once, and Sources records which models proposed each prompt. The assistant's
+// suggestions are returned unchanged if the ensemble model failed.
+func (run *ensembleRun) merge(ctx context.Context, suggestions []domain.Suggestion) []domain.Suggestion {
+	if run == nil {
+		return suggestions
+	}
+	<-run.done
+	if run.err != nil {
+		logging.From(ctx).Warn().Err(run.err).Str("model", run.model).Msg("Ensemble model failed, using the assistant's suggestions only")
+		addWarning(ctx, domain.WarningStepFailed, "第二模型 %s 產生建議失敗，僅提供主要模型的建議", run.model)
+		return suggestions
+	}
+
+	var merged []domain.Suggestion
+	byRole := map[string]int{}
+	add := func(suggestion domain.Suggestion, model string) {
+		i, ok := byRole[suggestion.Role]
+		if !ok {
+			i = len(merged)
+			byRole[suggestion.Role] = i
+			merged = append(merged, domain.Suggestion{Role: suggestion.Role})
+		}
+		target := &merged[i]
+	prompts:
+		for _, p := range suggestion.Prompt {
+			for j, existing := range target.Prompt {
+				if questionSimilarity(p, existing) >= duplicateSimilarity {
+					if !slices.Contains(target.Sources[j], model) {
+						target.Sources[j] = append(target.Sources[j], model)
+					}
+					continue prompts
+				}
+			}
+			target.Prompt = append(target.Prompt, p)
+			target.Sources = append(target.Sources, []string{model})
+		}
+	}
+	for _, suggestion := range suggestions {
+		add(suggestion, run.primaryModel)
+	}
+	for _, suggestion := range run.suggestions {
+		add(suggestion, run.model)
+	}
+	return merged
+}
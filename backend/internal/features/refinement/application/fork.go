@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// maxReplayMessageBytes caps the size of a message replaying the original thread on a fork's thread;
+// longer transcripts are replayed over several messages.
+const maxReplayMessageBytes = 32 * 1024
+
+// Fork copies a session into a new session on a new thread, so the PM can take the refinement in another
+// direction and keep the original. The original thread is replayed onto the new one as a transcript, so
+// the assistant continues from the same point; no run is needed until the fork's next round. Links to
+// trackers and reviews of the original's final output are not copied.
+func (s *refinementService) Fork(ctx context.Context, sessionID, userID string) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	original, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = sessionContext(ctx, original)
+
+	client, _, err := s.clientFor(original.WorkspaceID, original.UserID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := client.ListThreadMessages(ctx, original.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+
+	forkID := nextSessionID()
+	tags := costTags{SessionID: forkID, WorkspaceID: original.WorkspaceID, UserID: original.UserID}
+	if userID != "" {
+		tags.UserID = userID
+	}
+	threadID, err := client.CreateThread(ctx, tags.metadata("fork"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create thread: %w", err)
+	}
+	ctx = logging.With(ctx, "fork_id", forkID, "fork_thread_id", threadID)
+
+	var entries []string
+	for _, msg := range messages {
+		speaker := "PM"
+		if msg.Role == "assistant" {
+			speaker = "助理"
+		}
+		entries = append(entries, "["+speaker+"]\n"+messageText(msg))
+	}
+	header := fmt.Sprintf("[分支紀錄] 本對話分支自 session %s，以下是分支前的完整對話紀錄，請視為本對話已進行的內容，之後依相同的角色、格式與指示接續打磨：", original.ID)
+	for _, replay := range replayMessages(header, entries) {
+		if err := client.AddMessageToThread(ctx, threadID, replay); err != nil {
+			return nil, fmt.Errorf("failed to replay history to thread: %w", err)
+		}
+	}
+
+	fork := original.Clone()
+	fork.ID = forkID
+	fork.ThreadID = threadID
+	fork.UserID = tags.UserID
+	fork.Request.UserID = tags.UserID
+	fork.CreatedAt = time.Now()
+	fork.ForkedFrom = original.ID
+	fork.History = append(fork.History, fmt.Sprintf("[分支] 自 session %s 的第 %d 輪分支", original.ID, original.CurrentRound))
+	fork.JiraIssueKey, fork.JiraSyncedAt, fork.JiraCommentsPulledAt = "", nil, nil
+	fork.GitLabProjectID, fork.GitLabIssueIID = "", 0
+	fork.ApprovalStatus, fork.Approvals, fork.ReviewComments = "", nil, nil
+	fork.QualityScore = nil // Scored the original's output, which the fork goes on to change
+	fork.Timings = nil
+	fork.Warnings = nil
+	fork.Extra = nil
+	if instructions, ok := sentInstructions.Load(original.ID); ok {
+		sentInstructions.Store(forkID, instructions)
+	}
+	if err := storeSession(fork); err != nil {
+		return nil, err
+	}
+
+	s.publish(events.SessionForked, fork, map[string]any{"forked_from": original.ID, "round": original.CurrentRound})
+	return fork, nil
+}
+
+// replayMessages packs the transcript entries into messages of at most maxReplayMessageBytes each, the
+// first starting with the header. An entry longer than the limit gets a message of its own.
+func replayMessages(header string, entries []string) []string {
+	var messages []string
+	var b strings.Builder
+	b.WriteString(header)
+	for _, entry := range entries {
+		if b.Len()+len(entry)+2 > maxReplayMessageBytes {
+			messages = append(messages, b.String())
+			b.Reset()
+			b.WriteString("[分支紀錄（續）]")
+		}
+		b.WriteString("\n\n" + entry)
+	}
+	return append(messages, b.String())
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const gherkinSystemPrompt = `You write acceptance criteria as Gherkin scenarios for BDD tools.
+Given a user story and its numbered acceptance criteria, write one scenario per criterion with Given/When/Then steps, splitting criteria that describe several behaviors into several scenarios. Steps are short, concrete and testable, without the keywords themselves.
+Keep the language of the story. The feature is a short title of the story and the description its "As a ... I want ... so that ..." statement.
+Return only JSON: {"feature": "...", "description": "...", "scenarios": [{"name": "...", "criterion": 1, "given": ["..."], "when": ["..."], "then": ["..."]}]}`
+
+// ValidateFormat returns an error for finalize output formats other than the list and Gherkin.
+func ValidateFormat(format string) error {
+	if format != "" && format != domain.FormatList && format != domain.FormatGherkin {
+		return fmt.Errorf("unknown format %q, must be %q or %q", format, domain.FormatList, domain.FormatGherkin)
+	}
+	return nil
+}
+
+// WriteGherkin writes the finalized AC of a session as Gherkin scenarios and stores them, replacing
+// earlier ones. Scenarios without a Then step are dropped with a warning, as they test nothing.
+func (s *refinementService) WriteGherkin(ctx context.Context, sessionID string) (*domain.GherkinFeature, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("User story:\n" + session.FinalUserStory + "\n\nAcceptance criteria:\n")
+	for i, criterion := range session.FinalAC {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, criterion)
+	}
+	raw, err := client.Complete(ctx, model, gherkinSystemPrompt, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write gherkin scenarios: %w", err)
+	}
+	var feature domain.GherkinFeature
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &feature); err != nil {
+		return nil, fmt.Errorf("failed to parse gherkin scenarios from AI: %w, raw response: %s", err, raw)
+	}
+
+	scenarios := []domain.GherkinScenario{}
+	for _, scenario := range feature.Scenarios {
+		if len(scenario.Then) == 0 {
+			addWarning(ctx, domain.WarningScenarioDropped, "情境「%s」沒有 Then 步驟，已略過", scenario.Name)
+			continue
+		}
+		if scenario.Criterion < 0 || scenario.Criterion > len(session.FinalAC) {
+			scenario.Criterion = 0
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	feature.Scenarios = scenarios
+	if strings.TrimSpace(feature.Feature) == "" {
+		feature.Feature = storyTitle(session)
+	}
+	feature.CreatedAt = time.Now()
+
+	if _, err := mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.Gherkin = &feature
+		session.Warnings = append(session.Warnings, warnings.list()...)
+	}); err != nil {
+		return nil, err
+	}
+	return &feature, nil
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sofa-commander/backend/internal/events"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// budgetFor returns the latency budget of an operation of a session.
+func budgetFor(req *domain.RefinementRequest, operation string) configdomain.LatencyBudget {
+	return configdomain.LatencyBudgetFor(req.LatencyBudgets, operation)
+}
+
+// runWithinBudget runs the assistant. When the run exceeds the budget and a fallback model is set, the
+// run is cancelled and retried on the fallback model; a run that finishes while being cancelled is
+// still used. Without a fallback model the run is awaited, and the HTTP layer stops waiting for it.
+// Every run is cancelled once it exceeds the budget's run timeout or the context ends.
+func (s *refinementService) runWithinBudget(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, budget configdomain.LatencyBudget, schema *infrastructure.ResponseSchema) (*infrastructure.RunResult, error) {
+	metadata := tags.metadata(operation)
+	if budget.Seconds <= 0 || budget.FallbackModel == "" {
+		return s.runWithTimeout(ctx, client, threadID, assistantID, "", metadata, budget, schema)
+	}
+
+	type runOutcome struct {
+		result *infrastructure.RunResult
+		err    error
+	}
+	done := make(chan runOutcome, 1)
+	go func() {
+		result, err := s.runWithTimeout(ctx, client, threadID, assistantID, "", metadata, budget, schema)
+		done <- runOutcome{result, err}
+	}()
+
+	timer := time.NewTimer(time.Duration(budget.Seconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case outcome := <-done:
+		return outcome.result, outcome.err
+	case <-timer.C:
+	}
+
+	logging.From(ctx).Warn().Str("operation", operation).Int("budget_seconds", budget.Seconds).Str("fallback_model", budget.FallbackModel).Msg("Run exceeded its latency budget, switching to the fallback model")
+	if s.publisher != nil && tags.WorkspaceID != domain.TutorialWorkspaceID {
+		s.publisher.Publish(events.Event{
+			Type:        events.RunOverBudget,
+			SessionID:   tags.SessionID,
+			WorkspaceID: tags.WorkspaceID,
+			UserID:      tags.UserID,
+			Data:        map[string]any{"operation": operation, "budget_seconds": budget.Seconds, "fallback_model": budget.FallbackModel},
+		})
+	}
+	if err := client.CancelActiveRuns(ctx, threadID); err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to cancel the run over budget, waiting for it instead")
+		outcome := <-done
+		return outcome.result, outcome.err
+	}
+	if outcome := <-done; outcome.err == nil {
+		return outcome.result, nil // Finished before it was cancelled
+	}
+	return s.runWithTimeout(ctx, client, threadID, assistantID, budget.FallbackModel, metadata, budget, schema)
+}
+
+// runWithTimeout runs the assistant on a model, cancelling the run when it exceeds the budget's run timeout.
+func (s *refinementService) runWithTimeout(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID, model string, metadata map[string]string, budget configdomain.LatencyBudget, schema *infrastructure.ResponseSchema) (*infrastructure.RunResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, budget.RunTimeout())
+	defer cancel()
+	result, err := client.RunAssistantWithModel(ctx, threadID, assistantID, model, metadata, s.tools, schema)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("run timed out after %s: %w", budget.RunTimeout(), err)
+	}
+	return result, err
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// localizationSystemPrompt instructs the localization analysis, which runs alongside the suggesting phase.
+const localizationSystemPrompt = `You are a localization and internationalization specialist reviewing a user story during refinement.
+You are given the product context, the user story and the conversation so far. Flag the localization impacts of the story that the team must decide on: date, time and time zone formats, number and currency formats, right-to-left layouts, text expansion and truncation, pluralization and grammar, sorting and search of non-Latin text, character encoding, and culturally specific content.
+Flag only impacts this story actually has, each as one concrete suggestion, and return an empty list when it has none.
+Write the suggestions in %s.
+Return only JSON: [{"category": "date_time|number_currency|rtl|text_expansion|pluralization|sorting_search|encoding|content", "suggestion": "..."}]`
+
+// localizationRun is a localization analysis running alongside the suggesting phase.
+type localizationRun struct {
+	done       chan struct{}
+	suggestion *domain.Suggestion
+	err        error
+}
+
+// startLocalizationAnalysis flags the localization impacts of the story in parallel with the assistant
+// run. It returns nil when the session does not request the analysis.
+func (s *refinementService) startLocalizationAnalysis(ctx context.Context, client infrastructure.OpenAIClient, model string, session *domain.RefinementSession) *localizationRun {
+	if !session.Request.LocalizationAnalysis {
+		return nil
+	}
+	language := session.Request.Language
+	if language == "" {
+		language = "the language of the user story"
+	}
+	run := &localizationRun{done: make(chan struct{})}
+	prompt := ensemblePrompt(session, "Flag the localization impacts of the user story.")
+	go func() {
+		defer close(run.done)
+		raw, err := client.Complete(ctx, model, fmt.Sprintf(localizationSystemPrompt, language), prompt)
+		if err != nil {
+			run.err = err
+			return
+		}
+		var impacts []struct {
+			Category   string `json:"category"`
+			Suggestion string `json:"suggestion"`
+		}
+		if err := json.Unmarshal([]byte(stripCodeFence(raw)), &impacts); err != nil {
+			run.err = fmt.Errorf("failed to parse localization impacts: %w, raw response: %s", err, raw)
+			return
+		}
+		if len(impacts) == 0 {
+			return
+		}
+		run.suggestion = &domain.Suggestion{Role: domain.LocalizationRole}
+		for _, impact := range impacts {
+			if impact.Suggestion == "" {
+				continue
+			}
+			run.suggestion.Prompt = append(run.suggestion.Prompt, impact.Suggestion)
+			run.suggestion.Categories = append(run.suggestion.Categories, impact.Category)
+		}
+	}()
+	return run
+}
+
+// appendTo waits for the analysis and adds its impacts to the suggestions as the localization group.
+// The suggestions are returned unchanged if the analysis failed or found no impacts.
+func (run *localizationRun) appendTo(ctx context.Context, suggestions []domain.Suggestion) []domain.Suggestion {
+	if run == nil {
+		return suggestions
+	}
+	<-run.done
+	if run.err != nil {
+		logging.From(ctx).Warn().Err(run.err).Msg("Localization analysis failed, skipping its suggestions")
+		addWarning(ctx, domain.WarningStepFailed, "在地化影響分析失敗，本次未提供在地化建議")
+		return suggestions
+	}
+	if run.suggestion == nil || len(run.suggestion.Prompt) == 0 {
+		return suggestions
+	}
+	return append(suggestions, *run.suggestion)
+}
@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// optionalPhase produces extra output from a finalized story. The prompt is the phase's entry in the
+// session's phase prompts, or defaultPrompt; format is appended to it so a configured prompt cannot
+// change the output the phase parses. context, when set, adds session-specific input to the prompt.
+type optionalPhase struct {
+	defaultPrompt string
+	format        string
+	context       func(session *domain.RefinementSession) (string, error)
+	parse         func(raw string) (apply func(session *domain.RefinementSession), err error)
+}
+
+var optionalPhases = map[string]optionalPhase{
+	domain.OptionalPhaseSecurity: {
+		defaultPrompt: `You are an application security engineer reviewing a user story before development starts.
+Build a lightweight STRIDE threat model of the feature the story describes: for each STRIDE category that applies, list the concrete threats and how to mitigate them. Skip categories that do not apply instead of inventing threats.
+Then write the security acceptance criteria the story must meet, specific and testable.
+Write threats, mitigations and acceptance criteria in the language of the story.`,
+		format: `Return only JSON: {"threats": [{"category": "Spoofing|Tampering|Repudiation|Information Disclosure|Denial of Service|Elevation of Privilege", "threat": "...", "mitigation": "..."}], "security_ac": ["..."]}`,
+		parse: func(raw string) (func(session *domain.RefinementSession), error) {
+			var review domain.SecurityReview
+			if err := json.Unmarshal([]byte(raw), &review); err != nil {
+				return nil, err
+			}
+			review.ReviewedAt = time.Now()
+			return func(session *domain.RefinementSession) { session.SecurityReview = &review }, nil
+		},
+	},
+	domain.OptionalPhaseCompliance: {
+		defaultPrompt: `You are a compliance analyst reviewing a user story before development starts.
+Evaluate the feature the story describes against each regulation below. For every way the feature touches a regulation (e.g. collecting, storing or sharing regulated data), describe the concern and flag it for legal review when the requirement is ambiguous or the risk is high. Ignore regulations the story does not touch.
+Then write the compliance acceptance criteria the story must meet, specific and testable.
+Write concerns and acceptance criteria in the language of the story.`,
+		format: `Return only JSON: {"findings": [{"regulation": "...", "concern": "...", "legal_review": true}], "compliance_ac": ["..."]}`,
+		context: func(session *domain.RefinementSession) (string, error) {
+			if session.ConfigSnapshot == nil || len(session.ConfigSnapshot.Regulations) == 0 {
+				return "", fmt.Errorf("no regulations are configured")
+			}
+			text := "Regulations:\n"
+			for _, regulation := range session.ConfigSnapshot.Regulations {
+				text += "- " + regulation.Name
+				if regulation.Concerns != "" {
+					text += ": " + regulation.Concerns
+				}
+				text += "\n"
+			}
+			return text, nil
+		},
+		parse: func(raw string) (func(session *domain.RefinementSession), error) {
+			var review domain.ComplianceReview
+			if err := json.Unmarshal([]byte(raw), &review); err != nil {
+				return nil, err
+			}
+			for _, finding := range review.Findings {
+				review.LegalReviewRequired = review.LegalReviewRequired || finding.LegalReview
+			}
+			review.ReviewedAt = time.Now()
+			return func(session *domain.RefinementSession) { session.ComplianceReview = &review }, nil
+		},
+	},
+	domain.OptionalPhaseAccessibility: {
+		defaultPrompt: `You are an accessibility specialist reviewing a user story before development starts.
+Decide whether the story affects the user interface. If it does, derive acceptance criteria from the relevant WCAG 2.2 AA success criteria: keyboard operation and focus order, color contrast, screen reader names, roles and announcements, motion, and text resizing. Base them on the story and the mockups listed below, and name the UI elements they apply to. If the story does not affect the UI, return no criteria.
+Write the criteria in the language of the story.`,
+		format: `Return only JSON: {"ui_affecting": true, "criteria": [{"area": "keyboard|contrast|screen_reader|focus|motion|text", "wcag": "2.1.1 Keyboard", "criterion": "..."}]}`,
+		context: func(session *domain.RefinementSession) (string, error) {
+			if len(session.Request.Mockups) == 0 {
+				return "Mockups: none attached", nil
+			}
+			text := "Mockups:\n"
+			for _, mockup := range session.Request.Mockups {
+				text += "- " + mockup.URL
+				if mockup.Description != "" {
+					text += ": " + mockup.Description
+				}
+				text += "\n"
+			}
+			return text, nil
+		},
+		parse: func(raw string) (func(session *domain.RefinementSession), error) {
+			var review domain.AccessibilityReview
+			if err := json.Unmarshal([]byte(raw), &review); err != nil {
+				return nil, err
+			}
+			if !review.UIAffecting {
+				review.Criteria = nil
+			}
+			review.ReviewedAt = time.Now()
+			return func(session *domain.RefinementSession) { session.AccessibilityReview = &review }, nil
+		},
+	},
+}
+
+// ValidateOptionalPhases returns an error for unknown optional phases.
+func ValidateOptionalPhases(phases []string) error {
+	for _, phase := range phases {
+		if _, ok := optionalPhases[phase]; !ok {
+			return fmt.Errorf("unknown optional phase %q", phase)
+		}
+	}
+	return nil
+}
+
+// RunOptionalPhase runs an optional phase on a finalized session and stores its output, replacing
+// the output of an earlier run.
+func (s *refinementService) RunOptionalPhase(ctx context.Context, sessionID, phase string) (*domain.RefinementSession, error) {
+	if err := ValidateOptionalPhases([]string{phase}); err != nil {
+		return nil, err
+	}
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	apply, err := runOptionalPhase(ctx, client, model, session, phase, session.FinalUserStory, session.FinalAC)
+	if err != nil {
+		return nil, err
+	}
+	return mutateSession(sessionID, apply)
+}
+
+// runRequestedPhases runs the optional phases a session requested on its finalized output. Failed
+// phases are logged and skipped so they never fail the finalize.
+func runRequestedPhases(ctx context.Context, client infrastructure.OpenAIClient, model string, session *domain.RefinementSession, userStory string, ac []string) []func(session *domain.RefinementSession) {
+	var applies []func(session *domain.RefinementSession)
+	for _, phase := range session.Request.OptionalPhases {
+		apply, err := runOptionalPhase(ctx, client, model, session, phase, userStory, ac)
+		if err != nil {
+			logging.From(ctx).Warn().Err(err).Str("phase", string(phase)).Msg("Optional phase failed")
+			addWarning(ctx, domain.WarningStepFailed, "選用階段「%s」執行失敗，已略過", phase)
+			continue
+		}
+		applies = append(applies, apply)
+	}
+	return applies
+}
+
+// runOptionalPhase runs one optional phase and returns the update storing its output.
+func runOptionalPhase(ctx context.Context, client infrastructure.OpenAIClient, model string, session *domain.RefinementSession, phase, userStory string, ac []string) (func(session *domain.RefinementSession), error) {
+	definition, ok := optionalPhases[phase]
+	if !ok {
+		return nil, fmt.Errorf("unknown optional phase %q", phase)
+	}
+	prompt := session.PhasePrompts[phase]
+	if prompt == "" {
+		prompt = definition.defaultPrompt
+	}
+	if definition.context != nil {
+		context, err := definition.context(session)
+		if err != nil {
+			return nil, fmt.Errorf("cannot run %s phase: %w", phase, err)
+		}
+		prompt += "\n\n" + context
+	}
+	source, err := json.Marshal(map[string]any{"user_story": userStory, "ac": ac})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal finalized output: %w", err)
+	}
+	raw, err := client.Complete(ctx, model, prompt+"\n"+definition.format, string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s phase: %w", phase, err)
+	}
+	raw = stripCodeFence(raw)
+	apply, err := definition.parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s phase output from AI: %w, raw response: %s", phase, err, raw)
+	}
+	return apply, nil
+}
@@ -0,0 +1,272 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// checkOutput validates a round's raw JSON questions (or suggestions) and corrects them (see
+// correctOutput), then applies the output filter's blocklist (see filterOutput). Selected roles still
+// missing from the output are reported as warnings.
+func (s *refinementService) checkOutput(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, req *domain.RefinementRequest, suggestions bool, raw string) string {
+	checked := s.correctOutput(ctx, client, threadID, assistantID, tags, operation, req, suggestions, raw)
+	checked = s.filterOutput(ctx, client, threadID, assistantID, tags, operation, req, suggestions, checked)
+	if logging.Prompts() {
+		logging.From(ctx).Debug().Str("operation", operation).Str("output", checked).Msg("AI output")
+	}
+	kind := "問題"
+	if suggestions {
+		kind = "建議"
+	}
+	var items []roleItems
+	if err := json.Unmarshal([]byte(checked), &items); err == nil {
+		counts := make(map[string]int)
+		for _, item := range items {
+			for _, p := range item.Prompt {
+				if strings.TrimSpace(p) != "" {
+					counts[item.Role]++
+				}
+			}
+		}
+		for _, role := range req.SelectedRoles {
+			if counts[role] == 0 {
+				addWarning(ctx, domain.WarningRoleMissing, "角色「%s」這一輪沒有提出任何%s", role, kind)
+			}
+		}
+	}
+	return checked
+}
+
+// correctOutput validates a round's raw JSON questions (or suggestions) against the response schema,
+// the selected roles, the per-role limits and the requested language. Output that cannot be parsed is
+// repaired first (see parseWithRepair). When a role contributed too little or too much or the language
+// is wrong, the assistant is asked once to correct its output; the corrected output is used if it
+// parses. Items over a role's maximum that survive the correction are dropped.
+func (s *refinementService) correctOutput(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, req *domain.RefinementRequest, suggestions bool, raw string) string {
+	parsed, err := s.parseWithRepair(ctx, client, threadID, assistantID, tags, operation, budgetFor(req, operation), roleItemsSchema, raw)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Output could not be repaired")
+		addWarning(ctx, domain.WarningOutputUnparsed, "AI 回覆的格式無法解析，內容可能不完整")
+		return stripCodeFence(raw)
+	}
+	raw = unwrapItems(parsed)
+	problems := outputProblems(raw, req, suggestions)
+	if len(problems) == 0 {
+		return raw
+	}
+	logging.From(ctx).Warn().Str("operation", operation).Strs("problems", problems).Msg("Output needs correction")
+
+	correction := "你上一次的回覆有以下問題：\n- " + strings.Join(problems, "\n- ") + "\n請修正後重新輸出完整的 JSON 陣列（包含所有角色），不要加上任何說明。"
+	if err := client.AddMessageToThread(ctx, threadID, correction); err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to request output correction")
+		return capItems(raw, req.RoleLimits, suggestions)
+	}
+	if err := s.runAssistant(ctx, client, threadID, assistantID, tags, operation+"_correction", budgetFor(req, operation), roleItemsSchema); err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to run output correction")
+		return capItems(raw, req.RoleLimits, suggestions)
+	}
+	messages, err := client.GetAssistantResponse(ctx, threadID)
+	if err != nil || len(messages) == 0 || len(messages[len(messages)-1].Content) == 0 {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to get corrected output")
+		return capItems(raw, req.RoleLimits, suggestions)
+	}
+	corrected, err := parseOutput(messages[len(messages)-1].Content[0].Text.Value, roleItemsSchema)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Discarding unparsable corrected output")
+		return capItems(raw, req.RoleLimits, suggestions)
+	}
+	corrected = unwrapItems(corrected)
+	if remaining := outputProblems(corrected, req, suggestions); len(remaining) > 0 {
+		logging.From(ctx).Warn().Str("operation", operation).Strs("problems", remaining).Msg("Output still has problems after correction")
+	}
+	return capItems(corrected, req.RoleLimits, suggestions)
+}
+
+// roleItems is the shape shared by the questions and suggestions the assistant returns.
+type roleItems struct {
+	Role   string   `json:"role"`
+	Prompt []string `json:"prompt"`
+}
+
+// outputProblems lists whether the output is not a JSON array of role items, the roles that
+// contributed no items or violate their limits, and whether the items are in the wrong language.
+func outputProblems(raw string, req *domain.RefinementRequest, suggestions bool) []string {
+	var items []roleItems
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return []string{`回覆不是有效的 JSON 陣列，格式應為 [{"role": "角色", "prompt": ["..."]}]`}
+	}
+	counts := make(map[string]int)
+	var text strings.Builder
+	for _, item := range items {
+		if strings.TrimSpace(item.Role) == "" {
+			return []string{`每個項目都必須有 "role" 欄位`}
+		}
+		for _, p := range item.Prompt {
+			if strings.TrimSpace(p) != "" {
+				counts[item.Role]++
+				text.WriteString(p)
+			}
+		}
+	}
+
+	var problems []string
+	var missing []string
+	for _, role := range req.SelectedRoles {
+		minItems, maxItems := roleBounds(req.RoleLimits, role, suggestions)
+		switch {
+		case counts[role] == 0:
+			missing = append(missing, role)
+		case counts[role] < minItems:
+			problems = append(problems, fmt.Sprintf("角色「%s」只有 %d 項，至少需要 %d 項", role, counts[role], minItems))
+		case maxItems > 0 && counts[role] > maxItems:
+			problems = append(problems, fmt.Sprintf("角色「%s」有 %d 項，最多只能 %d 項", role, counts[role], maxItems))
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("缺少以下角色的內容（每個角色至少要有一項）：%s", strings.Join(missing, "、")))
+	}
+	if req.Language != "" && text.Len() > 0 && !matchesLanguage(text.String(), req.Language) {
+		problems = append(problems, fmt.Sprintf("內容未使用指定的語言 %s", req.Language))
+	}
+	return problems
+}
+
+// roleBounds returns the minimum (at least 1) and maximum (0 for unlimited) items of a role.
+func roleBounds(limits map[string]configdomain.RoleLimit, role string, suggestions bool) (int, int) {
+	limit := limits[role]
+	minItems, maxItems := limit.MinQuestions, limit.MaxQuestions
+	if suggestions {
+		minItems, maxItems = limit.MinSuggestions, limit.MaxSuggestions
+	}
+	if minItems < 1 {
+		minItems = 1
+	}
+	return minItems, maxItems
+}
+
+// capItems drops the items of each role beyond its maximum.
+func capItems(raw string, limits map[string]configdomain.RoleLimit, suggestions bool) string {
+	if len(limits) == 0 {
+		return raw
+	}
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return raw
+	}
+	capped := false
+	seen := make(map[string]int)
+	for _, item := range items {
+		role, _ := item["role"].(string)
+		prompts, _ := item["prompt"].([]any)
+		_, maxItems := roleBounds(limits, role, suggestions)
+		if maxItems == 0 {
+			continue
+		}
+		keep := maxItems - seen[role]
+		if keep < 0 {
+			keep = 0
+		}
+		if len(prompts) > keep {
+			prompts = prompts[:keep]
+			item["prompt"] = prompts
+			capped = true
+		}
+		seen[role] += len(prompts)
+	}
+	if !capped {
+		return raw
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return raw
+	}
+	return string(data)
+}
+
+// roleLimitsNote tells the assistant the per-role question and suggestion limits of the selected roles.
+func roleLimitsNote(roles []string, limits map[string]configdomain.RoleLimit) string {
+	var lines []string
+	for _, role := range roles {
+		limit, ok := limits[role]
+		if !ok {
+			continue
+		}
+		var parts []string
+		if limit.MinQuestions > 0 || limit.MaxQuestions > 0 {
+			parts = append(parts, "提問"+rangeText(limit.MinQuestions, limit.MaxQuestions))
+		}
+		if limit.MinSuggestions > 0 || limit.MaxSuggestions > 0 {
+			parts = append(parts, "建議"+rangeText(limit.MinSuggestions, limit.MaxSuggestions))
+		}
+		if len(parts) > 0 {
+			lines = append(lines, fmt.Sprintf("- %s：%s", role, strings.Join(parts, "，")))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "每個角色每一輪的項目數量限制：\n" + strings.Join(lines, "\n")
+}
+
+func rangeText(minItems, maxItems int) string {
+	switch {
+	case minItems > 0 && maxItems > 0:
+		return fmt.Sprintf("%d 至 %d 項", minItems, maxItems)
+	case maxItems > 0:
+		return fmt.Sprintf("最多 %d 項", maxItems)
+	default:
+		return fmt.Sprintf("至少 %d 項", minItems)
+	}
+}
+
+// matchesLanguage is a script-based heuristic for whether text is written in the given language
+// (a BCP 47 tag such as "zh-TW" or "en"). Unknown languages always match.
+func matchesLanguage(text, language string) bool {
+	var letters, han, kana, hangul int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case !unicode.IsLetter(r):
+			continue
+		}
+		letters++
+	}
+	if letters == 0 {
+		return true
+	}
+	share := func(n int) float64 { return float64(n) / float64(letters) }
+	switch strings.ToLower(strings.SplitN(language, "-", 2)[0]) {
+	case "zh":
+		return share(han) >= 0.3
+	case "ja":
+		return share(han+kana) >= 0.3 && kana > 0
+	case "ko":
+		return share(hangul) >= 0.3
+	case "en", "de", "fr", "es", "it", "pt", "nl":
+		return share(han+kana+hangul) < 0.2
+	default:
+		return true
+	}
+}
+
+// stripCodeFence removes markdown fences and commentary around the JSON of the assistant's output (see
+// extractJSON). Output without valid JSON is returned trimmed.
+func stripCodeFence(raw string) string {
+	if extracted, err := extractJSON(raw); err == nil {
+		return extracted
+	}
+	return strings.TrimSpace(raw)
+}
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"testing"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+func TestCapItems(t *testing.T) {
+	limits := map[string]configdomain.RoleLimit{
+		"PM": {MaxQuestions: 2, MaxSuggestions: 1},
+		"QA": {MinQuestions: 1},
+	}
+	tests := []struct {
+		name        string
+		raw         string
+		limits      map[string]configdomain.RoleLimit
+		suggestions bool
+		want        string
+	}{
+		{
+			name:   "within limits",
+			raw:    `[{"role":"PM","prompt":["a","b"]}]`,
+			limits: limits,
+			want:   `[{"role":"PM","prompt":["a","b"]}]`,
+		},
+		{
+			name:   "questions beyond the maximum",
+			raw:    `[{"role":"PM","prompt":["a","b","c"]}]`,
+			limits: limits,
+			want:   `[{"prompt":["a","b"],"role":"PM"}]`,
+		},
+		{
+			name:        "suggestions beyond the maximum",
+			raw:         `[{"role":"PM","prompt":["a","b"]}]`,
+			limits:      limits,
+			suggestions: true,
+			want:        `[{"prompt":["a"],"role":"PM"}]`,
+		},
+		{
+			name:   "maximum shared by the items of a role",
+			raw:    `[{"role":"PM","prompt":["a"]},{"role":"PM","prompt":["b","c"]},{"role":"PM","prompt":["d"]}]`,
+			limits: limits,
+			want:   `[{"prompt":["a"],"role":"PM"},{"prompt":["b"],"role":"PM"},{"prompt":[],"role":"PM"}]`,
+		},
+		{
+			name:   "role without a maximum",
+			raw:    `[{"role":"QA","prompt":["a","b","c"]}]`,
+			limits: limits,
+			want:   `[{"role":"QA","prompt":["a","b","c"]}]`,
+		},
+		{
+			name:   "item without prompts",
+			raw:    `[{"role":"PM"}]`,
+			limits: limits,
+			want:   `[{"role":"PM"}]`,
+		},
+		{
+			name: "no limits",
+			raw:  `[{"role":"PM","prompt":["a","b","c"]}]`,
+			want: `[{"role":"PM","prompt":["a","b","c"]}]`,
+		},
+		{
+			name:   "not an array",
+			raw:    `{"items":[]}`,
+			limits: limits,
+			want:   `{"items":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capItems(tt.raw, tt.limits, tt.suggestions); got != tt.want {
+				t.Errorf("capItems() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
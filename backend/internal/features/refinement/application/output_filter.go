@@ -0,0 +1,185 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// defaultFilterMask replaces blocked terms without a replacement of their own.
+const defaultFilterMask = "***"
+
+// blockedTermsIn returns the blocked terms found in the texts, in blocklist order.
+func blockedTermsIn(filter configdomain.OutputFilterConfig, texts ...string) []string {
+	var found []string
+	for _, blocked := range filter.BlockedTerms {
+		if strings.TrimSpace(blocked.Term) == "" {
+			continue
+		}
+		pattern := termPattern(blocked.Term)
+		for _, text := range texts {
+			if pattern.MatchString(text) {
+				found = append(found, blocked.Term)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// scrubText replaces the blocked terms in a text with their replacement or the filter's mask.
+func scrubText(filter configdomain.OutputFilterConfig, text string) string {
+	for _, blocked := range filter.BlockedTerms {
+		if strings.TrimSpace(blocked.Term) == "" {
+			continue
+		}
+		replacement := blocked.Replacement
+		if replacement == "" {
+			replacement = filter.Mask
+		}
+		if replacement == "" {
+			replacement = defaultFilterMask
+		}
+		text = termPattern(blocked.Term).ReplaceAllLiteralString(text, replacement)
+	}
+	return text
+}
+
+// scrubSuggestions scrubs the blocked terms from suggestions, including those merged from the ensemble
+// and localization runs, which do not pass through filterOutput.
+func scrubSuggestions(filter configdomain.OutputFilterConfig, suggestions []domain.Suggestion) []domain.Suggestion {
+	if len(filter.BlockedTerms) == 0 {
+		return suggestions
+	}
+	for i := range suggestions {
+		prompts := make([]string, len(suggestions[i].Prompt))
+		for j, p := range suggestions[i].Prompt {
+			prompts[j] = scrubText(filter, p)
+		}
+		suggestions[i].Prompt = prompts
+	}
+	return suggestions
+}
+
+// scrubItems scrubs the blocked terms from the prompts of a JSON array of role items.
+func scrubItems(filter configdomain.OutputFilterConfig, raw string) string {
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return scrubText(filter, raw)
+	}
+	for _, item := range items {
+		prompts, _ := item["prompt"].([]any)
+		for i, p := range prompts {
+			if text, ok := p.(string); ok {
+				prompts[i] = scrubText(filter, text)
+			}
+		}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return raw
+	}
+	return string(data)
+}
+
+// itemPrompts returns the prompts of a JSON array of role items, or the raw output when it is not one.
+func itemPrompts(raw string) []string {
+	var items []roleItems
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return []string{raw}
+	}
+	var prompts []string
+	for _, item := range items {
+		prompts = append(prompts, item.Prompt...)
+	}
+	return prompts
+}
+
+// filterAction describes what the filter does with offending output, for warnings.
+func filterAction(filter configdomain.OutputFilterConfig) string {
+	if filter.Regenerate {
+		return "要求 AI 改寫並遮蔽剩餘的用語"
+	}
+	return "遮蔽這些用語"
+}
+
+// filterRequest asks the assistant to rewrite its last response without the blocked terms.
+func filterRequest(found []string, format string) string {
+	return "你上一次的回覆包含不允許使用的用語：" + strings.Join(found, "、") + "\n請改寫相關內容，完全避免使用這些用語，並" + format + "，不要加上任何說明。"
+}
+
+// filterOutput checks a round's checked JSON questions (or suggestions) against the output filter's
+// blocklist. When blocked terms are found and the filter regenerates, the assistant is asked once to
+// rewrite its output; blocked terms remaining after that are scrubbed.
+func (s *refinementService) filterOutput(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, req *domain.RefinementRequest, suggestions bool, raw string) string {
+	found := blockedTermsIn(req.OutputFilter, itemPrompts(raw)...)
+	if len(found) == 0 {
+		return raw
+	}
+	logging.From(ctx).Warn().Str("operation", operation).Strs("terms", found).Msg("Output contains blocked terms")
+	addWarning(ctx, domain.WarningOutputFiltered, "AI 回覆包含不允許使用的用語（%s），已%s", strings.Join(found, "、"), filterAction(req.OutputFilter))
+	if !req.OutputFilter.Regenerate {
+		return scrubItems(req.OutputFilter, raw)
+	}
+
+	if err := client.AddMessageToThread(ctx, threadID, filterRequest(found, "重新輸出完整的 JSON 陣列（包含所有角色）")); err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to request filtered output")
+		return scrubItems(req.OutputFilter, raw)
+	}
+	if err := s.runAssistant(ctx, client, threadID, assistantID, tags, operation+"_filter", budgetFor(req, operation), roleItemsSchema); err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to run output filter regeneration")
+		return scrubItems(req.OutputFilter, raw)
+	}
+	messages, err := client.GetAssistantResponse(ctx, threadID)
+	if err != nil || len(messages) == 0 || len(messages[len(messages)-1].Content) == 0 {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Failed to get regenerated output")
+		return scrubItems(req.OutputFilter, raw)
+	}
+	regenerated, err := parseOutput(messages[len(messages)-1].Content[0].Text.Value, roleItemsSchema)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Msg("Discarding unparsable regenerated output")
+		return scrubItems(req.OutputFilter, raw)
+	}
+	return scrubItems(req.OutputFilter, capItems(unwrapItems(regenerated), req.RoleLimits, suggestions))
+}
+
+// filterFinalOutput checks the finalized story against the output filter's blocklist like filterOutput,
+// regenerating the story once when the filter asks for it. The story, AC and raw output are returned
+// with the remaining blocked terms scrubbed.
+func (s *refinementService) filterFinalOutput(ctx context.Context, client infrastructure.OpenAIClient, session *domain.RefinementSession, story, criteria, userStory string, ac []string, raw string) (string, []string, string) {
+	filter := session.Request.OutputFilter
+	found := blockedTermsIn(filter, append([]string{userStory, raw}, ac...)...)
+	if len(found) == 0 {
+		return userStory, ac, raw
+	}
+	logging.From(ctx).Warn().Str("operation", "finalize").Strs("terms", found).Msg("Output contains blocked terms")
+	addWarning(ctx, domain.WarningOutputFiltered, "最終輸出包含不允許使用的用語（%s），已%s", strings.Join(found, "、"), filterAction(filter))
+
+	if filter.Regenerate {
+		request := filterRequest(found, "依照要求重新輸出，包含「"+story+"」與「"+criteria+"」兩個段落")
+		if err := client.AddMessageToThread(ctx, session.ThreadID, request); err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to request filtered finalize output")
+		} else if err := s.runAssistant(ctx, client, session.ThreadID, session.AssistantID, tagsFor(session), "finalize_filter", budgetFor(&session.Request, "finalize"), finalOutputSchema); err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to run finalize filter regeneration")
+		} else if messages, err := client.GetAssistantResponse(ctx, session.ThreadID); err == nil && len(messages) > 0 && len(messages[len(messages)-1].Content) > 0 {
+			regenerated := messages[len(messages)-1].Content[0].Text.Value
+			if regeneratedStory, regeneratedAC, ok := parseFinalOutput(regenerated, story, criteria); ok {
+				userStory, ac, raw = regeneratedStory, regeneratedAC, regenerated
+				if json.Valid([]byte(stripCodeFence(raw))) {
+					raw = formatFinalOutput(userStory, ac, story, criteria)
+				}
+			}
+		}
+	}
+
+	scrubbed := make([]string, len(ac))
+	for i, criterion := range ac {
+		scrubbed[i] = scrubText(filter, criterion)
+	}
+	return scrubText(filter, userStory), scrubbed, scrubText(filter, raw)
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// maxRepairAttempts bounds how many times the assistant is asked to repair output that cannot be parsed.
+const maxRepairAttempts = 2
+
+// maxJSONCandidates bounds the opening brackets tried when scanning output for an embedded JSON value.
+const maxJSONCandidates = 20
+
+// codeFencePattern matches a markdown code fence with or without a language tag.
+var codeFencePattern = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\n?(.*?)```")
+
+// escapedJSONReplacer undoes the escaping of JSON that was returned as the body of a JSON string.
+var escapedJSONReplacer = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t", `\r`, "")
+
+// compiledSchemas caches the compiled response schemas by name.
+var compiledSchemas sync.Map
+
+// extractJSON finds the JSON value in the assistant's output. It tries, in order: the whole output,
+// the content of markdown code fences (with or without a language tag), the first balanced JSON value
+// surrounded by commentary, and the same again after undoing the escaping of partially escaped JSON.
+func extractJSON(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("output is empty")
+	}
+	extracted, found := findJSON(raw)
+	if found && !strings.HasPrefix(extracted, `"`) {
+		return extracted, nil
+	}
+	// Partially escaped JSON, e.g. [{\"role\": ...}] or the whole value quoted as a JSON string
+	var unquoted string
+	if err := json.Unmarshal([]byte(extracted), &unquoted); err == nil {
+		if inner, ok := findJSON(strings.TrimSpace(unquoted)); ok {
+			return inner, nil
+		}
+	}
+	if strings.Contains(raw, `\"`) {
+		if inner, ok := findJSON(escapedJSONReplacer.Replace(raw)); ok {
+			return inner, nil
+		}
+	}
+	if found {
+		return extracted, nil
+	}
+	return "", errors.New("output contains no valid JSON")
+}
+
+// findJSON applies the extraction strategies that do not change the output's text.
+func findJSON(text string) (string, bool) {
+	if json.Valid([]byte(text)) {
+		return text, true
+	}
+	for _, match := range codeFencePattern.FindAllStringSubmatch(text, -1) {
+		if fenced := strings.TrimSpace(match[1]); json.Valid([]byte(fenced)) {
+			return fenced, true
+		}
+	}
+	return balancedJSON(text)
+}
+
+// balancedJSON returns the first valid JSON object or array in text, skipping the commentary around it.
+func balancedJSON(text string) (string, bool) {
+	tried := 0
+	for start := 0; start < len(text) && tried < maxJSONCandidates; start++ {
+		if text[start] != '{' && text[start] != '[' {
+			continue
+		}
+		tried++
+		if end := matchingBracket(text, start); end > 0 && json.Valid([]byte(text[start:end+1])) {
+			return text[start : end+1], true
+		}
+	}
+	return "", false
+}
+
+// matchingBracket returns the index of the bracket closing the one at start, ignoring brackets inside
+// strings, or -1 when it is not closed.
+func matchingBracket(text string, start int) int {
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseOutput extracts the JSON value of the assistant's output and validates it against the response
+// schema. Role items are also accepted as a bare array, as providers without structured outputs return them.
+func parseOutput(raw string, schema *infrastructure.ResponseSchema) (string, error) {
+	extracted, err := extractJSON(raw)
+	if err != nil || schema == nil {
+		return extracted, err
+	}
+	var value any
+	if err := json.Unmarshal([]byte(extracted), &value); err != nil {
+		return "", err
+	}
+	if items, ok := value.([]any); ok && schema == roleItemsSchema {
+		value = map[string]any{"items": items}
+	}
+	if err := validateSchema(schema, value); err != nil {
+		return "", err
+	}
+	return extracted, nil
+}
+
+// validateSchema validates a decoded JSON value against a response schema.
+func validateSchema(schema *infrastructure.ResponseSchema, value any) error {
+	compiled, ok := compiledSchemas.Load(schema.Name)
+	if !ok {
+		data, err := json.Marshal(schema.Schema)
+		if err != nil {
+			return fmt.Errorf("failed to marshal schema %s: %w", schema.Name, err)
+		}
+		url := schema.Name + ".json"
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(url, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to load schema %s: %w", schema.Name, err)
+		}
+		if compiled, err = compiler.Compile(url); err != nil {
+			return fmt.Errorf("failed to compile schema %s: %w", schema.Name, err)
+		}
+		compiledSchemas.Store(schema.Name, compiled)
+	}
+	err := compiled.(*jsonschema.Schema).Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	// Report the leaf errors only, e.g. "/items/0: missing properties: 'prompt'"
+	var problems []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error != "" && !strings.HasPrefix(unit.Error, "doesn't validate with") {
+			location := unit.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			problems = append(problems, location+": "+unit.Error)
+		}
+	}
+	if len(problems) == 0 {
+		return validationErr
+	}
+	return fmt.Errorf("output does not match the schema: %s", strings.Join(problems, "; "))
+}
+
+// parseWithRepair parses the assistant's output like parseOutput. When that fails, the problem is sent
+// back to the thread and the assistant asked for a repaired response, at most maxRepairAttempts times.
+func (s *refinementService) parseWithRepair(ctx context.Context, client infrastructure.OpenAIClient, threadID, assistantID string, tags costTags, operation string, budget configdomain.LatencyBudget, schema *infrastructure.ResponseSchema, raw string) (string, error) {
+	parsed, err := parseOutput(raw, schema)
+	for attempt := 1; err != nil && attempt <= maxRepairAttempts; attempt++ {
+		recordParseFailure(tags.SessionID)
+		logging.From(ctx).Warn().Err(err).Str("operation", operation).Int("attempt", attempt).Int("max_attempts", maxRepairAttempts).Msg("Output cannot be parsed, requesting repair")
+		if addErr := client.AddMessageToThread(ctx, threadID, repairMessage(err, schema)); addErr != nil {
+			return "", fmt.Errorf("failed to request repaired output: %w", addErr)
+		}
+		if err := s.runAssistant(ctx, client, threadID, assistantID, tags, operation+"_repair", budget, schema); err != nil {
+			return "", fmt.Errorf("failed to run output repair: %w", err)
+		}
+		messages, getErr := client.GetAssistantResponse(ctx, threadID)
+		if getErr != nil || len(messages) == 0 || len(messages[len(messages)-1].Content) == 0 {
+			return "", fmt.Errorf("failed to get repaired output: %v", getErr)
+		}
+		parsed, err = parseOutput(messages[len(messages)-1].Content[0].Text.Value, schema)
+	}
+	if err != nil {
+		recordParseFailure(tags.SessionID)
+	}
+	return parsed, err
+}
+
+// repairMessage tells the assistant why its output could not be parsed and what to return instead.
+func repairMessage(err error, schema *infrastructure.ResponseSchema) string {
+	message := "你上一次的回覆無法解析：" + err.Error() + "\n請只輸出有效的 JSON，不要加上任何說明、Markdown 標記或跳脫字元。"
+	if schema != nil {
+		if data, marshalErr := json.Marshal(schema.Schema); marshalErr == nil {
+			message += "\nJSON 必須符合以下 JSON Schema：\n" + string(data)
+		}
+	}
+	return message
+}
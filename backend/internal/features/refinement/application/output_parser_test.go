@@ -0,0 +1,88 @@
+package application
+
+import (
+	"testing"
+
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		schema  *infrastructure.ResponseSchema
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "plain JSON",
+			raw:  `{"items":[{"role":"PM","prompt":["a"]}]}`,
+			want: `{"items":[{"role":"PM","prompt":["a"]}]}`,
+		},
+		{
+			name: "code fence with commentary",
+			raw:  "Here you go:\n```json\n[{\"role\":\"PM\",\"prompt\":[\"a\"]}]\n```\nDone.",
+			want: `[{"role":"PM","prompt":["a"]}]`,
+		},
+		{
+			name: "balanced value inside commentary",
+			raw:  `The questions are [{"role":"PM","prompt":["why [not]?"]}], thanks.`,
+			want: `[{"role":"PM","prompt":["why [not]?"]}]`,
+		},
+		{
+			name: "quoted JSON string",
+			raw:  `"[{\"role\":\"PM\",\"prompt\":[\"a\"]}]"`,
+			want: `[{"role":"PM","prompt":["a"]}]`,
+		},
+		{
+			name: "partially escaped JSON",
+			raw:  `Result: [{\"role\":\"PM\",\"prompt\":[\"a\"]}]`,
+			want: `[{"role":"PM","prompt":["a"]}]`,
+		},
+		{
+			name:    "empty",
+			raw:     "  ",
+			wantErr: true,
+		},
+		{
+			name:    "no JSON",
+			raw:     "I cannot answer that.",
+			wantErr: true,
+		},
+		{
+			name:   "role items as a bare array",
+			raw:    `[{"role":"PM","prompt":["a"]}]`,
+			schema: roleItemsSchema,
+			want:   `[{"role":"PM","prompt":["a"]}]`,
+		},
+		{
+			name:   "role items as an object",
+			raw:    `{"items":[{"role":"PM","prompt":["a"]}]}`,
+			schema: roleItemsSchema,
+			want:   `{"items":[{"role":"PM","prompt":["a"]}]}`,
+		},
+		{
+			name:    "role item without prompts",
+			raw:     `[{"role":"PM"}]`,
+			schema:  roleItemsSchema,
+			wantErr: true,
+		},
+		{
+			name:    "role item with extra properties",
+			raw:     `[{"role":"PM","prompt":["a"],"note":"x"}]`,
+			schema:  roleItemsSchema,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOutput(tt.raw, tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("parseOutput() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+// Structured outputs require an object at the root, so role items are wrapped in {"items": [...]}.
+var roleItemsSchema = &infrastructure.ResponseSchema{
+	Name: "role_items",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"role":   map[string]any{"type": "string"},
+						"prompt": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+					"required":             []string{"role", "prompt"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"items"},
+		"additionalProperties": false,
+	},
+}
+
+// finalOutputSchema is the structured form of the finalize output.
+var finalOutputSchema = &infrastructure.ResponseSchema{
+	Name: "final_output",
+	Schema: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"user_story":          map[string]any{"type": "string"},
+			"acceptance_criteria": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required":             []string{"user_story", "acceptance_criteria"},
+		"additionalProperties": false,
+	},
+}
+
+// finalOutput is the finalize output returned under finalOutputSchema.
+type finalOutput struct {
+	UserStory          string   `json:"user_story"`
+	AcceptanceCriteria []string `json:"acceptance_criteria"`
+}
+
+// unwrapItems turns role items returned under roleItemsSchema back into the JSON array the rest of the
+// flow parses. Other output, such as a bare array from providers without structured outputs, is
+// returned as is.
+func unwrapItems(raw string) string {
+	var wrapped struct {
+		Items json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(raw), &wrapped); err != nil || len(wrapped.Items) == 0 || wrapped.Items[0] != '[' {
+		return raw
+	}
+	return string(wrapped.Items)
+}
+
+// parseFinalOutput reads the user story and AC from the finalize output: JSON under finalOutputSchema,
+// or text with the story and criteria headings. It reports false when the output is in neither form.
+func parseFinalOutput(raw, storyHeading, criteriaHeading string) (string, []string, bool) {
+	var structured finalOutput
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &structured); err == nil && strings.TrimSpace(structured.UserStory) != "" {
+		ac := []string{}
+		for _, item := range structured.AcceptanceCriteria {
+			if item = strings.TrimSpace(item); item != "" {
+				ac = append(ac, item)
+			}
+		}
+		return strings.TrimSpace(structured.UserStory), ac, true
+	}
+
+	storyStart := strings.Index(raw, storyHeading)
+	criteriaStart := strings.Index(raw, criteriaHeading)
+	if storyStart == -1 || criteriaStart == -1 {
+		return "", nil, false
+	}
+	userStory := strings.TrimSpace(raw[storyStart+len(storyHeading) : criteriaStart])
+	ac := []string{}
+	for _, line := range strings.Split(raw[criteriaStart+len(criteriaHeading):], "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && (strings.HasPrefix(line, "1.") || strings.HasPrefix(line, "2.") || strings.HasPrefix(line, "3.") || strings.HasPrefix(line, "4.") || strings.HasPrefix(line, "5.")) {
+			// 移除數字前綴
+			item := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(line, "1."), "2."), "3."), "4."), "5."))
+			if item != "" {
+				ac = append(ac, item)
+			}
+		}
+	}
+	return userStory, ac, true
+}
+
+// numberedItemPattern matches an item of a numbered list, e.g. "6. ..." or "6、...".
+var numberedItemPattern = regexp.MustCompile(`^(\d+)[.、)]\s*(.*)$`)
+
+// droppedCriteria returns the numbered AC of the text format that parseFinalOutput does not keep, as it
+// only recognizes the criteria numbered 1 to 5.
+func droppedCriteria(raw, criteriaHeading string) []string {
+	criteriaStart := strings.Index(raw, criteriaHeading)
+	if criteriaStart == -1 {
+		return nil
+	}
+	var dropped []string
+	for _, line := range strings.Split(raw[criteriaStart+len(criteriaHeading):], "\n") {
+		match := numberedItemPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || strings.TrimSpace(match[2]) == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(match[1]); err == nil && n > 5 {
+			dropped = append(dropped, line)
+		}
+	}
+	return dropped
+}
+
+// formatFinalOutput renders a story and its AC with the headings of the text format, so the raw
+// finalize output reads the same whichever form the assistant returned.
+func formatFinalOutput(userStory string, ac []string, storyHeading, criteriaHeading string) string {
+	var b strings.Builder
+	b.WriteString(storyHeading + "\n" + userStory + "\n\n" + criteriaHeading + "\n")
+	for i, item := range ac {
+		b.WriteString(strconv.Itoa(i+1) + ". " + item + "\n")
+	}
+	return strings.TrimSpace(b.String())
+}
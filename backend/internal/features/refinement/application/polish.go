@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/events"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const polishSystemPrompt = `You are helping a Product Manager polish a finalized user story and its acceptance criteria.
+You are given the current draft with numbered acceptance criteria, the recent conversation and the PM's request.
+Change only what the request asks for and keep the language of the draft. Reply briefly to the PM in the language of the request.
+Return only JSON: {"reply": "...", "edits": [{"op": "set_story", "text": "..."}, {"op": "replace_ac", "index": 2, "text": "..."}, {"op": "add_ac", "text": "..."}, {"op": "remove_ac", "index": 3}]}
+Indexes refer to the numbering of the draft you were given; add_ac without an index appends. Return an empty edits array when the request needs no change.`
+
+// polishHistoryMessages is the number of recent polish messages sent along with a request, so the PM can
+// refer to earlier exchanges without the prompt growing with the whole chat.
+const polishHistoryMessages = 6
+
+// Polish sends a message of the PM to the polish chat of a finalized session. The assistant answers with
+// edits of the session's polish draft, which starts as the final output; the draft is updated with the
+// edits that fit it and returned with the reply.
+func (s *refinementService) Polish(ctx context.Context, sessionID, message string) (*domain.PolishResponse, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	draft := session.Polish
+	if draft == nil {
+		draft = &domain.PolishDraft{UserStory: session.FinalUserStory, AC: append([]string(nil), session.FinalAC...)}
+	}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.Complete(ctx, model, polishSystemPrompt, polishPrompt(draft, message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to polish: %w", err)
+	}
+	var output struct {
+		Reply string              `json:"reply"`
+		Edits []domain.PolishEdit `json:"edits"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse polish reply from AI: %w, raw response: %s", err, raw)
+	}
+
+	now := time.Now()
+	edits := applyPolishEdits(ctx, draft, output.Edits, session.Request.OutputFilter)
+	if len(edits) > 0 {
+		draft.Revision++
+	}
+	draft.Messages = append(draft.Messages,
+		domain.PolishMessage{Role: "pm", Content: message, CreatedAt: now},
+		domain.PolishMessage{Role: "assistant", Content: output.Reply, Edits: edits, CreatedAt: now},
+	)
+	draft.UpdatedAt = now
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.Polish = draft
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PolishResponse{Reply: output.Reply, Edits: edits, Draft: session.Polish, Warnings: warnings.list()}, nil
+}
+
+// polishPrompt presents the draft, the recent polish messages and the PM's request to the assistant.
+func polishPrompt(draft *domain.PolishDraft, message string) string {
+	var b strings.Builder
+	b.WriteString("User story:\n" + draft.UserStory + "\n\nAcceptance criteria:\n")
+	for i, criterion := range draft.AC {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, criterion)
+	}
+	history := draft.Messages[max(len(draft.Messages)-polishHistoryMessages, 0):]
+	if len(history) > 0 {
+		b.WriteString("\nRecent conversation:\n")
+		for _, msg := range history {
+			fmt.Fprintf(&b, "%s: %s\n", msg.Role, msg.Content)
+		}
+	}
+	b.WriteString("\nPM request:\n" + message)
+	return b.String()
+}
+
+// applyPolishEdits applies the edits that fit the draft, indexes referring to the AC before the edits,
+// and returns them. Blocked terms of the output filter are scrubbed from the edited texts; edits that do
+// not fit are skipped with a warning.
+func applyPolishEdits(ctx context.Context, draft *domain.PolishDraft, edits []domain.PolishEdit, filter configdomain.OutputFilterConfig) []domain.PolishEdit {
+	ac := append([]string(nil), draft.AC...)
+	removed := make([]bool, len(ac))
+	inserted := make(map[int][]string) // By the index of the AC the new ones go before, len(ac)+1 to append
+	var applied []domain.PolishEdit
+	for _, edit := range edits {
+		edit.Text = strings.TrimSpace(scrubText(filter, edit.Text))
+		inRange := edit.Index >= 1 && edit.Index <= len(ac)
+		switch {
+		case edit.Op == domain.PolishSetStory && edit.Text != "":
+			draft.UserStory = edit.Text
+		case edit.Op == domain.PolishReplaceAC && inRange && edit.Text != "":
+			ac[edit.Index-1] = edit.Text
+		case edit.Op == domain.PolishRemoveAC && inRange:
+			removed[edit.Index-1] = true
+		case edit.Op == domain.PolishAddAC && edit.Text != "":
+			at := edit.Index
+			if !inRange {
+				at = len(ac) + 1
+			}
+			inserted[at] = append(inserted[at], edit.Text)
+		default:
+			addWarning(ctx, domain.WarningEditSkipped, "AI 提出的修改無法套用到草稿（%s，第 %d 條）", edit.Op, edit.Index)
+			continue
+		}
+		applied = append(applied, edit)
+	}
+
+	result := make([]string, 0, len(ac))
+	for i, criterion := range ac {
+		result = append(result, inserted[i+1]...)
+		if !removed[i] {
+			result = append(result, criterion)
+		}
+	}
+	draft.AC = append(result, inserted[len(ac)+1]...)
+	return applied
+}
+
+// ApplyPolish makes the polish draft of a session its final output. Translations and scenarios of the
+// previous output are stale and dropped, and the session is published as finalized again so integrations sync the draft.
+func (s *refinementService) ApplyPolish(sessionID string) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	var applied bool
+	session, err := mutateSession(sessionID, func(session *domain.RefinementSession) {
+		if session.Polish == nil {
+			return
+		}
+		now := time.Now()
+		session.FinalUserStory = session.Polish.UserStory
+		session.FinalAC = append([]string(nil), session.Polish.AC...)
+		session.Translations = nil
+		session.Gherkin = nil
+		session.TestCases = nil
+		session.QualityScore = nil
+		session.Polish.AppliedAt = &now
+		applied = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, fmt.Errorf("session %s has no polish draft", sessionID)
+	}
+	s.publish(events.SessionFinalized, session, map[string]any{"polished": true, "revision": session.Polish.Revision})
+	return session, nil
+}
@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// discardPrefetchMessage tells the assistant to disregard suggestions prefetched for a path the PM did not take.
+const discardPrefetchMessage = "[捨棄預先產生的建議]\n請忽略你上一則回覆中的建議，PM 選擇了其他方向，請依接下來的指示繼續。"
+
+// prefetchedSuggestions are suggestions generated ahead of the PM asking for them.
+type prefetchedSuggestions struct {
+	key         string // Answers and additional info the suggestions were generated for
+	suggestions []domain.Suggestion
+	warnings    []domain.Warning
+}
+
+// prefetches holds the completed prefetch per session ID. Entries are only stored and taken while
+// holding the session's operation lock.
+var prefetches sync.Map
+
+// PrefetchSuggestions starts generating suggestions in the background when the PM is predicted to ask
+// for them after this round, reporting whether a prefetch was started. The next operation on the session
+// waits for the prefetch; SubmitAnswersAndGetSuggestions with the same answers uses its result and every
+// other operation discards it.
+func (s *refinementService) PrefetchSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (bool, error) {
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session.Phase != domain.PhaseQuestioning || !suggestingPredicted(session) || checkTransition(session, domain.PhaseSuggesting) != nil {
+		return false, nil
+	}
+
+	ctx, warnings := collectWarnings(sessionContext(context.WithoutCancel(ctx), session)) // The prefetch outlives the request starting it
+	go func() {
+		unlock := lockSession(sessionID)
+		defer unlock()
+		current, err := snapshotSession(sessionID)
+		if err != nil || current.Phase != domain.PhaseQuestioning || current.CurrentRound != session.CurrentRound {
+			return // The PM moved on before the prefetch could start
+		}
+		key := prefetchKey(answers, additionalInfo)
+		if p, ok := prefetches.Load(sessionID); ok && p.(*prefetchedSuggestions).key == key {
+			return // Already prefetched for these answers
+		}
+		s.takePrefetch(ctx, current, "")
+
+		suggestions, err := s.generateSuggestions(ctx, current, answers, additionalInfo, rolePrompts, phasePrompts, phaseFormatExamples, "prefetch_suggestions")
+		if err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to prefetch suggestions")
+			return
+		}
+		prefetches.Store(sessionID, &prefetchedSuggestions{key: key, suggestions: suggestions, warnings: warnings.list()})
+	}()
+	return true, nil
+}
+
+// suggestingPredicted reports whether the PM is likely to ask for suggestions after the current round:
+// the questions have converged or the planned number of rounds has been reached.
+func suggestingPredicted(session *domain.RefinementSession) bool {
+	return session.Converged || (session.TargetRounds > 0 && session.CurrentRound >= session.TargetRounds)
+}
+
+// prefetchKey identifies the answers and additional info suggestions were generated for.
+func prefetchKey(answers map[string]string, additionalInfo string) string {
+	b, _ := json.Marshal(struct {
+		Answers        map[string]string `json:"answers"`
+		AdditionalInfo string            `json:"additional_info"`
+	}{answers, additionalInfo})
+	return string(b)
+}
+
+// takePrefetch removes the session's prefetched suggestions and returns them if they were generated for
+// key, adding the warnings of their generation to the context's operation. Prefetched suggestions that do
+// not match are discarded on the thread, so the assistant ignores them. Callers must hold the session's
+// operation lock.
+func (s *refinementService) takePrefetch(ctx context.Context, session *domain.RefinementSession, key string) ([]domain.Suggestion, bool) {
+	p, ok := prefetches.LoadAndDelete(session.ID)
+	if !ok {
+		return nil, false
+	}
+	prefetched := p.(*prefetchedSuggestions)
+	if key != "" && prefetched.key == key {
+		for _, warning := range prefetched.warnings {
+			addWarning(ctx, warning.Code, "%s", warning.Message)
+		}
+		return prefetched.suggestions, true
+	}
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err == nil {
+		err = client.AddMessageToThread(ctx, session.ThreadID, discardPrefetchMessage)
+	}
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to discard prefetched suggestions")
+	}
+	return nil, false
+}
@@ -0,0 +1,65 @@
+package application
+
+import (
+	"sort"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// pastAnswer is a question answered in a past session.
+type pastAnswer struct {
+	answered   domain.AnsweredQuestion
+	sessionID  string
+	storyTitle string
+	createdAt  int64
+}
+
+// attachPriorAnswers offers, for each prompt of new questions, the answer of the most similar question
+// answered in another session of the same product, so the PM can confirm it instead of rewriting it.
+func attachPriorAnswers(sessionID, workspaceID string, questions []domain.Question) {
+	var past []pastAnswer
+	sessionsMutex.RLock()
+	for id, other := range sessions {
+		if id == sessionID || other.WorkspaceID != workspaceID || other.IsTutorial() {
+			continue
+		}
+		title := storyTitle(other)
+		for _, answered := range other.AnsweredQuestions {
+			past = append(past, pastAnswer{answered: answered, sessionID: id, storyTitle: title, createdAt: other.CreatedAt.UnixNano()})
+		}
+	}
+	sessionsMutex.RUnlock()
+	if len(past) == 0 {
+		return
+	}
+	// Latest first, so the latest of equally close answers wins
+	sort.SliceStable(past, func(i, j int) bool { return past[i].createdAt > past[j].createdAt })
+
+	for i := range questions {
+		priors := make([]*domain.PriorAnswer, len(questions[i].Prompt))
+		matched := false
+		for j, prompt := range questions[i].Prompt {
+			var best *pastAnswer
+			bestSimilarity := duplicateSimilarity
+			for k := range past {
+				if similarity := questionSimilarity(prompt, past[k].answered.Question); similarity > bestSimilarity || (best == nil && similarity == bestSimilarity) {
+					best, bestSimilarity = &past[k], similarity
+				}
+			}
+			if best == nil {
+				continue
+			}
+			priors[j] = &domain.PriorAnswer{
+				Question:   best.answered.Question,
+				Answer:     best.answered.Answer,
+				SessionID:  best.sessionID,
+				StoryTitle: best.storyTitle,
+				Similarity: bestSimilarity,
+			}
+			matched = true
+		}
+		if matched {
+			questions[i].PriorAnswers = priors
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// maxMemoryItems bounds the memory injected into new sessions; the newest items are kept.
+const maxMemoryItems = 50
+
+const memoryDistillSystemPrompt = `你負責維護產品團隊需求打磨的長期記憶。
+請從下方已定案的打磨 session 中，只擷取之後同一產品的故事也適用的長期知識：
+- "decision"：已做出的產品或技術決策
+- "constraint"：始終適用的限制（商業、法規、技術、平台）
+- "answer"：之後還會被問到的基本問題的答案（目標使用者、支援平台等）
+略過只與本故事相關的內容，也略過現有記憶中已有的項目。
+每個項目以一句簡潔的句子表達，使用 session 的語言。
+只回傳 JSON：[{"kind": "decision|constraint|answer", "content": "..."}]；沒有新的項目時回傳 []。`
+
+// ListMemory returns the long-term memory of a product (workspace).
+func (s *refinementService) ListMemory(workspaceID string) ([]domain.MemoryItem, error) {
+	if s.memoryStore == nil {
+		return []domain.MemoryItem{}, nil
+	}
+	return s.memoryStore.List(workspaceID)
+}
+
+// DeleteMemory removes an outdated or wrong item from a product's memory.
+func (s *refinementService) DeleteMemory(workspaceID, id string) error {
+	if s.memoryStore == nil {
+		return fmt.Errorf("memory item %s not found", id)
+	}
+	return s.memoryStore.Delete(workspaceID, id)
+}
+
+// memoryContext formats a product's memory for injection into a new session's instructions.
+func (s *refinementService) memoryContext(workspaceID string) string {
+	items, err := s.ListMemory(workspaceID)
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("workspace_id", workspaceID).Msg("Skipping product memory, failed to load it")
+		return ""
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	if len(items) > maxMemoryItems {
+		items = items[len(items)-maxMemoryItems:]
+	}
+	var b strings.Builder
+	b.WriteString("\n\n過往打磨累積的產品記憶（已確認的決策、限制與常見答案，請勿再重複詢問這些基本問題）：")
+	for _, item := range items {
+		b.WriteString(fmt.Sprintf("\n- [%s] %s", item.Kind, item.Content))
+	}
+	return b.String()
+}
+
+// distillMemory extracts durable knowledge from a finalized session into its product's memory.
+func (s *refinementService) distillMemory(ctx context.Context, session *domain.RefinementSession) {
+	if s.memoryStore == nil || session.IsTutorial() {
+		return
+	}
+	existing, err := s.memoryStore.List(session.WorkspaceID)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to load product memory")
+		return
+	}
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to distill product memory")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("現有記憶：\n")
+	for _, item := range existing {
+		b.WriteString("- " + item.Content + "\n")
+	}
+	b.WriteString("\nSession 紀錄：\n" + strings.Join(session.History, "\n"))
+	b.WriteString("\n\n最終用戶故事：\n" + session.FinalUserStory)
+	b.WriteString("\n\n驗收標準：\n- " + strings.Join(session.FinalAC, "\n- "))
+
+	raw, err := client.Complete(ctx, model, memoryDistillSystemPrompt, b.String())
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to distill product memory")
+		return
+	}
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "```json") && strings.HasSuffix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```json\n")
+		raw = strings.TrimSuffix(raw, "\n```")
+	}
+	var distilled []struct {
+		Kind    string `json:"kind"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(raw), &distilled); err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to parse product memory from AI")
+		if logging.Prompts() {
+			logging.From(ctx).Debug().Str("output", raw).Msg("Unparsable product memory output")
+		}
+		return
+	}
+
+	now := time.Now()
+	var items []domain.MemoryItem
+	for i, d := range distilled {
+		content := strings.TrimSpace(d.Content)
+		if content == "" {
+			continue
+		}
+		items = append(items, domain.MemoryItem{
+			ID:              fmt.Sprintf("mem-%d-%d", now.UnixNano(), i),
+			WorkspaceID:     session.WorkspaceID,
+			Kind:            d.Kind,
+			Content:         content,
+			SourceSessionID: session.ID,
+			CreatedAt:       now,
+		})
+	}
+	if len(items) == 0 {
+		return
+	}
+	if err := s.memoryStore.Add(items); err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to store product memory")
+	}
+}
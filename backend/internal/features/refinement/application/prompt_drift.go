@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// driftAlertDistance is the prompt drift at which a session is alerted on: about a third of a configured
+// prompt is no longer in the assistant's context.
+const driftAlertDistance = 0.2
+
+// promptDrifts holds the latest prompt drift of each session since the server started, by session ID.
+var promptDrifts sync.Map
+
+// sentInstructions holds the assistant instructions of the sessions started since the server started,
+// by session ID. They stay in the assistant's context however long its thread grows.
+var sentInstructions sync.Map
+
+// trackPromptDrift measures the prompt drift of a session after an operation in the background, as it
+// reads the thread from the provider. A session starting to drift beyond driftAlertDistance is logged
+// and published as a PromptDrifted event.
+func (s *refinementService) trackPromptDrift(ctx context.Context, session *domain.RefinementSession, operation string) {
+	if session.IsTutorial() || session.ThreadID == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx) // The measurement outlives the request
+	session = session.Clone()
+	go func() {
+		drift, err := s.measurePromptDrift(ctx, session, operation)
+		if err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to measure prompt drift")
+			return
+		}
+		previous, _ := promptDrifts.Swap(session.ID, drift)
+		if !drift.Alert || (previous != nil && previous.(*domain.PromptDrift).Alert) {
+			return
+		}
+		logging.From(ctx).Warn().Str("operation", operation).Float64("max_distance", drift.MaxDistance).Int("dropped_messages", drift.DroppedMessages).Msg("Prompts drifted")
+		s.publish(events.PromptDrifted, session, map[string]any{
+			"operation":        operation,
+			"max_distance":     drift.MaxDistance,
+			"prompts":          drift.Prompts,
+			"dropped_messages": drift.DroppedMessages,
+		})
+	}()
+}
+
+// measurePromptDrift compares the configured prompts of a session with its assistant instructions and
+// the messages of its thread that still fit the model's context window, newest first, the way the
+// provider truncates a thread.
+func (s *refinementService) measurePromptDrift(ctx context.Context, session *domain.RefinementSession, operation string) (*domain.PromptDrift, error) {
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := client.ListThreadMessages(ctx, session.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	drift := &domain.PromptDrift{
+		SessionID:     session.ID,
+		Operation:     operation,
+		MeasuredAt:    time.Now(),
+		Model:         model,
+		ContextWindow: contextWindow(model),
+	}
+	var kept []string
+	if instructions, ok := sentInstructions.Load(session.ID); ok {
+		kept = append(kept, instructions.(string))
+		drift.ContextTokens = estimateTokens(instructions.(string))
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		var text strings.Builder
+		for _, content := range messages[i].Content {
+			if content.Text != nil {
+				text.WriteString(content.Text.Value)
+				text.WriteString("\n")
+			}
+		}
+		tokens := estimateTokens(text.String())
+		drift.ContextTokens += tokens
+		if drift.ContextTokens > drift.ContextWindow {
+			drift.DroppedMessages++
+			continue
+		}
+		kept = append(kept, text.String())
+	}
+
+	inContext := embed(strings.Join(kept, "\n"))
+	for _, prompt := range configuredPrompts(session) {
+		distance := promptDistance(embed(prompt.text), inContext)
+		drift.Prompts = append(drift.Prompts, domain.PromptDriftScore{Prompt: prompt.name, Distance: distance})
+		drift.MaxDistance = math.Max(drift.MaxDistance, distance)
+	}
+	drift.Alert = drift.MaxDistance >= driftAlertDistance
+	return drift, nil
+}
+
+// configuredPrompt is a prompt of the config that should be in a session's context.
+type configuredPrompt struct {
+	name string
+	text string
+}
+
+// configuredPrompts returns the role prompts of the session's roles and the prompt of its current phase.
+func configuredPrompts(session *domain.RefinementSession) []configuredPrompt {
+	var prompts []configuredPrompt
+	for _, role := range session.Request.SelectedRoles {
+		if prompt := strings.TrimSpace(session.RolePrompts[role]); prompt != "" {
+			prompts = append(prompts, configuredPrompt{name: "role:" + role, text: prompt})
+		}
+	}
+	phase := ""
+	switch session.Phase {
+	case domain.PhaseQuestioning:
+		phase = questioningPrompt(session.PhasePrompts, session.CurrentRound)
+	case domain.PhaseSuggesting:
+		phase = session.PhasePrompts["suggesting"]
+	}
+	if strings.TrimSpace(phase) != "" {
+		prompts = append(prompts, configuredPrompt{name: "phase:" + strings.ToLower(string(session.Phase)), text: phase})
+	}
+	return prompts
+}
+
+// embed embeds a text as its character trigram counts, after folding case and whitespace. Trigrams work
+// for Chinese and English alike and keep the measurement on this server, without an embedding model.
+func embed(text string) map[string]float64 {
+	runes := []rune(strings.Join(strings.FieldsFunc(strings.ToLower(text), unicode.IsSpace), " "))
+	vector := make(map[string]float64)
+	for i := 0; i+3 <= len(runes); i++ {
+		vector[string(runes[i:i+3])]++
+	}
+	return vector
+}
+
+// promptDistance is the cosine distance between a prompt's embedding and the part of the context's
+// embedding that overlaps it, so the rest of the conversation does not count as drift. A prompt whose
+// every trigram is in the context has distance 0.
+func promptDistance(prompt, inContext map[string]float64) float64 {
+	var dot, promptNorm, overlapNorm float64
+	for trigram, count := range prompt {
+		overlap := math.Min(count, inContext[trigram])
+		dot += count * overlap
+		promptNorm += count * count
+		overlapNorm += overlap * overlap
+	}
+	if promptNorm == 0 {
+		return 0
+	}
+	if overlapNorm == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(promptNorm*overlapNorm)
+}
+
+// PromptDrift reports the latest prompt drift of every measured session, the most drifted first.
+func (s *refinementService) PromptDrift() *domain.PromptDriftReport {
+	report := &domain.PromptDriftReport{AlertThreshold: driftAlertDistance, Sessions: []domain.PromptDrift{}}
+	promptDrifts.Range(func(_, value any) bool {
+		drift := value.(*domain.PromptDrift)
+		report.Sessions = append(report.Sessions, *drift)
+		if drift.Alert {
+			report.Alerts++
+		}
+		return true
+	})
+	sort.Slice(report.Sessions, func(i, j int) bool {
+		return report.Sessions[i].MaxDistance > report.Sessions[j].MaxDistance
+	})
+	return report
+}
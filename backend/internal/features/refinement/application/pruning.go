@@ -0,0 +1,49 @@
+package application
+
+import (
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const (
+	// maxAskedQuestions caps the questions kept for convergence detection; older ones are pruned.
+	maxAskedQuestions = 200
+	// maxHistoryBytes caps the total size of a session's history; the oldest entries are pruned first.
+	maxHistoryBytes = 64 * 1024
+)
+
+// pruneSession drops the oldest asked questions and history entries of a session beyond the caps,
+// returning what was dropped. The first history entry, the initial story, is always kept.
+// Callers must hold sessionsMutex, e.g. by pruning inside mutateSession.
+func pruneSession(session *domain.RefinementSession) (askedQuestions, history []string) {
+	if excess := len(session.AskedQuestions) - maxAskedQuestions; excess > 0 {
+		askedQuestions = append([]string{}, session.AskedQuestions[:excess]...)
+		session.AskedQuestions = append([]string{}, session.AskedQuestions[excess:]...)
+	}
+
+	size := 0
+	for _, entry := range session.History {
+		size += len(entry)
+	}
+	drop := 0
+	for size > maxHistoryBytes && 1+drop < len(session.History) {
+		size -= len(session.History[1+drop])
+		drop++
+	}
+	if drop > 0 {
+		history = append([]string{}, session.History[1:1+drop]...)
+		session.History = append(session.History[:1:1], session.History[1+drop:]...)
+	}
+	return askedQuestions, history
+}
+
+// publishPruned publishes the asked questions and history entries pruned from a session, if any.
+func (s *refinementService) publishPruned(session *domain.RefinementSession, askedQuestions, history []string) {
+	if len(askedQuestions) == 0 && len(history) == 0 {
+		return
+	}
+	s.publish(events.SessionPruned, session, map[string]any{
+		"asked_questions": askedQuestions,
+		"history":         history,
+	})
+}
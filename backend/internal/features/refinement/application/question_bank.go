@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+const (
+	// minBankOccurrences is how many sessions must ask a mined question before its answer is injected.
+	minBankOccurrences = 2
+	// maxBankContextQuestions bounds the bank questions injected into new sessions, most asked first.
+	maxBankContextQuestions = 30
+)
+
+const questionBankSystemPrompt = `You maintain the bank of frequently asked questions of a product team's requirement refinement.
+From the questions answered in the refinement session below, pick only baseline questions about the product that would be asked again for other stories (target users, supported platforms, authentication, data retention, etc.); skip questions specific to this story.
+For each, give the question in a general form and its answer as the PM gave it. When it repeats a question of the existing bank, set "bank_id" to that question's ID, otherwise leave it empty.
+Write in the language of the session.
+Return only JSON: [{"bank_id": "", "question": "...", "answer": "..."}]; return [] when there is nothing to add.`
+
+// recordAnswers keeps the answered questions of the current round on the session. Callers must hold
+// sessionsMutex (e.g. call it inside mutateSession).
+func recordAnswers(session *domain.RefinementSession, answers map[string]string) {
+	for _, q := range session.Questions {
+		for _, p := range q.Prompt {
+			if answer := strings.TrimSpace(answers[q.Role+"_"+p]); answer != "" {
+				session.AnsweredQuestions = append(session.AnsweredQuestions, domain.AnsweredQuestion{Role: q.Role, Question: p, Answer: answer, Round: session.CurrentRound})
+			}
+		}
+	}
+}
+
+// recordSuggestions keeps the accepted suggestions on the session, after its current round. Callers
+// must hold sessionsMutex (e.g. call it inside mutateSession).
+func recordSuggestions(session *domain.RefinementSession, accepted []domain.Suggestion) {
+	for _, suggestion := range accepted {
+		for _, p := range suggestion.Prompt {
+			if p = strings.TrimSpace(p); p != "" {
+				session.AcceptedSuggestions = append(session.AcceptedSuggestions, domain.AcceptedSuggestion{Role: suggestion.Role, Prompt: p, Round: session.CurrentRound})
+			}
+		}
+	}
+}
+
+// ListQuestionBank returns the question bank of a product (workspace), most asked first.
+func (s *refinementService) ListQuestionBank(workspaceID string) ([]domain.BankQuestion, error) {
+	if s.questionBank == nil {
+		return []domain.BankQuestion{}, nil
+	}
+	questions, err := s.questionBank.List(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(questions, func(i, j int) bool { return questions[i].Occurrences > questions[j].Occurrences })
+	return questions, nil
+}
+
+// SaveBankQuestion adds a curated question to a product's bank, or edits the one with the given ID.
+func (s *refinementService) SaveBankQuestion(workspaceID, id string, req *domain.BankQuestionRequest) (*domain.BankQuestion, error) {
+	if s.questionBank == nil {
+		return nil, fmt.Errorf("the question bank is not available")
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		return nil, fmt.Errorf("question is required")
+	}
+	now := time.Now()
+	question := domain.BankQuestion{ID: fmt.Sprintf("bq-%d", now.UnixNano()), WorkspaceID: workspaceID, CreatedAt: now}
+	if id != "" {
+		existing, err := s.questionBank.List(workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(existing, func(q domain.BankQuestion) bool { return q.ID == id })
+		if i < 0 {
+			return nil, fmt.Errorf("bank question %s not found", id)
+		}
+		question = existing[i]
+	}
+	question.Question = strings.TrimSpace(req.Question)
+	question.CanonicalAnswer = strings.TrimSpace(req.CanonicalAnswer)
+	question.Curated = true
+	question.UpdatedAt = now
+	if err := s.questionBank.Save(question); err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// DeleteBankQuestion removes a question from a product's bank.
+func (s *refinementService) DeleteBankQuestion(workspaceID, id string) error {
+	if s.questionBank == nil {
+		return fmt.Errorf("bank question %s not found", id)
+	}
+	return s.questionBank.Delete(workspaceID, id)
+}
+
+// questionBankContext formats the answered recurring questions of a product for injection into a new
+// session's instructions, so the assistant does not ask them again.
+func (s *refinementService) questionBankContext(workspaceID string) string {
+	questions, err := s.ListQuestionBank(workspaceID)
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("workspace_id", workspaceID).Msg("Skipping question bank, failed to load it")
+		return ""
+	}
+	var lines []string
+	for _, q := range questions {
+		if q.CanonicalAnswer == "" || (!q.Curated && q.Occurrences < minBankOccurrences) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("\n- 問：%s\n  答：%s", q.Question, q.CanonicalAnswer))
+		if len(lines) == maxBankContextQuestions {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\n常見問題與標準答案（已有答案，請勿再次詢問）：" + strings.Join(lines, "")
+}
+
+// mineQuestionBank adds the baseline questions answered in a finalized session to its product's bank,
+// counting the questions the bank already has.
+func (s *refinementService) mineQuestionBank(ctx context.Context, session *domain.RefinementSession) {
+	if s.questionBank == nil || session.IsTutorial() || len(session.AnsweredQuestions) == 0 {
+		return
+	}
+	existing, err := s.questionBank.List(session.WorkspaceID)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to load question bank")
+		return
+	}
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to mine question bank")
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Existing bank:\n")
+	for _, q := range existing {
+		fmt.Fprintf(&b, "- [%s] %s\n", q.ID, q.Question)
+	}
+	b.WriteString("\nAnswered questions:\n")
+	for _, q := range session.AnsweredQuestions {
+		fmt.Fprintf(&b, "- (%s) Q: %s\n  A: %s\n", q.Role, q.Question, q.Answer)
+	}
+
+	raw, err := client.Complete(ctx, model, questionBankSystemPrompt, b.String())
+	if err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to mine question bank")
+		return
+	}
+	var mined []struct {
+		BankID   string `json:"bank_id"`
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &mined); err != nil {
+		logging.From(ctx).Warn().Err(err).Msg("Failed to parse question bank from AI")
+		if logging.Prompts() {
+			logging.From(ctx).Debug().Str("output", raw).Msg("Unparsable question bank output")
+		}
+		return
+	}
+
+	now := time.Now()
+	for i, m := range mined {
+		question, answer := strings.TrimSpace(m.Question), strings.TrimSpace(m.Answer)
+		if question == "" {
+			continue
+		}
+		j := slices.IndexFunc(existing, func(q domain.BankQuestion) bool { return m.BankID != "" && q.ID == m.BankID })
+		var bq domain.BankQuestion
+		if j >= 0 {
+			bq = existing[j]
+			if slices.Contains(bq.SourceSessionIDs, session.ID) {
+				continue // Refinalizing a session does not count it again
+			}
+			if !bq.Curated && answer != "" {
+				bq.CanonicalAnswer = answer // The latest answer wins until an admin curates it
+			}
+		} else {
+			bq = domain.BankQuestion{ID: fmt.Sprintf("bq-%d-%d", now.UnixNano(), i), WorkspaceID: session.WorkspaceID, Question: question, CanonicalAnswer: answer, CreatedAt: now}
+		}
+		bq.Occurrences++
+		bq.SourceSessionIDs = append(bq.SourceSessionIDs, session.ID)
+		bq.UpdatedAt = now
+		if err := s.questionBank.Save(bq); err != nil {
+			logging.From(ctx).Warn().Err(err).Str("bank_question_id", bq.ID).Msg("Failed to store bank question")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const questionInsightsSystemPrompt = `你負責分析審查者在打磨用戶故事時提出的釐清問題，作為團隊回顧會議的素材。
+請將問題歸納為反覆出現的主題，以及初始故事缺少的資訊類別（例如「錯誤處理」、「權限」、「效能目標」）。
+統計每個主題與類別包含的問題數，依出現次數由多到少排列，並各列出最多 3 個範例問題。
+只回傳 JSON：{"themes": [{"name": "...", "count": 3, "examples": ["..."]}], "missing_info": [{"name": "...", "count": 2, "examples": ["..."]}]}`
+
+// maxInsightQuestions caps the questions sent for classification to keep the prompt bounded.
+const maxInsightQuestions = 500
+
+// tallyAnswers counts the questions of the current round per role, and how many of them the answers
+// leave blank. Callers must hold sessionsMutex (e.g. call it inside mutateSession).
+func tallyAnswers(session *domain.RefinementSession, answers map[string]string) {
+	if session.RoleQuestionStats == nil {
+		session.RoleQuestionStats = make(map[string]domain.QuestionTally)
+	}
+	for _, q := range session.Questions {
+		tally := session.RoleQuestionStats[q.Role]
+		for _, p := range q.Prompt {
+			tally.Asked++
+			if strings.TrimSpace(answers[q.Role+"_"+p]) == "" {
+				tally.Unanswered++
+			}
+		}
+		session.RoleQuestionStats[q.Role] = tally
+	}
+}
+
+// ClassifyQuestions groups questions asked across sessions into themes and missing information
+// categories, using the AI provider of the given workspace.
+func (s *refinementService) ClassifyQuestions(ctx context.Context, workspaceID string, questions []string) (*domain.QuestionInsights, error) {
+	if len(questions) == 0 {
+		return &domain.QuestionInsights{Themes: []domain.InsightCount{}, MissingInfo: []domain.InsightCount{}}, nil
+	}
+	if len(questions) > maxInsightQuestions {
+		questions = questions[len(questions)-maxInsightQuestions:]
+	}
+	client, model, err := s.clientFor(workspaceID, "")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.Complete(ctx, model, questionInsightsSystemPrompt, "問題：\n- "+strings.Join(questions, "\n- "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify questions: %w", err)
+	}
+	var insights domain.QuestionInsights
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &insights); err != nil {
+		return nil, fmt.Errorf("failed to parse question insights from AI: %w, raw response: %s", err, raw)
+	}
+	return &insights, nil
+}
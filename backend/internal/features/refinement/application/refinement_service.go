@@ -1,49 +1,140 @@
 package application
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"sofa-commander/backend/internal/events"
 	configdomain "sofa-commander/backend/internal/features/config/domain"
+	projectapp "sofa-commander/backend/internal/features/project/application"
 	"sofa-commander/backend/internal/features/refinement/domain"
 	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	usageapp "sofa-commander/backend/internal/features/usage/application"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/offline"
+	"sofa-commander/backend/internal/readonly"
 )
 
+// defaultModel is used when neither the workspace nor the deployment configures a model.
+const defaultModel = "o4-mini"
+
 // In-memory store for sessions (for demonstration purposes)
 var sessions = make(map[string]*domain.RefinementSession)
 var sessionsMutex sync.RWMutex
 
 // RefinementService defines the interface for the refinement application service.
 type RefinementService interface {
-	StartSession(req *domain.RefinementRequest, productContext string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
-	SubmitAnswersAndContinue(sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
-	SubmitAnswersAndGetSuggestions(sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
-	AcceptSuggestions(sessionID string, acceptedSuggestions []domain.Suggestion, nextPhase string, additionalInfo string) (*domain.RefinementSession, []domain.Suggestion, error)
-	Finalize(sessionID string, currentPhase string, currentAnswers map[string]string, currentSuggestions []string, modificationSuggestion string) (string, []string, string, error)
+	StartSession(ctx context.Context, req *domain.RefinementRequest, productContext string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
+	SubmitAnswersAndContinue(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
+	SubmitAnswersAndGetSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error)
+	PrefetchSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (bool, error)
+	AcceptSuggestions(ctx context.Context, sessionID string, acceptedSuggestions []domain.Suggestion, nextPhase string, additionalInfo string) (*domain.RefinementSession, []domain.Suggestion, error)
+	Finalize(ctx context.Context, sessionID string, currentPhase string, currentAnswers map[string]string, currentSuggestions []string, modificationSuggestion string) (string, []string, string, error)
+	Resume(ctx context.Context, sessionID string) (*domain.RefinementSession, *domain.ResumeReport, error)
+	Fork(ctx context.Context, sessionID, userID string) (*domain.RefinementSession, error)
+	UndoRound(ctx context.Context, sessionID string) (*domain.RefinementSession, error)
+	GetSession(sessionID string) (*domain.RefinementSession, error)
+	ListSessions() []*domain.RefinementSession
+	CheckAnswer(ctx context.Context, req *domain.CheckAnswerRequest) (*domain.AnswerQualityHint, error)
+	Translate(ctx context.Context, sessionID, targetLanguage string) (*domain.TranslatedOutput, error)
+	ProposeEndpoints(ctx context.Context, sessionID string) ([]domain.EndpointStub, error)
+	RunOptionalPhase(ctx context.Context, sessionID, phase string) (*domain.RefinementSession, error)
+	AdvancePhase(ctx context.Context, sessionID string, req *domain.AdvancePhaseRequest) (*domain.RefinementSession, error)
+	CheckTerminology(sessionID string, glossary []configdomain.GlossaryTerm) ([]domain.TermFinding, error)
+	ApplyTermCorrections(sessionID string, glossary []configdomain.GlossaryTerm, corrections []domain.TermCorrection) (*domain.RefinementSession, error)
+	UpdateSession(sessionID string, update func(session *domain.RefinementSession)) (*domain.RefinementSession, error)
+	AddContext(ctx context.Context, sessionID, label, content string) error
+	ListMemory(workspaceID string) ([]domain.MemoryItem, error)
+	DeleteMemory(workspaceID, id string) error
+	ListQuestionBank(workspaceID string) ([]domain.BankQuestion, error)
+	SaveBankQuestion(workspaceID, id string, req *domain.BankQuestionRequest) (*domain.BankQuestion, error)
+	DeleteBankQuestion(workspaceID, id string) error
+	ListShadowRuns(shadowModel string) ([]domain.ShadowRun, error)
+	ScoreStory(ctx context.Context, sessionID string, rubric configdomain.ScoringRubric) (*domain.StoryScore, error)
+	ClassifyQuestions(ctx context.Context, workspaceID string, questions []string) (*domain.QuestionInsights, error)
+	AnalyzeStory(ctx context.Context, workspaceID, title, description string) (*domain.StoryAnalysis, error)
+	CheckConsistency(ctx context.Context, sessionID string) (*domain.ConsistencyReport, error)
+	ParseBulkAnswers(ctx context.Context, sessionID, content, format string) (*domain.BulkAnswers, error)
+	SessionTiming(sessionID string) (*domain.SessionTiming, error)
+	TimingReport(workspaceID, userID string) *domain.TimingReport
+	Diagnostics(ctx context.Context, sessionID string) (*domain.SessionDiagnostics, error)
+	PromptDrift() *domain.PromptDriftReport
+	Polish(ctx context.Context, sessionID, message string) (*domain.PolishResponse, error)
+	ApplyPolish(sessionID string) (*domain.RefinementSession, error)
+	WriteGherkin(ctx context.Context, sessionID string) (*domain.GherkinFeature, error)
+	WriteTestCases(ctx context.Context, sessionID string) (*domain.TestCaseSuite, error)
+	PreviewRole(ctx context.Context, req *domain.RolePreviewRequest, role, rolePrompt, productContext string) (*domain.RolePreview, error)
+	ConfigFor(workspaceID, projectID string, appConfig *configdomain.AppConfig) (*configdomain.AppConfig, error)
+}
+
+// WorkspaceMembers reports whether users may use workspaces, per the memberships provisioned by the
+// identity provider.
+type WorkspaceMembers interface {
+	CanUseWorkspace(userName, workspaceID string) bool
 }
 
 // refinementService is the implementation of RefinementService.
 type refinementService struct {
-	openaiClient infrastructure.OpenAIClient
-	assistantID  string // Store the assistant ID here
+	openaiClient     infrastructure.OpenAIClient // Default client used by sessions without a workspace
+	clientFactory    infrastructure.OpenAIClientFactory
+	workspaceService workspaceapp.WorkspaceService
+	members          WorkspaceMembers // May be nil
+	projectService   projectapp.ProjectService
+	usageService     usageapp.UsageService
+	publisher        events.Publisher
+	tools            infrastructure.ToolExecutor      // Function tools offered to the assistant, may be nil
+	memoryStore      infrastructure.MemoryStore       // Long-term memory per product, may be nil
+	questionBank     infrastructure.QuestionBankStore // Recurring questions per product, may be nil
+	shadowStore      infrastructure.ShadowRunStore    // Shadow model runs, may be nil
+	tutorialClient   infrastructure.OpenAIClient      // Scripted provider of tutorial sessions
 }
 
 // NewRefinementService creates a new instance of refinementService.
-func NewRefinementService(client infrastructure.OpenAIClient) RefinementService {
-	return &refinementService{openaiClient: client}
+func NewRefinementService(client infrastructure.OpenAIClient, clientFactory infrastructure.OpenAIClientFactory, workspaceService workspaceapp.WorkspaceService, members WorkspaceMembers, projectService projectapp.ProjectService, usageService usageapp.UsageService, publisher events.Publisher, tools infrastructure.ToolExecutor, memoryStore infrastructure.MemoryStore, questionBank infrastructure.QuestionBankStore, shadowStore infrastructure.ShadowRunStore, sessionRepository infrastructure.SessionRepository) RefinementService {
+	if sessionRepository != nil {
+		if err := loadSessions(sessionRepository); err != nil {
+			logging.Default().Error().Err(err).Msg("Failed to load stored sessions")
+		}
+	}
+	return &refinementService{
+		openaiClient:     client,
+		clientFactory:    clientFactory,
+		workspaceService: workspaceService,
+		members:          members,
+		projectService:   projectService,
+		usageService:     usageService,
+		publisher:        publisher,
+		tools:            tools,
+		memoryStore:      memoryStore,
+		questionBank:     questionBank,
+		shadowStore:      shadowStore,
+		tutorialClient:   infrastructure.NewTutorialClient(),
+	}
 }
 
 // StartSession starts a new refinement session by fetching questions from all roles concurrently.
-func (s *refinementService) StartSession(req *domain.RefinementRequest, productContext string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
-	log.Println("StartSession: Received request.")
-	userStory := req.InitialUserStory
+func (s *refinementService) StartSession(ctx context.Context, req *domain.RefinementRequest, productContext string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	logging.From(ctx).Debug().Str("workspace_id", req.WorkspaceID).Str("project_id", req.ProjectID).Msg("Starting session")
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(ctx)
+	userStory := storyToRefine(req)
+
+	client, model, err := s.clientFor(req.WorkspaceID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1. Get or Create Assistant
 	assistantName := "Refinement Assistant"
-	assistantInstructionsTemplate := `You are a multi-role requirement refinement assistant. Your goal is to help a Product Manager refine a user story.\n\nProduct Context: %s\n\nCurrent User Story to Refine: "%s"\n\nIMPORTANT GUIDELINES:\n1. All your questions and suggestions must be directly related to this specific user story\n2. Focus on clarifying implementation details, edge cases, and factors that could impact the successful delivery of THIS user story\n3. Consider the product context deeply - understand the target users, core values, and business goals\n4. Ask specific, actionable questions that can be answered with concrete information\n5. Provide suggestions that are measurable, implementable, and aligned with the product vision\n6. Avoid generic or theoretical questions/suggestions\n\nRoles:\n%s\n%s\n格式範例：%s\n請勿加上任何說明、標題或條列，僅回傳JSON。`
+	assistantInstructionsTemplate := `You are a multi-role requirement refinement assistant. Your goal is to help a Product Manager refine a user story.\n\nProduct Context: %s\n\nThe user story to refine is given in the first message of the conversation.\n\nIMPORTANT GUIDELINES:\n1. All your questions and suggestions must be directly related to this specific user story\n2. Focus on clarifying implementation details, edge cases, and factors that could impact the successful delivery of THIS user story\n3. Consider the product context deeply - understand the target users, core values, and business goals\n4. Ask specific, actionable questions that can be answered with concrete information\n5. Provide suggestions that are measurable, implementable, and aligned with the product vision\n6. Avoid generic or theoretical questions/suggestions\n\nRoles:\n%s\n%s\n格式範例：%s\n請勿加上任何說明、標題或條列，僅回傳JSON。`
 	// 只針對 selectedRoles 組合角色角度
 	selectedRoles := req.SelectedRoles
 	rolePromptsString := ""
@@ -52,12 +143,13 @@ func (s *refinementService) StartSession(req *domain.RefinementRequest, productC
 			rolePromptsString += fmt.Sprintf("- %s: %s\n", role, prompt)
 		}
 	}
+	rolePromptsString += roleWeightNote(req.SelectedRoles, req.RoleWeights)
 	// 組合階段說明
 	phaseDesc := ""
 	if phasePrompts != nil {
 		phaseDesc = ""
 		if len(selectedRoles) > 0 {
-			phaseDesc = phasePrompts["questioning"]
+			phaseDesc = questioningPrompt(phasePrompts, 1)
 		}
 	}
 	// 組合格式範例
@@ -77,32 +169,50 @@ func (s *refinementService) StartSession(req *domain.RefinementRequest, productC
 			formatExample = string(b)
 		}
 	}
-	assistantInstructions := fmt.Sprintf(assistantInstructionsTemplate, productContext, userStory, rolePromptsString, phaseDesc, formatExample)
+	productContext += s.memoryContext(req.WorkspaceID) + s.questionBankContext(req.WorkspaceID)
+	// The story is left out of the instructions and sent as the first message, so sessions with the same
+	// configuration share an assistant rather than creating one per story
+	assistantInstructions := fmt.Sprintf(assistantInstructionsTemplate, productContext, rolePromptsString, phaseDesc, formatExample)
 
-	assistantID, err := s.openaiClient.GetOrCreateAssistant(assistantName, assistantInstructions, "o4-mini") // Hardcoding model for now
+	// Sessions with the same instructions share an assistant, others never clobber each other's
+	assistantID, err := client.GetOrCreateAssistant(ctx, assistantName+" "+instructionsHash(assistantInstructions, model), assistantInstructions, model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get or create assistant: %w", err)
 	}
-	s.assistantID = assistantID // Store for later use
+
+	sessionID := nextSessionID()
+	tags := costTags{SessionID: sessionID, WorkspaceID: req.WorkspaceID, UserID: req.UserID}
+	sentInstructions.Store(sessionID, assistantInstructions)
 
 	// 2. Create Thread
-	threadID, err := s.openaiClient.CreateThread()
+	threadID, err := client.CreateThread(ctx, tags.metadata("start"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create thread: %w", err)
 	}
+	ctx = logging.With(ctx, "session_id", sessionID, "thread_id", threadID)
 
 	// 3. Add initial User Story message to thread
-	if err := s.openaiClient.AddMessageToThread(threadID, assistantInstructions); err != nil {
+	initialMessage := assistantInstructions + fmt.Sprintf("\n\nCurrent User Story to Refine: \"%s\"", userStory)
+	if note := roundBudgetNote(1, req.TargetRounds); note != "" {
+		initialMessage += "\n" + note
+	}
+	if req.Language != "" {
+		initialMessage += fmt.Sprintf("\n請以 %s 撰寫所有問題與建議。", req.Language)
+	}
+	if note := roleLimitsNote(req.SelectedRoles, req.RoleLimits); note != "" {
+		initialMessage += "\n" + note
+	}
+	if err := client.AddMessageToThread(ctx, threadID, initialMessage); err != nil {
 		return nil, fmt.Errorf("failed to add initial message to thread: %w", err)
 	}
 
 	// Run Assistant to get initial questions
-	if err := s.openaiClient.RunAssistant(threadID, assistantID); err != nil {
+	if err := s.runAssistant(ctx, client, threadID, assistantID, tags, "start", budgetFor(req, "start"), roleItemsSchema); err != nil {
 		return nil, fmt.Errorf("failed to run assistant for initial questions: %w", err)
 	}
 
 	// Get Assistant's response (initial questions)
-	assistantMessages, err := s.openaiClient.GetAssistantResponse(threadID)
+	assistantMessages, err := client.GetAssistantResponse(ctx, threadID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assistant response for initial questions: %w", err)
 	}
@@ -117,7 +227,7 @@ func (s *refinementService) StartSession(req *domain.RefinementRequest, productC
 				rawJSON = strings.TrimPrefix(rawJSON, "```json\n")
 				rawJSON = strings.TrimSuffix(rawJSON, "\n```")
 			}
-			fmt.Println("[DEBUG] AI raw response:", rawJSON)
+			rawJSON = s.checkOutput(ctx, client, threadID, assistantID, tags, "start", req, false, rawJSON)
 			err = json.Unmarshal([]byte(rawJSON), &questions)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse initial questions from AI: %w, raw response: %s", err, rawJSON)
@@ -126,52 +236,88 @@ func (s *refinementService) StartSession(req *domain.RefinementRequest, productC
 	}
 
 	session := &domain.RefinementSession{
-		ID:                  fmt.Sprintf("session-%d", len(sessions)+1), // Generate a simple unique ID
+		ID:                  sessionID,
 		ThreadID:            threadID,
+		AssistantID:         assistantID,
+		WorkspaceID:         req.WorkspaceID,
+		ProjectID:           req.ProjectID,
+		UserID:              req.UserID,
+		CreatedAt:           time.Now(),
+		TranscriptMirroring: req.AllowTranscriptMirroring,
 		Request:             *req,
 		UserStory:           userStory,
 		RolePrompts:         rolePrompts, // Store role prompts
 		PhasePrompts:        phasePrompts,
 		PhaseFormatExamples: phaseFormatExamples,
 		Questions:           questions,
-		Phase:               domain.PhaseQuestioning,           // Set initial phase
+		Phase:               domain.PhaseQuestioning, // Set initial phase
+		CurrentRound:        1,
+		TargetRounds:        req.TargetRounds,
+		RoleWeights:         req.RoleWeights,
 		History:             []string{"[初始用戶故事] " + userStory}, // Keep history for our own reference/logging
 	}
+	recordTiming(session, "start", requestedAt)
+	updateConvergence(session, questions)
+	attachPriorAnswers(sessionID, req.WorkspaceID, session.Questions)
+	shadow := session.Clone()
+	shadow.Questions = nil // The shadow model answers the same opening instruction on its own
+	s.shadowRound(ctx, shadow, "start", initialMessage, questions)
+
+	session.Warnings = warnings.list()
+	if err := storeSession(session); err != nil {
+		return nil, err
+	}
 
-	sessionsMutex.Lock()
-	sessions[session.ID] = session
-	sessionsMutex.Unlock()
-
-	log.Println("StartSession: Returning session.")
+	logging.From(ctx).Info().Int("questions", len(session.Questions)).Msg("Started session")
+	s.trackPromptDrift(ctx, session, "start")
+	s.publish(events.SessionStarted, session, nil)
 	return session, nil
 }
 
 // SubmitAnswersAndContinue updates the session with answers and generates new questions.
-func (s *refinementService) SubmitAnswersAndContinue(sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
-	sessionsMutex.RLock()
-	session, ok := sessions[sessionID]
-	sessionsMutex.RUnlock()
-	if !ok {
-		return nil, fmt.Errorf("session %s not found", sessionID)
+func (s *refinementService) SubmitAnswersAndContinue(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
 	}
+	if err := checkTransition(session, domain.PhaseQuestioning); err != nil {
+		return nil, err
+	}
+	checkpoint := roundCheckpoint(session, "submit_answers_and_continue")
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	s.takePrefetch(ctx, session, "")
+	phasePrompts = sessionPhasePrompts(session, phasePrompts)
 
-	// Update session with answers
-	sessionsMutex.Lock()
-	defer sessionsMutex.Unlock()
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	assistantID := session.AssistantID
 
+	// Update session with answers
 	userResponse := ""
-	for i := range session.Questions {
-		for _, p := range session.Questions[i].Prompt {
-			key := session.Questions[i].Role + "_" + p
-			if ans, found := answers[key]; found {
-				session.Questions[i].Answer = ans
-				userResponse += fmt.Sprintf("PM Answer to %s's question \"%s\": %s\n", session.Questions[i].Role, p, ans)
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		for i := range session.Questions {
+			for _, p := range session.Questions[i].Prompt {
+				key := session.Questions[i].Role + "_" + p
+				if ans, found := answers[key]; found {
+					session.Questions[i].Answer = ans
+					userResponse += fmt.Sprintf("PM Answer to %s's question \"%s\": %s\n", session.Questions[i].Role, p, ans)
+				}
 			}
 		}
+		tallyAnswers(session, answers)
+		recordAnswers(session, answers)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if strings.TrimSpace(userResponse) != "" {
-		if err := s.openaiClient.AddMessageToThread(session.ThreadID, userResponse); err != nil {
+		if err := client.AddMessageToThread(ctx, session.ThreadID, userResponse); err != nil {
 			return nil, fmt.Errorf("failed to add user response to thread: %w", err)
 		}
 	}
@@ -185,11 +331,12 @@ func (s *refinementService) SubmitAnswersAndContinue(sessionID string, answers m
 			rolePromptsString += fmt.Sprintf("- %s: %s\n", role, prompt)
 		}
 	}
+	rolePromptsString += roleWeightNote(selectedRoles, session.Request.RoleWeights)
 	phaseDesc := ""
 	if phasePrompts != nil {
 		phaseDesc = ""
 		if len(selectedRoles) > 0 {
-			phaseDesc = phasePrompts["questioning"]
+			phaseDesc = questioningPrompt(phasePrompts, session.CurrentRound+1)
 		}
 	}
 	formatExample := ""
@@ -210,22 +357,25 @@ func (s *refinementService) SubmitAnswersAndContinue(sessionID string, answers m
 
 	// 組合完整的指令，包含補充資訊
 	instructionMessage := "基於當前的 User Story 和對話歷史，請根據下列角色角度：\n" + rolePromptsString + "\n" + phaseDesc + "\n格式範例：" + formatExample + "\n請勿加上任何說明、標題或條列，僅回傳 JSON 陣列。"
+	if note := roundBudgetNote(session.CurrentRound+1, session.TargetRounds); note != "" {
+		instructionMessage = note + "\n" + instructionMessage
+	}
 
 	// 如果有補充資訊，整合到指令中
 	if strings.TrimSpace(additionalInfo) != "" {
 		instructionMessage = "補充資訊：\n" + additionalInfo + "\n\n" + instructionMessage
 	}
-	if err := s.openaiClient.AddMessageToThread(session.ThreadID, instructionMessage); err != nil {
+	if err := client.AddMessageToThread(ctx, session.ThreadID, instructionMessage); err != nil {
 		return nil, fmt.Errorf("failed to add instruction message to thread: %w", err)
 	}
 
 	// Run Assistant to get new questions
-	if err := s.openaiClient.RunAssistant(session.ThreadID, s.assistantID); err != nil {
+	if err := s.runAssistant(ctx, client, session.ThreadID, assistantID, tagsFor(session), "submit_answers_and_continue", budgetFor(&session.Request, "submit_answers_and_continue"), roleItemsSchema); err != nil {
 		return nil, fmt.Errorf("failed to run assistant for new questions: %w", err)
 	}
 
 	// Get Assistant's response (new questions)
-	assistantMessages, err := s.openaiClient.GetAssistantResponse(session.ThreadID)
+	assistantMessages, err := client.GetAssistantResponse(ctx, session.ThreadID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assistant response for new questions: %w", err)
 	}
@@ -240,7 +390,7 @@ func (s *refinementService) SubmitAnswersAndContinue(sessionID string, answers m
 				rawJSON = strings.TrimPrefix(rawJSON, "```json\n")
 				rawJSON = strings.TrimSuffix(rawJSON, "\n```")
 			}
-			fmt.Println("[DEBUG] AI raw response:", rawJSON)
+			rawJSON = s.checkOutput(ctx, client, session.ThreadID, assistantID, tagsFor(session), "submit_answers_and_continue", &session.Request, false, rawJSON)
 			err = json.Unmarshal([]byte(rawJSON), &newQuestions)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse new questions from AI: %w, raw response: %s", err, rawJSON)
@@ -248,38 +398,100 @@ func (s *refinementService) SubmitAnswersAndContinue(sessionID string, answers m
 		}
 	}
 
-	session.Questions = newQuestions // Replace old questions with new ones
-	// Keep phase as QUESTIONING
-
+	s.shadowRound(ctx, session, "submit_answers_and_continue", instructionMessage, newQuestions)
+	attachPriorAnswers(sessionID, session.WorkspaceID, newQuestions)
+
+	var askedQuestions, history []string
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		recordTiming(session, "submit_answers_and_continue", requestedAt)
+		session.Warnings = warnings.list()
+		pushCheckpoint(session, checkpoint)
+		updateConvergence(session, newQuestions)
+		session.Questions = newQuestions // Replace old questions with new ones
+		session.CurrentRound++
+		// Keep phase as QUESTIONING
+		askedQuestions, history = pruneSession(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishPruned(session, askedQuestions, history)
+	s.trackPromptDrift(ctx, session, "submit_answers_and_continue")
+	s.publish(events.QuestionsGenerated, session, map[string]any{"round": session.CurrentRound})
 	return session, nil
 }
 
 // SubmitAnswersAndGetSuggestions updates the session with answers and generates suggestions.
-func (s *refinementService) SubmitAnswersAndGetSuggestions(sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
-	sessionsMutex.RLock()
-	session, ok := sessions[sessionID]
-	sessionsMutex.RUnlock()
-	if !ok {
-		return nil, fmt.Errorf("session %s not found", sessionID)
+func (s *refinementService) SubmitAnswersAndGetSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(session, domain.PhaseSuggesting); err != nil {
+		return nil, err
+	}
+	checkpoint := roundCheckpoint(session, "submit_answers_and_get_suggestions")
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+
+	suggestions, prefetched := s.takePrefetch(ctx, session, prefetchKey(answers, additionalInfo))
+	if !prefetched {
+		suggestions, err = s.generateSuggestions(ctx, session, answers, additionalInfo, rolePrompts, phasePrompts, phaseFormatExamples, "submit_answers_and_get_suggestions")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Update session with answers
-	sessionsMutex.Lock()
-	defer sessionsMutex.Unlock()
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		recordTiming(session, "submit_answers_and_get_suggestions", requestedAt)
+		session.Warnings = warnings.list()
+		pushCheckpoint(session, checkpoint)
+		session.Suggestions = suggestions
+		session.Questions = nil                // Clear questions once suggestions are generated
+		session.Phase = domain.PhaseSuggesting // Change phase to SUGGESTING
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.trackPromptDrift(ctx, session, "submit_answers_and_get_suggestions")
+	s.publish(events.SuggestionsGenerated, session, nil)
+	return session, nil
+}
 
+// generateSuggestions records the PM's answers and runs the suggesting phase on the session's thread,
+// returning the parsed suggestions without applying them to the session.
+func (s *refinementService) generateSuggestions(ctx context.Context, session *domain.RefinementSession, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample, operation string) ([]domain.Suggestion, error) {
+	phasePrompts = sessionPhasePrompts(session, phasePrompts)
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	assistantID := session.AssistantID
+
+	// Update session with answers
 	userResponse := ""
-	for i := range session.Questions {
-		for _, p := range session.Questions[i].Prompt {
-			key := session.Questions[i].Role + "_" + p
-			if ans, found := answers[key]; found {
-				session.Questions[i].Answer = ans
-				userResponse += fmt.Sprintf("PM Answer to %s's question \"%s\": %s\n", session.Questions[i].Role, p, ans)
+	session, err = mutateSession(session.ID, func(session *domain.RefinementSession) {
+		for i := range session.Questions {
+			for _, p := range session.Questions[i].Prompt {
+				key := session.Questions[i].Role + "_" + p
+				if ans, found := answers[key]; found {
+					session.Questions[i].Answer = ans
+					userResponse += fmt.Sprintf("PM Answer to %s's question \"%s\": %s\n", session.Questions[i].Role, p, ans)
+				}
 			}
 		}
+		tallyAnswers(session, answers)
+		recordAnswers(session, answers)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if strings.TrimSpace(userResponse) != "" {
-		if err := s.openaiClient.AddMessageToThread(session.ThreadID, userResponse); err != nil {
+		if err := client.AddMessageToThread(ctx, session.ThreadID, userResponse); err != nil {
 			return nil, fmt.Errorf("failed to add user response to thread: %w", err)
 		}
 	}
@@ -293,6 +505,7 @@ func (s *refinementService) SubmitAnswersAndGetSuggestions(sessionID string, ans
 			rolePromptsString += fmt.Sprintf("- %s: %s\n", role, prompt)
 		}
 	}
+	rolePromptsString += roleWeightNote(selectedRoles, session.Request.RoleWeights)
 	phaseDesc := ""
 	if phasePrompts != nil {
 		phaseDesc = ""
@@ -323,17 +536,20 @@ func (s *refinementService) SubmitAnswersAndGetSuggestions(sessionID string, ans
 	if strings.TrimSpace(additionalInfo) != "" {
 		instructionMessage = "補充資訊：\n" + additionalInfo + "\n\n" + instructionMessage
 	}
-	if err := s.openaiClient.AddMessageToThread(session.ThreadID, instructionMessage); err != nil {
+	if err := client.AddMessageToThread(ctx, session.ThreadID, instructionMessage); err != nil {
 		return nil, fmt.Errorf("failed to add instruction message to thread: %w", err)
 	}
 
+	ensemble := s.startEnsemble(ctx, client, model, session, instructionMessage)
+	localization := s.startLocalizationAnalysis(ctx, client, model, session)
+
 	// Run Assistant to get suggestions
-	if err := s.openaiClient.RunAssistant(session.ThreadID, s.assistantID); err != nil {
+	if err := s.runAssistant(ctx, client, session.ThreadID, assistantID, tagsFor(session), operation, budgetFor(&session.Request, operation), roleItemsSchema); err != nil {
 		return nil, fmt.Errorf("failed to run assistant for suggestions: %w", err)
 	}
 
 	// Get Assistant's response (suggestions)
-	assistantMessages, err := s.openaiClient.GetAssistantResponse(session.ThreadID)
+	assistantMessages, err := client.GetAssistantResponse(ctx, session.ThreadID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assistant response for suggestions: %w", err)
 	}
@@ -348,7 +564,7 @@ func (s *refinementService) SubmitAnswersAndGetSuggestions(sessionID string, ans
 				rawJSON = strings.TrimPrefix(rawJSON, "```json\n")
 				rawJSON = strings.TrimSuffix(rawJSON, "\n```")
 			}
-			fmt.Println("[DEBUG] AI raw response:", rawJSON)
+			rawJSON = s.checkOutput(ctx, client, session.ThreadID, assistantID, tagsFor(session), operation, &session.Request, true, rawJSON)
 			err = json.Unmarshal([]byte(rawJSON), &suggestions)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse suggestions from AI: %w, raw response: %s", err, rawJSON)
@@ -356,21 +572,35 @@ func (s *refinementService) SubmitAnswersAndGetSuggestions(sessionID string, ans
 		}
 	}
 
-	session.Suggestions = suggestions
-	session.Questions = nil                // Clear questions once suggestions are generated
-	session.Phase = domain.PhaseSuggesting // Change phase to SUGGESTING
-
-	return session, nil
+	s.shadowRound(ctx, session, operation, instructionMessage, suggestions)
+	return scrubSuggestions(session.Request.OutputFilter, localization.appendTo(ctx, ensemble.merge(ctx, suggestions))), nil
 }
 
 // AcceptSuggestions accepts suggestions and starts a new refinement round.
-func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggestions []domain.Suggestion, nextPhase string, additionalInfo string) (*domain.RefinementSession, []domain.Suggestion, error) {
-	sessionsMutex.RLock()
-	session, ok := sessions[sessionID]
-	sessionsMutex.RUnlock()
-	if !ok {
-		return nil, nil, fmt.Errorf("session %s not found", sessionID)
+func (s *refinementService) AcceptSuggestions(ctx context.Context, sessionID string, acceptedSuggestions []domain.Suggestion, nextPhase string, additionalInfo string) (*domain.RefinementSession, []domain.Suggestion, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := domain.PhaseQuestioning
+	if nextPhase == "suggesting" {
+		next = domain.PhaseSuggesting
+	}
+	if err := checkTransition(session, next); err != nil {
+		return nil, nil, err
+	}
+	checkpoint := roundCheckpoint(session, "accept_suggestions")
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	s.takePrefetch(ctx, session, "")
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, nil, err
 	}
+	assistantID := session.AssistantID
 
 	// 將被採納的建議組合成新 context，送給 AI 產生新一輪問題
 	acceptedText := "[採納建議] \n"
@@ -385,7 +615,7 @@ func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggesti
 	}
 
 	// 這裡直接 append 建議內容到 thread
-	if err := s.openaiClient.AddMessageToThread(session.ThreadID, acceptedText); err != nil {
+	if err := client.AddMessageToThread(ctx, session.ThreadID, acceptedText); err != nil {
 		return nil, nil, fmt.Errorf("failed to add accepted suggestions to thread: %w", err)
 	}
 
@@ -405,9 +635,13 @@ func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggesti
 			rolePromptsString += fmt.Sprintf("- %s: %s\n", role, prompt)
 		}
 	}
+	rolePromptsString += roleWeightNote(session.Request.SelectedRoles, session.Request.RoleWeights)
 	phaseDesc := ""
 	if session.PhasePrompts != nil {
 		phaseDesc = session.PhasePrompts[phaseKey]
+		if setQuestions {
+			phaseDesc = questioningPrompt(session.PhasePrompts, session.CurrentRound+1)
+		}
 	}
 	formatExample := ""
 	if arr, ok := session.PhaseFormatExamples[phaseKey]; ok {
@@ -433,25 +667,36 @@ func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggesti
 			instructionMessage = "基於當前的 User Story 和對話歷史，請給我下輪提問，僅回傳 JSON 陣列。"
 		}
 	}
+	if setQuestions {
+		if note := roundBudgetNote(session.CurrentRound+1, session.TargetRounds); note != "" {
+			instructionMessage = note + "\n" + instructionMessage
+		}
+	}
 
 	// 如果有補充資訊，整合到指令中
 	if strings.TrimSpace(additionalInfo) != "" {
 		instructionMessage = "補充資訊：\n" + additionalInfo + "\n\n" + instructionMessage
 	}
-	if err := s.openaiClient.AddMessageToThread(session.ThreadID, instructionMessage); err != nil {
+	if err := client.AddMessageToThread(ctx, session.ThreadID, instructionMessage); err != nil {
 		return nil, nil, fmt.Errorf("failed to add instruction message to thread: %w", err)
 	}
 
+	var ensemble *ensembleRun
+	if !setQuestions {
+		ensemble = s.startEnsemble(ctx, client, model, session, instructionMessage)
+	}
+
 	// Run Assistant to get new questions or suggestions
-	if err := s.openaiClient.RunAssistant(session.ThreadID, s.assistantID); err != nil {
+	if err := s.runAssistant(ctx, client, session.ThreadID, assistantID, tagsFor(session), "accept_suggestions", budgetFor(&session.Request, "accept_suggestions"), roleItemsSchema); err != nil {
 		return nil, nil, fmt.Errorf("failed to run assistant for new round: %w", err)
 	}
 
-	assistantMessages, err := s.openaiClient.GetAssistantResponse(session.ThreadID)
+	assistantMessages, err := client.GetAssistantResponse(ctx, session.ThreadID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get assistant response for new round: %w", err)
 	}
 
+	var askedQuestions, history []string
 	if setQuestions {
 		var newQuestions []domain.Question
 		if len(assistantMessages) > 0 {
@@ -462,18 +707,26 @@ func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggesti
 					rawJSON = strings.TrimPrefix(rawJSON, "```json\n")
 					rawJSON = strings.TrimSuffix(rawJSON, "\n```")
 				}
-				fmt.Println("[DEBUG] AI raw response:", rawJSON)
+				rawJSON = s.checkOutput(ctx, client, session.ThreadID, assistantID, tagsFor(session), "accept_suggestions", &session.Request, false, rawJSON)
 				err = json.Unmarshal([]byte(rawJSON), &newQuestions)
 				if err != nil {
 					return nil, nil, fmt.Errorf("failed to parse new questions from AI: %w, raw response: %s", err, rawJSON)
 				}
 			}
 		}
-		sessionsMutex.Lock()
-		session.Questions = newQuestions
-		session.Suggestions = nil
-		session.Phase = domain.PhaseQuestioning
-		sessionsMutex.Unlock()
+		attachPriorAnswers(sessionID, session.WorkspaceID, newQuestions)
+		session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+			recordTiming(session, "accept_suggestions", requestedAt)
+			session.Warnings = warnings.list()
+			pushCheckpoint(session, checkpoint)
+			recordSuggestions(session, acceptedSuggestions)
+			updateConvergence(session, newQuestions)
+			session.Questions = newQuestions
+			session.Suggestions = nil
+			session.Phase = domain.PhaseQuestioning
+			session.CurrentRound++
+			askedQuestions, history = pruneSession(session)
+		})
 	} else {
 		var newSuggestions []domain.Suggestion
 		if len(assistantMessages) > 0 {
@@ -484,34 +737,58 @@ func (s *refinementService) AcceptSuggestions(sessionID string, acceptedSuggesti
 					rawJSON = strings.TrimPrefix(rawJSON, "```json\n")
 					rawJSON = strings.TrimSuffix(rawJSON, "\n```")
 				}
-				fmt.Println("[DEBUG] AI raw response:", rawJSON)
+				rawJSON = s.checkOutput(ctx, client, session.ThreadID, assistantID, tagsFor(session), "accept_suggestions", &session.Request, true, rawJSON)
 				err = json.Unmarshal([]byte(rawJSON), &newSuggestions)
 				if err != nil {
 					return nil, nil, fmt.Errorf("failed to parse new suggestions from AI: %w, raw response: %s", err, rawJSON)
 				}
 			}
 		}
-		sessionsMutex.Lock()
-		session.Questions = nil
-		session.Suggestions = newSuggestions
-		session.Phase = domain.PhaseSuggesting
-		sessionsMutex.Unlock()
+		newSuggestions = scrubSuggestions(session.Request.OutputFilter, ensemble.merge(ctx, newSuggestions))
+		session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+			recordTiming(session, "accept_suggestions", requestedAt)
+			session.Warnings = warnings.list()
+			pushCheckpoint(session, checkpoint)
+			recordSuggestions(session, acceptedSuggestions)
+			session.Questions = nil
+			session.Suggestions = newSuggestions
+			session.Phase = domain.PhaseSuggesting
+		})
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
+	s.publishPruned(session, askedQuestions, history)
+	s.trackPromptDrift(ctx, session, "accept_suggestions")
+	s.publish(events.SuggestionsAccepted, session, map[string]any{"accepted": len(acceptedSuggestions), "next_phase": string(session.Phase)})
 	return session, acceptedSuggestions, nil
 }
 
 // Finalize 產生 user story + AC
-func (s *refinementService) Finalize(sessionID string, currentPhase string, currentAnswers map[string]string, currentSuggestions []string, modificationSuggestion string) (string, []string, string, error) {
-	sessionsMutex.RLock()
-	session, ok := sessions[sessionID]
-	sessionsMutex.RUnlock()
-	if !ok {
-		return "", nil, "", fmt.Errorf("session %s not found", sessionID)
+func (s *refinementService) Finalize(ctx context.Context, sessionID string, currentPhase string, currentAnswers map[string]string, currentSuggestions []string, modificationSuggestion string) (string, []string, string, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return "", nil, "", err
 	}
+	if err := checkTransition(session, domain.PhaseFinalizing); err != nil {
+		return "", nil, "", err
+	}
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	s.takePrefetch(ctx, session, "")
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return "", nil, "", err
+	}
+	assistantID := session.AssistantID
 
 	// 1. 先將當前數據加入到 thread
-	if currentPhase == "QUESTIONING" && len(currentAnswers) > 0 {
+	var acceptedSuggestions []domain.Suggestion
+	if phaseOutput(session, currentPhase) == configdomain.WorkflowOutputQuestions && len(currentAnswers) > 0 {
 		// 將當前回答加入到 thread
 		userResponse := ""
 		for i := range session.Questions {
@@ -523,11 +800,11 @@ func (s *refinementService) Finalize(sessionID string, currentPhase string, curr
 			}
 		}
 		if strings.TrimSpace(userResponse) != "" {
-			if err := s.openaiClient.AddMessageToThread(session.ThreadID, userResponse); err != nil {
+			if err := client.AddMessageToThread(ctx, session.ThreadID, userResponse); err != nil {
 				return "", nil, "", fmt.Errorf("failed to add current answers to thread: %w", err)
 			}
 		}
-	} else if currentPhase == "SUGGESTING" && len(currentSuggestions) > 0 {
+	} else if phaseOutput(session, currentPhase) == configdomain.WorkflowOutputSuggestions && len(currentSuggestions) > 0 {
 		// 將當前採納的建議加入到 thread
 		acceptedText := "[採納建議] \n"
 		for _, suggestionKey := range currentSuggestions {
@@ -536,11 +813,12 @@ func (s *refinementService) Finalize(sessionID string, currentPhase string, curr
 				for _, p := range s.Prompt {
 					if s.Role+"_"+p == suggestionKey {
 						acceptedText += fmt.Sprintf("- %s: %s\n", s.Role, p)
+						acceptedSuggestions = append(acceptedSuggestions, domain.Suggestion{Role: s.Role, Prompt: []string{p}})
 					}
 				}
 			}
 		}
-		if err := s.openaiClient.AddMessageToThread(session.ThreadID, acceptedText); err != nil {
+		if err := client.AddMessageToThread(ctx, session.ThreadID, acceptedText); err != nil {
 			return "", nil, "", fmt.Errorf("failed to add current suggestions to thread: %w", err)
 		}
 	}
@@ -548,7 +826,7 @@ func (s *refinementService) Finalize(sessionID string, currentPhase string, curr
 	// 如果有修改建議，加入到 thread
 	if strings.TrimSpace(modificationSuggestion) != "" {
 		message := "[修改建議]\n" + modificationSuggestion
-		if err := s.openaiClient.AddMessageToThread(session.ThreadID, message); err != nil {
+		if err := client.AddMessageToThread(ctx, session.ThreadID, message); err != nil {
 			return "", nil, "", fmt.Errorf("failed to add modification suggestion to thread: %w", err)
 		}
 	}
@@ -588,13 +866,17 @@ func (s *refinementService) Finalize(sessionID string, currentPhase string, curr
 3. 驗收標準3（具體、可測量）
 4. 驗收標準4（具體、可測量）
 5. 驗收標準5（具體、可測量）`
-	if err := s.openaiClient.AddMessageToThread(session.ThreadID, prompt); err != nil {
+	story, criteria := storyHeading, criteriaHeading
+	if typePrompt, typeStory, typeCriteria, ok := finalizeFormat(session); ok {
+		prompt, story, criteria = typePrompt, typeStory, typeCriteria
+	}
+	if err := client.AddMessageToThread(ctx, session.ThreadID, prompt); err != nil {
 		return "", nil, "", fmt.Errorf("failed to add finalize prompt to thread: %w", err)
 	}
-	if err := s.openaiClient.RunAssistant(session.ThreadID, s.assistantID); err != nil {
+	if err := s.runAssistant(ctx, client, session.ThreadID, assistantID, tagsFor(session), "finalize", budgetFor(&session.Request, "finalize"), finalOutputSchema); err != nil {
 		return "", nil, "", fmt.Errorf("failed to run assistant for finalize: %w", err)
 	}
-	assistantMessages, err := s.openaiClient.GetAssistantResponse(session.ThreadID)
+	assistantMessages, err := client.GetAssistantResponse(ctx, session.ThreadID)
 	if err != nil {
 		return "", nil, "", fmt.Errorf("failed to get assistant response for finalize: %w", err)
 	}
@@ -603,35 +885,267 @@ func (s *refinementService) Finalize(sessionID string, currentPhase string, curr
 	}
 	raw := assistantMessages[len(assistantMessages)-1].Content[0].Text.Value
 
-	// 解析純文字格式
-	userStory := ""
-	ac := []string{}
-
-	// 尋找【用戶故事】和【驗收標準】標記
-	userStoryStart := strings.Index(raw, "【用戶故事】")
-	userStoryEnd := strings.Index(raw, "【驗收標準】")
-
-	if userStoryStart != -1 && userStoryEnd != -1 {
-		// 提取用戶故事
-		userStory = strings.TrimSpace(raw[userStoryStart+len("【用戶故事】") : userStoryEnd])
-
-		// 提取驗收標準
-		acSection := raw[userStoryEnd+len("【驗收標準】"):]
-		lines := strings.Split(acSection, "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" && (strings.HasPrefix(line, "1.") || strings.HasPrefix(line, "2.") || strings.HasPrefix(line, "3.") || strings.HasPrefix(line, "4.") || strings.HasPrefix(line, "5.")) {
-				// 移除數字前綴
-				acItem := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(line, "1."), "2."), "3."), "4."), "5."))
-				if acItem != "" {
-					ac = append(ac, acItem)
-				}
-			}
+	// 解析輸出（結構化 JSON，或帶有【用戶故事】和【驗收標準】等標記的純文字）
+	userStory, ac, ok := parseFinalOutput(raw, story, criteria)
+	if !ok {
+		// 格式不符時要求重新輸出一次
+		logging.From(ctx).Warn().Msg("Finalize output has neither the JSON nor the text format, asking for a correction")
+		correction := fmt.Sprintf("你上一次的回覆格式不正確。請依照要求重新輸出，包含「%s」與「%s」兩個段落，不要加上任何說明。", story, criteria)
+		if err := client.AddMessageToThread(ctx, session.ThreadID, correction); err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to request finalize correction")
+		} else if err := s.runAssistant(ctx, client, session.ThreadID, assistantID, tagsFor(session), "finalize_correction", budgetFor(&session.Request, "finalize"), finalOutputSchema); err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to run finalize correction")
+		} else if messages, err := client.GetAssistantResponse(ctx, session.ThreadID); err == nil && len(messages) > 0 && len(messages[len(messages)-1].Content) > 0 {
+			raw = messages[len(messages)-1].Content[0].Text.Value
+			userStory, ac, ok = parseFinalOutput(raw, story, criteria)
 		}
-	} else {
+	}
+	if !ok {
 		// fallback: 如果找不到標記，直接回傳原始內容作為用戶故事
-		userStory = raw
+		userStory, ac = raw, []string{}
+		addWarning(ctx, domain.WarningOutputUnparsed, "最終輸出的格式不正確，已直接以 AI 的原始回覆作為用戶故事")
+	} else if json.Valid([]byte(stripCodeFence(raw))) {
+		raw = formatFinalOutput(userStory, ac, story, criteria)
+	} else {
+		for _, dropped := range droppedCriteria(raw, criteria) {
+			addWarning(ctx, domain.WarningACDropped, "驗收標準未能解析而被捨棄：%s", truncate(strings.TrimSpace(dropped), 40))
+		}
+	}
+	userStory, ac, raw = s.filterFinalOutput(ctx, client, session, story, criteria, userStory, ac, raw)
+
+	s.shadowRound(ctx, session, "finalize", prompt, map[string]any{"user_story": userStory, "ac": ac})
+
+	// API features also get proposed endpoint stubs alongside their AC
+	var endpointStubs []domain.EndpointStub
+	if session.Request.HasTag(domain.TagAPI) {
+		endpointStubs, err = proposeEndpoints(ctx, client, model, userStory, ac)
+		if err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to propose endpoints")
+			addWarning(ctx, domain.WarningStepFailed, "產生 API 端點建議失敗，已略過")
+		}
+	}
+	phaseOutputs := runRequestedPhases(ctx, client, model, session, userStory, ac)
+
+	now := time.Now()
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		recordTiming(session, "finalize", requestedAt)
+		session.Warnings = warnings.list()
+		if phaseOutput(session, currentPhase) == configdomain.WorkflowOutputQuestions && session.FinalizedAt == nil {
+			tallyAnswers(session, currentAnswers)
+			recordAnswers(session, currentAnswers)
+		}
+		if session.FinalizedAt == nil {
+			recordSuggestions(session, acceptedSuggestions)
+		}
+		session.FinalUserStory = userStory
+		session.FinalAC = ac
+		session.FinalizedAt = &now
+		session.Translations = nil // Translations of an earlier finalize are stale
+		session.Polish = nil       // So is a polish draft of it
+		session.Gherkin = nil      // And its scenarios
+		session.TestCases = nil    // And test cases
+		session.QualityScore = nil // And its quality score
+		session.EndpointStubs = endpointStubs
+		for _, apply := range phaseOutputs {
+			apply(session)
+		}
+	})
+	if err != nil {
+		return "", nil, "", err
 	}
 
+	s.trackPromptDrift(ctx, session, "finalize")
+	s.publish(events.SessionFinalized, session, nil)
+	go s.distillMemory(context.WithoutCancel(ctx), session)
+	go s.mineQuestionBank(context.WithoutCancel(ctx), session)
 	return userStory, ac, raw, nil
 }
+
+// GetSession returns a copy of the session with the given ID.
+func (s *refinementService) GetSession(sessionID string) (*domain.RefinementSession, error) {
+	return snapshotSession(sessionID)
+}
+
+// ListSessions returns copies of all sessions, newest first.
+func (s *refinementService) ListSessions() []*domain.RefinementSession {
+	if readonly.Enabled() && sessionRepository != nil {
+		stored, err := sessionRepository.List()
+		if err != nil {
+			logging.Default().Error().Err(err).Msg("Failed to list stored sessions")
+		}
+		sort.Slice(stored, func(i, j int) bool { return stored[i].CreatedAt.After(stored[j].CreatedAt) })
+		return stored
+	}
+	sessionsMutex.RLock()
+	defer sessionsMutex.RUnlock()
+	result := make([]*domain.RefinementSession, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, session.Clone())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// UpdateSession applies an update to a session atomically and returns a copy of the result.
+func (s *refinementService) UpdateSession(sessionID string, update func(session *domain.RefinementSession)) (*domain.RefinementSession, error) {
+	return mutateSession(sessionID, update)
+}
+
+// AddContext adds external information (e.g. tracker comments) to the session's thread and history.
+func (s *refinementService) AddContext(ctx context.Context, sessionID, label, content string) error {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	s.takePrefetch(ctx, session, "")
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return err
+	}
+	message := "[" + label + "]\n" + content
+	if err := client.AddMessageToThread(ctx, session.ThreadID, message); err != nil {
+		return fmt.Errorf("failed to add context to thread: %w", err)
+	}
+	var askedQuestions, history []string
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.History = append(session.History, message)
+		askedQuestions, history = pruneSession(session)
+	})
+	if err != nil {
+		return err
+	}
+	s.publishPruned(session, askedQuestions, history)
+	return nil
+}
+
+// publish publishes a session event if a publisher is configured.
+func (s *refinementService) publish(eventType string, session *domain.RefinementSession, data map[string]any) {
+	if s.publisher == nil || session.IsTutorial() {
+		return
+	}
+	s.publisher.Publish(events.Event{
+		Type:        eventType,
+		SessionID:   session.ID,
+		WorkspaceID: session.WorkspaceID,
+		UserID:      session.UserID,
+		Data:        data,
+	})
+}
+
+// sessionContext returns a context whose logger adds the session and thread IDs to every line.
+func sessionContext(ctx context.Context, session *domain.RefinementSession) context.Context {
+	return logging.With(ctx, "session_id", session.ID, "thread_id", session.ThreadID)
+}
+
+// clientFor resolves the OpenAI client and model for a workspace used by a user; an empty ID uses the
+// default client. Users who are not members of a workspace restricted to its members cannot use it,
+// while operations on behalf of no user are not restricted.
+func (s *refinementService) clientFor(workspaceID, userID string) (infrastructure.OpenAIClient, string, error) {
+	if workspaceID == domain.TutorialWorkspaceID {
+		return s.tutorialClient, defaultModel, nil
+	}
+	if err := offline.Check(); err != nil {
+		return nil, "", err
+	}
+	if workspaceID == "" || s.workspaceService == nil {
+		return s.openaiClient, defaultModel, nil
+	}
+	if userID != "" && s.members != nil && !s.members.CanUseWorkspace(userID, workspaceID) {
+		return nil, "", fmt.Errorf("%w: %s", domain.ErrNotWorkspaceMember, workspaceID)
+	}
+	provider, err := s.workspaceService.ResolveProvider(workspaceID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve provider for workspace %s: %w", workspaceID, err)
+	}
+	client, err := s.clientFactory.CreateOpenAIClient(infrastructure.AIConfig{
+		Provider: provider.Provider,
+		APIKey:   provider.APIKey,
+		Model:    provider.Model,
+		Options:  map[string]string{"organization": provider.Organization, "base_url": provider.BaseURL},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create AI client for workspace %s: %w", workspaceID, err)
+	}
+	model := provider.Model
+	if model == "" {
+		model = defaultModel
+	}
+	return client, model, nil
+}
+
+// instructionsHash identifies the assistant built from the given instructions and model.
+func instructionsHash(instructions, model string) string {
+	sum := sha256.Sum256([]byte(model + "\n" + instructions))
+	return hex.EncodeToString(sum[:6])
+}
+
+// StartWithConfig starts a session using the prompts of the given app config, for callers
+// outside the HTTP start handler such as webhooks and integrations.
+func StartWithConfig(ctx context.Context, service RefinementService, req *domain.RefinementRequest, appConfig *configdomain.AppConfig) (*domain.RefinementSession, error) {
+	return startWithContext(ctx, service, req, appConfig, "")
+}
+
+// startWithContext starts a session with extra context appended to the product context and records
+// the config snapshot the session was started with.
+func startWithContext(ctx context.Context, service RefinementService, req *domain.RefinementRequest, appConfig *configdomain.AppConfig, extraContext string) (*domain.RefinementSession, error) {
+	appConfig, err := service.ConfigFor(req.WorkspaceID, req.ProjectID, appConfig)
+	if err != nil {
+		return nil, err
+	}
+	phasePrompts, err := phasePromptsForType(req.SessionType, appConfig)
+	if err != nil {
+		return nil, err
+	}
+	roleRules := selectRoles(ctx, req, appConfig)
+	if req.RoleLimits == nil {
+		req.RoleLimits = appConfig.RoleLimits
+	}
+	if req.EnsembleModel == "" {
+		req.EnsembleModel = appConfig.SuggestionEnsemble.Model
+	}
+	req.LocalizationAnalysis = req.LocalizationAnalysis || appConfig.Localization.AnalyzeSuggestions
+	req.ShadowModel = appConfig.ShadowModel.Model
+	req.LatencyBudgets = appConfig.LatencyBudgets
+	req.OutputFilter = appConfig.OutputFilter
+	session, err := service.StartSession(ctx, req, appConfig.ProductContext+extraContext, appConfig.RolePromptsWithExemplars(), phasePrompts, appConfig.PhaseFormatExamples)
+	if err != nil {
+		return nil, err
+	}
+	return service.UpdateSession(session.ID, func(session *domain.RefinementSession) {
+		session.ConfigSnapshot = &domain.ConfigSnapshot{
+			ProductContext: appConfig.ProductContext,
+			Glossary:       appConfig.Glossary,
+			Regulations:    appConfig.Regulations,
+			TakenAt:        time.Now(),
+		}
+		session.RoleRules = roleRules
+		session.Workflow = appConfig.Workflow.Phases
+	})
+}
+
+// ConfigForSession returns the app config with the prompt overrides of the session's workspace and the
+// context and prompts of its project applied. Sessions of a deleted project fall back to the workspace's.
+func ConfigForSession(service RefinementService, sessionID string, appConfig *configdomain.AppConfig) *configdomain.AppConfig {
+	session, err := service.GetSession(sessionID)
+	if err != nil {
+		return appConfig
+	}
+	resolved, err := service.ConfigFor(session.WorkspaceID, session.ProjectID, appConfig)
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("project_id", session.ProjectID).Str("session_id", sessionID).Msg("Failed to apply project to session")
+		return appConfig.ForWorkspace(session.WorkspaceID)
+	}
+	return resolved
+}
+
+// ConfigFor returns the app config with the prompt overrides of a workspace applied, then the product
+// context and prompts of a project, which is more specific. Either ID may be empty.
+func (s *refinementService) ConfigFor(workspaceID, projectID string, appConfig *configdomain.AppConfig) (*configdomain.AppConfig, error) {
+	appConfig = appConfig.ForWorkspace(workspaceID)
+	if projectID == "" || s.projectService == nil {
+		return appConfig, nil
+	}
+	return s.projectService.ForProject(projectID, appConfig)
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+)
+
+// stubWorkspaces resolves every workspace to the same provider.
+type stubWorkspaces struct {
+	workspaceapp.WorkspaceService
+	provider string
+}
+
+func (w stubWorkspaces) ResolveProvider(id string) (*workspacedomain.ResolvedProvider, error) {
+	return &workspacedomain.ResolvedProvider{Provider: w.provider, Model: "gpt-4o"}, nil
+}
+
+// stubClientFactory creates no actual clients.
+type stubClientFactory struct{}
+
+func (stubClientFactory) CreateOpenAIClient(config infrastructure.AIConfig) (infrastructure.OpenAIClient, error) {
+	return nil, nil
+}
+
+// stubMembers makes alice the only member of ws-restricted.
+type stubMembers struct{}
+
+func (stubMembers) CanUseWorkspace(userName, workspaceID string) bool {
+	return workspaceID != "ws-restricted" || userName == "alice"
+}
+
+func TestClientForMembership(t *testing.T) {
+	s := &refinementService{clientFactory: stubClientFactory{}, workspaceService: stubWorkspaces{provider: "openai"}, members: stubMembers{}}
+	tests := []struct {
+		name        string
+		workspaceID string
+		userID      string
+		wantErr     bool
+	}{
+		{name: "member", workspaceID: "ws-restricted", userID: "alice"},
+		{name: "not a member", workspaceID: "ws-restricted", userID: "bob", wantErr: true},
+		{name: "no user", workspaceID: "ws-restricted"},
+		{name: "open workspace", workspaceID: "ws-open", userID: "bob"},
+		{name: "no workspace", userID: "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, model, err := s.clientFor(tt.workspaceID, tt.userID)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrNotWorkspaceMember) {
+					t.Errorf("clientFor() error = %v, want ErrNotWorkspaceMember", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clientFor() error = %v", err)
+			}
+			if model == "" {
+				t.Error("clientFor() returned no model")
+			}
+		})
+	}
+}
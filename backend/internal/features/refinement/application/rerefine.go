@@ -0,0 +1,157 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// Rerefine starts a new session for the story of an earlier session, injecting a summary of what
+// changed in the product context, role prompts, glossary and product memory since that session so
+// the AI focuses on the deltas.
+func Rerefine(ctx context.Context, service RefinementService, sessionID, userID string, appConfig *configdomain.AppConfig) (*domain.RefinementSession, error) {
+	original, err := service.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	appConfig, err = service.ConfigFor(original.WorkspaceID, original.ProjectID, appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// A change is re-refined against the story it was delivered with, not the changed story
+	req := original.Request
+	if req.SessionType != domain.SessionTypeChange {
+		req.InitialUserStory = original.FinalUserStory
+		if req.InitialUserStory == "" {
+			req.InitialUserStory = original.UserStory
+		}
+	}
+	if userID != "" {
+		req.UserID = userID
+	}
+	changes := contextChanges(original, appConfig)
+
+	if memory, err := service.ListMemory(original.WorkspaceID); err == nil && original.ConfigSnapshot != nil {
+		var added []string
+		for _, item := range memory {
+			if item.CreatedAt.After(original.ConfigSnapshot.TakenAt) {
+				added = append(added, item.Content)
+			}
+		}
+		if len(added) > 0 {
+			changes = append(changes, "新增的產品記憶：\n  - "+strings.Join(added, "\n  - "))
+		}
+	}
+
+	summary := ""
+	if len(changes) > 0 {
+		summary = "- " + strings.Join(changes, "\n- ")
+	}
+	extraContext := ""
+	if summary != "" {
+		extraContext = fmt.Sprintf("\n\n這是對先前已打磨過的故事（session %s）重新打磨。自上次打磨後，產品設定有以下變更，請聚焦於這些變更對故事的影響，不要重複詢問先前已釐清的內容：\n%s", original.ID, summary)
+	} else {
+		extraContext = fmt.Sprintf("\n\n這是對先前已打磨過的故事（session %s）重新打磨，請聚焦於尚未釐清的部分。", original.ID)
+	}
+
+	session, err := startWithContext(ctx, service, &req, appConfig, extraContext)
+	if err != nil {
+		return nil, err
+	}
+	return service.UpdateSession(session.ID, func(session *domain.RefinementSession) {
+		session.RerefinedFrom = original.ID
+		session.ContextChanges = summary
+	})
+}
+
+// contextChanges lists the differences between the config a session was started with and the current config.
+func contextChanges(session *domain.RefinementSession, appConfig *configdomain.AppConfig) []string {
+	var changes []string
+	snapshot := session.ConfigSnapshot
+	if snapshot != nil && snapshot.ProductContext != appConfig.ProductContext {
+		added, removed := lineDiff(snapshot.ProductContext, appConfig.ProductContext)
+		change := "產品背景已更新"
+		if len(added) > 0 {
+			change += "\n  新增：" + strings.Join(added, "；")
+		}
+		if len(removed) > 0 {
+			change += "\n  移除：" + strings.Join(removed, "；")
+		}
+		changes = append(changes, change)
+	}
+
+	currentPrompts := appConfig.RolePromptsWithExemplars()
+	for _, role := range session.Request.SelectedRoles {
+		if prompt, ok := currentPrompts[role]; ok && prompt != session.RolePrompts[role] {
+			changes = append(changes, fmt.Sprintf("角色「%s」的提示已更新", role))
+		}
+	}
+
+	if snapshot != nil {
+		changes = append(changes, glossaryChanges(snapshot.Glossary, appConfig.Glossary)...)
+	}
+	return changes
+}
+
+// lineDiff returns the non-empty lines only present in after (added) and only present in before (removed).
+func lineDiff(before, after string) (added, removed []string) {
+	beforeLines := lineSet(before)
+	afterLines := lineSet(after)
+	for _, line := range strings.Split(after, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !beforeLines[line] {
+			added = append(added, line)
+		}
+	}
+	for _, line := range strings.Split(before, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !afterLines[line] {
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func lineSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = true
+		}
+	}
+	return set
+}
+
+// glossaryChanges describes glossary terms that were added, removed or redefined.
+func glossaryChanges(before, after []configdomain.GlossaryTerm) []string {
+	old := make(map[string]configdomain.GlossaryTerm, len(before))
+	for _, term := range before {
+		old[term.Term] = term
+	}
+	var changes []string
+	current := make(map[string]bool, len(after))
+	for _, term := range after {
+		current[term.Term] = true
+		prev, ok := old[term.Term]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("術語表新增「%s」：%s", term.Term, term.Definition))
+		case prev.Definition != term.Definition || strings.Join(prev.Variants, ",") != strings.Join(term.Variants, ","):
+			changes = append(changes, fmt.Sprintf("術語「%s」的定義已更新：%s", term.Term, term.Definition))
+		}
+	}
+	var removed []string
+	for term := range old {
+		if !current[term] {
+			removed = append(removed, term)
+		}
+	}
+	sort.Strings(removed)
+	for _, term := range removed {
+		changes = append(changes, fmt.Sprintf("術語表移除「%s」", term))
+	}
+	return changes
+}
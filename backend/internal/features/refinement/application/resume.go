@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	"sofa-commander/backend/internal/logging"
+)
+
+// Resume recovers a session a backend restart interrupted. A session missing from memory is loaded from
+// the session store, and a round whose reply reached the thread but not the session record, e.g. new
+// questions or suggestions the process died before storing, is rebuilt from the thread, which the
+// provider keeps. Runs left active on the thread are cancelled so the session can continue. Finalized
+// sessions are only restored. Sessions of providers other than OpenAI, whose conversations the backend
+// keeps in memory only, cannot be resumed.
+func (s *refinementService) Resume(ctx context.Context, sessionID string) (*domain.RefinementSession, *domain.ResumeReport, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	restored, err := restoreSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	keeps, err := s.keepsThreads(session.WorkspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !keeps {
+		return nil, nil, fmt.Errorf("%w: session %s", domain.ErrResumeUnsupported, sessionID)
+	}
+	report := &domain.ResumeReport{Restored: restored}
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := client.CancelActiveRuns(ctx, session.ThreadID); err != nil {
+		logging.From(ctx).Warn().Err(err).Str("session_id", sessionID).Msg("Failed to cancel active runs of session")
+	}
+	messages, err := client.ListThreadMessages(ctx, session.ThreadID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(messages) == 0 || session.FinalizedAt != nil {
+		return session, report, nil
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "assistant" {
+		report.PendingRun = unanswered(session, messages)
+		return session, report, nil
+	}
+
+	reply := messageText(last)
+	phase := replyPhase(session, messages)
+	var items []domain.Question
+	if err := json.Unmarshal([]byte(scrubItems(session.Request.OutputFilter, stripCodeFence(reply))), &items); err != nil || len(items) == 0 {
+		story, criteria := storyHeading, criteriaHeading
+		if _, typeStory, typeCriteria, ok := finalizeFormat(session); ok {
+			story, criteria = typeStory, typeCriteria
+		}
+		if _, _, ok := parseFinalOutput(reply, story, criteria); !ok {
+			return session, report, nil
+		}
+		addWarning(ctx, domain.WarningRoundLost, "定稿結果在重啟時遺失，請重新定稿")
+	} else if !holdsReply(session, phase, items) {
+		report.Reconstructed = true
+		if session.Phase == domain.PhaseQuestioning && len(session.Questions) > 0 {
+			addWarning(ctx, domain.WarningRoundLost, "第 %d 輪的回答在重啟時遺失，已從對話還原下一步，但回答未記錄", session.CurrentRound)
+		}
+		if phase == domain.PhaseQuestioning {
+			attachPriorAnswers(sessionID, session.WorkspaceID, items)
+		}
+	} else {
+		return session, report, nil
+	}
+
+	var askedQuestions, history []string
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.Warnings = warnings.list()
+		switch {
+		case !report.Reconstructed:
+		case phase == domain.PhaseSuggesting:
+			suggestions := make([]domain.Suggestion, len(items))
+			for i, item := range items {
+				suggestions[i] = domain.Suggestion{Role: item.Role, Prompt: item.Prompt}
+			}
+			session.Suggestions = suggestions
+			session.Questions = nil
+			session.Phase = domain.PhaseSuggesting
+		default:
+			updateConvergence(session, items)
+			session.Questions = items
+			session.Suggestions = nil
+			session.Phase = domain.PhaseQuestioning
+			session.CurrentRound++
+			askedQuestions, history = pruneSession(session)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.publishPruned(session, askedQuestions, history)
+	return session, report, nil
+}
+
+// keepsThreads reports whether the AI provider of a workspace keeps the threads of its sessions: the
+// OpenAI Assistants API does, while the conversations of other providers are kept in the backend's memory.
+// Scripted tutorial sessions need no provider.
+func (s *refinementService) keepsThreads(workspaceID string) (bool, error) {
+	if workspaceID == "" || workspaceID == domain.TutorialWorkspaceID || s.workspaceService == nil {
+		return true, nil
+	}
+	provider, err := s.workspaceService.ResolveProvider(workspaceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve provider for workspace %s: %w", workspaceID, err)
+	}
+	return provider.Provider == "" || provider.Provider == workspaceapp.DefaultProvider, nil
+}
+
+// replyPhase tells whether the last reply on a thread holds suggestions or questions, from the
+// instruction it answered: suggestion rounds are asked for with the suggesting phase prompt.
+func replyPhase(session *domain.RefinementSession, messages []openai.Message) domain.RefinementPhase {
+	var instruction string
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			instruction = messageText(messages[i])
+			break
+		}
+	}
+	if prompt := strings.TrimSpace(session.PhasePrompts["suggesting"]); prompt != "" && strings.Contains(instruction, prompt) {
+		return domain.PhaseSuggesting
+	}
+	if strings.Contains(instruction, "下輪建議") {
+		return domain.PhaseSuggesting
+	}
+	return domain.PhaseQuestioning
+}
+
+// holdsReply reports whether the session already holds the items of a reply in the given phase. Any
+// shared prompt will do, as the stored items may be capped or merged with those of other sources.
+func holdsReply(session *domain.RefinementSession, phase domain.RefinementPhase, items []domain.Question) bool {
+	if session.Phase != phase {
+		return false
+	}
+	held := make(map[string]bool)
+	for _, q := range session.Questions {
+		for _, p := range q.Prompt {
+			held[q.Role+"_"+p] = true
+		}
+	}
+	for _, sg := range session.Suggestions {
+		for _, p := range sg.Prompt {
+			held[sg.Role+"_"+p] = true
+		}
+	}
+	for _, item := range items {
+		for _, p := range item.Prompt {
+			if held[item.Role+"_"+p] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unanswered reports whether the thread ends with messages of an operation the assistant never answered,
+// rather than context added to the session's history, which needs no answer.
+func unanswered(session *domain.RefinementSession, messages []openai.Message) bool {
+	for i := len(messages) - 1; i >= 0 && messages[i].Role != "assistant"; i-- {
+		if !slices.Contains(session.History, messageText(messages[i])) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageText joins the text contents of a thread message.
+func messageText(msg openai.Message) string {
+	var text strings.Builder
+	for _, content := range msg.Content {
+		if content.Text != nil {
+			text.WriteString(content.Text.Value)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"testing"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+func TestKeepsThreads(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		workspaceID string
+		want        bool
+	}{
+		{name: "no workspace", provider: "gemini", want: true},
+		{name: "tutorial", provider: "gemini", workspaceID: domain.TutorialWorkspaceID, want: true},
+		{name: "openai", provider: "openai", workspaceID: "ws-1", want: true},
+		{name: "gemini", provider: "gemini", workspaceID: "ws-1", want: false},
+		{name: "claude", provider: "claude", workspaceID: "ws-1", want: false},
+		{name: "ollama", provider: "ollama", workspaceID: "ws-1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &refinementService{workspaceService: stubWorkspaces{provider: tt.provider}}
+			got, err := s.keepsThreads(tt.workspaceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("keepsThreads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// rolePreviewQuestions is the most sample questions a preview returns.
+const rolePreviewQuestions = 3
+
+const rolePreviewSystemPrompt = `You preview how a role of a requirement refinement session would question a draft user story.
+Acting as the given role, with its instructions and the product context, write the 2 or 3 questions the role would most want answered about this story. Keep them specific to the story.
+Return only JSON: {"questions": ["...", "..."]}`
+
+// PreviewRole generates sample questions a role would ask about a draft story with a single completion,
+// without creating a session or thread.
+func (s *refinementService) PreviewRole(ctx context.Context, req *domain.RolePreviewRequest, role, rolePrompt, productContext string) (*domain.RolePreview, error) {
+	if strings.TrimSpace(req.Story) == "" {
+		return nil, fmt.Errorf("the story is empty")
+	}
+	client, model, err := s.clientFor(req.WorkspaceID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	prompt := fmt.Sprintf("Product context:\n%s\n\nRole: %s\nRole instructions:\n%s\n\nDraft story:\n%s", productContext, role, rolePrompt, req.Story)
+	if req.Language != "" {
+		prompt += fmt.Sprintf("\n\n請以 %s 撰寫問題。", req.Language)
+	}
+	raw, err := client.Complete(ctx, model, rolePreviewSystemPrompt, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview role: %w", err)
+	}
+	var output struct {
+		Questions []string `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse role preview from AI: %w, raw response: %s", err, raw)
+	}
+	preview := &domain.RolePreview{Role: role, Questions: []string{}}
+	for _, question := range output.Questions {
+		if question = strings.TrimSpace(question); question != "" && len(preview.Questions) < rolePreviewQuestions {
+			preview.Questions = append(preview.Questions, question)
+		}
+	}
+	return preview, nil
+}
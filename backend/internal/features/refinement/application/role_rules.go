@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// selectRoles fills the roles of a request without selected roles from the role rules matching its
+// story, in the order of the configured roles, and returns the names of the rules applied. Requests
+// no rule matches get every configured role.
+func selectRoles(ctx context.Context, req *domain.RefinementRequest, appConfig *configdomain.AppConfig) []string {
+	if len(req.SelectedRoles) > 0 {
+		return nil
+	}
+	story := strings.ToLower(storyToRefine(req))
+	selected := make(map[string]bool)
+	var applied []string
+	for _, rule := range appConfig.RoleRules {
+		if !ruleMatches(rule, req, story) {
+			continue
+		}
+		applied = append(applied, rule.Name)
+		for _, role := range rule.Roles {
+			if _, ok := appConfig.RolePrompts[role]; !ok {
+				logging.From(ctx).Warn().Str("rule", rule.Name).Str("role", role).Msg("Role rule names a role which has no prompt")
+				continue
+			}
+			selected[role] = true
+		}
+	}
+	for _, role := range appConfig.RoleNames() {
+		if len(selected) == 0 || selected[role] {
+			req.SelectedRoles = append(req.SelectedRoles, role)
+		}
+	}
+	return applied
+}
+
+// ruleMatches reports whether a request has one of the rule's labels or its story one of its keywords.
+func ruleMatches(rule configdomain.RoleRule, req *domain.RefinementRequest, story string) bool {
+	if len(rule.Labels) == 0 && len(rule.Keywords) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rule.Labels, req.HasTag) || slices.ContainsFunc(rule.Keywords, func(keyword string) bool {
+		return keyword != "" && strings.Contains(story, strings.ToLower(keyword))
+	})
+}
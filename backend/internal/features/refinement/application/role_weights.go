@@ -0,0 +1,30 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+)
+
+// roleWeightNote asks the assistant to allocate its attention by the session's role weights.
+// Roles without a weight count as 1; no note is added when all selected roles weigh the same.
+func roleWeightNote(roles []string, weights map[string]float64) string {
+	if len(weights) == 0 {
+		return ""
+	}
+	var lines []string
+	uniform := true
+	for _, role := range roles {
+		weight, ok := weights[role]
+		if !ok || weight < 0 {
+			weight = 1
+		}
+		if weight != 1 {
+			uniform = false
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %g", role, weight))
+	}
+	if uniform {
+		return ""
+	}
+	return "本故事的角色權重（預設為 1，權重越高代表越需要該角色的觀點，請依權重比例分配關注程度與項目數量）：\n" + strings.Join(lines, "\n") + "\n"
+}
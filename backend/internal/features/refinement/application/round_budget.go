@@ -0,0 +1,18 @@
+package application
+
+import "fmt"
+
+// roundBudgetNote tells the assistant where the session is in its planned questioning rounds,
+// nudging it toward convergence. It returns an empty string when no round budget is set.
+func roundBudgetNote(round, targetRounds int) string {
+	switch {
+	case targetRounds <= 0:
+		return ""
+	case round < targetRounds:
+		return fmt.Sprintf("【輪次規劃】目前為第 %d 輪提問（共 %d 輪），請聚焦於尚未釐清的關鍵缺口，避免重複已回答的內容。", round, targetRounds)
+	case round == targetRounds:
+		return fmt.Sprintf("【輪次規劃】目前為第 %d 輪提問（共 %d 輪），這是最後一輪，請只針對仍會阻礙交付的缺口提問，為下一步的建議做收斂。", round, targetRounds)
+	default:
+		return fmt.Sprintf("【輪次規劃】已超出預定的 %d 輪（目前第 %d 輪），請只提出絕對必要的問題，並盡快收斂。", targetRounds, round)
+	}
+}
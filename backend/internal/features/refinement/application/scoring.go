@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const scoringSystemPrompt = `You are a reviewer grading refined user stories against a rubric.
+Score the given user story and acceptance criteria on every criterion from 0 (not met at all) to 10 (fully met) and briefly explain each score.
+Return only JSON: [{"name": "<criterion name>", "score": 7, "reason": "..."}]`
+
+// ScoreStory scores the finalized story of a session against the rubric and stores the score on the session.
+func (s *refinementService) ScoreStory(ctx context.Context, sessionID string, rubric configdomain.ScoringRubric) (*domain.StoryScore, error) {
+	if len(rubric.Criteria) == 0 {
+		return nil, fmt.Errorf("the scoring rubric has no criteria")
+	}
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("Rubric:\n")
+	for _, criterion := range rubric.Criteria {
+		fmt.Fprintf(&b, "- %s: %s\n", criterion.Name, criterion.Description)
+	}
+	b.WriteString("\nUser story:\n" + session.FinalUserStory)
+	b.WriteString("\n\nAcceptance criteria:\n- " + strings.Join(session.FinalAC, "\n- "))
+
+	raw, err := client.Complete(ctx, model, scoringSystemPrompt, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to score story: %w", err)
+	}
+	var graded []domain.CriterionScore
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &graded); err != nil {
+		return nil, fmt.Errorf("failed to parse score from AI: %w, raw response: %s", err, raw)
+	}
+
+	score := scoreAgainst(rubric, graded)
+	if _, err := s.UpdateSession(sessionID, func(session *domain.RefinementSession) {
+		session.QualityScore = score
+	}); err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+// scoreAgainst weighs the graded criteria by the rubric. Criteria the AI did not grade score 0.
+func scoreAgainst(rubric configdomain.ScoringRubric, graded []domain.CriterionScore) *domain.StoryScore {
+	byName := make(map[string]domain.CriterionScore, len(graded))
+	for _, g := range graded {
+		byName[strings.ToLower(strings.TrimSpace(g.Name))] = g
+	}
+
+	score := &domain.StoryScore{ScoredAt: time.Now()}
+	var weighted, totalWeight float64
+	for _, criterion := range rubric.Criteria {
+		weight := criterion.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		g := byName[strings.ToLower(strings.TrimSpace(criterion.Name))]
+		points := min(max(g.Score, 0), 10)
+		score.Criteria = append(score.Criteria, domain.CriterionScore{Name: criterion.Name, Weight: weight, Score: points, Reason: g.Reason})
+		weighted += weight * float64(points)
+		totalWeight += weight
+	}
+	score.Total = math.Round(weighted/totalWeight*10*10) / 10
+	return score
+}
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/readonly"
+)
+
+// sessionSeq numbers the sessions started by this process.
+var sessionSeq atomic.Int64
+
+// sessionLocks holds a mutex per session ID, serializing the operations that talk to a session's
+// thread without blocking other sessions. sessionsMutex is only held briefly to read or apply
+// changes, never across an AI call.
+var sessionLocks sync.Map
+
+// lockHolds holds the time each held session lock was acquired, by session ID.
+var lockHolds sync.Map
+
+// sessionRepository persists the sessions held in memory, may be nil. Every change applied through
+// storeSession or mutateSession is written through after sessionsMutex is released.
+var sessionRepository infrastructure.SessionRepository
+
+// changeSeq numbers the changes applied to the sessions in memory. Callers must hold sessionsMutex.
+var changeSeq int64
+
+// persistStates holds the write state of each session by session ID, serializing its writes so a
+// write finishing late never overwrites a newer change with an older one.
+var persistStates sync.Map
+
+type persistState struct {
+	mu    sync.Mutex
+	saved int64 // Change number of the last write
+}
+
+// loadSessions restores the sessions of a repository into memory and continues the session ID
+// sequence after the highest stored ID.
+func loadSessions(repository infrastructure.SessionRepository) error {
+	stored, err := repository.List()
+	if err != nil {
+		return err
+	}
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	sessionRepository = repository
+	for _, session := range stored {
+		sessions[session.ID] = session
+		if n, err := strconv.ParseInt(strings.TrimPrefix(session.ID, "session-"), 10, 64); err == nil && n > sessionSeq.Load() {
+			sessionSeq.Store(n)
+		}
+	}
+	return nil
+}
+
+// persistSession writes a copy of a session, taken with the given change number, through to the
+// repository, unless a later change of it was written already.
+func persistSession(session *domain.RefinementSession, change int64) error {
+	if sessionRepository == nil {
+		return nil
+	}
+	value, _ := persistStates.LoadOrStore(session.ID, &persistState{})
+	state := value.(*persistState)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if change <= state.saved {
+		return nil
+	}
+	if err := sessionRepository.Save(session); err != nil {
+		return fmt.Errorf("failed to persist session %s: %w", session.ID, err)
+	}
+	state.saved = change
+	return nil
+}
+
+// storeSession adds a new session. A session that cannot be persisted is not added.
+func storeSession(session *domain.RefinementSession) error {
+	sessionsMutex.Lock()
+	session.LastActivityAt = time.Now()
+	session.SchemaVersion = domain.SessionSchemaVersion
+	sessions[session.ID] = session.Clone()
+	changeSeq++
+	change := changeSeq
+	sessionsMutex.Unlock()
+
+	if err := persistSession(session, change); err != nil {
+		sessionsMutex.Lock()
+		delete(sessions, session.ID)
+		sessionsMutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// nextSessionID reserves a new session ID.
+func nextSessionID() string {
+	return fmt.Sprintf("session-%d", sessionSeq.Add(1))
+}
+
+// lockSession acquires the operation lock of a session and returns the function releasing it.
+func lockSession(sessionID string) func() {
+	mu, _ := sessionLocks.LoadOrStore(sessionID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	lockHolds.Store(sessionID, time.Now())
+	return func() {
+		lockHolds.Delete(sessionID)
+		mu.(*sync.Mutex).Unlock()
+	}
+}
+
+// lockHeldSince returns when the operation lock of a session was acquired, if it is held.
+func lockHeldSince(sessionID string) (time.Time, bool) {
+	since, ok := lockHolds.Load(sessionID)
+	if !ok {
+		return time.Time{}, false
+	}
+	return since.(time.Time), true
+}
+
+// snapshotSession returns a copy of a stored session that is safe to read without holding sessionsMutex.
+func snapshotSession(sessionID string) (*domain.RefinementSession, error) {
+	if readonly.Enabled() && sessionRepository != nil {
+		// Read-only replicas read the shared store, which interactive instances keep changing
+		session, err := sessionRepository.Get(sessionID)
+		if errors.Is(err, infrastructure.ErrSessionNotFound) {
+			return nil, fmt.Errorf("session %s not found", sessionID)
+		}
+		return session, err
+	}
+	sessionsMutex.RLock()
+	defer sessionsMutex.RUnlock()
+	session, ok := sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	return session.Clone(), nil
+}
+
+// restoreSession loads a session missing from memory from the repository, e.g. one a restart lost or
+// another instance started, and reports whether it had to.
+func restoreSession(sessionID string) (bool, error) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	if _, ok := sessions[sessionID]; ok {
+		return false, nil
+	}
+	if sessionRepository == nil {
+		return false, fmt.Errorf("session %s not found", sessionID)
+	}
+	session, err := sessionRepository.Get(sessionID)
+	if errors.Is(err, infrastructure.ErrSessionNotFound) {
+		return false, fmt.Errorf("session %s not found", sessionID)
+	}
+	if err != nil {
+		return false, err
+	}
+	sessions[sessionID] = session
+	return true, nil
+}
+
+// mutateSession applies an update to a stored session atomically and returns a copy of the result. It
+// fails when the result cannot be persisted; the update stays applied in memory, and is written with
+// the session's next change.
+func mutateSession(sessionID string, update func(session *domain.RefinementSession)) (*domain.RefinementSession, error) {
+	sessionsMutex.Lock()
+	session, ok := sessions[sessionID]
+	if !ok {
+		sessionsMutex.Unlock()
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	update(session)
+	session.LastActivityAt = time.Now()
+	changeSeq++
+	change := changeSeq
+	result := session.Clone()
+	sessionsMutex.Unlock()
+
+	if err := persistSession(result, change); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
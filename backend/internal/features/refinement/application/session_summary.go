@@ -0,0 +1,79 @@
+package application
+
+import (
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// maxTitleRunes bounds the title of a session summary.
+const maxTitleRunes = 80
+
+// currentStory returns a session's story, the finalized one once finalized.
+func currentStory(session *domain.RefinementSession) string {
+	if session.FinalUserStory != "" {
+		return session.FinalUserStory
+	}
+	return session.UserStory
+}
+
+// storyTitle returns the first line of a session's current story.
+func storyTitle(session *domain.RefinementSession) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(currentStory(session)), "\n", 2)[0])
+	if runes := []rune(title); len(runes) > maxTitleRunes {
+		title = string(runes[:maxTitleRunes]) + "…"
+	}
+	return title
+}
+
+// Summarize projects a session to the fields list and summary views need.
+func Summarize(session *domain.RefinementSession) domain.SessionSummary {
+	title := storyTitle(session)
+
+	questionCount := 0
+	for _, q := range session.Questions {
+		questionCount += len(q.Prompt)
+	}
+	suggestionCount := 0
+	for _, sg := range session.Suggestions {
+		suggestionCount += len(sg.Prompt)
+	}
+	openComments := 0
+	for _, c := range session.ReviewComments {
+		if c.ResolvedAt == nil {
+			openComments++
+		}
+	}
+	lastActivity := session.LastActivityAt
+	if lastActivity.IsZero() {
+		lastActivity = session.CreatedAt
+	}
+	roles := session.Request.SelectedRoles
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return domain.SessionSummary{
+		ID:              session.ID,
+		Title:           title,
+		WorkspaceID:     session.WorkspaceID,
+		ProjectID:       session.ProjectID,
+		UserID:          session.UserID,
+		Phase:           session.Phase,
+		CurrentRound:    session.CurrentRound,
+		TargetRounds:    session.TargetRounds,
+		Converged:       session.Converged,
+		SelectedRoles:   roles,
+		QuestionCount:   questionCount,
+		SuggestionCount: suggestionCount,
+		Finalized:       session.FinalizedAt != nil,
+		FinalizedAt:     session.FinalizedAt,
+		ApprovalStatus:  session.ApprovalStatus,
+		OpenComments:    openComments,
+		JiraIssueKey:    session.JiraIssueKey,
+		GitLabIssueIID:  session.GitLabIssueIID,
+		CreatedAt:       session.CreatedAt,
+		LastActivityAt:  lastActivity,
+		UserStory:       currentStory(session),
+	}
+}
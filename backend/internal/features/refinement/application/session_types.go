@@ -0,0 +1,230 @@
+package application
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// Headings of the sections of a user story's finalize output.
+const (
+	storyHeading    = "【用戶故事】"
+	criteriaHeading = "【驗收標準】"
+)
+
+// sessionTemplate describes how a session type other than the default user story is refined.
+type sessionTemplate struct {
+	phasePrompts    map[string]string // Default prompt per phase, including "finalize"
+	storyHeading    string            // Heading of the description in the finalize output
+	criteriaHeading string            // Heading of the numbered criteria in the finalize output
+}
+
+var sessionTemplates = map[string]sessionTemplate{
+	domain.SessionTypeSpike: {
+		phasePrompts: map[string]string{
+			"questioning": "這是一個技術 Spike（技術調查），不是 User Story，請勿以用戶故事或驗收標準的角度提問。請針對每個角色提出 3~5 個最需要釐清的問題。要求：1) 問題要幫助界定這次調查要回答的具體問題與不需要回答的範圍；2) 釐清目前已知的資訊、限制與可選的技術方向；3) 詢問可接受的時間盒（timebox）以及超時時的處理方式；4) 詢問什麼樣的產出（例如 PoC、比較表、決策紀錄）代表調查完成。",
+			"suggesting":  "這是一個技術 Spike（技術調查），不是 User Story。基於對話歷史，請針對每個角色給出 3~5 條具體建議。要求：1) 建議應補充或收斂調查問題；2) 建議合理的時間盒長度並說明理由；3) 建議調查應產出的成果；4) 建議可驗證的完成條件（exit criteria），而不是驗收標準。",
+			"finalize": `你現在需要基於我們在這個 thread 中的完整對話歷史，撰寫這個技術 Spike 的調查計畫。這是技術調查，不是用戶故事，請勿使用「身為…我想要…」的用戶故事句型，也不要撰寫驗收標準。
+
+請整合各角色的問題、產品經理的回答以及採納的建議，撰寫：
+- 調查目標：為什麼需要這次調查、調查結果將支援什麼決策
+- 調查問題：這次調查必須回答的具體問題
+- 時間盒：調查投入時間的上限，以及超時時的處理方式
+- 完成條件：什麼樣的產出代表調查完成（例如 PoC、比較表、決策紀錄）
+
+請按照以下格式回傳：
+
+【Spike 說明】
+調查目標：...
+調查問題：
+- 問題1
+- 問題2
+時間盒：...
+
+【完成條件】
+1. 完成條件1（具體、可驗證）
+2. 完成條件2（具體、可驗證）
+3. 完成條件3（具體、可驗證）`,
+		},
+		storyHeading:    "【Spike 說明】",
+		criteriaHeading: "【完成條件】",
+	},
+	domain.SessionTypeBug: {
+		phasePrompts: map[string]string{
+			"questioning":   "這是一份 Bug 回報，不是 User Story。本輪為「重現步驟釐清」階段，請針對每個角色提出 3~5 個最需要釐清的問題。要求：1) 釐清重現步驟、前置條件與測試資料；2) 釐清預期行為與實際行為的差異；3) 詢問發生的環境（版本、裝置、瀏覽器、帳號類型）與發生頻率；4) 詢問是否有錯誤訊息、日誌或截圖可供參考。",
+			"questioning_2": "這是一份 Bug 回報，不是 User Story。本輪為「影響評估」階段，請針對每個角色提出 3~5 個最需要釐清的問題。要求：1) 釐清受影響的使用者範圍與比例；2) 釐清對業務、資料正確性與安全性的影響；3) 詢問是否有暫時的替代方案（workaround）；4) 詢問問題開始發生的時間點以及可能相關的近期變更，以判斷修復的優先順序。",
+			"suggesting":    "這是一份 Bug 回報，不是 User Story。基於對話歷史，請針對每個角色給出 3~5 條具體建議。要求：1) 建議可能的根因調查方向與修復方式；2) 建議修復後需要驗證的情境，包含原始重現步驟與相關的邊界情況；3) 建議防止再次發生的措施（例如回歸測試、監控告警）；4) 建議應有助於撰寫修復的驗收標準。",
+			"finalize": `你現在需要基於我們在這個 thread 中的完整對話歷史，整理這份 Bug 回報並撰寫修復的驗收標準。這是 Bug 回報，不是用戶故事，請勿使用「身為…我想要…」的用戶故事句型。
+
+請整合各角色的問題、產品經理的回答以及採納的建議，撰寫：
+- 重現步驟：前置條件與逐步操作
+- 預期行為與實際行為
+- 影響範圍：受影響的使用者、嚴重程度與替代方案
+- 修復驗收標準：修復完成時必須成立、可測試的條件，包含原始重現步驟不再發生問題
+
+請按照以下格式回傳：
+
+【Bug 說明】
+重現步驟：
+1. 步驟1
+2. 步驟2
+預期行為：...
+實際行為：...
+影響範圍：...
+
+【修復驗收標準】
+1. 修復驗收標準1（具體、可測試）
+2. 修復驗收標準2（具體、可測試）
+3. 修復驗收標準3（具體、可測試）`,
+		},
+		storyHeading:    "【Bug 說明】",
+		criteriaHeading: "【修復驗收標準】",
+	},
+	domain.SessionTypeChange: {
+		phasePrompts: map[string]string{
+			"questioning":   "這是對一個已交付故事的變更，不是新的 User Story。本輪為「影響範圍」階段，請針對每個角色提出 3~5 個最需要釐清的問題。要求：1) 釐清變更的動機與確切範圍，哪些行為改變、哪些必須維持不變；2) 逐條檢視已交付的驗收標準，釐清哪些會被修改、取代或失效；3) 詢問依賴現有行為的使用者、整合、報表或下游系統；4) 不要重複詢問已交付故事中已確定的內容。",
+			"questioning_2": "這是對一個已交付故事的變更，不是新的 User Story。本輪為「迴歸風險與遷移」階段，請針對每個角色提出 3~5 個最需要釐清的問題。要求：1) 釐清最可能因變更而壞掉的既有功能與需要的迴歸測試；2) 釐清既有資料、設定或 API 是否需要遷移，以及遷移失敗時的回復方式；3) 詢問是否需要功能開關、分階段上線或相容期；4) 詢問需要通知哪些使用者或團隊。",
+			"suggesting":    "這是對一個已交付故事的變更，不是新的 User Story。基於對話歷史，請針對每個角色給出 3~5 條具體建議。要求：1) 建議應降低迴歸風險，例如需要補上的迴歸測試與監控；2) 建議受影響驗收標準的修改方式；3) 建議資料或 API 的遷移與回復步驟；4) 建議上線方式與對既有使用者的溝通。",
+			"finalize": `你現在需要基於我們在這個 thread 中的完整對話歷史，撰寫變更後的故事。這是對已交付故事的變更，請以已交付的故事與驗收標準為基礎，只做變更所需的修改，未受影響的內容保持原樣。
+
+請整合各角色的問題、產品經理的回答以及採納的建議，撰寫：
+- 變更摘要：逐項列出相對於已交付故事的變更，標明新增、修改或移除
+- 受影響的驗收標準：已交付的哪幾條驗收標準被修改、取代或移除
+- 迴歸風險：可能因變更而壞掉的既有功能，以及對應的迴歸測試
+- 遷移考量：資料、設定或 API 的遷移與回復方式；不需要時寫「無」
+- 更新後的用戶故事
+- 更新後的完整驗收標準：包含未變更的條目，並在新增或修改的條目開頭標註「（新增）」或「（修改）」
+
+請按照以下格式回傳：
+
+【變更後的用戶故事】
+變更摘要：
+- （新增／修改／移除）變更1
+- （新增／修改／移除）變更2
+受影響的驗收標準：...
+迴歸風險：...
+遷移考量：...
+更新後的用戶故事：...
+
+【更新後的驗收標準】
+1. 驗收標準1（具體、可測試）
+2. （修改）驗收標準2（具體、可測試）
+3. （新增）驗收標準3（具體、可測試）`,
+		},
+		storyHeading:    "【變更後的用戶故事】",
+		criteriaHeading: "【更新後的驗收標準】",
+	},
+}
+
+// PrepareChangeRequest checks the request of a "change" session, taking the delivered story and AC from
+// the finalized output of its delivered session when it names one. Requests of other types are left
+// as they are.
+func PrepareChangeRequest(service RefinementService, req *domain.RefinementRequest) error {
+	if req.SessionType != domain.SessionTypeChange {
+		return nil
+	}
+	if req.DeliveredSessionID != "" {
+		delivered, err := service.GetSession(req.DeliveredSessionID)
+		if err != nil {
+			return err
+		}
+		if delivered.FinalizedAt == nil {
+			return fmt.Errorf("delivered session %s has not been finalized", req.DeliveredSessionID)
+		}
+		req.InitialUserStory = delivered.FinalUserStory
+		req.DeliveredAC = append([]string(nil), delivered.FinalAC...)
+	}
+	if strings.TrimSpace(req.InitialUserStory) == "" {
+		return fmt.Errorf("a change session needs the delivered story as its initial user story")
+	}
+	if strings.TrimSpace(req.ProposedChange) == "" {
+		return fmt.Errorf("a change session needs a proposed change")
+	}
+	return nil
+}
+
+// storyToRefine returns the story a session starts from: the initial user story, and for "change"
+// sessions the delivered story with its AC and the proposed change.
+func storyToRefine(req *domain.RefinementRequest) string {
+	if req.SessionType != domain.SessionTypeChange {
+		return req.InitialUserStory
+	}
+	var b strings.Builder
+	b.WriteString("已交付的故事：\n" + strings.TrimSpace(req.InitialUserStory) + "\n")
+	if len(req.DeliveredAC) > 0 {
+		b.WriteString("已交付的驗收標準：\n")
+		for i, criterion := range req.DeliveredAC {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, criterion)
+		}
+	}
+	b.WriteString("提議的變更：\n" + strings.TrimSpace(req.ProposedChange))
+	return b.String()
+}
+
+// ValidateSessionType returns an error for session types without a template.
+func ValidateSessionType(sessionType string) error {
+	if sessionType == "" || sessionType == domain.SessionTypeStory {
+		return nil
+	}
+	if _, ok := sessionTemplates[sessionType]; !ok {
+		return fmt.Errorf("unknown session type %q", sessionType)
+	}
+	return nil
+}
+
+// phasePromptsForType returns the phase prompts to start a session of the given type with: the app
+// config's prompts for user stories, and the type's own prompts with the app config's overrides
+// applied for other types.
+func phasePromptsForType(sessionType string, appConfig *configdomain.AppConfig) (map[string]string, error) {
+	if err := ValidateSessionType(sessionType); err != nil {
+		return nil, err
+	}
+	template, ok := sessionTemplates[sessionType]
+	if !ok {
+		return appConfig.PhasePrompts, nil
+	}
+	prompts := make(map[string]string, len(template.phasePrompts))
+	for phase, prompt := range template.phasePrompts {
+		prompts[phase] = prompt
+	}
+	for phase, prompt := range appConfig.SessionTypes[sessionType].PhasePrompts {
+		if prompt != "" {
+			prompts[phase] = prompt
+		}
+	}
+	return prompts, nil
+}
+
+// sessionPhasePrompts returns the phase prompts a session was started with when its type has its own,
+// and the given app config prompts for user stories.
+func sessionPhasePrompts(session *domain.RefinementSession, phasePrompts map[string]string) map[string]string {
+	if _, ok := sessionTemplates[session.Request.SessionType]; ok {
+		return session.PhasePrompts
+	}
+	return phasePrompts
+}
+
+// questioningPrompt returns the questioning prompt of a round: the "questioning_<n>" prompt with the
+// highest n not above the round, and the "questioning" prompt when there is none. Round-specific
+// prompts let a session type move through phases of questions, e.g. reproduction and then impact.
+func questioningPrompt(phasePrompts map[string]string, round int) string {
+	for n := round; n > 1; n-- {
+		if prompt := phasePrompts["questioning_"+strconv.Itoa(n)]; prompt != "" {
+			return prompt
+		}
+	}
+	return phasePrompts["questioning"]
+}
+
+// finalizeFormat returns the finalize prompt and output headings of a session's type, or ok false for
+// user stories.
+func finalizeFormat(session *domain.RefinementSession) (prompt, story, criteria string, ok bool) {
+	template, ok := sessionTemplates[session.Request.SessionType]
+	if !ok {
+		return "", "", "", false
+	}
+	return session.PhasePrompts["finalize"], template.storyHeading, template.criteriaHeading, true
+}
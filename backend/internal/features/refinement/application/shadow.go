@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+)
+
+// shadowRound silently runs a round's instruction on the session's shadow model in the background and
+// records its output next to the primary output. Shadow runs never affect the session.
+func (s *refinementService) shadowRound(ctx context.Context, session *domain.RefinementSession, operation, instruction string, primaryOutput any) {
+	shadowModel := session.Request.ShadowModel
+	if shadowModel == "" || s.shadowStore == nil {
+		return
+	}
+	client, primaryModel, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil || shadowModel == primaryModel {
+		return
+	}
+	prompt := ensemblePrompt(session, instruction)
+	run := domain.ShadowRun{
+		SessionID:     session.ID,
+		WorkspaceID:   session.WorkspaceID,
+		Operation:     operation,
+		Round:         session.CurrentRound,
+		PrimaryModel:  primaryModel,
+		PrimaryOutput: primaryOutput,
+		ShadowModel:   shadowModel,
+	}
+	ctx = context.WithoutCancel(ctx) // Shadow runs are recorded even when the request is aborted
+	go func() {
+		started := time.Now()
+		output, usage, err := client.CompleteWithUsage(ctx, shadowModel, ensembleSystemPrompt, prompt)
+		run.LatencyMs = time.Since(started).Milliseconds()
+		run.CreatedAt = time.Now()
+		if err != nil {
+			run.Error = err.Error()
+		} else {
+			run.ShadowOutput = output
+			run.PromptTokens = usage.PromptTokens
+			run.CompletionTokens = usage.CompletionTokens
+			run.TotalTokens = usage.TotalTokens
+		}
+		if err := s.shadowStore.Append(run); err != nil {
+			logging.From(ctx).Warn().Err(err).Msg("Failed to record shadow run")
+		}
+	}()
+}
+
+// ListShadowRuns returns the recorded shadow runs of a shadow model, or of every model when empty.
+func (s *refinementService) ListShadowRuns(shadowModel string) ([]domain.ShadowRun, error) {
+	if s.shadowStore == nil {
+		return []domain.ShadowRun{}, nil
+	}
+	return s.shadowStore.List(shadowModel)
+}
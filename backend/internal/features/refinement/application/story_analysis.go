@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const storyAnalysisSystemPrompt = `You assess how ready backlog items are for development, before they are refined.
+Rate the readiness of the given story from 0 (not ready at all) to 100 (ready for development) and list the categories of information it is missing, using these names when they apply: "user role", "business value", "acceptance criteria", "scope", "edge cases", "error handling", "non-functional requirements", "dependencies", "data", "ui/ux".
+Summarize in one sentence what most needs clarifying.
+Return only JSON: {"readiness": 40, "missing_info": ["acceptance criteria", "edge cases"], "summary": "..."}`
+
+// AnalyzeStory rates the readiness of a story without starting a session, using the AI provider of
+// the given workspace.
+func (s *refinementService) AnalyzeStory(ctx context.Context, workspaceID, title, description string) (*domain.StoryAnalysis, error) {
+	if strings.TrimSpace(title) == "" && strings.TrimSpace(description) == "" {
+		return nil, fmt.Errorf("the story is empty")
+	}
+	client, model, err := s.clientFor(workspaceID, "")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.Complete(ctx, model, storyAnalysisSystemPrompt, "Title: "+title+"\n\nDescription:\n"+description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze story: %w", err)
+	}
+	var analysis domain.StoryAnalysis
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse story analysis from AI: %w, raw response: %s", err, raw)
+	}
+	analysis.Readiness = min(max(analysis.Readiness, 0), 100)
+	if analysis.MissingInfo == nil {
+		analysis.MissingInfo = []string{}
+	}
+	return &analysis, nil
+}
@@ -0,0 +1,102 @@
+package application
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+var (
+	defaultMaxSentenceLength = 120
+	defaultUserClauses       = []string{"as a", "as an", "作為", "身為", "身爲"}
+	defaultBenefitClauses    = []string{"so that", "in order to", "以便", "從而", "讓我", "使我", "以利"}
+	defaultUntestableTerms   = []string{"fast", "quick", "user-friendly", "easy", "intuitive", "robust", "seamless", "快速", "友善", "友好", "簡單", "直觀", "流暢", "順暢", "良好"}
+
+	sentenceSplitter = regexp.MustCompile(`[。！？!?\n]+|\.\s+`)
+	englishPassive   = regexp.MustCompile(`(?i)\b(is|are|was|were|be|been|being)\s+\w+ed\b`)
+)
+
+// LintStory runs the configured readability and style checks on a finalized story and its AC.
+func LintStory(userStory string, ac []string, cfg configdomain.StyleLintConfig) []domain.LintFinding {
+	findings := []domain.LintFinding{}
+	if cfg.Disabled {
+		return findings
+	}
+
+	maxLen := cfg.MaxSentenceLength
+	if maxLen <= 0 {
+		maxLen = defaultMaxSentenceLength
+	}
+	userClauses := orDefault(cfg.UserClauses, defaultUserClauses)
+	benefitClauses := orDefault(cfg.BenefitClauses, defaultBenefitClauses)
+	untestable := orDefault(cfg.UntestableTerms, defaultUntestableTerms)
+
+	if !containsAny(userStory, userClauses) {
+		findings = append(findings, domain.LintFinding{Rule: "missing_user_clause", Severity: "warning", Location: "user_story", Message: "用戶故事缺少明確的用戶角色（例如「作為…」）"})
+	}
+	if !containsAny(userStory, benefitClauses) {
+		findings = append(findings, domain.LintFinding{Rule: "missing_benefit_clause", Severity: "warning", Location: "user_story", Message: "用戶故事缺少價值/效益描述（例如「以便…」）"})
+	}
+
+	check := func(text, location string) {
+		for _, sentence := range sentenceSplitter.Split(text, -1) {
+			sentence = strings.TrimSpace(sentence)
+			if n := utf8.RuneCountInString(sentence); n > maxLen {
+				findings = append(findings, domain.LintFinding{Rule: "sentence_length", Severity: "info", Location: location, Message: fmt.Sprintf("句子過長（%d 字，上限 %d）：%s", n, maxLen, truncate(sentence, 40))})
+			}
+			if cfg.CheckPassiveVoice && (englishPassive.MatchString(sentence) || strings.Contains(sentence, "被")) {
+				findings = append(findings, domain.LintFinding{Rule: "passive_voice", Severity: "info", Location: location, Message: "建議改用主動語態：" + truncate(sentence, 40)})
+			}
+		}
+	}
+
+	check(userStory, "user_story")
+	for i, item := range ac {
+		location := fmt.Sprintf("ac[%d]", i)
+		check(item, location)
+		if hasDigit(item) {
+			continue // A measurable threshold makes the term testable
+		}
+		lower := strings.ToLower(item)
+		for _, term := range untestable {
+			if strings.Contains(lower, strings.ToLower(term)) {
+				findings = append(findings, domain.LintFinding{Rule: "untestable_term", Severity: "warning", Location: location, Message: fmt.Sprintf("「%s」無法直接驗證，請加入可量測的標準", term)})
+			}
+		}
+	}
+	return findings
+}
+
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+func containsAny(text string, needles []string) bool {
+	lower := strings.ToLower(text)
+	for _, n := range needles {
+		if strings.Contains(lower, strings.ToLower(n)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDigit(text string) bool {
+	return strings.IndexFunc(text, unicode.IsDigit) >= 0
+}
+
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
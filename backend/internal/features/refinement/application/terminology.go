@@ -0,0 +1,133 @@
+package application
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// CheckTerminology cross-checks the finalized output against the glossary and previously
+// finalized stories, flagging variants that should be replaced by the canonical term.
+func (s *refinementService) CheckTerminology(sessionID string, glossary []configdomain.GlossaryTerm) ([]domain.TermFinding, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+
+	sessionsMutex.RLock()
+	var previous []string
+	for id, other := range sessions {
+		if id != sessionID && other.FinalizedAt != nil {
+			previous = append(previous, other.FinalUserStory+"\n"+strings.Join(other.FinalAC, "\n"))
+		}
+	}
+	sessionsMutex.RUnlock()
+
+	sections := finalSections(session)
+	findings := []domain.TermFinding{}
+	for _, term := range glossary {
+		// Variants listed in the glossary are always inconsistent
+		for _, variant := range term.Variants {
+			for _, sec := range sections {
+				if termPattern(variant).MatchString(sec.text) {
+					findings = append(findings, domain.TermFinding{Term: term.Term, Found: variant, Location: sec.location, Reason: "glossary", Replacement: term.Term})
+				}
+			}
+		}
+
+		// The canonical term itself is inconsistent if earlier stories settled on a different wording
+		preferred := preferredWording(term, previous)
+		if preferred == "" || preferred == term.Term {
+			continue
+		}
+		for _, sec := range sections {
+			if termPattern(term.Term).MatchString(sec.text) {
+				findings = append(findings, domain.TermFinding{Term: term.Term, Found: term.Term, Location: sec.location, Reason: "previous_stories", Replacement: preferred})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// ApplyTermCorrections rewrites the finalized output with the given corrections, or with
+// every suggested correction when none are given.
+func (s *refinementService) ApplyTermCorrections(sessionID string, glossary []configdomain.GlossaryTerm, corrections []domain.TermCorrection) (*domain.RefinementSession, error) {
+	if len(corrections) == 0 {
+		findings, err := s.CheckTerminology(sessionID, glossary)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range findings {
+			corrections = append(corrections, domain.TermCorrection{Found: f.Found, Replacement: f.Replacement})
+		}
+	}
+	return s.UpdateSession(sessionID, func(session *domain.RefinementSession) {
+		for _, c := range corrections {
+			if c.Found == "" {
+				continue
+			}
+			pattern := termPattern(c.Found)
+			session.FinalUserStory = pattern.ReplaceAllLiteralString(session.FinalUserStory, c.Replacement)
+			for i := range session.FinalAC {
+				session.FinalAC[i] = pattern.ReplaceAllLiteralString(session.FinalAC[i], c.Replacement)
+			}
+		}
+		session.Translations = nil // Translations no longer match the corrected output
+		session.Gherkin = nil
+		session.TestCases = nil
+		session.QualityScore = nil
+	})
+}
+
+type textSection struct {
+	location string
+	text     string
+}
+
+func finalSections(session *domain.RefinementSession) []textSection {
+	sections := []textSection{{location: "user_story", text: session.FinalUserStory}}
+	for i, item := range session.FinalAC {
+		sections = append(sections, textSection{location: fmt.Sprintf("ac[%d]", i), text: item})
+	}
+	return sections
+}
+
+// preferredWording returns the wording of a term group used most in previous stories.
+func preferredWording(term configdomain.GlossaryTerm, previous []string) string {
+	best, bestCount := "", 0
+	for _, wording := range append([]string{term.Term}, term.Variants...) {
+		count := 0
+		pattern := termPattern(wording)
+		for _, text := range previous {
+			count += len(pattern.FindAllStringIndex(text, -1))
+		}
+		if count > bestCount {
+			best, bestCount = wording, count
+		}
+	}
+	return best
+}
+
+// termPattern matches a term case-insensitively, on word boundaries for ASCII terms.
+func termPattern(term string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(term)
+	if isASCII(term) {
+		quoted = `\b` + quoted + `\b`
+	}
+	return regexp.MustCompile(`(?i)` + quoted)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
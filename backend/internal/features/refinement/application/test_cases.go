@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const testCasesSystemPrompt = `You are a QA engineer deriving test cases from a user story and its numbered acceptance criteria.
+Write concrete, executable test cases covering every criterion: the expected behavior, rejected invalid input or actions, boundaries, and non-functional concerns the story implies. Use concrete example values. Each case has a short title, its type ("positive", "negative", "edge" or "non_functional"), the number of the criterion it covers (0 when it covers several), the preconditions, the numbered steps without numbers, and a single observable expected result.
+Keep the language of the story.
+Return only JSON: {"test_cases": [{"title": "...", "type": "positive", "criterion": 1, "preconditions": ["..."], "steps": ["..."], "expected_result": "..."}]}`
+
+// testCaseTypes are the types of test cases.
+var testCaseTypes = []string{domain.TestCasePositive, domain.TestCaseNegative, domain.TestCaseEdge, domain.TestCaseNonFunctional}
+
+// WriteTestCases derives test cases from the finalized story and AC of a session and stores them,
+// replacing earlier ones. Cases without steps or an expected result are dropped with a warning, as
+// they cannot be run.
+func (s *refinementService) WriteTestCases(ctx context.Context, sessionID string) (*domain.TestCaseSuite, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("User story:\n" + session.FinalUserStory + "\n\nAcceptance criteria:\n")
+	for i, criterion := range session.FinalAC {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, criterion)
+	}
+	raw, err := client.Complete(ctx, model, testCasesSystemPrompt, b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write test cases: %w", err)
+	}
+	var suite domain.TestCaseSuite
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test cases from AI: %w, raw response: %s", err, raw)
+	}
+
+	cases := []domain.TestCase{}
+	for _, tc := range suite.Cases {
+		if len(tc.Steps) == 0 || strings.TrimSpace(tc.ExpectedResult) == "" {
+			addWarning(ctx, domain.WarningTestCaseDropped, "測試案例「%s」缺少步驟或預期結果，已略過", tc.Title)
+			continue
+		}
+		if !slices.Contains(testCaseTypes, tc.Type) {
+			tc.Type = domain.TestCasePositive
+		}
+		if tc.Criterion < 0 || tc.Criterion > len(session.FinalAC) {
+			tc.Criterion = 0
+		}
+		if tc.Preconditions == nil {
+			tc.Preconditions = []string{}
+		}
+		cases = append(cases, tc)
+	}
+	suite.Cases = cases
+	suite.CreatedAt = time.Now()
+
+	if _, err := mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.TestCases = &suite
+		session.Warnings = append(session.Warnings, warnings.list()...)
+	}); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
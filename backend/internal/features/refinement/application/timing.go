@@ -0,0 +1,100 @@
+package application
+
+import (
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// recordTiming appends the timing of an operation requested at requestedAt and completing now. The
+// PM's time is measured from the previous operation's output. Call it before the operation changes the
+// session's phase or round. Callers must hold sessionsMutex (e.g. call it inside mutateSession).
+func recordTiming(session *domain.RefinementSession, operation string, requestedAt time.Time) {
+	now := time.Now()
+	timing := domain.OperationTiming{
+		Operation:   operation,
+		Phase:       session.Phase,
+		Round:       session.CurrentRound,
+		AISeconds:   now.Sub(requestedAt).Seconds(),
+		RequestedAt: requestedAt,
+		CompletedAt: now,
+	}
+	if n := len(session.Timings); n > 0 {
+		timing.HumanSeconds = max(requestedAt.Sub(session.Timings[n-1].CompletedAt).Seconds(), 0)
+	}
+	session.Timings = append(session.Timings, timing)
+}
+
+// SessionTiming summarizes the human and AI time of a session per phase.
+func (s *refinementService) SessionTiming(sessionID string) (*domain.SessionTiming, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeTiming(session), nil
+}
+
+// TimingReport aggregates the timings of all sessions, optionally of one workspace or user. Tutorial
+// sessions are left out.
+func (s *refinementService) TimingReport(workspaceID, userID string) *domain.TimingReport {
+	report := &domain.TimingReport{ByPhase: make(map[domain.RefinementPhase]domain.PhaseTime)}
+	var wallClock, toFinalize float64
+	for _, session := range s.ListSessions() {
+		if session.IsTutorial() || len(session.Timings) == 0 {
+			continue
+		}
+		if (workspaceID != "" && session.WorkspaceID != workspaceID) || (userID != "" && session.UserID != userID) {
+			continue
+		}
+		timing := summarizeTiming(session)
+		report.Sessions++
+		report.HumanSeconds += timing.HumanSeconds
+		report.AISeconds += timing.AISeconds
+		wallClock += timing.WallClockSeconds
+		for phase, t := range timing.ByPhase {
+			total := report.ByPhase[phase]
+			total.HumanSeconds += t.HumanSeconds
+			total.AISeconds += t.AISeconds
+			total.Operations += t.Operations
+			report.ByPhase[phase] = total
+		}
+		for _, op := range session.Timings {
+			if op.Operation == "finalize" {
+				report.Finalized++
+				toFinalize += op.CompletedAt.Sub(session.Timings[0].RequestedAt).Seconds()
+				break
+			}
+		}
+	}
+	if report.Sessions > 0 {
+		n := float64(report.Sessions)
+		report.AverageHumanSeconds = report.HumanSeconds / n
+		report.AverageAISeconds = report.AISeconds / n
+		report.AverageWallClockSeconds = wallClock / n
+	}
+	if report.Finalized > 0 {
+		report.AverageToFinalize = toFinalize / float64(report.Finalized)
+	}
+	return report
+}
+
+func summarizeTiming(session *domain.RefinementSession) *domain.SessionTiming {
+	timing := &domain.SessionTiming{
+		SessionID:  session.ID,
+		ByPhase:    make(map[domain.RefinementPhase]domain.PhaseTime),
+		Operations: append([]domain.OperationTiming{}, session.Timings...),
+	}
+	for _, op := range session.Timings {
+		timing.HumanSeconds += op.HumanSeconds
+		timing.AISeconds += op.AISeconds
+		phase := timing.ByPhase[op.Phase]
+		phase.HumanSeconds += op.HumanSeconds
+		phase.AISeconds += op.AISeconds
+		phase.Operations++
+		timing.ByPhase[op.Phase] = phase
+	}
+	if n := len(session.Timings); n > 0 {
+		timing.WallClockSeconds = session.Timings[n-1].CompletedAt.Sub(session.Timings[0].RequestedAt).Seconds()
+	}
+	return timing
+}
@@ -0,0 +1,202 @@
+package application
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/tracing"
+)
+
+// tracedService decorates a RefinementService with a span per AI operation. The assistant runs of an
+// operation are traced as its children by runAssistant, and the provider calls by the tracing client.
+type tracedService struct {
+	RefinementService
+}
+
+// NewTracedService wraps a service so its AI operations are traced when tracing is configured.
+func NewTracedService(service RefinementService) RefinementService {
+	if !tracing.Configured() {
+		return service
+	}
+	return &tracedService{RefinementService: service}
+}
+
+// startSpan starts the span of an operation on a session.
+func startSpan(ctx context.Context, operation, sessionID string) (context.Context, func(error)) {
+	ctx, span := tracing.Start(ctx, "refinement."+operation, attribute.String("refinement.session.id", sessionID))
+	return ctx, func(err error) { tracing.End(span, err) }
+}
+
+func (s *tracedService) StartSession(ctx context.Context, req *domain.RefinementRequest, productContext string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "StartSession", "")
+	session, err := s.RefinementService.StartSession(ctx, req, productContext, rolePrompts, phasePrompts, phaseFormatExamples)
+	if session != nil {
+		tracing.Event(ctx, "session.started", attribute.String("refinement.session.id", session.ID))
+	}
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) SubmitAnswersAndContinue(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "SubmitAnswersAndContinue", sessionID)
+	session, err := s.RefinementService.SubmitAnswersAndContinue(ctx, sessionID, answers, additionalInfo, rolePrompts, phasePrompts, phaseFormatExamples)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) SubmitAnswersAndGetSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "SubmitAnswersAndGetSuggestions", sessionID)
+	session, err := s.RefinementService.SubmitAnswersAndGetSuggestions(ctx, sessionID, answers, additionalInfo, rolePrompts, phasePrompts, phaseFormatExamples)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) PrefetchSuggestions(ctx context.Context, sessionID string, answers map[string]string, additionalInfo string, rolePrompts, phasePrompts map[string]string, phaseFormatExamples map[string][]configdomain.PhaseFormatExample) (bool, error) {
+	ctx, end := startSpan(ctx, "PrefetchSuggestions", sessionID)
+	started, err := s.RefinementService.PrefetchSuggestions(ctx, sessionID, answers, additionalInfo, rolePrompts, phasePrompts, phaseFormatExamples)
+	end(err)
+	return started, err
+}
+
+func (s *tracedService) AcceptSuggestions(ctx context.Context, sessionID string, acceptedSuggestions []domain.Suggestion, nextPhase string, additionalInfo string) (*domain.RefinementSession, []domain.Suggestion, error) {
+	ctx, end := startSpan(ctx, "AcceptSuggestions", sessionID)
+	session, suggestions, err := s.RefinementService.AcceptSuggestions(ctx, sessionID, acceptedSuggestions, nextPhase, additionalInfo)
+	end(err)
+	return session, suggestions, err
+}
+
+func (s *tracedService) Finalize(ctx context.Context, sessionID string, currentPhase string, currentAnswers map[string]string, currentSuggestions []string, modificationSuggestion string) (string, []string, string, error) {
+	ctx, end := startSpan(ctx, "Finalize", sessionID)
+	userStory, ac, raw, err := s.RefinementService.Finalize(ctx, sessionID, currentPhase, currentAnswers, currentSuggestions, modificationSuggestion)
+	end(err)
+	return userStory, ac, raw, err
+}
+
+func (s *tracedService) Resume(ctx context.Context, sessionID string) (*domain.RefinementSession, *domain.ResumeReport, error) {
+	ctx, end := startSpan(ctx, "Resume", sessionID)
+	session, report, err := s.RefinementService.Resume(ctx, sessionID)
+	end(err)
+	return session, report, err
+}
+
+func (s *tracedService) Fork(ctx context.Context, sessionID, userID string) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "Fork", sessionID)
+	session, err := s.RefinementService.Fork(ctx, sessionID, userID)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) UndoRound(ctx context.Context, sessionID string) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "UndoRound", sessionID)
+	session, err := s.RefinementService.UndoRound(ctx, sessionID)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) CheckAnswer(ctx context.Context, req *domain.CheckAnswerRequest) (*domain.AnswerQualityHint, error) {
+	ctx, end := startSpan(ctx, "CheckAnswer", req.SessionID)
+	hint, err := s.RefinementService.CheckAnswer(ctx, req)
+	end(err)
+	return hint, err
+}
+
+func (s *tracedService) Translate(ctx context.Context, sessionID, targetLanguage string) (*domain.TranslatedOutput, error) {
+	ctx, end := startSpan(ctx, "Translate", sessionID)
+	output, err := s.RefinementService.Translate(ctx, sessionID, targetLanguage)
+	end(err)
+	return output, err
+}
+
+func (s *tracedService) ProposeEndpoints(ctx context.Context, sessionID string) ([]domain.EndpointStub, error) {
+	ctx, end := startSpan(ctx, "ProposeEndpoints", sessionID)
+	stubs, err := s.RefinementService.ProposeEndpoints(ctx, sessionID)
+	end(err)
+	return stubs, err
+}
+
+func (s *tracedService) RunOptionalPhase(ctx context.Context, sessionID, phase string) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "RunOptionalPhase", sessionID)
+	session, err := s.RefinementService.RunOptionalPhase(ctx, sessionID, phase)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) AdvancePhase(ctx context.Context, sessionID string, req *domain.AdvancePhaseRequest) (*domain.RefinementSession, error) {
+	ctx, end := startSpan(ctx, "AdvancePhase", sessionID)
+	session, err := s.RefinementService.AdvancePhase(ctx, sessionID, req)
+	end(err)
+	return session, err
+}
+
+func (s *tracedService) AddContext(ctx context.Context, sessionID, label, content string) error {
+	ctx, end := startSpan(ctx, "AddContext", sessionID)
+	err := s.RefinementService.AddContext(ctx, sessionID, label, content)
+	end(err)
+	return err
+}
+
+func (s *tracedService) ScoreStory(ctx context.Context, sessionID string, rubric configdomain.ScoringRubric) (*domain.StoryScore, error) {
+	ctx, end := startSpan(ctx, "ScoreStory", sessionID)
+	score, err := s.RefinementService.ScoreStory(ctx, sessionID, rubric)
+	end(err)
+	return score, err
+}
+
+func (s *tracedService) ParseBulkAnswers(ctx context.Context, sessionID, content, format string) (*domain.BulkAnswers, error) {
+	ctx, end := startSpan(ctx, "ParseBulkAnswers", sessionID)
+	answers, err := s.RefinementService.ParseBulkAnswers(ctx, sessionID, content, format)
+	end(err)
+	return answers, err
+}
+
+func (s *tracedService) ClassifyQuestions(ctx context.Context, workspaceID string, questions []string) (*domain.QuestionInsights, error) {
+	ctx, end := startSpan(ctx, "ClassifyQuestions", "")
+	insights, err := s.RefinementService.ClassifyQuestions(ctx, workspaceID, questions)
+	end(err)
+	return insights, err
+}
+
+func (s *tracedService) AnalyzeStory(ctx context.Context, workspaceID, title, description string) (*domain.StoryAnalysis, error) {
+	ctx, end := startSpan(ctx, "AnalyzeStory", "")
+	analysis, err := s.RefinementService.AnalyzeStory(ctx, workspaceID, title, description)
+	end(err)
+	return analysis, err
+}
+
+func (s *tracedService) CheckConsistency(ctx context.Context, sessionID string) (*domain.ConsistencyReport, error) {
+	ctx, end := startSpan(ctx, "CheckConsistency", sessionID)
+	report, err := s.RefinementService.CheckConsistency(ctx, sessionID)
+	end(err)
+	return report, err
+}
+
+func (s *tracedService) WriteGherkin(ctx context.Context, sessionID string) (*domain.GherkinFeature, error) {
+	ctx, end := startSpan(ctx, "WriteGherkin", sessionID)
+	feature, err := s.RefinementService.WriteGherkin(ctx, sessionID)
+	end(err)
+	return feature, err
+}
+
+func (s *tracedService) WriteTestCases(ctx context.Context, sessionID string) (*domain.TestCaseSuite, error) {
+	ctx, end := startSpan(ctx, "WriteTestCases", sessionID)
+	suite, err := s.RefinementService.WriteTestCases(ctx, sessionID)
+	end(err)
+	return suite, err
+}
+
+func (s *tracedService) PreviewRole(ctx context.Context, req *domain.RolePreviewRequest, role, rolePrompt, productContext string) (*domain.RolePreview, error) {
+	ctx, end := startSpan(ctx, "PreviewRole", "")
+	preview, err := s.RefinementService.PreviewRole(ctx, req, role, rolePrompt, productContext)
+	end(err)
+	return preview, err
+}
+
+func (s *tracedService) Polish(ctx context.Context, sessionID, message string) (*domain.PolishResponse, error) {
+	ctx, end := startSpan(ctx, "Polish", sessionID)
+	resp, err := s.RefinementService.Polish(ctx, sessionID, message)
+	end(err)
+	return resp, err
+}
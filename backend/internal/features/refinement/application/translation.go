@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+const translationSystemPrompt = `You are a professional translator for software requirements.
+Translate the given user story and acceptance criteria into %s.
+Preserve the structure exactly: the same number of acceptance criteria in the same order, product names and technical terms unchanged.
+Return only JSON: {"user_story": "...", "ac": ["...", "..."]}`
+
+// Translate produces the finalized story and AC in the target language, caching the result on the session.
+func (s *refinementService) Translate(ctx context.Context, sessionID, targetLanguage string) (*domain.TranslatedOutput, error) {
+	session, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt == nil {
+		return nil, fmt.Errorf("session %s has not been finalized", sessionID)
+	}
+	if cached, ok := session.Translations[targetLanguage]; ok {
+		return &cached, nil
+	}
+
+	client, model, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := json.Marshal(map[string]any{"user_story": session.FinalUserStory, "ac": session.FinalAC})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal finalized output: %w", err)
+	}
+	raw, err := client.Complete(ctx, model, fmt.Sprintf(translationSystemPrompt, targetLanguage), string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate: %w", err)
+	}
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "```json") && strings.HasSuffix(raw, "```") {
+		raw = strings.TrimPrefix(raw, "```json\n")
+		raw = strings.TrimSuffix(raw, "\n```")
+	}
+
+	translated := domain.TranslatedOutput{Language: targetLanguage}
+	if err := json.Unmarshal([]byte(raw), &translated); err != nil {
+		return nil, fmt.Errorf("failed to parse translation from AI: %w, raw response: %s", err, raw)
+	}
+	if len(translated.AC) != len(session.FinalAC) {
+		return nil, fmt.Errorf("translation changed the number of acceptance criteria from %d to %d", len(session.FinalAC), len(translated.AC))
+	}
+
+	if _, err := mutateSession(sessionID, func(session *domain.RefinementSession) {
+		if session.Translations == nil {
+			session.Translations = make(map[string]domain.TranslatedOutput)
+		}
+		session.Translations[targetLanguage] = translated
+	}); err != nil {
+		return nil, err
+	}
+
+	return &translated, nil
+}
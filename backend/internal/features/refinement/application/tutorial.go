@@ -0,0 +1,20 @@
+package application
+
+import (
+	"context"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// StartTutorial starts a tutorial session for a new user. It runs the regular refinement flow with the
+// app config's prompts, but in the tutorial workspace, whose scripted provider spends no tokens.
+func StartTutorial(ctx context.Context, service RefinementService, userID string, appConfig *configdomain.AppConfig) (*domain.RefinementSession, error) {
+	req := &domain.RefinementRequest{
+		InitialUserStory: domain.TutorialStory,
+		SelectedRoles:    append([]string(nil), domain.TutorialRoles...),
+		WorkspaceID:      domain.TutorialWorkspaceID,
+		UserID:           userID,
+		TargetRounds:     domain.TutorialTargetRounds,
+	}
+	return service.StartSession(ctx, req, appConfig.ProductContext, appConfig.RolePrompts, appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// maxRoundCheckpoints caps the rounds of a session that can be undone; older checkpoints are dropped.
+const maxRoundCheckpoints = 5
+
+// roundCheckpoint captures the round state of a session snapshot, before an operation replaces it. The
+// checkpoint shares the snapshot's questions and suggestions, which the operation replaces rather than
+// modifies.
+func roundCheckpoint(session *domain.RefinementSession, operation string) domain.RoundCheckpoint {
+	return domain.RoundCheckpoint{
+		Operation:           operation,
+		Phase:               session.Phase,
+		CurrentRound:        session.CurrentRound,
+		Questions:           session.Questions,
+		Suggestions:         session.Suggestions,
+		PhaseOutputs:        maps.Clone(session.PhaseOutputs),
+		AskedQuestions:      append([]string(nil), session.AskedQuestions...),
+		AnsweredQuestions:   len(session.AnsweredQuestions),
+		AcceptedSuggestions: len(session.AcceptedSuggestions),
+		ConvergenceScore:    session.ConvergenceScore,
+		Converged:           session.Converged,
+		RoleQuestionStats:   maps.Clone(session.RoleQuestionStats),
+		CreatedAt:           time.Now(),
+	}
+}
+
+// pushCheckpoint keeps the checkpoint of a round an operation completed, so it can be undone. Callers
+// must hold sessionsMutex (e.g. call it inside mutateSession).
+func pushCheckpoint(session *domain.RefinementSession, checkpoint domain.RoundCheckpoint) {
+	session.RoundCheckpoints = append(session.RoundCheckpoints, checkpoint)
+	if excess := len(session.RoundCheckpoints) - maxRoundCheckpoints; excess > 0 {
+		session.RoundCheckpoints = append([]domain.RoundCheckpoint(nil), session.RoundCheckpoints[excess:]...)
+	}
+}
+
+// UndoRound reverts a session to the questions or suggestions it had before its latest round, dropping the
+// answers and accepted suggestions recorded since, and tells the assistant to disregard the undone
+// exchange. Rounds can be undone one after the other, up to maxRoundCheckpoints; finalized sessions
+// cannot be undone.
+func (s *refinementService) UndoRound(ctx context.Context, sessionID string) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.FinalizedAt != nil {
+		return nil, fmt.Errorf("%w: session %s is finalized", domain.ErrNothingToUndo, sessionID)
+	}
+	if len(session.RoundCheckpoints) == 0 {
+		return nil, fmt.Errorf("%w: session %s", domain.ErrNothingToUndo, sessionID)
+	}
+	ctx = sessionContext(ctx, session)
+	s.takePrefetch(ctx, session, "")
+	checkpoint := session.RoundCheckpoints[len(session.RoundCheckpoints)-1]
+
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var restored []byte
+	if len(checkpoint.Suggestions) > 0 {
+		restored, _ = json.Marshal(checkpoint.Suggestions)
+	} else {
+		restored, _ = json.Marshal(checkpoint.Questions)
+	}
+	message := fmt.Sprintf("[撤銷] PM 撤銷了上一輪：請忽略 PM 最近一次的回答、採納的建議與指示，以及你對其的回覆，視為從未發生。對話回到第 %d 輪，目前待處理的內容如下：\n%s", checkpoint.CurrentRound, restored)
+	if err := client.AddMessageToThread(ctx, session.ThreadID, message); err != nil {
+		return nil, fmt.Errorf("failed to add undo message to thread: %w", err)
+	}
+
+	undoneRound := session.CurrentRound
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		session.RoundCheckpoints = session.RoundCheckpoints[:len(session.RoundCheckpoints)-1]
+		session.Phase = checkpoint.Phase
+		session.CurrentRound = checkpoint.CurrentRound
+		session.Questions = append([]domain.Question(nil), checkpoint.Questions...)
+		session.Suggestions = append([]domain.Suggestion(nil), checkpoint.Suggestions...)
+		session.PhaseOutputs = checkpoint.PhaseOutputs
+		session.AskedQuestions = checkpoint.AskedQuestions
+		session.AnsweredQuestions = session.AnsweredQuestions[:min(checkpoint.AnsweredQuestions, len(session.AnsweredQuestions))]
+		session.AcceptedSuggestions = session.AcceptedSuggestions[:min(checkpoint.AcceptedSuggestions, len(session.AcceptedSuggestions))]
+		session.ConvergenceScore = checkpoint.ConvergenceScore
+		session.Converged = checkpoint.Converged
+		session.RoleQuestionStats = checkpoint.RoleQuestionStats
+		session.Warnings = nil
+		session.History = append(session.History, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publish(events.RoundUndone, session, map[string]any{"round": session.CurrentRound, "undone_round": undoneRound, "operation": checkpoint.Operation})
+	return session, nil
+}
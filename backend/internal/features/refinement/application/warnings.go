@@ -0,0 +1,49 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// warningsKey is the context key of the warning collector of an operation.
+type warningsKey struct{}
+
+// warningCollector gathers the warnings of an operation, which may come from concurrent steps.
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []domain.Warning
+}
+
+// collectWarnings returns a context collecting the warnings added with addWarning.
+func collectWarnings(ctx context.Context) (context.Context, *warningCollector) {
+	collector := &warningCollector{}
+	return context.WithValue(ctx, warningsKey{}, collector), collector
+}
+
+// addWarning adds a warning to the operation of the context. Warnings outside of an operation collecting
+// them, e.g. of background work, are dropped; the server logs still have them.
+func addWarning(ctx context.Context, code, format string, args ...any) {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return
+	}
+	warning := domain.Warning{Code: code, Message: fmt.Sprintf(format, args...)}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	for _, existing := range collector.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	collector.warnings = append(collector.warnings, warning)
+}
+
+// list returns the collected warnings.
+func (c *warningCollector) list() []domain.Warning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]domain.Warning(nil), c.warnings...)
+}
@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/events"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+)
+
+// checkTransition fails unless the workflow of a session allows moving from its current phase to the
+// given one. Sessions in a phase their workflow does not define, e.g. one removed from the workflow,
+// may move anywhere the workflow has.
+func checkTransition(session *domain.RefinementSession, to domain.RefinementPhase) error {
+	phases := session.WorkflowPhases()
+	if _, ok := configdomain.FindWorkflowPhase(phases, string(to)); !ok {
+		return fmt.Errorf("%w: the workflow of session %s has no %s phase", domain.ErrPhaseTransition, session.ID, to)
+	}
+	current, ok := configdomain.FindWorkflowPhase(phases, string(session.Phase))
+	if ok && !slices.Contains(current.Transitions, string(to)) {
+		return fmt.Errorf("%w: session %s cannot move from %s to %s", domain.ErrPhaseTransition, session.ID, session.Phase, to)
+	}
+	return nil
+}
+
+// phaseOutput returns the output of a phase of a session's workflow, empty for phases it does not define.
+func phaseOutput(session *domain.RefinementSession, phase string) string {
+	p, _ := configdomain.FindWorkflowPhase(session.WorkflowPhases(), phase)
+	return p.Output
+}
+
+// AdvancePhase moves a session to a phase of its workflow other than the built-in ones, which keep
+// their own operations. The answers to the current phase's questions are recorded, then the assistant
+// runs the phase's prompt: questions and suggestions replace the session's, structured output is kept
+// by phase.
+func (s *refinementService) AdvancePhase(ctx context.Context, sessionID string, req *domain.AdvancePhaseRequest) (*domain.RefinementSession, error) {
+	unlock := lockSession(sessionID)
+	defer unlock()
+	session, err := snapshotSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	phase, _ := configdomain.FindWorkflowPhase(session.WorkflowPhases(), req.Phase)
+	switch req.Phase {
+	case configdomain.WorkflowQuestioning, configdomain.WorkflowSuggesting, configdomain.WorkflowFinalizing:
+		return nil, fmt.Errorf("%w: %s is entered through its own operation", domain.ErrPhaseTransition, req.Phase)
+	}
+	if err := checkTransition(session, domain.RefinementPhase(req.Phase)); err != nil {
+		return nil, err
+	}
+	checkpoint := roundCheckpoint(session, "advance_phase")
+	requestedAt := time.Now()
+	ctx, warnings := collectWarnings(sessionContext(ctx, session))
+	s.takePrefetch(ctx, session, "")
+
+	client, _, err := s.clientFor(session.WorkspaceID, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	operation := "advance_phase"
+
+	userResponse := ""
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		for i := range session.Questions {
+			for _, p := range session.Questions[i].Prompt {
+				if ans, found := req.Answers[session.Questions[i].Role+"_"+p]; found {
+					session.Questions[i].Answer = ans
+					userResponse += fmt.Sprintf("PM Answer to %s's question \"%s\": %s\n", session.Questions[i].Role, p, ans)
+				}
+			}
+		}
+		tallyAnswers(session, req.Answers)
+		recordAnswers(session, req.Answers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(userResponse) != "" {
+		if err := client.AddMessageToThread(ctx, session.ThreadID, userResponse); err != nil {
+			return nil, fmt.Errorf("failed to add user response to thread: %w", err)
+		}
+	}
+
+	instructionMessage, schema := phaseInstruction(session, phase)
+	if strings.TrimSpace(req.AdditionalInfo) != "" {
+		instructionMessage = "補充資訊：\n" + req.AdditionalInfo + "\n\n" + instructionMessage
+	}
+	if err := client.AddMessageToThread(ctx, session.ThreadID, instructionMessage); err != nil {
+		return nil, fmt.Errorf("failed to add instruction message to thread: %w", err)
+	}
+	if err := s.runAssistant(ctx, client, session.ThreadID, session.AssistantID, tagsFor(session), operation, budgetFor(&session.Request, operation), schema); err != nil {
+		return nil, fmt.Errorf("failed to run assistant for phase %s: %w", phase.Name, err)
+	}
+	assistantMessages, err := client.GetAssistantResponse(ctx, session.ThreadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assistant response for phase %s: %w", phase.Name, err)
+	}
+	if len(assistantMessages) == 0 || len(assistantMessages[len(assistantMessages)-1].Content) == 0 {
+		return nil, fmt.Errorf("AI did not return any content for phase %s", phase.Name)
+	}
+	raw := assistantMessages[len(assistantMessages)-1].Content[0].Text.Value
+
+	var apply func(session *domain.RefinementSession)
+	switch phase.Output {
+	case configdomain.WorkflowOutputQuestions:
+		var questions []domain.Question
+		raw = s.checkOutput(ctx, client, session.ThreadID, session.AssistantID, tagsFor(session), operation, &session.Request, false, stripCodeFence(raw))
+		if err := json.Unmarshal([]byte(raw), &questions); err != nil {
+			return nil, fmt.Errorf("failed to parse questions of phase %s from AI: %w, raw response: %s", phase.Name, err, raw)
+		}
+		attachPriorAnswers(sessionID, session.WorkspaceID, questions)
+		apply = func(session *domain.RefinementSession) {
+			updateConvergence(session, questions)
+			session.Questions = questions
+			session.Suggestions = nil
+		}
+	case configdomain.WorkflowOutputSuggestions:
+		var suggestions []domain.Suggestion
+		raw = s.checkOutput(ctx, client, session.ThreadID, session.AssistantID, tagsFor(session), operation, &session.Request, true, stripCodeFence(raw))
+		if err := json.Unmarshal([]byte(raw), &suggestions); err != nil {
+			return nil, fmt.Errorf("failed to parse suggestions of phase %s from AI: %w, raw response: %s", phase.Name, err, raw)
+		}
+		suggestions = scrubSuggestions(session.Request.OutputFilter, suggestions)
+		apply = func(session *domain.RefinementSession) {
+			session.Questions = nil
+			session.Suggestions = suggestions
+		}
+	default:
+		output := stripCodeFence(raw)
+		if !json.Valid([]byte(output)) {
+			return nil, fmt.Errorf("failed to parse output of phase %s from AI, raw response: %s", phase.Name, raw)
+		}
+		apply = func(session *domain.RefinementSession) {
+			if session.PhaseOutputs == nil {
+				session.PhaseOutputs = make(map[string]json.RawMessage)
+			}
+			session.PhaseOutputs[phase.Name] = json.RawMessage(output)
+		}
+	}
+
+	session, err = mutateSession(sessionID, func(session *domain.RefinementSession) {
+		recordTiming(session, operation, requestedAt)
+		session.Warnings = warnings.list()
+		pushCheckpoint(session, checkpoint)
+		apply(session)
+		session.Phase = domain.RefinementPhase(phase.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.trackPromptDrift(ctx, session, operation)
+	s.publish(events.PhaseAdvanced, session, map[string]any{"phase": phase.Name})
+	return session, nil
+}
+
+// phaseInstruction builds the instruction of a workflow phase from its prompt, and returns the schema
+// its output is requested under. Phases asking roles for questions or suggestions get the role angles
+// and format examples the built-in phases get; structured phases get their schema.
+func phaseInstruction(session *domain.RefinementSession, phase configdomain.WorkflowPhase) (string, *infrastructure.ResponseSchema) {
+	phaseDesc := session.PhasePrompts[phase.PromptKey]
+	if strings.TrimSpace(phaseDesc) == "" {
+		phaseDesc = fmt.Sprintf("請針對目前的 User Story 進行「%s」階段的分析。", phase.Name)
+	}
+	if phase.Output == configdomain.WorkflowOutputStructured {
+		schema, _ := json.Marshal(phase.Schema)
+		return "基於當前的 User Story 和對話歷史：\n" + phaseDesc + "\n請勿加上任何說明、標題或條列，僅回傳符合以下 JSON Schema 的 JSON 物件：" + string(schema),
+			&infrastructure.ResponseSchema{Name: "phase_output", Schema: phase.Schema}
+	}
+
+	selectedRoles := session.Request.SelectedRoles
+	var rolePromptsString string
+	for _, role := range selectedRoles {
+		if prompt, ok := session.RolePrompts[role]; ok {
+			rolePromptsString += fmt.Sprintf("- %s: %s\n", role, prompt)
+		}
+	}
+	rolePromptsString += roleWeightNote(selectedRoles, session.Request.RoleWeights)
+	formatExample := ""
+	if arr, ok := session.PhaseFormatExamples[phase.PromptKey]; ok {
+		var filtered []configdomain.PhaseFormatExample
+		for _, ex := range arr {
+			if slices.Contains(selectedRoles, ex.Role) {
+				filtered = append(filtered, ex)
+			}
+		}
+		b, _ := json.Marshal(filtered)
+		formatExample = string(b)
+	}
+	return "基於當前的 User Story 和對話歷史，請根據下列角色角度：\n" + rolePromptsString + "\n" + phaseDesc + "\n格式範例：" + formatExample + "\n請勿加上任何說明、標題或條列，僅回傳 JSON 陣列。", roleItemsSchema
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TagAPI marks a story as an API feature. Finalizing it also proposes endpoint stubs.
+const TagAPI = "api"
+
+// EndpointStub is a proposed API endpoint of an API feature, a starting contract for the dev team.
+type EndpointStub struct {
+	Method         string         `json:"method"` // Upper case, e.g. "POST"
+	Path           string         `json:"path"`   // With OpenAPI path parameters, e.g. "/orders/{id}"
+	Summary        string         `json:"summary,omitempty"`
+	RequestSchema  map[string]any `json:"request_schema,omitempty"` // JSON Schema of the request body, nil without a body
+	ResponseStatus int            `json:"response_status,omitempty"`
+	ResponseSchema map[string]any `json:"response_schema,omitempty"` // JSON Schema of the success response body
+}
+
+// HasTag reports whether the request is tagged with the given tag, ignoring case.
+func (r *RefinementRequest) HasTag(tag string) bool {
+	return slices.ContainsFunc(r.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// OpenAPIFragment returns the session's endpoint stubs as an OpenAPI 3 document with paths only.
+func OpenAPIFragment(session *RefinementSession) map[string]any {
+	paths := make(map[string]any)
+	for _, stub := range session.EndpointStubs {
+		operation := map[string]any{}
+		if stub.Summary != "" {
+			operation["summary"] = stub.Summary
+		}
+		if stub.RequestSchema != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": stub.RequestSchema}},
+			}
+		}
+		status := stub.ResponseStatus
+		if status == 0 {
+			status = 200
+		}
+		response := map[string]any{"description": "Success"}
+		if stub.ResponseSchema != nil {
+			response["content"] = map[string]any{"application/json": map[string]any{"schema": stub.ResponseSchema}}
+		}
+		operation["responses"] = map[string]any{strconv.Itoa(status): response}
+
+		item, ok := paths[stub.Path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[stub.Path] = item
+		}
+		item[strings.ToLower(stub.Method)] = operation
+	}
+
+	title := session.FinalUserStory
+	if title == "" {
+		title = session.UserStory
+	}
+	if runes := []rune(title); len(runes) > 80 {
+		title = string(runes[:80]) + "…"
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": title, "version": "0.1.0"},
+		"paths":   paths,
+	}
+}
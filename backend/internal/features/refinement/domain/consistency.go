@@ -0,0 +1,32 @@
+package domain
+
+// StoryConflict is a contradiction between a story and a previously finalized story of the same product,
+// e.g. conflicting business rules.
+type StoryConflict struct {
+	SessionID     string  `json:"session_id"` // Session of the conflicting story
+	StoryTitle    string  `json:"story_title"`
+	Similarity    float64 `json:"similarity"`            // Of the two stories, which made the story a candidate
+	Statement     string  `json:"statement"`             // What the checked story says
+	ConflictsWith string  `json:"conflicts_with"`        // What the earlier story says
+	Explanation   string  `json:"explanation,omitempty"` // Why the two cannot both hold
+	SessionURL    string  `json:"session_url,omitempty"` // Link to the earlier session, set with a public base URL
+}
+
+// ConsistencyReport lists the conflicts of a story with the previously finalized stories most similar to it.
+type ConsistencyReport struct {
+	SessionID string          `json:"session_id"`
+	Compared  int             `json:"compared"` // Number of earlier stories compared
+	Conflicts []StoryConflict `json:"conflicts"`
+}
+
+// WithLinks links the conflicts of the report to their sessions. It copies the conflicts, so the
+// report itself is not modified.
+func (r ConsistencyReport) WithLinks(sessionURL func(sessionID string) string) ConsistencyReport {
+	conflicts := make([]StoryConflict, len(r.Conflicts))
+	for i, conflict := range r.Conflicts {
+		conflict.SessionURL = sessionURL(conflict.SessionID)
+		conflicts[i] = conflict
+	}
+	r.Conflicts = conflicts
+	return r
+}
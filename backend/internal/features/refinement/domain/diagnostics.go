@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// SessionDiagnostics describes the health of a session, to help understand why it feels slow or broken.
+// Run and parse statistics cover the runs since the server started.
+type SessionDiagnostics struct {
+	SessionID              string       `json:"session_id"`
+	ThreadID               string       `json:"thread_id"`
+	ThreadMessages         int          `json:"thread_messages"`
+	EstimatedContextTokens int          `json:"estimated_context_tokens"`
+	ThreadError            string       `json:"thread_error,omitempty"` // Set when the thread could not be read
+	Runs                   int          `json:"runs"`
+	FailedRuns             int          `json:"failed_runs"`
+	LastRunOperation       string       `json:"last_run_operation,omitempty"`
+	LastRunSeconds         float64      `json:"last_run_seconds,omitempty"`
+	LastRunPromptTokens    int          `json:"last_run_prompt_tokens,omitempty"` // As reported by the provider
+	LastRunAt              *time.Time   `json:"last_run_at,omitempty"`
+	LastRunError           string       `json:"last_run_error,omitempty"`
+	ParseFailures          int          `json:"parse_failures"` // Outputs that could not be parsed, repaired or not
+	Locked                 bool         `json:"locked"`         // An operation is running on the session
+	LockedSeconds          float64      `json:"locked_seconds,omitempty"`
+	StaleLock              bool         `json:"stale_lock"`             // The operation has held the lock for suspiciously long
+	PromptDrift            *PromptDrift `json:"prompt_drift,omitempty"` // As measured after the last operation
+}
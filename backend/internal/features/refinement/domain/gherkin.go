@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Finalize output formats of the acceptance criteria.
+const (
+	FormatList    = "list"    // Numbered list, the default
+	FormatGherkin = "gherkin" // Given/When/Then scenarios in addition to the list
+)
+
+// GherkinScenario is an acceptance criterion written as a Given/When/Then scenario.
+type GherkinScenario struct {
+	Name      string   `json:"name"`
+	Criterion int      `json:"criterion,omitempty"` // Number of the AC the scenario covers, 0 when it covers several
+	Given     []string `json:"given"`
+	When      []string `json:"when"`
+	Then      []string `json:"then"`
+}
+
+// GherkinFeature is the final story as a Gherkin feature.
+type GherkinFeature struct {
+	Feature     string            `json:"feature"`
+	Description string            `json:"description,omitempty"`
+	Scenarios   []GherkinScenario `json:"scenarios"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// File renders the feature as the contents of a .feature file.
+func (f *GherkinFeature) File() string {
+	var b strings.Builder
+	b.WriteString("Feature: " + f.Feature + "\n")
+	for _, line := range strings.Split(strings.TrimSpace(f.Description), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	for _, scenario := range f.Scenarios {
+		b.WriteString("\n  Scenario: " + scenario.Name + "\n")
+		writeSteps(&b, "Given", scenario.Given)
+		writeSteps(&b, "When", scenario.When)
+		writeSteps(&b, "Then", scenario.Then)
+	}
+	return b.String()
+}
+
+// writeSteps writes the steps of a keyword, continuing with "And" after the first.
+func writeSteps(b *strings.Builder, keyword string, steps []string) {
+	for i, step := range steps {
+		if i > 0 {
+			keyword = "And"
+		}
+		b.WriteString("    " + keyword + " " + step + "\n")
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Kinds of product memory items.
+const (
+	MemoryKindDecision   = "decision"
+	MemoryKindConstraint = "constraint"
+	MemoryKindAnswer     = "answer" // A recurring answer to a baseline question
+)
+
+// MemoryItem is a piece of long-term product knowledge distilled from a finalized session.
+// Memory is kept per workspace; sessions without a workspace share the default product's memory.
+type MemoryItem struct {
+	ID              string    `json:"id"`
+	WorkspaceID     string    `json:"workspace_id,omitempty"`
+	Kind            string    `json:"kind"`
+	Content         string    `json:"content"`
+	SourceSessionID string    `json:"source_session_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,71 @@
+package domain
+
+import "time"
+
+// Optional phases run after the story is finalized, only for sessions that request them in
+// RefinementRequest.OptionalPhases or through the phase endpoint.
+const (
+	OptionalPhaseSecurity      = "security"      // STRIDE-style threat list and required security AC
+	OptionalPhaseCompliance    = "compliance"    // Required compliance AC and legal review flags for the configured regulations
+	OptionalPhaseAccessibility = "accessibility" // WCAG-based AC for stories that affect the UI
+)
+
+// Threat is a threat of the story's feature in one STRIDE category.
+type Threat struct {
+	Category   string `json:"category"` // Spoofing, Tampering, Repudiation, Information Disclosure, Denial of Service or Elevation of Privilege
+	Threat     string `json:"threat"`
+	Mitigation string `json:"mitigation,omitempty"`
+}
+
+// SecurityReview is the output of the security phase.
+type SecurityReview struct {
+	Threats    []Threat  `json:"threats"`
+	SecurityAC []string  `json:"security_ac"` // Acceptance criteria the story must meet to mitigate the threats
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
+// ComplianceFinding is a way the story touches a configured regulation.
+type ComplianceFinding struct {
+	Regulation  string `json:"regulation"`
+	Concern     string `json:"concern"`
+	LegalReview bool   `json:"legal_review"` // Legal must review the story before development
+}
+
+// ComplianceReview is the output of the compliance phase.
+type ComplianceReview struct {
+	Findings            []ComplianceFinding `json:"findings"`
+	ComplianceAC        []string            `json:"compliance_ac"` // Acceptance criteria the story must meet to comply
+	LegalReviewRequired bool                `json:"legal_review_required"`
+	ReviewedAt          time.Time           `json:"reviewed_at"`
+}
+
+// AccessibilityCriterion is an acceptance criterion derived from a WCAG success criterion.
+type AccessibilityCriterion struct {
+	Area      string `json:"area"`           // "keyboard", "contrast", "screen_reader", "focus", "motion" or "text"
+	WCAG      string `json:"wcag,omitempty"` // Success criterion, e.g. "2.1.1 Keyboard"
+	Criterion string `json:"criterion"`
+}
+
+// AccessibilityReview is the output of the accessibility phase.
+type AccessibilityReview struct {
+	UIAffecting bool                     `json:"ui_affecting"` // False when the story does not change the UI and has no criteria
+	Criteria    []AccessibilityCriterion `json:"criteria"`
+	ReviewedAt  time.Time                `json:"reviewed_at"`
+}
+
+// AccessibilityAC returns the accessibility criteria of the session as a distinct AC section, nil
+// without an accessibility review.
+func (s *RefinementSession) AccessibilityAC() []string {
+	if s.AccessibilityReview == nil {
+		return nil
+	}
+	var ac []string
+	for _, c := range s.AccessibilityReview.Criteria {
+		if c.WCAG != "" {
+			ac = append(ac, c.Criterion+" (WCAG "+c.WCAG+")")
+		} else {
+			ac = append(ac, c.Criterion)
+		}
+	}
+	return ac
+}
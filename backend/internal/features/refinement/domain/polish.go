@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Polish edit operations.
+const (
+	PolishSetStory  = "set_story"  // Replace the user story with Text
+	PolishReplaceAC = "replace_ac" // Replace the AC at Index with Text
+	PolishAddAC     = "add_ac"     // Insert Text as the AC at Index, or append it when Index is 0
+	PolishRemoveAC  = "remove_ac"  // Remove the AC at Index
+)
+
+// PolishDraft is a draft of the finalized story and AC that the PM polishes in a chat after finalize.
+// Each exchange edits the draft instead of running finalize again; applying the draft makes it the
+// session's final output.
+type PolishDraft struct {
+	UserStory string          `json:"user_story"`
+	AC        []string        `json:"ac"`
+	Revision  int             `json:"revision"` // Incremented by every exchange that edits the draft
+	Messages  []PolishMessage `json:"messages"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	AppliedAt *time.Time      `json:"applied_at,omitempty"` // Last time the draft became the final output
+}
+
+// PolishMessage is a message of the polish chat.
+type PolishMessage struct {
+	Role      string       `json:"role"` // "pm" or "assistant"
+	Content   string       `json:"content"`
+	Edits     []PolishEdit `json:"edits,omitempty"` // Edits of an assistant message applied to the draft
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// PolishEdit is an edit of the polish draft. AC indexes start at 1.
+type PolishEdit struct {
+	Op    string `json:"op"`
+	Index int    `json:"index,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// PolishRequest is the request structure for a message of the polish chat.
+type PolishRequest struct {
+	Message string `json:"message" binding:"required"` // e.g. "tighten AC 2" or "add an error-handling AC"
+}
+
+// PolishResponse is the assistant's reply to a polish message and the draft after its edits.
+type PolishResponse struct {
+	Reply    string       `json:"reply"`
+	Edits    []PolishEdit `json:"edits"`
+	Draft    *PolishDraft `json:"draft"`
+	Warnings []Warning    `json:"warnings,omitempty"`
+}
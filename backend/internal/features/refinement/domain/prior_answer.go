@@ -0,0 +1,41 @@
+package domain
+
+// PriorAnswer is the answer given in a past session of the same product to a question closely matching
+// a new one, offered to the PM as a suggested response.
+type PriorAnswer struct {
+	Question   string  `json:"question"` // The question as it was asked in the past session
+	Answer     string  `json:"answer"`
+	SessionID  string  `json:"session_id"`
+	StoryTitle string  `json:"story_title"`
+	Similarity float64 `json:"similarity"`
+	SourceURL  string  `json:"source_url,omitempty"` // Link to the past session, set with a public base URL
+}
+
+// WithPriorAnswerLinks links the prior answers of the response's questions to their sessions. It copies
+// the questions it changes, so the response can be built from a shared session.
+func (r SessionResponse) WithPriorAnswerLinks(sessionURL func(sessionID string) string) SessionResponse {
+	if r.RefinementSession == nil || sessionURL == nil {
+		return r
+	}
+	linked := false
+	questions := cloneQuestions(r.Questions)
+	for i, q := range questions {
+		for j, prior := range q.PriorAnswers {
+			if prior == nil {
+				continue
+			}
+			if url := sessionURL(prior.SessionID); url != "" {
+				withLink := *prior
+				withLink.SourceURL = url
+				questions[i].PriorAnswers[j] = &withLink
+				linked = true
+			}
+		}
+	}
+	if linked {
+		session := *r.RefinementSession
+		session.Questions = questions
+		r.RefinementSession = &session
+	}
+	return r
+}
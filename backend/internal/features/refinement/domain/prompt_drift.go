@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// PromptDrift measures how far the configured prompts of a session are from what the assistant actually
+// has in its context after an operation: the prompts as templated into the messages sent, minus the
+// messages the provider truncates once the thread outgrows the model's context window.
+type PromptDrift struct {
+	SessionID       string             `json:"session_id"`
+	Operation       string             `json:"operation"` // The operation after which the drift was measured
+	MeasuredAt      time.Time          `json:"measured_at"`
+	Model           string             `json:"model"`
+	ContextWindow   int                `json:"context_window"`
+	ContextTokens   int                `json:"context_tokens"`   // Estimated tokens of the whole thread
+	DroppedMessages int                `json:"dropped_messages"` // Oldest messages not fitting the context window
+	Prompts         []PromptDriftScore `json:"prompts"`
+	MaxDistance     float64            `json:"max_distance"`
+	Alert           bool               `json:"alert"` // MaxDistance reached the alert threshold
+}
+
+// PromptDriftScore is the embedding distance between a configured prompt and the context, from 0 for a
+// prompt fully present to 1 for a prompt entirely lost.
+type PromptDriftScore struct {
+	Prompt   string  `json:"prompt"` // "role:<role>" or "phase:<phase>"
+	Distance float64 `json:"distance"`
+}
+
+// PromptDriftReport lists the latest prompt drift of every session measured since the server started,
+// the most drifted first.
+type PromptDriftReport struct {
+	AlertThreshold float64       `json:"alert_threshold"`
+	Alerts         int           `json:"alerts"`
+	Sessions       []PromptDrift `json:"sessions"`
+}
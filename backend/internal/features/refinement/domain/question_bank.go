@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// AnsweredQuestion is a question of a round with the PM's answer to it.
+type AnsweredQuestion struct {
+	Role     string `json:"role"`
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Round    int    `json:"round"`
+}
+
+// BankQuestion is a recurring question of a product with its canonical answer. Questions are mined from
+// finalized sessions; admins curate them, and curated answers are no longer overwritten by mining.
+type BankQuestion struct {
+	ID               string    `json:"id"`
+	WorkspaceID      string    `json:"workspace_id,omitempty"`
+	Question         string    `json:"question"`
+	CanonicalAnswer  string    `json:"canonical_answer"`
+	Occurrences      int       `json:"occurrences"` // Sessions the question was asked in
+	SourceSessionIDs []string  `json:"source_session_ids,omitempty"`
+	Curated          bool      `json:"curated"` // Edited or added by an admin
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// BankQuestionRequest is an admin's new or edited bank question.
+type BankQuestionRequest struct {
+	Question        string `json:"question" binding:"required"`
+	CanonicalAnswer string `json:"canonical_answer"`
+}
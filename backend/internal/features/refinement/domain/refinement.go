@@ -1,6 +1,16 @@
 package domain
 
-import configdomain "sofa-commander/backend/internal/features/config/domain"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// ErrNotWorkspaceMember is returned when a user uses a workspace restricted to its members without being
+// one of them.
+var ErrNotWorkspaceMember = errors.New("not a member of the workspace")
 
 // TechStack defines the technology stack.
 type TechStack struct {
@@ -24,8 +34,39 @@ type RefinementRequest struct {
 		Backend  string `json:"backend"`
 		Agent    string `json:"agent"`
 	} `json:"tech_stack"`
-	ModelParams   ModelParams `json:"model_params"`
-	SelectedRoles []string    `json:"selected_roles"`
+	ModelParams    ModelParams                           `json:"model_params"`
+	SelectedRoles  []string                              `json:"selected_roles"`
+	WorkspaceID    string                                `json:"workspace_id,omitempty"`    // Selects the workspace whose AI provider is used
+	ProjectID      string                                `json:"project_id,omitempty"`      // Selects the project whose product context and prompts are used
+	UserID         string                                `json:"user_id,omitempty"`         // Set from the X-User-ID header for cost attribution
+	TargetRounds   int                                   `json:"target_rounds,omitempty"`   // Intended number of questioning rounds
+	Language       string                                `json:"language,omitempty"`        // Requested output language, e.g. "zh-TW"; checked on every round
+	SessionType    string                                `json:"session_type,omitempty"`    // "story" (default), "spike", "bug" or "change"
+	Tags           []string                              `json:"tags,omitempty"`            // e.g. "api" for API features
+	OptionalPhases []string                              `json:"optional_phases,omitempty"` // Run after finalize: "security", "compliance" or "accessibility"
+	Mockups        []Mockup                              `json:"mockups,omitempty"`         // UI mockups the story refers to
+	RoleLimits     map[string]configdomain.RoleLimit     `json:"role_limits,omitempty"`     // Filled from the app config when not given
+	RoleWeights    map[string]float64                    `json:"role_weights,omitempty"`    // Relative emphasis per role, 1 when not given
+	EnsembleModel  string                                `json:"ensemble_model,omitempty"`  // Second model generating suggestions alongside the assistant, filled from the app config when not given
+	ShadowModel    string                                `json:"-"`                         // Candidate model silently run on every round, set from the app config only
+	LatencyBudgets map[string]configdomain.LatencyBudget `json:"-"`                         // Set from the app config only
+	OutputFilter   configdomain.OutputFilterConfig       `json:"-"`                         // Set from the app config only
+	// DeliveredAC are the AC of the delivered story a "change" session changes; the story is the initial user story
+	DeliveredAC []string `json:"delivered_ac,omitempty"`
+	// DeliveredSessionID names a finalized session whose output is the delivered story, instead of giving it
+	DeliveredSessionID string `json:"delivered_session_id,omitempty"`
+	// ProposedChange is the change to the delivered story of a "change" session
+	ProposedChange string `json:"proposed_change,omitempty"`
+	// LocalizationAnalysis adds a suggestion group flagging localization impacts, enabled by the app config too
+	LocalizationAnalysis bool `json:"localization_analysis,omitempty"`
+	// AllowTranscriptMirroring consents to admins watching the session transcript live
+	AllowTranscriptMirroring bool `json:"allow_transcript_mirroring,omitempty"`
+}
+
+// Mockup is a UI mockup attached to a story.
+type Mockup struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"` // What the mockup shows, for prompts that cannot view images
 }
 
 // Question represents a question from a role.
@@ -33,14 +74,22 @@ type Question struct {
 	Role   string   `json:"role"`
 	Prompt []string `json:"prompt"`
 	Answer string   `json:"answer,omitempty"` // PM's answer to the question
+	// PriorAnswers holds the matching answer of a past session for each prompt, nil where none matched
+	PriorAnswers []*PriorAnswer `json:"prior_answers,omitempty"`
 }
 
 // Suggestion represents a suggestion from a role.
 type Suggestion struct {
-	Role   string   `json:"role"`
-	Prompt []string `json:"prompt"`
+	Role    string     `json:"role"`
+	Prompt  []string   `json:"prompt"`
+	Sources [][]string `json:"sources,omitempty"` // Models that proposed each prompt, set in ensemble mode
+	// Categories is the category of each prompt, set for the localization group (e.g. "rtl", "text_expansion")
+	Categories []string `json:"categories,omitempty"`
 }
 
+// LocalizationRole is the role of the suggestion group flagging localization impacts.
+const LocalizationRole = "Localization"
+
 // RefinementPhase defines the current phase of the refinement process.
 type RefinementPhase string
 
@@ -54,6 +103,13 @@ const (
 type RefinementSession struct {
 	ID                     string                                       `json:"id"`
 	ThreadID               string                                       `json:"thread_id"` // New: OpenAI Thread ID
+	AssistantID            string                                       `json:"assistant_id,omitempty"`
+	WorkspaceID            string                                       `json:"workspace_id,omitempty"`
+	ProjectID              string                                       `json:"project_id,omitempty"`
+	UserID                 string                                       `json:"user_id,omitempty"`
+	CreatedAt              time.Time                                    `json:"created_at"`
+	LastActivityAt         time.Time                                    `json:"last_activity_at"`     // Last change to the session
+	TranscriptMirroring    bool                                         `json:"transcript_mirroring"` // Admins may mirror the transcript live
 	Request                RefinementRequest                            `json:"request"`
 	UserStory              string                                       `json:"user_story"`
 	RolePrompts            map[string]string                            `json:"role_prompts"` // Store role prompts for continued questioning
@@ -63,8 +119,51 @@ type RefinementSession struct {
 	Suggestions            []Suggestion                                 `json:"suggestions,omitempty"` // Stores suggestions during SUGGESTING phase
 	History                []string                                     `json:"history,omitempty"`     // Stores conversation history
 	Phase                  RefinementPhase                              `json:"phase"`
+	Workflow               []configdomain.WorkflowPhase                 `json:"workflow,omitempty"`             // Phases configured when the session started, nil for the default workflow
+	PhaseOutputs           map[string]json.RawMessage                   `json:"phase_outputs,omitempty"`        // Output of the structured workflow phases, keyed by phase
+	CurrentRound           int                                          `json:"current_round"`                  // Questioning round, starting at 1
+	TargetRounds           int                                          `json:"target_rounds,omitempty"`        // Planned questioning rounds, 0 if unplanned
+	RoleWeights            map[string]float64                           `json:"role_weights,omitempty"`         // Relative emphasis per role for this session
+	RoleRules              []string                                     `json:"role_rules,omitempty"`           // Role rules that selected the roles, when none were given
+	AskedQuestions         []string                                     `json:"asked_questions,omitempty"`      // All questions asked so far, for convergence detection
+	AnsweredQuestions      []AnsweredQuestion                           `json:"answers,omitempty"`              // Every answered question, across rounds
+	AcceptedSuggestions    []AcceptedSuggestion                         `json:"accepted_suggestions,omitempty"` // Every accepted suggestion, across rounds
+	ConvergenceScore       float64                                      `json:"convergence_score,omitempty"`    // Share of the latest round's questions that repeat earlier ones
+	Converged              bool                                         `json:"converged"`                      // Hint to move on to suggestions/finalize
+	FinalUserStory         string                                       `json:"final_user_story,omitempty"`
+	FinalAC                []string                                     `json:"final_ac,omitempty"`
+	FinalizedAt            *time.Time                                   `json:"finalized_at,omitempty"`
+	Translations           map[string]TranslatedOutput                  `json:"translations,omitempty"`   // Keyed by target language
+	JiraIssueKey           string                                       `json:"jira_issue_key,omitempty"` // Linked Jira issue kept in sync on finalize
+	JiraSyncedAt           *time.Time                                   `json:"jira_synced_at,omitempty"`
+	JiraCommentsPulledAt   *time.Time                                   `json:"jira_comments_pulled_at,omitempty"` // Jira's creation time of the latest pulled comment
+	GitLabProjectID        string                                       `json:"gitlab_project_id,omitempty"`
+	GitLabIssueIID         int                                          `json:"gitlab_issue_iid,omitempty"`
+	RequesterEmail         string                                       `json:"requester_email,omitempty"`         // Set for sessions started by inbound email
+	ConfigSnapshot         *ConfigSnapshot                              `json:"config_snapshot,omitempty"`         // Config the session was started with
+	RerefinedFrom          string                                       `json:"rerefined_from,omitempty"`          // Session this one re-refines
+	ForkedFrom             string                                       `json:"forked_from,omitempty"`             // Session this one was forked from
+	ApprovalStatus         ApprovalStatus                               `json:"approval_status,omitempty"`         // Set when a finalized story needs sign-off
+	Approvals              []ApprovalDecision                           `json:"approvals,omitempty"`               // Reviewer decisions on the latest finalize
+	ReviewComments         []ReviewComment                              `json:"review_comments,omitempty"`         // Inline comments on the final output
+	QualityScore           *StoryScore                                  `json:"quality_score,omitempty"`           // Score of the final output against the rubric
+	RoleQuestionStats      map[string]QuestionTally                     `json:"role_question_stats,omitempty"`     // Questions asked and left unanswered per role
+	Timings                []OperationTiming                            `json:"timings,omitempty"`                 // Wall-clock time of each AI round trip
+	EndpointStubs          []EndpointStub                               `json:"endpoint_stubs,omitempty"`          // Proposed endpoints of API features, set on finalize
+	SecurityReview         *SecurityReview                              `json:"security_review,omitempty"`         // Output of the optional security phase
+	ComplianceReview       *ComplianceReview                            `json:"compliance_review,omitempty"`       // Output of the optional compliance phase
+	AccessibilityReview    *AccessibilityReview                         `json:"accessibility_review,omitempty"`    // Output of the optional accessibility phase
+	ContextChanges         string                                       `json:"context_changes,omitempty"`         // Summary of config changes since RerefinedFrom
+	Warnings               []Warning                                    `json:"warnings,omitempty"`                // Non-fatal issues of the latest operation
+	Polish                 *PolishDraft                                 `json:"polish,omitempty"`                  // Draft of the polish chat after finalize
+	Gherkin                *GherkinFeature                              `json:"gherkin,omitempty"`                 // Final AC as Gherkin scenarios, when requested
+	TestCases              *TestCaseSuite                               `json:"test_cases,omitempty"`              // Test cases for QA, when requested
+	RoundCheckpoints       []RoundCheckpoint                            `json:"round_checkpoints,omitempty"`       // Round states before the latest rounds, to undo them
 	AdditionalInfo         string                                       `json:"additional_info,omitempty"`         // 補充資訊
 	ModificationSuggestion string                                       `json:"modification_suggestion,omitempty"` // 修改建議
+	SchemaVersion          int                                          `json:"schema_version,omitempty"`          // Version of the stored format, see SessionSchemaVersion
+	// Extra holds the top-level fields of a session stored by a newer release, written back on save
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // SubmitAnswersRequest is the request structure for submitting answers.
@@ -87,9 +186,242 @@ type FinalizeRequest struct {
 	CurrentAnswers         map[string]string `json:"current_answers,omitempty"`
 	CurrentSuggestions     []string          `json:"current_suggestions,omitempty"`     // 只傳 key
 	ModificationSuggestion string            `json:"modification_suggestion,omitempty"` // 修改建議
+	Format                 string            `json:"format,omitempty"`                  // "list" (default) or "gherkin"
 }
 type FinalizeResponse struct {
+	UserStory    string          `json:"user_story"`
+	AC           []string        `json:"ac"`
+	RawAI        string          `json:"raw_ai_response"`
+	LintFindings []LintFinding   `json:"lint_findings"`
+	Tutorial     *TutorialStep   `json:"tutorial,omitempty"`    // Set for tutorial sessions
+	Endpoints    []EndpointStub  `json:"endpoints,omitempty"`   // Proposed endpoints, set for API features
+	Gherkin      *GherkinFeature `json:"gherkin,omitempty"`     // Set with the gherkin format
+	FeatureURL   string          `json:"feature_url,omitempty"` // Download of the .feature file, set with the gherkin format
+	Warnings     []Warning       `json:"warnings,omitempty"`
+}
+
+// LintFinding is a readability or style issue found in the finalized story.
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // "info" or "warning"
+	Location string `json:"location"` // "user_story" or "ac[i]"
+	Message  string `json:"message"`
+}
+
+// BulkAnswersRequest is the request structure for submitting a round's answers as one Markdown or YAML document.
+type BulkAnswersRequest struct {
+	Content        string `json:"content" binding:"required"`
+	Format         string `json:"format,omitempty"`          // "markdown" or "yaml", detected when empty
+	Submit         string `json:"submit,omitempty"`          // "continue" or "suggestions" submits the parsed answers; empty only parses them
+	AdditionalInfo string `json:"additional_info,omitempty"` // 補充資訊
+}
+
+// BulkAnswers is the result of mapping a bulk answer document to the current questions.
+type BulkAnswers struct {
+	Answers    map[string]string `json:"answers"`              // Keyed like SubmitAnswersRequest.Answers
+	AIMatched  []string          `json:"ai_matched,omitempty"` // Keys matched with AI assistance, worth a second look
+	Unmatched  []BulkAnswerBlock `json:"unmatched,omitempty"`  // Answers no question could be found for
+	Unanswered []string          `json:"unanswered,omitempty"` // Keys of questions the document does not answer
+}
+
+// BulkAnswerBlock is an answer of a bulk answer document with the heading written above it.
+type BulkAnswerBlock struct {
+	Heading string `json:"heading"`
+	Answer  string `json:"answer"`
+}
+
+// CheckAnswerRequest is the request structure for checking the quality of a PM answer.
+type CheckAnswerRequest struct {
+	SessionID string `json:"session_id"`
+	Role      string `json:"role"`
+	Question  string `json:"question"`
+	Answer    string `json:"answer"`
+}
+
+// AnswerQualityHint is the coaching feedback for a PM answer.
+type AnswerQualityHint struct {
+	Vague          bool     `json:"vague"`
+	MatchedPhrases []string `json:"matched_phrases,omitempty"` // Vague phrases found without asking the AI
+	Reason         string   `json:"reason,omitempty"`
+	NeededDetail   string   `json:"needed_detail,omitempty"` // What concrete detail the role actually needs
+}
+
+// TranslateRequest is the request structure for translating the finalized output.
+type TranslateRequest struct {
+	TargetLanguage string `json:"target_language" binding:"required"` // e.g. "English", "ja"
+}
+
+// TranslatedOutput is the finalized story and AC in another language.
+type TranslatedOutput struct {
+	Language  string   `json:"language"`
 	UserStory string   `json:"user_story"`
 	AC        []string `json:"ac"`
-	RawAI     string   `json:"raw_ai_response"`
+}
+
+// TermFinding is an inconsistent use of a glossary term in the finalized output.
+type TermFinding struct {
+	Term        string `json:"term"`        // Canonical term
+	Found       string `json:"found"`       // Variant used in the output
+	Location    string `json:"location"`    // "user_story" or "ac[i]"
+	Reason      string `json:"reason"`      // "glossary" or "previous_stories"
+	Replacement string `json:"replacement"` // Suggested auto-correction
+}
+
+// TermCorrection replaces a term in the finalized output.
+type TermCorrection struct {
+	Found       string `json:"found"`
+	Replacement string `json:"replacement"`
+}
+
+// ApplyTermCorrectionsRequest is the request structure for applying terminology corrections.
+// An empty list applies every suggested correction.
+type ApplyTermCorrectionsRequest struct {
+	Corrections []TermCorrection `json:"corrections"`
+}
+
+// ConfigSnapshot records the product configuration a session was started with, so a later
+// re-refinement can tell what changed since.
+type ConfigSnapshot struct {
+	ProductContext string                      `json:"product_context"`
+	Glossary       []configdomain.GlossaryTerm `json:"glossary,omitempty"`
+	Regulations    []configdomain.Regulation   `json:"regulations,omitempty"`
+	TakenAt        time.Time                   `json:"taken_at"`
+}
+
+// ApprovalStatus is the sign-off status of a finalized story.
+type ApprovalStatus string
+
+const (
+	ApprovalPending          ApprovalStatus = "pending"
+	ApprovalApproved         ApprovalStatus = "approved"
+	ApprovalChangesRequested ApprovalStatus = "changes_requested"
+)
+
+// ApprovalDecision is a reviewer's decision on a finalized story.
+type ApprovalDecision struct {
+	ReviewerID   string         `json:"reviewer_id"`
+	ReviewerName string         `json:"reviewer_name,omitempty"`
+	Decision     ApprovalStatus `json:"decision"` // approved or changes_requested
+	Comment      string         `json:"comment,omitempty"`
+	DecidedAt    time.Time      `json:"decided_at"`
+}
+
+// Targets of review comments.
+const (
+	CommentTargetStory = "story" // A sentence of the final user story
+	CommentTargetAC    = "ac"    // An acceptance criterion
+)
+
+// ReviewComment is a reviewer's inline comment on a sentence of the final story or an AC item.
+type ReviewComment struct {
+	ID         string     `json:"id"`
+	Author     string     `json:"author,omitempty"`
+	Target     string     `json:"target"` // "story" or "ac"
+	Index      int        `json:"index"`  // Sentence or AC index, starting at 0
+	Quote      string     `json:"quote"`  // The commented text at the time of commenting
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"` // Set when submitted in a revision
+}
+
+// SessionSummary is a lightweight projection of a session for list and summary views.
+type SessionSummary struct {
+	ID              string          `json:"id"`
+	Title           string          `json:"title"` // First line of the story
+	WorkspaceID     string          `json:"workspace_id,omitempty"`
+	ProjectID       string          `json:"project_id,omitempty"`
+	UserID          string          `json:"user_id,omitempty"`
+	Phase           RefinementPhase `json:"phase"`
+	CurrentRound    int             `json:"current_round"`
+	TargetRounds    int             `json:"target_rounds,omitempty"`
+	Converged       bool            `json:"converged"`
+	SelectedRoles   []string        `json:"selected_roles"`
+	QuestionCount   int             `json:"question_count"`
+	SuggestionCount int             `json:"suggestion_count"`
+	Finalized       bool            `json:"finalized"`
+	FinalizedAt     *time.Time      `json:"finalized_at,omitempty"`
+	ApprovalStatus  ApprovalStatus  `json:"approval_status,omitempty"`
+	OpenComments    int             `json:"open_comments"`
+	JiraIssueKey    string          `json:"jira_issue_key,omitempty"`
+	GitLabIssueIID  int             `json:"gitlab_issue_iid,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	LastActivityAt  time.Time       `json:"last_activity_at"`
+	UserStory       string          `json:"user_story"` // Current story, the finalized one once finalized
+}
+
+// StoryScore is the score of a finalized story against the scoring rubric.
+type StoryScore struct {
+	Total    float64          `json:"total"` // Weighted score from 0 to 100
+	Criteria []CriterionScore `json:"criteria"`
+	ScoredAt time.Time        `json:"scored_at"`
+}
+
+// CriterionScore is the score of a finalized story on one rubric criterion.
+type CriterionScore struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Score  int     `json:"score"` // 0 to 10
+	Reason string  `json:"reason,omitempty"`
+}
+
+// QuestionTally counts the questions of a role that were asked and that the PM left unanswered.
+type QuestionTally struct {
+	Asked      int `json:"asked"`
+	Unanswered int `json:"unanswered"`
+}
+
+// QuestionInsights groups questions asked across sessions into recurring themes and the categories
+// of information they show was missing from the initial stories.
+type QuestionInsights struct {
+	Themes      []InsightCount `json:"themes"`
+	MissingInfo []InsightCount `json:"missing_info"`
+}
+
+// InsightCount is a theme or category with the number of questions in it.
+type InsightCount struct {
+	Name     string   `json:"name"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples,omitempty"`
+}
+
+// OperationTiming is the wall-clock time of one round trip: the PM's time since the previous AI output,
+// and the AI's time producing the next one.
+type OperationTiming struct {
+	Operation    string          `json:"operation"`
+	Phase        RefinementPhase `json:"phase"` // Phase the PM was working in when requesting the operation
+	Round        int             `json:"round"`
+	HumanSeconds float64         `json:"human_seconds"` // From the previous output to this request, 0 for the first operation
+	AISeconds    float64         `json:"ai_seconds"`    // From this request to its output
+	RequestedAt  time.Time       `json:"requested_at"`
+	CompletedAt  time.Time       `json:"completed_at"`
+}
+
+// PhaseTime totals the human and AI time spent in a phase.
+type PhaseTime struct {
+	HumanSeconds float64 `json:"human_seconds"`
+	AISeconds    float64 `json:"ai_seconds"`
+	Operations   int     `json:"operations"`
+}
+
+// SessionTiming summarizes where the time of a session went.
+type SessionTiming struct {
+	SessionID        string                        `json:"session_id"`
+	WallClockSeconds float64                       `json:"wall_clock_seconds"` // From the start request to the latest output
+	HumanSeconds     float64                       `json:"human_seconds"`
+	AISeconds        float64                       `json:"ai_seconds"`
+	ByPhase          map[RefinementPhase]PhaseTime `json:"by_phase"`
+	Operations       []OperationTiming             `json:"operations"`
+}
+
+// TimingReport aggregates the timings of many sessions.
+type TimingReport struct {
+	Sessions                int                           `json:"sessions"`
+	Finalized               int                           `json:"finalized"`
+	HumanSeconds            float64                       `json:"human_seconds"`
+	AISeconds               float64                       `json:"ai_seconds"`
+	AverageHumanSeconds     float64                       `json:"average_human_seconds"`                 // Per session
+	AverageAISeconds        float64                       `json:"average_ai_seconds"`                    // Per session
+	AverageWallClockSeconds float64                       `json:"average_wall_clock_seconds"`            // Per session
+	AverageToFinalize       float64                       `json:"average_to_finalize_seconds,omitempty"` // Wall clock of finalized sessions until their first finalize
+	ByPhase                 map[RefinementPhase]PhaseTime `json:"by_phase"`
 }
@@ -0,0 +1,23 @@
+package domain
+
+import "errors"
+
+// ErrResumeUnsupported is returned when resuming a session whose AI provider does not keep its threads:
+// the backend keeps the conversations of providers other than OpenAI in memory, and loses them on
+// restart.
+var ErrResumeUnsupported = errors.New("sessions of this AI provider cannot be resumed, their conversations do not survive a restart")
+
+// ResumeReport tells what resuming a session recovered.
+type ResumeReport struct {
+	Restored      bool `json:"restored"`      // The session was loaded from the session store, as it was not in memory
+	Reconstructed bool `json:"reconstructed"` // Questions or suggestions lost mid-operation were rebuilt from the thread
+	// PendingRun is set when the thread ends with messages the assistant never answered; the session stays
+	// at its last recorded round and the operation has to be repeated
+	PendingRun bool `json:"pending_run"`
+}
+
+// ResumeResponse is the response of resuming a session.
+type ResumeResponse struct {
+	SessionResponse
+	Resume *ResumeReport `json:"resume"`
+}
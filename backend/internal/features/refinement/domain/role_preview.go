@@ -0,0 +1,17 @@
+package domain
+
+// RolePreviewRequest is the request structure for previewing the questions a role would ask about a draft story.
+type RolePreviewRequest struct {
+	Story       string `json:"story" binding:"required"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	ProjectID   string `json:"project_id,omitempty"` // Previews the role with the project's prompt
+	Language    string `json:"language,omitempty"`
+	UserID      string `json:"-"` // Set from the X-User-ID header
+}
+
+// RolePreview is a sample of the questions a role would ask about a draft story, so the PM can decide
+// whether to include the role before starting a session.
+type RolePreview struct {
+	Role      string   `json:"role"`
+	Questions []string `json:"questions"`
+}
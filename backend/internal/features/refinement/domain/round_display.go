@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"sort"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// RoundDisplay lays out the current questions and suggestions of a session for rendering: grouped by
+// role in a stable order, with each role's presentation metadata from the config.
+type RoundDisplay struct {
+	Questions   []DisplayGroup `json:"questions,omitempty"`
+	Suggestions []DisplayGroup `json:"suggestions,omitempty"`
+}
+
+// DisplayGroup is the items of one role.
+type DisplayGroup struct {
+	Role         string            `json:"role"`
+	DisplayName  string            `json:"display_name"`            // In the session's language when configured
+	DisplayNames map[string]string `json:"display_names,omitempty"` // All configured languages, for clients that pick their own
+	Description  string            `json:"description,omitempty"`
+	Color        string            `json:"color,omitempty"`
+	Icon         string            `json:"icon,omitempty"`
+	Position     int               `json:"position"` // 1-based position of the group
+	Items        []DisplayItem     `json:"items"`
+}
+
+// DisplayItem is a question or suggestion with its position across all groups of the round.
+type DisplayItem struct {
+	Key      string `json:"key"`      // Answer key of a question, "<role>_<prompt>"
+	Position int    `json:"position"` // 1-based position across the round, usable as a question number
+	Text     string `json:"text"`
+}
+
+// WithRoleDisplay adds the round layout to the response, using the configured role display metadata.
+func (r SessionResponse) WithRoleDisplay(roles map[string]configdomain.RoleDisplay) SessionResponse {
+	if r.RefinementSession == nil {
+		return r
+	}
+	display := &RoundDisplay{}
+	var questions, suggestions []roleItems
+	for _, q := range r.Questions {
+		questions = append(questions, roleItems{role: q.Role, prompts: q.Prompt})
+	}
+	for _, s := range r.Suggestions {
+		suggestions = append(suggestions, roleItems{role: s.Role, prompts: s.Prompt})
+	}
+	display.Questions = displayGroups(questions, r.Request.SelectedRoles, r.Request.Language, roles)
+	display.Suggestions = displayGroups(suggestions, r.Request.SelectedRoles, r.Request.Language, roles)
+	if display.Questions != nil || display.Suggestions != nil {
+		r.Display = display
+	}
+	return r
+}
+
+// roleItems is the shape shared by questions and suggestions.
+type roleItems struct {
+	role    string
+	prompts []string
+}
+
+// displayGroups groups items by role. Roles are ordered by their configured order, then by their
+// position in the session's selected roles, then by name; a role's items keep the AI's order.
+func displayGroups(items []roleItems, selectedRoles []string, language string, roles map[string]configdomain.RoleDisplay) []DisplayGroup {
+	if len(items) == 0 {
+		return nil
+	}
+	rank := func(role string) int {
+		for i, r := range selectedRoles {
+			if r == role {
+				return i
+			}
+		}
+		return len(selectedRoles)
+	}
+	byRole := make(map[string]*DisplayGroup)
+	var groups []*DisplayGroup
+	for _, item := range items {
+		group, ok := byRole[item.role]
+		if !ok {
+			meta := roles[item.role]
+			group = &DisplayGroup{
+				Role:         item.role,
+				DisplayName:  meta.DisplayName(item.role, language),
+				DisplayNames: meta.Names,
+				Description:  meta.Description,
+				Color:        meta.Color,
+				Icon:         meta.Icon,
+			}
+			byRole[item.role] = group
+			groups = append(groups, group)
+		}
+		for _, p := range item.prompts {
+			group.Items = append(group.Items, DisplayItem{Key: item.role + "_" + p, Text: p})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i].Role, groups[j].Role
+		if roles[a].Order != roles[b].Order {
+			return roles[a].Order < roles[b].Order
+		}
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a < b
+	})
+
+	result := make([]DisplayGroup, len(groups))
+	position := 0
+	for i, group := range groups {
+		group.Position = i + 1
+		for j := range group.Items {
+			position++
+			group.Items[j].Position = position
+		}
+		result[i] = *group
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "maps"
+
+// Clone returns a copy of the session that can be read while the original is being updated.
+// Slices and maps that are modified in place are copied; the prompt maps, which never change after
+// a session starts, are shared, as is its workflow.
+func (s *RefinementSession) Clone() *RefinementSession {
+	c := *s
+	c.Questions = cloneQuestions(s.Questions)
+	c.Suggestions = append([]Suggestion(nil), s.Suggestions...)
+	c.History = append([]string(nil), s.History...)
+	c.AskedQuestions = append([]string(nil), s.AskedQuestions...)
+	c.AnsweredQuestions = append([]AnsweredQuestion(nil), s.AnsweredQuestions...)
+	c.AcceptedSuggestions = append([]AcceptedSuggestion(nil), s.AcceptedSuggestions...)
+	c.FinalAC = append([]string(nil), s.FinalAC...)
+	c.Translations = maps.Clone(s.Translations)
+	c.PhaseOutputs = maps.Clone(s.PhaseOutputs)
+	c.Extra = maps.Clone(s.Extra)
+	c.Approvals = append([]ApprovalDecision(nil), s.Approvals...)
+	c.ReviewComments = append([]ReviewComment(nil), s.ReviewComments...)
+	c.RoleQuestionStats = maps.Clone(s.RoleQuestionStats)
+	c.Timings = append([]OperationTiming(nil), s.Timings...)
+	c.EndpointStubs = append([]EndpointStub(nil), s.EndpointStubs...)
+	c.Warnings = append([]Warning(nil), s.Warnings...)
+	c.RoundCheckpoints = append([]RoundCheckpoint(nil), s.RoundCheckpoints...)
+	if s.Polish != nil {
+		polish := *s.Polish
+		polish.AC = append([]string(nil), s.Polish.AC...)
+		polish.Messages = append([]PolishMessage(nil), s.Polish.Messages...)
+		c.Polish = &polish
+	}
+	if s.Gherkin != nil {
+		gherkin := *s.Gherkin
+		gherkin.Scenarios = append([]GherkinScenario(nil), s.Gherkin.Scenarios...)
+		c.Gherkin = &gherkin
+	}
+	if s.TestCases != nil {
+		testCases := *s.TestCases
+		testCases.Cases = append([]TestCase(nil), s.TestCases.Cases...)
+		c.TestCases = &testCases
+	}
+	return &c
+}
+
+// cloneQuestions copies questions including their prompts.
+func cloneQuestions(questions []Question) []Question {
+	if questions == nil {
+		return nil
+	}
+	result := make([]Question, len(questions))
+	for i, q := range questions {
+		q.Prompt = append([]string(nil), q.Prompt...)
+		q.PriorAnswers = append([]*PriorAnswer(nil), q.PriorAnswers...)
+		result[i] = q
+	}
+	return result
+}
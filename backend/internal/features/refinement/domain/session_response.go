@@ -0,0 +1,19 @@
+package domain
+
+// SessionResponse is the client-facing view of a RefinementSession. The role prompts, phase prompts and
+// phase format examples a session was started with stay server-side: the shadowing fields below take
+// precedence over the embedded session's and are always omitted.
+type SessionResponse struct {
+	*RefinementSession
+	RolePrompts         *struct{}     `json:"role_prompts,omitempty"`
+	PhasePrompts        *struct{}     `json:"phase_prompts,omitempty"`
+	PhaseFormatExamples *struct{}     `json:"phase_format_examples,omitempty"`
+	Tutorial            *TutorialStep `json:"tutorial,omitempty"` // Set for tutorial sessions
+	Display             *RoundDisplay `json:"display,omitempty"`  // Set by WithRoleDisplay
+	NextPhases          []string      `json:"next_phases"`        // Phases the workflow allows next
+}
+
+// NewSessionResponse wraps a session for an API response.
+func NewSessionResponse(session *RefinementSession) SessionResponse {
+	return SessionResponse{RefinementSession: session, Tutorial: TutorialAnnotation(session), NextPhases: session.NextPhases()}
+}
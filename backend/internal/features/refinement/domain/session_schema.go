@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SessionSchemaVersion is the version of the stored session format this release writes. Bump it with
+// an upgrade in sessionUpgrades when stored sessions need transforming to be read by this release;
+// fields added with a zero value meaning "unset" need none.
+const SessionSchemaVersion = 1
+
+// sessionUpgrades transform a stored session from the version of their index to the next.
+var sessionUpgrades = []func(doc map[string]json.RawMessage) error{
+	upgradeSessionV0,
+}
+
+// zeroTime is the JSON encoding of the zero time.Time.
+var zeroTime = json.RawMessage(`"0001-01-01T00:00:00Z"`)
+
+// upgradeSessionV0 upgrades sessions stored before the format was versioned, which may predate the
+// phase, the questioning rounds and the activity time.
+func upgradeSessionV0(doc map[string]json.RawMessage) error {
+	if phase, ok := doc["phase"]; !ok || string(phase) == `""` || string(phase) == "null" {
+		doc["phase"] = json.RawMessage(`"` + PhaseQuestioning + `"`)
+	}
+	if round, ok := doc["current_round"]; !ok || string(round) == "0" || string(round) == "null" {
+		doc["current_round"] = json.RawMessage("1")
+	}
+	if at, ok := doc["last_activity_at"]; !ok || bytes.Equal(at, zeroTime) || string(at) == "null" {
+		if created, ok := doc["created_at"]; ok {
+			doc["last_activity_at"] = created
+		}
+	}
+	return nil
+}
+
+// sessionFields returns the JSON names of the fields of RefinementSession.
+var sessionFields = sync.OnceValue(func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeFor[RefinementSession]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+})
+
+// DecodeSession decodes a stored session, upgrading it from older versions of the format, and reports
+// whether it was upgraded. Sessions written by a newer release are read as they are; their fields this
+// release does not know are kept in Extra, so saving them again loses nothing.
+func DecodeSession(data []byte) (*RefinementSession, bool, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	version := 0
+	if raw, ok := doc["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, false, fmt.Errorf("invalid session schema version: %w", err)
+		}
+	}
+	upgraded := version < SessionSchemaVersion
+	for ; version < SessionSchemaVersion; version++ {
+		if err := sessionUpgrades[version](doc); err != nil {
+			return nil, false, fmt.Errorf("failed to upgrade session from schema version %d: %w", version, err)
+		}
+	}
+	if upgraded {
+		doc["schema_version"] = json.RawMessage(strconv.Itoa(version))
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var session RefinementSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, false, err
+	}
+	for name, value := range doc {
+		if !sessionFields()[name] {
+			if session.Extra == nil {
+				session.Extra = make(map[string]json.RawMessage)
+			}
+			session.Extra[name] = value
+		}
+	}
+	return &session, upgraded, nil
+}
+
+// EncodeSession encodes a session for storage, including the fields of a newer release kept in Extra.
+func EncodeSession(session *RefinementSession) ([]byte, error) {
+	data, err := json.Marshal(session)
+	if err != nil || len(session.Extra) == 0 {
+		return data, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for name, value := range session.Extra {
+		if _, ok := doc[name]; !ok {
+			doc[name] = value
+		}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDecodeSession(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	active := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		data         string
+		wantUpgraded bool
+		wantPhase    RefinementPhase
+		wantRound    int
+		wantActivity time.Time
+		wantVersion  int
+		wantExtra    []string
+		wantErr      bool
+	}{
+		{
+			name:         "unversioned session without phase, round and activity",
+			data:         `{"id":"s1","created_at":"2024-03-01T09:00:00Z"}`,
+			wantUpgraded: true,
+			wantPhase:    PhaseQuestioning,
+			wantRound:    1,
+			wantActivity: created,
+			wantVersion:  1,
+		},
+		{
+			name:         "unversioned session with empty values",
+			data:         `{"id":"s1","phase":"","current_round":0,"created_at":"2024-03-01T09:00:00Z","last_activity_at":"0001-01-01T00:00:00Z"}`,
+			wantUpgraded: true,
+			wantPhase:    PhaseQuestioning,
+			wantRound:    1,
+			wantActivity: created,
+			wantVersion:  1,
+		},
+		{
+			name:         "unversioned session keeps its values",
+			data:         `{"id":"s1","phase":"FINALIZING","current_round":3,"created_at":"2024-03-01T09:00:00Z","last_activity_at":"2024-03-02T09:00:00Z"}`,
+			wantUpgraded: true,
+			wantPhase:    PhaseFinalizing,
+			wantRound:    3,
+			wantActivity: active,
+			wantVersion:  1,
+		},
+		{
+			name:         "current session",
+			data:         `{"schema_version":1,"id":"s1","phase":"SUGGESTING","current_round":2,"created_at":"2024-03-01T09:00:00Z","last_activity_at":"2024-03-02T09:00:00Z"}`,
+			wantPhase:    PhaseSuggesting,
+			wantRound:    2,
+			wantActivity: active,
+			wantVersion:  1,
+		},
+		{
+			name:         "session of a newer release",
+			data:         `{"schema_version":2,"id":"s1","phase":"SUGGESTING","current_round":2,"created_at":"2024-03-01T09:00:00Z","last_activity_at":"2024-03-02T09:00:00Z","reviewers":["ann"]}`,
+			wantPhase:    PhaseSuggesting,
+			wantRound:    2,
+			wantActivity: active,
+			wantVersion:  2,
+			wantExtra:    []string{"reviewers"},
+		},
+		{
+			name:    "invalid schema version",
+			data:    `{"schema_version":"one","id":"s1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			data:    `{"id":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, upgraded, err := DecodeSession([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if upgraded != tt.wantUpgraded {
+				t.Errorf("upgraded = %v, want %v", upgraded, tt.wantUpgraded)
+			}
+			if session.Phase != tt.wantPhase {
+				t.Errorf("Phase = %q, want %q", session.Phase, tt.wantPhase)
+			}
+			if session.CurrentRound != tt.wantRound {
+				t.Errorf("CurrentRound = %d, want %d", session.CurrentRound, tt.wantRound)
+			}
+			if !session.LastActivityAt.Equal(tt.wantActivity) {
+				t.Errorf("LastActivityAt = %v, want %v", session.LastActivityAt, tt.wantActivity)
+			}
+			if session.SchemaVersion != tt.wantVersion {
+				t.Errorf("SchemaVersion = %d, want %d", session.SchemaVersion, tt.wantVersion)
+			}
+			if len(session.Extra) != len(tt.wantExtra) {
+				t.Errorf("Extra = %v, want the fields %v", session.Extra, tt.wantExtra)
+			}
+			for _, name := range tt.wantExtra {
+				if _, ok := session.Extra[name]; !ok {
+					t.Errorf("Extra misses %q", name)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeSessionKeepsExtra(t *testing.T) {
+	data := `{"schema_version":2,"id":"s1","reviewers":["ann"]}`
+	session, _, err := DecodeSession([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeSession() error = %v", err)
+	}
+	encoded, err := EncodeSession(session)
+	if err != nil {
+		t.Fatalf("EncodeSession() error = %v", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		t.Fatalf("encoded session is not JSON: %v", err)
+	}
+	if string(doc["reviewers"]) != `["ann"]` {
+		t.Errorf("reviewers = %s, want [\"ann\"]", doc["reviewers"])
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// Session types select the prompts a session is refined with.
+const (
+	SessionTypeStory  = "story"  // User story with acceptance criteria, the default
+	SessionTypeSpike  = "spike"  // Technical investigation with investigation questions, timebox and exit criteria
+	SessionTypeBug    = "bug"    // Bug report with reproduction, impact and fix acceptance criteria
+	SessionTypeChange = "change" // Change to a delivered story, refined for regression risk, affected AC and migration
+)
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// ShadowRun is the output of a shadow model for a round, recorded next to the primary model's output
+// so a candidate model can be compared on real traffic. Shadow runs are never shown to PMs.
+type ShadowRun struct {
+	SessionID        string    `json:"session_id"`
+	WorkspaceID      string    `json:"workspace_id,omitempty"`
+	Operation        string    `json:"operation"`
+	Round            int       `json:"round"`
+	PrimaryModel     string    `json:"primary_model"`
+	PrimaryOutput    any       `json:"primary_output"`
+	ShadowModel      string    `json:"shadow_model"`
+	ShadowOutput     string    `json:"shadow_output,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	LatencyMs        int64     `json:"latency_ms"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CreatedAt        time.Time `json:"created_at"`
+}
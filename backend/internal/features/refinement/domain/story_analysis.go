@@ -0,0 +1,9 @@
+package domain
+
+// StoryAnalysis is a quick quality analysis of a story that has not been refined, used to decide which
+// stories of an existing backlog to refine first.
+type StoryAnalysis struct {
+	Readiness   int      `json:"readiness"`    // 0 (not ready at all) to 100 (ready for development)
+	MissingInfo []string `json:"missing_info"` // Categories of information the story lacks
+	Summary     string   `json:"summary,omitempty"`
+}
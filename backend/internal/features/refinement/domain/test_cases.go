@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Types of test cases.
+const (
+	TestCasePositive      = "positive"       // The behavior an AC describes
+	TestCaseNegative      = "negative"       // Invalid input or a disallowed action is rejected
+	TestCaseEdge          = "edge"           // Boundaries and unusual but valid conditions
+	TestCaseNonFunctional = "non_functional" // e.g. performance, security or accessibility
+)
+
+// TestCase is a concrete test of the final story for QA.
+type TestCase struct {
+	Title          string   `json:"title"`
+	Type           string   `json:"type"`                // One of the TestCase types
+	Criterion      int      `json:"criterion,omitempty"` // Number of the AC the case covers, 0 when it covers several
+	Preconditions  []string `json:"preconditions"`
+	Steps          []string `json:"steps"`
+	ExpectedResult string   `json:"expected_result"`
+}
+
+// TestCaseSuite is the set of test cases derived from the final story and AC.
+type TestCaseSuite struct {
+	Cases     []TestCase `json:"test_cases"`
+	CreatedAt time.Time  `json:"created_at"`
+}
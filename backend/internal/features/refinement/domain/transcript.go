@@ -0,0 +1,39 @@
+package domain
+
+// AcceptedSuggestion is a suggestion prompt the PM accepted, after the questioning round it followed.
+type AcceptedSuggestion struct {
+	Role   string `json:"role"`
+	Prompt string `json:"prompt"`
+	Round  int    `json:"round"`
+}
+
+// TranscriptRound is a questioning round of a session with its answers and the suggestions accepted after it.
+type TranscriptRound struct {
+	Round               int                  `json:"round"`
+	Answers             []AnsweredQuestion   `json:"answers,omitempty"`
+	AcceptedSuggestions []AcceptedSuggestion `json:"accepted_suggestions,omitempty"`
+}
+
+// Transcript groups the answered questions and accepted suggestions of the session by round, in order.
+// Rounds without either are left out.
+func (s *RefinementSession) Transcript() []TranscriptRound {
+	var rounds []TranscriptRound
+	at := func(round int) *TranscriptRound {
+		for i := range rounds {
+			if rounds[i].Round == round {
+				return &rounds[i]
+			}
+		}
+		rounds = append(rounds, TranscriptRound{Round: round})
+		return &rounds[len(rounds)-1]
+	}
+	for _, answer := range s.AnsweredQuestions {
+		r := at(answer.Round)
+		r.Answers = append(r.Answers, answer)
+	}
+	for _, suggestion := range s.AcceptedSuggestions {
+		r := at(suggestion.Round)
+		r.AcceptedSuggestions = append(r.AcceptedSuggestions, suggestion)
+	}
+	return rounds
+}
@@ -0,0 +1,64 @@
+package domain
+
+// TutorialWorkspaceID is the virtual workspace of tutorial sessions. Its provider is a scripted mock,
+// so tutorials spend no tokens and stay out of real workspaces' usage, memory and integrations.
+const TutorialWorkspaceID = "tutorial"
+
+// TutorialStory is the user story refined in the tutorial.
+const TutorialStory = "身為通勤族，我想要在 App 中收藏常用的路線，以便下次快速查詢。"
+
+// TutorialRoles are the roles whose scripted questions and suggestions the tutorial walks through.
+var TutorialRoles = []string{"ProductManager", "Designer", "Backend"}
+
+// TutorialTargetRounds is the number of scripted questioning rounds.
+const TutorialTargetRounds = 2
+
+// TutorialStep explains the current step of a tutorial session and what to do next.
+type TutorialStep struct {
+	Step        int    `json:"step"`
+	Title       string `json:"title"`
+	Explanation string `json:"explanation"`
+	Next        string `json:"next"` // The action to take, e.g. "POST /api/refine/submit_answers_and_continue"
+}
+
+// IsTutorial reports whether the session is a tutorial session.
+func (s *RefinementSession) IsTutorial() bool {
+	return s.WorkspaceID == TutorialWorkspaceID
+}
+
+// TutorialAnnotation returns the explanation of the step a tutorial session is at, or nil for other sessions.
+func TutorialAnnotation(session *RefinementSession) *TutorialStep {
+	if session == nil || !session.IsTutorial() {
+		return nil
+	}
+	switch {
+	case session.FinalizedAt != nil:
+		return &TutorialStep{
+			Step:        4,
+			Title:       "完成：最終用戶故事",
+			Explanation: "AI 依據所有回答與採納的建議，產出改寫後的用戶故事與驗收標準（final_user_story、final_ac）。實際使用時可再匯出到 Jira、GitLab 或送審。",
+			Next:        "教學結束，可用 POST /api/refine/start 開始真正的精煉流程",
+		}
+	case session.Phase == PhaseSuggesting:
+		return &TutorialStep{
+			Step:        3,
+			Title:       "建議階段",
+			Explanation: "提問告一段落後，各角色會針對用戶故事提出具體建議（suggestions）。勾選想採納的建議，可以再進入一輪提問，或直接產出最終結果。",
+			Next:        "POST /api/refine/finalize（current_phase 為 SUGGESTING，current_suggestions 填入採納的建議）",
+		}
+	case session.CurrentRound <= 1:
+		return &TutorialStep{
+			Step:        1,
+			Title:       "第一輪提問",
+			Explanation: "每個選定的角色都從自己的角度對用戶故事提問（questions）。回答時以「角色_問題」為 key 填入 answers；答不出來的可以留白，AI 會在下一輪追問。",
+			Next:        "POST /api/refine/submit_answers_and_continue",
+		}
+	default:
+		return &TutorialStep{
+			Step:        2,
+			Title:       "追問與收斂",
+			Explanation: "AI 讀過上一輪的回答後提出更深入的問題。current_round 與 target_rounds 顯示進度；當 converged 為 true，代表問題開始重複，適合進入建議階段。",
+			Next:        "POST /api/refine/submit_answers_and_get_suggestions",
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrNothingToUndo is returned when undoing a round of a session without a round to undo.
+var ErrNothingToUndo = errors.New("no round to undo")
+
+// RoundCheckpoint is the round state of a session before an operation replaced its questions or
+// suggestions, kept so the round can be undone. The answers and suggestions recorded since are
+// dropped on undo.
+type RoundCheckpoint struct {
+	Operation           string                     `json:"operation"` // Operation that replaced the round, e.g. "submit_answers_and_continue"
+	Phase               RefinementPhase            `json:"phase"`
+	CurrentRound        int                        `json:"current_round"`
+	Questions           []Question                 `json:"questions,omitempty"`
+	Suggestions         []Suggestion               `json:"suggestions,omitempty"`
+	PhaseOutputs        map[string]json.RawMessage `json:"phase_outputs,omitempty"`
+	AskedQuestions      []string                   `json:"asked_questions,omitempty"`
+	AnsweredQuestions   int                        `json:"answered_questions"`   // Number of answers recorded before the operation
+	AcceptedSuggestions int                        `json:"accepted_suggestions"` // Number of suggestions accepted before the operation
+	ConvergenceScore    float64                    `json:"convergence_score,omitempty"`
+	Converged           bool                       `json:"converged"`
+	RoleQuestionStats   map[string]QuestionTally   `json:"role_question_stats,omitempty"`
+	CreatedAt           time.Time                  `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+// Warning is a non-fatal issue of an operation the user should know about, e.g. a role that returned no
+// suggestions or acceptance criteria the parser dropped.
+type Warning struct {
+	Code    string `json:"code"` // e.g. "role_missing", "ac_dropped" or "context_near_limit"
+	Message string `json:"message"`
+}
+
+// Warning codes.
+const (
+	WarningRoleMissing      = "role_missing"       // A selected role contributed no items
+	WarningItemsCapped      = "items_capped"       // Items over a role's maximum were dropped
+	WarningOutputUnparsed   = "output_unparsed"    // The output could not be parsed, even after a repair
+	WarningOutputFiltered   = "output_filtered"    // Blocked terms were scrubbed from the output
+	WarningACDropped        = "ac_dropped"         // Acceptance criteria the parser could not keep
+	WarningContextNearLimit = "context_near_limit" // The thread nearly fills the model's context window
+	WarningStepFailed       = "step_failed"        // An auxiliary step, e.g. the ensemble or endpoint proposal, failed
+	WarningEditSkipped      = "edit_skipped"       // A polish edit that did not fit the draft
+	WarningScenarioDropped  = "scenario_dropped"   // A Gherkin scenario without a Then step
+	WarningTestCaseDropped  = "test_case_dropped"  // A test case without steps or an expected result
+	WarningRoundLost        = "round_lost"         // Part of a round lost to a restart, e.g. its answers or final output
+)
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"errors"
+
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+)
+
+// ErrPhaseTransition is returned for operations moving a session to a phase its workflow does not
+// allow next.
+var ErrPhaseTransition = errors.New("phase transition not allowed")
+
+// AdvancePhaseRequest is the request to move a session to a phase of its workflow other than the
+// built-in ones.
+type AdvancePhaseRequest struct {
+	Phase          string            `json:"phase" binding:"required"`
+	Answers        map[string]string `json:"answers,omitempty"`         // Answers to the questions of the current phase, keyed like SubmitAnswersRequest
+	AdditionalInfo string            `json:"additional_info,omitempty"` // 補充資訊
+}
+
+// WorkflowPhases returns the workflow of a session: the one configured when it started, or the
+// default one.
+func (s *RefinementSession) WorkflowPhases() []configdomain.WorkflowPhase {
+	return configdomain.WorkflowConfig{Phases: s.Workflow}.Resolved()
+}
+
+// NextPhases returns the phases the session's workflow allows next; all of them for a session in a
+// phase the workflow does not define.
+func (s *RefinementSession) NextPhases() []string {
+	phases := s.WorkflowPhases()
+	current, ok := configdomain.FindWorkflowPhase(phases, string(s.Phase))
+	if ok {
+		return current.Transitions
+	}
+	names := make([]string, len(phases))
+	for i, phase := range phases {
+		names[i] = phase.Name
+	}
+	return names
+}
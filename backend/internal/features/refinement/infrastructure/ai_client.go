@@ -18,8 +18,11 @@ type Conversation struct {
 
 // AIResponse represents the response from an AI service
 type AIResponse struct {
-	Content string `json:"content"`
-	Error   error  `json:"error,omitempty"`
+	Content          string `json:"content"`
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	Error            error  `json:"error,omitempty"`
 }
 
 // AIClient defines a generic interface for AI services
@@ -30,19 +33,23 @@ type AIClient interface {
 	// AddMessage adds a message to the conversation
 	AddMessage(ctx context.Context, conversationID string, role, content string) error
 
-	// GenerateResponse generates a response based on the conversation
+	// GenerateResponse generates a response based on the conversation and appends it to the
+	// conversation as an "assistant" message
 	GenerateResponse(ctx context.Context, conversationID string, systemPrompt string) (*AIResponse, error)
 
 	// GetConversation retrieves a conversation by ID
 	GetConversation(ctx context.Context, conversationID string) (*Conversation, error)
 
+	// DeleteConversation removes a conversation that is no longer needed
+	DeleteConversation(ctx context.Context, conversationID string) error
+
 	// Close closes the client and cleans up resources
 	Close() error
 }
 
 // AIConfig holds configuration for AI clients
 type AIConfig struct {
-	Provider string            `json:"provider"` // "openai", "gemini", "claude"
+	Provider string            `json:"provider"` // "openai", "gemini", "claude", "ollama"
 	APIKey   string            `json:"api_key"`
 	Model    string            `json:"model"`
 	Options  map[string]string `json:"options,omitempty"`
@@ -0,0 +1,26 @@
+package infrastructure
+
+import "fmt"
+
+// aiClientFactory creates the AIClient of a provider.
+type aiClientFactory struct{}
+
+// NewAIClientFactory creates a factory for the Gemini, Claude and Ollama providers. OpenAI is served by
+// the Assistants API client of OpenAIClientFactory instead.
+func NewAIClientFactory() AIClientFactory {
+	return &aiClientFactory{}
+}
+
+// CreateClient creates a client for the provider of the configuration.
+func (f *aiClientFactory) CreateClient(config AIConfig) (AIClient, error) {
+	switch config.Provider {
+	case "gemini":
+		return NewGeminiClient(config)
+	case "claude":
+		return NewClaudeClient(config)
+	case "ollama":
+		return NewOllamaClient(config)
+	default:
+		return nil, fmt.Errorf("unsupported AI provider: %s", config.Provider)
+	}
+}
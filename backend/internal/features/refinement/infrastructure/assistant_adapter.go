@@ -0,0 +1,141 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// assistantAdapter runs the refinement flow, written against the Assistants API, on a generic AIClient:
+// threads are conversations and an assistant is the system prompt its runs are generated with.
+// Runs are synchronous, so CancelActiveRuns cannot stop them, and function tools are not offered.
+// The client's configured model is used for every call unless a run names another one, which the
+// generic providers cannot switch to and ignore as well.
+type assistantAdapter struct {
+	client       AIClient
+	mu           sync.Mutex
+	instructions map[string]string // Keyed by assistant ID
+	nextRunID    int
+}
+
+// NewAssistantAdapter wraps a generic AIClient as an OpenAIClient.
+func NewAssistantAdapter(client AIClient) OpenAIClient {
+	return &assistantAdapter{client: client, instructions: make(map[string]string)}
+}
+
+// GetOrCreateAssistant returns an assistant ID per name, updating its instructions.
+func (a *assistantAdapter) GetOrCreateAssistant(ctx context.Context, name, instructions, model string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	assistantID := "asst_" + name
+	a.instructions[assistantID] = instructions
+	return assistantID, nil
+}
+
+// CreateThread creates a conversation; metadata is not supported by generic providers.
+func (a *assistantAdapter) CreateThread(ctx context.Context, metadata map[string]string) (string, error) {
+	conversation, err := a.client.CreateConversation(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create thread: %w", err)
+	}
+	return conversation.ID, nil
+}
+
+// AddMessageToThread adds a user message to the conversation.
+func (a *assistantAdapter) AddMessageToThread(ctx context.Context, threadID, content string) error {
+	if err := a.client.AddMessage(ctx, threadID, "user", content); err != nil {
+		return fmt.Errorf("failed to add message to thread: %w", err)
+	}
+	return nil
+}
+
+// RunAssistant generates the next assistant message of the conversation.
+func (a *assistantAdapter) RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error) {
+	return a.RunAssistantWithModel(ctx, threadID, assistantID, "", metadata, tools, nil)
+}
+
+// RunAssistantWithModel runs the assistant like RunAssistant; the model and schema are ignored.
+func (a *assistantAdapter) RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error) {
+	a.mu.Lock()
+	instructions := a.instructions[assistantID]
+	a.nextRunID++
+	runID := fmt.Sprintf("run_%d", a.nextRunID)
+	a.mu.Unlock()
+
+	resp, err := a.client.GenerateResponse(ctx, threadID, instructions)
+	if err != nil {
+		return nil, fmt.Errorf("run did not complete successfully: %w", err)
+	}
+	return &RunResult{
+		RunID:            runID,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+		TotalTokens:      resp.PromptTokens + resp.CompletionTokens,
+	}, nil
+}
+
+// CancelActiveRuns does nothing: runs finish within RunAssistant.
+func (a *assistantAdapter) CancelActiveRuns(ctx context.Context, threadID string) error {
+	return nil
+}
+
+// GetAssistantResponse returns the assistant messages of the conversation, oldest first.
+func (a *assistantAdapter) GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error) {
+	conversation, err := a.client.GetConversation(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	var assistantMessages []openai.Message
+	for _, msg := range conversation.Messages {
+		if msg.Role == "assistant" {
+			assistantMessages = append(assistantMessages, tutorialMessage(threadID, msg.Role, msg.Content))
+		}
+	}
+	return assistantMessages, nil
+}
+
+// ListThreadMessages returns every message of the conversation, oldest first.
+func (a *assistantAdapter) ListThreadMessages(ctx context.Context, threadID string) ([]openai.Message, error) {
+	conversation, err := a.client.GetConversation(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	messages := make([]openai.Message, 0, len(conversation.Messages))
+	for _, msg := range conversation.Messages {
+		messages = append(messages, tutorialMessage(threadID, msg.Role, msg.Content))
+	}
+	return messages, nil
+}
+
+// Complete runs a single-shot completion in a conversation of its own.
+func (a *assistantAdapter) Complete(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+	content, _, err := a.CompleteWithUsage(ctx, model, systemPrompt, userPrompt)
+	return content, err
+}
+
+// CompleteWithUsage runs a single-shot completion and reports its token usage. Its conversation is
+// removed afterwards.
+func (a *assistantAdapter) CompleteWithUsage(ctx context.Context, model, systemPrompt, userPrompt string) (string, *RunResult, error) {
+	conversation, err := a.client.CreateConversation(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create chat completion: %w", err)
+	}
+	defer a.client.DeleteConversation(context.WithoutCancel(ctx), conversation.ID)
+	if err := a.client.AddMessage(ctx, conversation.ID, "user", userPrompt); err != nil {
+		return "", nil, fmt.Errorf("failed to create chat completion: %w", err)
+	}
+	resp, err := a.client.GenerateResponse(ctx, conversation.ID, systemPrompt)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create chat completion: %w", err)
+	}
+	return resp.Content, &RunResult{
+		RunID:            conversation.ID,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+		TotalTokens:      resp.PromptTokens + resp.CompletionTokens,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	claudeBaseURL      = "https://api.anthropic.com/v1"
+	claudeAPIVersion   = "2023-06-01"
+	claudeDefaultModel = "claude-3-5-sonnet-latest"
+	claudeMaxTokens    = 4096
+)
+
+// claudeClient implements AIClient with the Anthropic Messages API.
+type claudeClient struct {
+	apiKey        string
+	model         string
+	baseURL       string
+	maxTokens     int
+	conversations *conversationStore
+}
+
+// NewClaudeClient creates an Anthropic Claude client. The model defaults to claude-3-5-sonnet-latest;
+// Options["max_tokens"] bounds the length of responses.
+func NewClaudeClient(config AIConfig) (AIClient, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for provider claude")
+	}
+	model := config.Model
+	if model == "" {
+		model = claudeDefaultModel
+	}
+	baseURL := config.Options["base_url"]
+	if baseURL == "" {
+		baseURL = claudeBaseURL
+	}
+	maxTokens := claudeMaxTokens
+	if n, err := strconv.Atoi(config.Options["max_tokens"]); err == nil && n > 0 {
+		maxTokens = n
+	}
+	return &claudeClient{apiKey: config.APIKey, model: model, baseURL: baseURL, maxTokens: maxTokens, conversations: newConversationStore("claude")}, nil
+}
+
+type claudeMessage struct {
+	Role    string `json:"role"` // "user" or "assistant"
+	Content string `json:"content"`
+}
+
+type claudeRequest struct {
+	Model     string          `json:"model"`
+	MaxTokens int             `json:"max_tokens"`
+	System    string          `json:"system,omitempty"`
+	Messages  []claudeMessage `json:"messages"`
+}
+
+type claudeResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// CreateConversation creates a new conversation session.
+func (c *claudeClient) CreateConversation(ctx context.Context) (*Conversation, error) {
+	return c.conversations.create()
+}
+
+// AddMessage adds a message to the conversation.
+func (c *claudeClient) AddMessage(ctx context.Context, conversationID string, role, content string) error {
+	return c.conversations.add(conversationID, role, content)
+}
+
+// GenerateResponse sends the conversation to Claude and appends the reply to it. Consecutive messages
+// of the same role are merged, as the Messages API requires alternating roles.
+func (c *claudeClient) GenerateResponse(ctx context.Context, conversationID string, systemPrompt string) (*AIResponse, error) {
+	conversation, err := c.conversations.get(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	req := claudeRequest{Model: c.model, MaxTokens: c.maxTokens, System: systemPrompt}
+	for _, msg := range conversation.Messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "assistant"
+		}
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == role {
+			req.Messages[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		if len(req.Messages) == 0 && role == "assistant" {
+			continue // The conversation must start with a user message
+		}
+		req.Messages = append(req.Messages, claudeMessage{Role: role, Content: msg.Content})
+	}
+
+	var resp claudeResponse
+	headers := map[string]string{"x-api-key": c.apiKey, "anthropic-version": claudeAPIVersion}
+	if err := postJSON(ctx, c.baseURL+"/messages", headers, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to generate Claude response: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if err := c.conversations.add(conversationID, "assistant", text.String()); err != nil {
+		return nil, err
+	}
+	return &AIResponse{
+		Content:          text.String(),
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+	}, nil
+}
+
+// GetConversation retrieves a conversation by ID.
+func (c *claudeClient) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	return c.conversations.get(conversationID)
+}
+
+// DeleteConversation removes a conversation.
+func (c *claudeClient) DeleteConversation(ctx context.Context, conversationID string) error {
+	c.conversations.remove(conversationID)
+	return nil
+}
+
+// Close does nothing: the client holds no connections of its own.
+func (c *claudeClient) Close() error {
+	return nil
+}
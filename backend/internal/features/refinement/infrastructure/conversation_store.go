@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrConversationNotFound is returned for a conversation the store does not hold, which includes every
+// conversation created before the last restart.
+var ErrConversationNotFound = errors.New("conversation not found; conversations of this provider are kept in memory and do not survive a restart")
+
+// conversationStore keeps the conversations of the providers whose APIs are stateless, which receive
+// the whole conversation on every request. Conversations are kept in memory only; their IDs are random,
+// so a session restored after a restart can never reach another session's conversation, and such
+// sessions cannot be resumed.
+type conversationStore struct {
+	mu            sync.Mutex
+	conversations map[string]*Conversation
+	prefix        string
+}
+
+func newConversationStore(prefix string) *conversationStore {
+	return &conversationStore{conversations: make(map[string]*Conversation), prefix: prefix}
+}
+
+// create starts an empty conversation.
+func (s *conversationStore) create() (*Conversation, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate conversation ID: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation := &Conversation{ID: s.prefix + "_" + hex.EncodeToString(b)}
+	s.conversations[conversation.ID] = conversation
+	return s.copy(conversation), nil
+}
+
+// add appends a message to a conversation.
+func (s *conversationStore) add(conversationID, role, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	conversation.Messages = append(conversation.Messages, Message{Role: role, Content: content})
+	return nil
+}
+
+// get returns a copy of a conversation.
+func (s *conversationStore) get(conversationID string) (*Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, conversationID)
+	}
+	return s.copy(conversation), nil
+}
+
+// remove drops a conversation; removing an unknown one does nothing.
+func (s *conversationStore) remove(conversationID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, conversationID)
+}
+
+func (s *conversationStore) copy(conversation *Conversation) *Conversation {
+	c := *conversation
+	c.Messages = append([]Message(nil), conversation.Messages...)
+	return &c
+}
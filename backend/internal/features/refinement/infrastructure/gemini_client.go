@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	geminiBaseURL      = "https://generativelanguage.googleapis.com/v1beta"
+	geminiDefaultModel = "gemini-1.5-pro"
+)
+
+// geminiClient implements AIClient with the Gemini generateContent API.
+type geminiClient struct {
+	apiKey        string
+	model         string
+	baseURL       string
+	conversations *conversationStore
+}
+
+// NewGeminiClient creates a Gemini client; the model defaults to gemini-1.5-pro.
+func NewGeminiClient(config AIConfig) (AIClient, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for provider gemini")
+	}
+	model := config.Model
+	if model == "" {
+		model = geminiDefaultModel
+	}
+	baseURL := config.Options["base_url"]
+	if baseURL == "" {
+		baseURL = geminiBaseURL
+	}
+	return &geminiClient{apiKey: config.APIKey, model: model, baseURL: baseURL, conversations: newConversationStore("gemini")}, nil
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// CreateConversation creates a new conversation session.
+func (c *geminiClient) CreateConversation(ctx context.Context) (*Conversation, error) {
+	return c.conversations.create()
+}
+
+// AddMessage adds a message to the conversation.
+func (c *geminiClient) AddMessage(ctx context.Context, conversationID string, role, content string) error {
+	return c.conversations.add(conversationID, role, content)
+}
+
+// GenerateResponse sends the conversation to Gemini and appends the reply to it. Consecutive messages
+// of the same role are sent as one turn.
+func (c *geminiClient) GenerateResponse(ctx context.Context, conversationID string, systemPrompt string) (*AIResponse, error) {
+	conversation, err := c.conversations.get(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	req := geminiRequest{}
+	if systemPrompt != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}}
+	}
+	for _, msg := range conversation.Messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "model"
+		}
+		if n := len(req.Contents); n > 0 && req.Contents[n-1].Role == role {
+			req.Contents[n-1].Parts = append(req.Contents[n-1].Parts, geminiPart{Text: msg.Content})
+			continue
+		}
+		req.Contents = append(req.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: msg.Content}}})
+	}
+
+	var resp geminiResponse
+	// The key goes in a header, as errors of the request quote its URL
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, url.PathEscape(c.model))
+	if err := postJSON(ctx, endpoint, map[string]string{"x-goog-api-key": c.apiKey}, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to generate Gemini response: %w", err)
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("Gemini returned no candidates")
+	}
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	if err := c.conversations.add(conversationID, "assistant", text.String()); err != nil {
+		return nil, err
+	}
+	return &AIResponse{
+		Content:          text.String(),
+		Model:            c.model,
+		PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
+
+// GetConversation retrieves a conversation by ID.
+func (c *geminiClient) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	return c.conversations.get(conversationID)
+}
+
+// DeleteConversation removes a conversation.
+func (c *geminiClient) DeleteConversation(ctx context.Context, conversationID string) error {
+	c.conversations.remove(conversationID)
+	return nil
+}
+
+// Close does nothing: the client holds no connections of its own.
+func (c *geminiClient) Close() error {
+	return nil
+}
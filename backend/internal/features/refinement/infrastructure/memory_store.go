@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// MemoryStore defines the interface for product memory persistence.
+type MemoryStore interface {
+	List(workspaceID string) ([]domain.MemoryItem, error)
+	Add(items []domain.MemoryItem) error
+	Delete(workspaceID, id string) error
+}
+
+// jsonMemoryStore stores the memory of all products in a JSON file.
+type jsonMemoryStore struct {
+	file *jsonfile.Store[[]domain.MemoryItem]
+	mu   sync.Mutex
+}
+
+// NewJSONMemoryStore creates a new memory store backed by the given JSON file.
+func NewJSONMemoryStore(path string) MemoryStore {
+	return &jsonMemoryStore{file: jsonfile.NewStore[[]domain.MemoryItem](path, "memory")}
+}
+
+// List returns the memory items of a workspace, oldest first.
+func (s *jsonMemoryStore) List(workspaceID string) ([]domain.MemoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.MemoryItem{}
+	for _, item := range items {
+		if item.WorkspaceID == workspaceID {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// Add appends memory items.
+func (s *jsonMemoryStore) Add(newItems []domain.MemoryItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.file.Load()
+	if err != nil {
+		return err
+	}
+	return s.file.Store(append(items, newItems...))
+}
+
+// Delete removes a memory item of a workspace.
+func (s *jsonMemoryStore) Delete(workspaceID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if items[i].ID == id && items[i].WorkspaceID == workspaceID {
+			return s.file.Store(append(items[:i], items[i+1:]...))
+		}
+	}
+	return fmt.Errorf("memory item %s not found", id)
+}
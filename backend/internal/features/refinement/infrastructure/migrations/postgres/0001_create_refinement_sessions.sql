@@ -0,0 +1,8 @@
+-- Stores predating the migrations already have the table
+CREATE TABLE IF NOT EXISTS refinement_sessions (
+	id TEXT PRIMARY KEY,
+	phase TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	data JSONB NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Stores predating the migrations already have the table
+CREATE TABLE IF NOT EXISTS refinement_sessions (
+	id TEXT PRIMARY KEY,
+	phase TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	data TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Sessions are listed oldest first
+CREATE INDEX IF NOT EXISTS refinement_sessions_created_at ON refinement_sessions (created_at);
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"context"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// mirroringClient decorates an OpenAIClient and publishes thread activity to a TranscriptHub.
+type mirroringClient struct {
+	OpenAIClient
+	hub TranscriptHub
+}
+
+// NewMirroringClient wraps a client so messages and assistant outputs are mirrored to the hub.
+func NewMirroringClient(client OpenAIClient, hub TranscriptHub) OpenAIClient {
+	if hub == nil {
+		return client
+	}
+	return &mirroringClient{OpenAIClient: client, hub: hub}
+}
+
+// AddMessageToThread adds the message and mirrors it.
+func (c *mirroringClient) AddMessageToThread(ctx context.Context, threadID, content string) error {
+	if err := c.OpenAIClient.AddMessageToThread(ctx, threadID, content); err != nil {
+		return err
+	}
+	c.hub.Publish(TranscriptEvent{Type: "user_message", ThreadID: threadID, Content: content})
+	return nil
+}
+
+// RunAssistant runs the assistant and mirrors the run status.
+func (c *mirroringClient) RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error) {
+	return c.RunAssistantWithModel(ctx, threadID, assistantID, "", metadata, tools, nil)
+}
+
+// RunAssistantWithModel runs the assistant on the given model and mirrors the run status. Clients that
+// can stream their runs also mirror each status change and the output as it is generated.
+func (c *mirroringClient) RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error) {
+	c.hub.Publish(TranscriptEvent{Type: "run_started", ThreadID: threadID})
+	var result *RunResult
+	var err error
+	if streamer, ok := c.OpenAIClient.(RunStreamer); ok {
+		result, err = streamer.StreamAssistantRun(ctx, threadID, assistantID, model, metadata, tools, schema, func(eventType, content string) {
+			c.hub.Publish(TranscriptEvent{Type: eventType, ThreadID: threadID, Content: content})
+		})
+	} else {
+		result, err = c.OpenAIClient.RunAssistantWithModel(ctx, threadID, assistantID, model, metadata, tools, schema)
+	}
+	if err != nil {
+		c.hub.Publish(TranscriptEvent{Type: "run_failed", ThreadID: threadID, Content: err.Error()})
+		return nil, err
+	}
+	c.hub.Publish(TranscriptEvent{Type: "run_completed", ThreadID: threadID})
+	return result, nil
+}
+
+// GetAssistantResponse fetches the assistant messages and mirrors the latest one.
+func (c *mirroringClient) GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error) {
+	messages, err := c.OpenAIClient.GetAssistantResponse(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) > 0 {
+		latest := messages[len(messages)-1]
+		if len(latest.Content) > 0 && latest.Content[0].Text != nil {
+			c.hub.Publish(TranscriptEvent{Type: "assistant_message", ThreadID: threadID, Content: latest.Content[0].Text.Value})
+		}
+	}
+	return messages, nil
+}
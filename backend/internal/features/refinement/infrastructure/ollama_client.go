@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	ollamaBaseURL      = "http://localhost:11434"
+	ollamaDefaultModel = "llama3.1"
+)
+
+// ollamaClient implements AIClient with the chat API of a local Ollama server.
+type ollamaClient struct {
+	model         string
+	baseURL       string
+	conversations *conversationStore
+}
+
+// NewOllamaClient creates an Ollama client. Options["base_url"] selects the server, by default
+// http://localhost:11434; no API key is needed.
+func NewOllamaClient(config AIConfig) (AIClient, error) {
+	model := config.Model
+	if model == "" {
+		model = ollamaDefaultModel
+	}
+	baseURL := strings.TrimSuffix(config.Options["base_url"], "/")
+	if baseURL == "" {
+		baseURL = ollamaBaseURL
+	}
+	return &ollamaClient{model: model, baseURL: baseURL, conversations: newConversationStore("ollama")}, nil
+}
+
+type ollamaRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type ollamaResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+}
+
+// CreateConversation creates a new conversation session.
+func (c *ollamaClient) CreateConversation(ctx context.Context) (*Conversation, error) {
+	return c.conversations.create()
+}
+
+// AddMessage adds a message to the conversation.
+func (c *ollamaClient) AddMessage(ctx context.Context, conversationID string, role, content string) error {
+	return c.conversations.add(conversationID, role, content)
+}
+
+// GenerateResponse sends the conversation to Ollama and appends the reply to it.
+func (c *ollamaClient) GenerateResponse(ctx context.Context, conversationID string, systemPrompt string) (*AIResponse, error) {
+	conversation, err := c.conversations.get(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	req := ollamaRequest{Model: c.model}
+	if systemPrompt != "" {
+		req.Messages = append(req.Messages, Message{Role: "system", Content: systemPrompt})
+	}
+	req.Messages = append(req.Messages, conversation.Messages...)
+
+	var resp ollamaResponse
+	if err := postJSON(ctx, c.baseURL+"/api/chat", nil, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to generate Ollama response: %w", err)
+	}
+	if err := c.conversations.add(conversationID, "assistant", resp.Message.Content); err != nil {
+		return nil, err
+	}
+	return &AIResponse{
+		Content:          resp.Message.Content,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+	}, nil
+}
+
+// GetConversation retrieves a conversation by ID.
+func (c *ollamaClient) GetConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	return c.conversations.get(conversationID)
+}
+
+// DeleteConversation removes a conversation.
+func (c *ollamaClient) DeleteConversation(ctx context.Context, conversationID string) error {
+	c.conversations.remove(conversationID)
+	return nil
+}
+
+// Close does nothing: the client holds no connections of its own.
+func (c *ollamaClient) Close() error {
+	return nil
+}
@@ -3,27 +3,61 @@ package infrastructure
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
+
+	"sofa-commander/backend/internal/chaos"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/tracing"
 	// "sofa-commander/backend/internal/features/refinement/domain" // Not directly used here, but might be needed for other functions later
 )
 
 // OpenAIClient defines the interface for an OpenAI client using Assistants API.
 type OpenAIClient interface {
-	GetOrCreateAssistant(name, instructions, model string) (string, error)
-	CreateThread() (string, error)
-	AddMessageToThread(threadID, content string) error
-	RunAssistant(threadID, assistantID string) error
-	GetAssistantResponse(threadID string) ([]openai.Message, error)
+	GetOrCreateAssistant(ctx context.Context, name, instructions, model string) (string, error)
+	CreateThread(ctx context.Context, metadata map[string]string) (string, error)
+	AddMessageToThread(ctx context.Context, threadID, content string) error
+	RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error)
+	RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error)
+	CancelActiveRuns(ctx context.Context, threadID string) error
+	GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error)
+	ListThreadMessages(ctx context.Context, threadID string) ([]openai.Message, error)
+	Complete(ctx context.Context, model, systemPrompt, userPrompt string) (string, error)
+	CompleteWithUsage(ctx context.Context, model, systemPrompt, userPrompt string) (string, *RunResult, error)
+}
+
+// RunResult describes a completed assistant run and its token usage.
+type RunResult struct {
+	RunID            string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// ToolExecutor provides the function tools available to an assistant run and executes the calls the
+// assistant makes to them.
+type ToolExecutor interface {
+	Tools() []openai.Tool
+	Execute(name, arguments string) (string, error)
 }
 
 // openAIClient is the implementation of OpenAIClient.
 type openAIClient struct {
 	client *openai.Client
-	// Store assistant ID in memory for now, could be persisted later
-	assistantID string
+	// Credentials for the streaming endpoints, which the SDK does not cover
+	apiKey string
+	orgID  string
+	// Assistant IDs by name, so each assistant is looked up once
+	assistants map[string]string
+	mu         sync.Mutex
+	lookups    singleflight.Group // Assistant lookups in flight, by name
 }
 
 // NewOpenAIClient creates a new OpenAI client, requires OPENAI_API_KEY env var.
@@ -32,104 +66,297 @@ func NewOpenAIClient() (OpenAIClient, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
 	}
-	client := openai.NewClient(apiKey)
-	return &openAIClient{client: client}, nil
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.HTTPClient = &http.Client{Transport: chaos.Transport(nil)}
+	return &openAIClient{client: openai.NewClientWithConfig(clientConfig), apiKey: apiKey}, nil
 }
 
-// GetOrCreateAssistant creates an assistant if it doesn't exist, or retrieves it.
-func (c *openAIClient) GetOrCreateAssistant(name, instructions, model string) (string, error) {
-	if c.assistantID != "" {
-		return c.assistantID, nil // Already created/retrieved in this session
+// GetOrCreateAssistant returns the assistant with the given name, creating it when there is none.
+// Concurrent lookups of the same name share one, so the assistant is created once; the client's lock is
+// not held across the requests, which would serialize every session start.
+func (c *openAIClient) GetOrCreateAssistant(ctx context.Context, name, instructions, model string) (string, error) {
+	c.mu.Lock()
+	assistantID, ok := c.assistants[name]
+	c.mu.Unlock()
+	if ok {
+		return assistantID, nil // Already created/retrieved by this client
 	}
 
-	// List assistants (paginated, but we just get the first page)
-	assistantsList, err := c.client.ListAssistants(context.Background(), nil, nil, nil, nil)
+	id, err, _ := c.lookups.Do(name, func() (any, error) {
+		assistantID, err := c.findAssistant(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if assistantID == "" {
+			if assistantID, err = c.createAssistant(ctx, name, instructions, model); err != nil {
+				return "", err
+			}
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.assistants == nil {
+			c.assistants = make(map[string]string)
+		}
+		c.assistants[name] = assistantID
+		return assistantID, nil
+	})
 	if err != nil {
-		fmt.Printf("[OpenAI] ListAssistants error: %+v\n", err)
-		return "", fmt.Errorf("failed to list assistants: %w", err)
+		return "", err
 	}
+	return id.(string), nil
+}
 
-	for _, asst := range assistantsList.Assistants {
-		if asst.Name != nil && *asst.Name == name {
-			c.assistantID = asst.ID
-			return asst.ID, nil
+// findAssistant returns the ID of the assistant with the given name, or an empty string when there is
+// none. All pages are searched, so assistants created before a restart are found.
+func (c *openAIClient) findAssistant(ctx context.Context, name string) (string, error) {
+	limit := 100
+	var after *string
+	for {
+		assistantsList, err := withRetry(ctx, "ListAssistants", func() (openai.AssistantsList, error) {
+			return c.client.ListAssistants(ctx, &limit, nil, after, nil)
+		})
+		if err != nil {
+			logging.From(ctx).Error().Err(err).Str("request", "ListAssistants").Msg("OpenAI request failed")
+			return "", fmt.Errorf("failed to list assistants: %w", err)
+		}
+		for _, asst := range assistantsList.Assistants {
+			if asst.Name != nil && *asst.Name == name {
+				return asst.ID, nil
+			}
 		}
+		if !assistantsList.HasMore || assistantsList.LastID == nil {
+			return "", nil
+		}
+		after = assistantsList.LastID
 	}
+}
 
-	// Assistant not found, create a new one
-	fmt.Printf("Creating Assistant with Name: %s, Instructions: %s, Model: %s\n", name, instructions, model)
-	newAssistant, err := c.client.CreateAssistant(context.Background(), openai.AssistantRequest{
-		Name:         &name,
-		Instructions: &instructions,
-		Model:        model,
+// createAssistant creates an assistant and returns its ID.
+func (c *openAIClient) createAssistant(ctx context.Context, name, instructions, model string) (string, error) {
+	event := logging.From(ctx).Info().Str("assistant", name).Str("model", model)
+	if logging.Prompts() {
+		event = event.Str("instructions", instructions)
+	}
+	event.Msg("Creating assistant")
+	newAssistant, err := withCreateRetry(ctx, "CreateAssistant", func() (openai.Assistant, error) {
+		return c.client.CreateAssistant(ctx, openai.AssistantRequest{
+			Name:         &name,
+			Instructions: &instructions,
+			Model:        model,
+		})
 	})
 	if err != nil {
-		fmt.Printf("[OpenAI] CreateAssistant error: %+v\n", err)
+		logging.From(ctx).Error().Err(err).Str("request", "CreateAssistant").Msg("OpenAI request failed")
 		return "", fmt.Errorf("failed to create assistant: %w", err)
 	}
-	c.assistantID = newAssistant.ID
 	return newAssistant.ID, nil
 }
 
-// CreateThread creates a new conversation thread.
-func (c *openAIClient) CreateThread() (string, error) {
-	fmt.Println("Creating new thread...")
-	thread, err := c.client.CreateThread(context.Background(), openai.ThreadRequest{})
+// CreateThread creates a new conversation thread tagged with the given metadata.
+func (c *openAIClient) CreateThread(ctx context.Context, metadata map[string]string) (string, error) {
+	logging.From(ctx).Debug().Msg("Creating thread")
+	thread, err := withCreateRetry(ctx, "CreateThread", func() (openai.Thread, error) {
+		return c.client.CreateThread(ctx, openai.ThreadRequest{
+			Metadata: toOpenAIMetadata(metadata),
+		})
+	})
 	if err != nil {
-		fmt.Printf("[OpenAI] CreateThread error: %+v\n", err)
+		logging.From(ctx).Error().Err(err).Str("request", "CreateThread").Msg("OpenAI request failed")
 		return "", fmt.Errorf("failed to create thread: %w", err)
 	}
 	return thread.ID, nil
 }
 
 // AddMessageToThread adds a user message to a specific thread.
-func (c *openAIClient) AddMessageToThread(threadID, content string) error {
-	fmt.Printf("Adding message to thread %s: %s\n", threadID, content)
-	_, err := c.client.CreateMessage(context.Background(), threadID, openai.MessageRequest{
-		Role:    "user",
-		Content: content,
+func (c *openAIClient) AddMessageToThread(ctx context.Context, threadID, content string) error {
+	event := logging.From(ctx).Debug().Str("thread_id", threadID).Int("length", len(content))
+	if logging.Prompts() {
+		event = event.Str("content", content)
+	}
+	event.Msg("Adding message to thread")
+	_, err := withCreateRetry(ctx, "CreateMessage", func() (openai.Message, error) {
+		return c.client.CreateMessage(ctx, threadID, openai.MessageRequest{
+			Role:    "user",
+			Content: content,
+		})
 	})
 
 	if err != nil {
-		fmt.Printf("[OpenAI] CreateMessage error: %+v\n", err)
+		logging.From(ctx).Error().Err(err).Str("request", "CreateMessage").Msg("OpenAI request failed")
 		return fmt.Errorf("failed to add message to thread: %w", err)
 	}
 	return nil
 }
 
-// RunAssistant creates a run on a thread and polls for its completion.
-func (c *openAIClient) RunAssistant(threadID, assistantID string) error {
-	fmt.Printf("Running assistant %s on thread %s\n", assistantID, threadID)
-	run, err := c.client.CreateRun(context.Background(), threadID, openai.RunRequest{
+// RunAssistant creates a run on a thread tagged with the given metadata and polls for its completion.
+// Function calls requested by the assistant are answered by the tool executor, which may be nil. When
+// the context ends first, the run is cancelled.
+func (c *openAIClient) RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error) {
+	return c.RunAssistantWithModel(ctx, threadID, assistantID, "", metadata, tools, nil)
+}
+
+// RunAssistantWithModel runs the assistant like RunAssistant, overriding the assistant's model unless
+// the model is empty, and enforcing the response schema unless it is nil.
+func (c *openAIClient) RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error) {
+	logging.From(ctx).Debug().Str("assistant_id", assistantID).Str("thread_id", threadID).Str("model", model).Msg("Running assistant")
+	req := openai.RunRequest{
 		AssistantID: assistantID,
+		Model:       model,
+		Metadata:    toOpenAIMetadata(metadata),
+	}
+	if tools != nil {
+		req.Tools = tools.Tools()
+	}
+	if format := schema.responseFormat(); format != nil {
+		req.ResponseFormat = format
+	}
+	run, err := withCreateRetry(ctx, "CreateRun", func() (openai.Run, error) {
+		return c.client.CreateRun(ctx, threadID, req)
 	})
 
 	if err != nil {
-		fmt.Printf("[OpenAI] CreateRun error: %+v\n", err)
-		return fmt.Errorf("failed to create run: %w", err)
+		logging.From(ctx).Error().Err(err).Str("request", "CreateRun").Msg("OpenAI request failed")
+		return nil, fmt.Errorf("failed to create run: %w", err)
 	}
 
-	// Poll for run completion
+	// Poll for run completion, backing off while the run takes longer
+	poll := 0
 	for run.Status != openai.RunStatusCompleted && run.Status != openai.RunStatusFailed && run.Status != openai.RunStatusCancelled && run.Status != openai.RunStatusExpired {
-		time.Sleep(1 * time.Second) // Poll every second
-		run, err = c.client.RetrieveRun(context.Background(), threadID, run.ID)
+		if run.Status == openai.RunStatusRequiresAction {
+			next, err := c.submitToolOutputs(ctx, threadID, run, tools)
+			if err != nil {
+				if ctx.Err() != nil {
+					c.abandonRun(threadID, run.ID)
+				}
+				return nil, err
+			}
+			run = next
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			c.abandonRun(threadID, run.ID)
+			return nil, fmt.Errorf("run %s abandoned: %w", run.ID, ctx.Err())
+		case <-time.After(backoff(poll, pollBaseInterval, pollMaxInterval)):
+		}
+		poll++
+		next, err := withRetry(ctx, "RetrieveRun", func() (openai.Run, error) {
+			return c.client.RetrieveRun(ctx, threadID, run.ID)
+		})
+		if err == nil {
+			tracing.Event(ctx, "run.poll", attribute.Int("ai.run.poll", poll), attribute.String("ai.run.status", string(next.Status)))
+		}
 		if err != nil {
-			fmt.Printf("[OpenAI] RetrieveRun error: %+v\n", err)
-			return fmt.Errorf("failed to retrieve run status: %w", err)
+			logging.From(ctx).Error().Err(err).Str("request", "RetrieveRun").Msg("OpenAI request failed")
+			if ctx.Err() != nil {
+				c.abandonRun(threadID, run.ID)
+			}
+			return nil, fmt.Errorf("failed to retrieve run status: %w", err)
 		}
+		run = next
 	}
 
 	if run.Status != openai.RunStatusCompleted {
-		return fmt.Errorf("run did not complete successfully, status: %s", run.Status)
+		return nil, fmt.Errorf("run did not complete successfully, status: %s", run.Status)
+	}
+	return &RunResult{
+		RunID:            run.ID,
+		Model:            run.Model,
+		PromptTokens:     run.Usage.PromptTokens,
+		CompletionTokens: run.Usage.CompletionTokens,
+		TotalTokens:      run.Usage.TotalTokens,
+	}, nil
+}
+
+// CancelActiveRuns cancels the runs of a thread that have not finished yet. A run being polled by
+// RunAssistant then ends with an error.
+func (c *openAIClient) CancelActiveRuns(ctx context.Context, threadID string) error {
+	limit := 10
+	runs, err := withRetry(ctx, "ListRuns", func() (openai.RunList, error) {
+		return c.client.ListRuns(ctx, threadID, openai.Pagination{Limit: &limit})
+	})
+	if err != nil {
+		logging.From(ctx).Error().Err(err).Str("request", "ListRuns").Msg("OpenAI request failed")
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+	for _, run := range runs.Runs {
+		switch run.Status {
+		case openai.RunStatusQueued, openai.RunStatusInProgress, openai.RunStatusRequiresAction:
+			_, err := withRetry(ctx, "CancelRun", func() (openai.Run, error) {
+				return c.client.CancelRun(ctx, threadID, run.ID)
+			})
+			if err != nil {
+				logging.From(ctx).Error().Err(err).Str("request", "CancelRun").Msg("OpenAI request failed")
+				return fmt.Errorf("failed to cancel run %s: %w", run.ID, err)
+			}
+		}
 	}
 	return nil
 }
 
+// abandonRun cancels a run whose caller stopped waiting for it, because the client disconnected or the
+// run timed out, so it does not keep running on the provider. It does not use the caller's context,
+// which has ended.
+func (c *openAIClient) abandonRun(threadID, runID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logging.From(ctx).Info().Str("run_id", runID).Str("thread_id", threadID).Msg("Cancelling abandoned run")
+	_, err := withRetry(ctx, "CancelRun", func() (openai.Run, error) {
+		return c.client.CancelRun(ctx, threadID, runID)
+	})
+	if err != nil {
+		logging.From(ctx).Error().Err(err).Str("request", "CancelRun").Msg("OpenAI request failed")
+	}
+}
+
+// submitToolOutputs executes the tool calls a run is waiting for and submits their outputs.
+// Tool errors are reported back to the assistant instead of failing the run.
+func (c *openAIClient) submitToolOutputs(ctx context.Context, threadID string, run openai.Run, tools ToolExecutor) (openai.Run, error) {
+	if run.RequiredAction == nil || run.RequiredAction.SubmitToolOutputs == nil {
+		return run, fmt.Errorf("run %s requires an unsupported action", run.ID)
+	}
+	outputs, err := executeToolCalls(ctx, run, tools)
+	if err != nil {
+		return run, err
+	}
+	run, err = withCreateRetry(ctx, "SubmitToolOutputs", func() (openai.Run, error) {
+		return c.client.SubmitToolOutputs(ctx, threadID, run.ID, openai.SubmitToolOutputsRequest{ToolOutputs: outputs})
+	})
+	if err != nil {
+		logging.From(ctx).Error().Err(err).Str("request", "SubmitToolOutputs").Msg("OpenAI request failed")
+		return run, fmt.Errorf("failed to submit tool outputs: %w", err)
+	}
+	return run, nil
+}
+
+// executeToolCalls executes the tool calls a run is waiting for and returns their outputs.
+func executeToolCalls(ctx context.Context, run openai.Run, tools ToolExecutor) ([]openai.ToolOutput, error) {
+	if tools == nil {
+		return nil, fmt.Errorf("run %s requested tool calls but no tools are available", run.ID)
+	}
+	var outputs []openai.ToolOutput
+	for _, call := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+		event := logging.From(ctx).Debug().Str("tool", call.Function.Name)
+		if logging.Prompts() {
+			event = event.Str("arguments", call.Function.Arguments)
+		}
+		event.Msg("Executing tool")
+		output, err := tools.Execute(call.Function.Name, call.Function.Arguments)
+		if err != nil {
+			output = "error: " + err.Error()
+		}
+		outputs = append(outputs, openai.ToolOutput{ToolCallID: call.ID, Output: output})
+	}
+	return outputs, nil
+}
+
 // GetAssistantResponse retrieves the latest assistant message from a thread.
-func (c *openAIClient) GetAssistantResponse(threadID string) ([]openai.Message, error) {
-	messages, err := c.client.ListMessage(context.Background(), threadID, nil, nil, nil, nil, nil)
+func (c *openAIClient) GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error) {
+	messages, err := withRetry(ctx, "ListMessage", func() (openai.MessagesList, error) {
+		return c.client.ListMessage(ctx, threadID, nil, nil, nil, nil, nil)
+	})
 	if err != nil {
-		fmt.Printf("[OpenAI] ListMessage error: %+v\n", err)
+		logging.From(ctx).Error().Err(err).Str("request", "ListMessage").Msg("OpenAI request failed")
 		return nil, fmt.Errorf("failed to list messages: %w", err)
 	}
 
@@ -146,3 +373,71 @@ func (c *openAIClient) GetAssistantResponse(threadID string) ([]openai.Message,
 
 	return assistantMessages, nil
 }
+
+// ListThreadMessages returns every message of a thread, oldest first.
+func (c *openAIClient) ListThreadMessages(ctx context.Context, threadID string) ([]openai.Message, error) {
+	limit, order := 100, "asc"
+	var messages []openai.Message
+	var after *string
+	for {
+		page, err := withRetry(ctx, "ListMessage", func() (openai.MessagesList, error) {
+			return c.client.ListMessage(ctx, threadID, &limit, &order, after, nil, nil)
+		})
+		if err != nil {
+			logging.From(ctx).Error().Err(err).Str("request", "ListMessage").Msg("OpenAI request failed")
+			return nil, fmt.Errorf("failed to list messages: %w", err)
+		}
+		messages = append(messages, page.Messages...)
+		if !page.HasMore || page.LastID == nil {
+			return messages, nil
+		}
+		after = page.LastID
+	}
+}
+
+// Complete runs a single-shot chat completion outside of any thread.
+func (c *openAIClient) Complete(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+	content, _, err := c.CompleteWithUsage(ctx, model, systemPrompt, userPrompt)
+	return content, err
+}
+
+// CompleteWithUsage runs a single-shot chat completion and reports its token usage.
+func (c *openAIClient) CompleteWithUsage(ctx context.Context, model, systemPrompt, userPrompt string) (string, *RunResult, error) {
+	resp, err := withRetry(ctx, "CreateChatCompletion", func() (openai.ChatCompletionResponse, error) {
+		return c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+				{Role: openai.ChatMessageRoleUser, Content: userPrompt},
+			},
+		})
+	})
+	if err != nil {
+		logging.From(ctx).Error().Err(err).Str("request", "CreateChatCompletion").Msg("OpenAI request failed")
+		return "", nil, fmt.Errorf("failed to create chat completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil, fmt.Errorf("chat completion returned no choices")
+	}
+	return resp.Choices[0].Message.Content, &RunResult{
+		RunID:            resp.ID,
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}, nil
+}
+
+// toOpenAIMetadata converts string metadata to the OpenAI request format, dropping empty values.
+func toOpenAIMetadata(metadata map[string]string) map[string]any {
+	if len(metadata) == 0 {
+		return nil
+	}
+	result := make(map[string]any, len(metadata))
+	for k, v := range metadata {
+		if v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"sofa-commander/backend/internal/chaos"
+)
+
+// OpenAIClientFactory creates OpenAI clients from an AIConfig. Other providers are created by the
+// AIClientFactory and adapted to the OpenAIClient interface.
+type OpenAIClientFactory interface {
+	CreateOpenAIClient(config AIConfig) (OpenAIClient, error)
+}
+
+// openAIClientFactory caches one client per provider, API key and organization (or model for other
+// providers) so assistants and conversations are reused.
+type openAIClientFactory struct {
+	mu        sync.Mutex
+	clients   map[string]OpenAIClient
+	hub       TranscriptHub
+	aiFactory AIClientFactory
+}
+
+// NewOpenAIClientFactory creates a new OpenAIClientFactory. Created clients mirror their
+// thread activity to the hub when one is given, and trace their calls when tracing is configured.
+func NewOpenAIClientFactory(hub TranscriptHub) OpenAIClientFactory {
+	return &openAIClientFactory{clients: make(map[string]OpenAIClient), hub: hub, aiFactory: NewAIClientFactory()}
+}
+
+// CreateOpenAIClient returns a client for the given configuration.
+func (f *openAIClientFactory) CreateOpenAIClient(config AIConfig) (OpenAIClient, error) {
+	if config.Provider != "" && config.Provider != "openai" {
+		return f.createAdaptedClient(config)
+	}
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for provider %s", config.Provider)
+	}
+
+	organization := config.Options["organization"]
+	key := organization + "|" + config.APIKey
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if client, ok := f.clients[key]; ok {
+		return client, nil
+	}
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	clientConfig.OrgID = organization
+	clientConfig.HTTPClient = &http.Client{Transport: chaos.Transport(nil)}
+	client := NewTracingClient(NewMirroringClient(&openAIClient{client: openai.NewClientWithConfig(clientConfig), apiKey: config.APIKey, orgID: organization}, f.hub))
+	f.clients[key] = client
+	return client, nil
+}
+
+// createAdaptedClient returns a client of a provider other than OpenAI.
+func (f *openAIClientFactory) createAdaptedClient(config AIConfig) (OpenAIClient, error) {
+	key := config.Provider + "|" + config.Model + "|" + config.Options["base_url"] + "|" + config.APIKey
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if client, ok := f.clients[key]; ok {
+		return client, nil
+	}
+	aiClient, err := f.aiFactory.CreateClient(config)
+	if err != nil {
+		return nil, err
+	}
+	client := NewTracingClient(NewMirroringClient(NewAssistantAdapter(aiClient), f.hub))
+	f.clients[key] = client
+	return client, nil
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/tracing"
+)
+
+const (
+	// maxRetryAttempts bounds the attempts of a provider request failing with a transient error.
+	maxRetryAttempts = 5
+	// retryBudget bounds the total time spent waiting between the attempts of a request.
+	retryBudget    = time.Minute
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 20 * time.Second
+
+	// The interval between polls of a run's status grows from pollBaseInterval to pollMaxInterval.
+	pollBaseInterval = 500 * time.Millisecond
+	pollMaxInterval  = 5 * time.Second
+)
+
+// RetryExhaustedError is returned when a provider request keeps failing with a transient error, being
+// rate limited (429) or a server error (5xx), until its retries are exhausted.
+type RetryExhaustedError struct {
+	Operation  string
+	Attempts   int
+	StatusCode int // Of the last attempt
+	Err        error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	return fmt.Sprintf("%s failed after %d attempts (status %d): %v", e.Operation, e.Attempts, e.StatusCode, e.Err)
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimited reports whether the provider was still rate limiting the request.
+func (e *RetryExhaustedError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// transientStatus returns the HTTP status of a provider error worth retrying, or 0. An exhausted quota
+// is reported as 429 too, but does not recover by waiting.
+func transientStatus(err error) int {
+	status := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		if apiErr.Type == "insufficient_quota" || apiErr.Code == "insufficient_quota" {
+			return 0
+		}
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return status
+	}
+	return 0
+}
+
+// backoff returns the delay before the given retry (0 for the first), growing exponentially from base
+// up to limit, with jitter so clients rate limited together do not retry together.
+func backoff(attempt int, base, limit time.Duration) time.Duration {
+	delay := limit
+	if attempt < 16 && base<<attempt < limit {
+		delay = base << attempt
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// withRetry calls a provider request, retrying it with backoff while it fails with a transient error,
+// within maxRetryAttempts and retryBudget. A request still failing then returns a RetryExhaustedError.
+// Only requests that can safely be repeated go through withRetry; see withCreateRetry.
+func withRetry[T any](ctx context.Context, operation string, call func() (T, error)) (T, error) {
+	return retry(ctx, operation, true, call)
+}
+
+// withCreateRetry calls a provider request creating something, e.g. a thread, message or run, retrying it
+// only while it is rate limited. A server error may come after the provider created it, so repeating the
+// request could create it twice.
+func withCreateRetry[T any](ctx context.Context, operation string, call func() (T, error)) (T, error) {
+	return retry(ctx, operation, false, call)
+}
+
+func retry[T any](ctx context.Context, operation string, retryServerErrors bool, call func() (T, error)) (T, error) {
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		result, err := call()
+		status := transientStatus(err)
+		if err == nil || status == 0 || (!retryServerErrors && status != http.StatusTooManyRequests) {
+			return result, err
+		}
+		delay := backoff(attempt-1, retryBaseDelay, retryMaxDelay)
+		if attempt >= maxRetryAttempts || waited+delay > retryBudget {
+			return result, &RetryExhaustedError{Operation: operation, Attempts: attempt, StatusCode: status, Err: err}
+		}
+		logging.From(ctx).Warn().Str("request", operation).Int("status", status).Dur("delay", delay).Int("attempt", attempt).Int("max_attempts", maxRetryAttempts).Msg("OpenAI request failed, retrying")
+		tracing.Event(ctx, "retry", attribute.String("ai.request", operation), attribute.Int("http.response.status_code", status), attribute.Int("retry.attempt", attempt))
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+		waited += delay
+	}
+}
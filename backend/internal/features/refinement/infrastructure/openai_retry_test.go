@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestTransientStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", err: nil, want: 0},
+		{name: "rate limited", err: &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, want: http.StatusTooManyRequests},
+		{name: "server error", err: &openai.APIError{HTTPStatusCode: http.StatusBadGateway}, want: http.StatusBadGateway},
+		{name: "wrapped server error", err: fmt.Errorf("create run: %w", &openai.APIError{HTTPStatusCode: http.StatusServiceUnavailable}), want: http.StatusServiceUnavailable},
+		{name: "request error", err: &openai.RequestError{HTTPStatusCode: http.StatusInternalServerError, Err: errors.New("bad gateway")}, want: http.StatusInternalServerError},
+		{name: "client error", err: &openai.APIError{HTTPStatusCode: http.StatusBadRequest}, want: 0},
+		{name: "exhausted quota by type", err: &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Type: "insufficient_quota"}, want: 0},
+		{name: "exhausted quota by code", err: &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Code: "insufficient_quota"}, want: 0},
+		{name: "other error", err: errors.New("connection reset"), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transientStatus(tt.err); got != tt.want {
+				t.Errorf("transientStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, limit := 500*time.Millisecond, 20*time.Second
+	tests := []struct {
+		attempt int
+		want    time.Duration // Delay before jitter, which keeps it between half of it and all of it
+	}{
+		{attempt: 0, want: 500 * time.Millisecond},
+		{attempt: 1, want: time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 5, want: 16 * time.Second},
+		{attempt: 6, want: 20 * time.Second},
+		{attempt: 40, want: 20 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			for range 100 {
+				if got := backoff(tt.attempt, base, limit); got < tt.want/2 || got > tt.want {
+					t.Fatalf("backoff() = %v, want between %v and %v", got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
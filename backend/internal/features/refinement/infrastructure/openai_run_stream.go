@@ -0,0 +1,179 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/tracing"
+)
+
+const openAIBaseURL = "https://api.openai.com/v1"
+
+// RunStreamer is implemented by clients that can report the progress of an assistant run while it
+// executes. onEvent receives "run_status" events carrying the new run status and "delta" events carrying
+// the next piece of the assistant's output.
+type RunStreamer interface {
+	StreamAssistantRun(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema, onEvent func(eventType, content string)) (*RunResult, error)
+}
+
+// streamRunRequest is a RunRequest with streaming turned on.
+type streamRunRequest struct {
+	openai.RunRequest
+	Stream bool `json:"stream"`
+}
+
+// streamToolOutputsRequest is a SubmitToolOutputsRequest with streaming turned on.
+type streamToolOutputsRequest struct {
+	openai.SubmitToolOutputsRequest
+	Stream bool `json:"stream"`
+}
+
+// messageDelta is the payload of a thread.message.delta event.
+type messageDelta struct {
+	Delta struct {
+		Content []struct {
+			Text *struct {
+				Value string `json:"value"`
+			} `json:"text,omitempty"`
+		} `json:"content"`
+	} `json:"delta"`
+}
+
+// StreamAssistantRun runs the assistant like RunAssistantWithModel, streaming the run's status changes
+// and output to onEvent instead of polling. When the context ends first, the run is cancelled.
+func (c *openAIClient) StreamAssistantRun(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema, onEvent func(eventType, content string)) (*RunResult, error) {
+	logging.From(ctx).Debug().Str("assistant_id", assistantID).Str("thread_id", threadID).Str("model", model).Msg("Streaming assistant")
+	req := openai.RunRequest{
+		AssistantID: assistantID,
+		Model:       model,
+		Metadata:    toOpenAIMetadata(metadata),
+	}
+	if tools != nil {
+		req.Tools = tools.Tools()
+	}
+	if format := schema.responseFormat(); format != nil {
+		req.ResponseFormat = format
+	}
+	run, err := withCreateRetry(ctx, "CreateRun", func() (openai.Run, error) {
+		return c.streamRun(ctx, openAIBaseURL+"/threads/"+threadID+"/runs", streamRunRequest{RunRequest: req, Stream: true}, onEvent)
+	})
+	if err != nil {
+		if ctx.Err() != nil && run.ID != "" {
+			c.abandonRun(threadID, run.ID)
+		}
+		return nil, fmt.Errorf("failed to stream run: %w", err)
+	}
+
+	for run.Status == openai.RunStatusRequiresAction {
+		if run.RequiredAction == nil || run.RequiredAction.SubmitToolOutputs == nil {
+			return nil, fmt.Errorf("run %s requires an unsupported action", run.ID)
+		}
+		outputs, err := executeToolCalls(ctx, run, tools)
+		if err != nil {
+			return nil, err
+		}
+		runID := run.ID
+		run, err = withCreateRetry(ctx, "SubmitToolOutputs", func() (openai.Run, error) {
+			return c.streamRun(ctx, openAIBaseURL+"/threads/"+threadID+"/runs/"+runID+"/submit_tool_outputs",
+				streamToolOutputsRequest{SubmitToolOutputsRequest: openai.SubmitToolOutputsRequest{ToolOutputs: outputs}, Stream: true}, onEvent)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				c.abandonRun(threadID, runID)
+			}
+			return nil, fmt.Errorf("failed to stream tool outputs: %w", err)
+		}
+	}
+
+	if run.Status != openai.RunStatusCompleted {
+		return nil, fmt.Errorf("run did not complete successfully, status: %s", run.Status)
+	}
+	return &RunResult{
+		RunID:            run.ID,
+		Model:            run.Model,
+		PromptTokens:     run.Usage.PromptTokens,
+		CompletionTokens: run.Usage.CompletionTokens,
+		TotalTokens:      run.Usage.TotalTokens,
+	}, nil
+}
+
+// streamRun posts a streaming request and reads its events until the stream ends, returning the last
+// reported state of the run.
+func (c *openAIClient) streamRun(ctx context.Context, url string, body any, onEvent func(eventType, content string)) (openai.Run, error) {
+	var run openai.Run
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return run, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return run, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	if c.orgID != "" {
+		req.Header.Set("OpenAI-Organization", c.orgID)
+	}
+	resp, err := aiHTTPClient.Do(req)
+	if err != nil {
+		return run, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		// Reported like the SDK's errors, so transient failures are retried (see withRetry)
+		return run, &openai.RequestError{HTTPStatus: resp.Status, HTTPStatusCode: resp.StatusCode, Err: fmt.Errorf("provider returned %s", resp.Status), Body: respBody}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024) // Run objects carry the full instructions
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				return run, nil
+			}
+			switch {
+			case event == "thread.message.delta":
+				var delta messageDelta
+				if err := json.Unmarshal([]byte(data), &delta); err != nil {
+					return run, fmt.Errorf("failed to parse message delta: %w", err)
+				}
+				for _, content := range delta.Delta.Content {
+					if content.Text != nil && content.Text.Value != "" {
+						onEvent("delta", content.Text.Value)
+					}
+				}
+			case strings.HasPrefix(event, "thread.run.") && !strings.HasPrefix(event, "thread.run.step."):
+				if err := json.Unmarshal([]byte(data), &run); err != nil {
+					return run, fmt.Errorf("failed to parse run: %w", err)
+				}
+				onEvent("run_status", string(run.Status))
+				tracing.Event(ctx, "run.status", attribute.String("ai.run.status", string(run.Status)))
+			case event == "error":
+				return run, fmt.Errorf("stream error: %s", data)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return run, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return run, nil
+}
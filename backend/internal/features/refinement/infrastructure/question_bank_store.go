@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// QuestionBankStore defines the interface for question bank persistence.
+type QuestionBankStore interface {
+	List(workspaceID string) ([]domain.BankQuestion, error)
+	Save(question domain.BankQuestion) error
+	Delete(workspaceID, id string) error
+}
+
+// jsonQuestionBankStore stores the question banks of all products in a JSON file.
+type jsonQuestionBankStore struct {
+	file *jsonfile.Store[[]domain.BankQuestion]
+	mu   sync.Mutex
+}
+
+// NewJSONQuestionBankStore creates a new question bank store backed by the given JSON file.
+func NewJSONQuestionBankStore(path string) QuestionBankStore {
+	return &jsonQuestionBankStore{file: jsonfile.NewStore[[]domain.BankQuestion](path, "question bank")}
+}
+
+// List returns the bank questions of a workspace, oldest first.
+func (s *jsonQuestionBankStore) List(workspaceID string) ([]domain.BankQuestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	questions, err := s.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.BankQuestion{}
+	for _, q := range questions {
+		if q.WorkspaceID == workspaceID {
+			result = append(result, q)
+		}
+	}
+	return result, nil
+}
+
+// Save inserts a bank question or replaces the one with the same ID.
+func (s *jsonQuestionBankStore) Save(question domain.BankQuestion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	questions, err := s.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range questions {
+		if questions[i].ID == question.ID {
+			questions[i] = question
+			return s.file.Store(questions)
+		}
+	}
+	return s.file.Store(append(questions, question))
+}
+
+// Delete removes a bank question of a workspace.
+func (s *jsonQuestionBankStore) Delete(workspaceID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	questions, err := s.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range questions {
+		if questions[i].ID == id && questions[i].WorkspaceID == workspaceID {
+			return s.file.Store(append(questions[:i], questions[i+1:]...))
+		}
+	}
+	return fmt.Errorf("bank question %s not found", id)
+}
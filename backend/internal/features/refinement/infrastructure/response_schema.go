@@ -0,0 +1,24 @@
+package infrastructure
+
+// ResponseSchema is a JSON schema the output of an assistant run must follow. Providers that support
+// structured outputs enforce it; the others only get the instructions of the thread.
+type ResponseSchema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// responseFormat returns the schema as a strict OpenAI json_schema response format, or nil without a
+// schema.
+func (s *ResponseSchema) responseFormat() any {
+	if s == nil {
+		return nil
+	}
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   s.Name,
+			"schema": s.Schema,
+			"strict": true,
+		},
+	}
+}
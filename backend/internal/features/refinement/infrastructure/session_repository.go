@@ -0,0 +1,240 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/migrate"
+	"sofa-commander/backend/internal/quiesce"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" driver
+	_ "modernc.org/sqlite"             // Registers the "sqlite" driver
+)
+
+// ErrSessionNotFound is returned when a session is not in the repository.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository defines the interface for refinement session persistence. Sessions are stored as
+// a whole, including their questions, suggestions and history.
+type SessionRepository interface {
+	Save(session *domain.RefinementSession) error
+	Get(id string) (*domain.RefinementSession, error)
+	List() ([]*domain.RefinementSession, error)
+}
+
+// sqlSessionRepository stores sessions as JSON documents in a SQL table.
+type sqlSessionRepository struct {
+	db          *sql.DB
+	upsertQuery string
+	getQuery    string
+}
+
+// sessionMigrations holds the schema migrations of the session store, per driver.
+//
+//go:embed migrations
+var sessionMigrations embed.FS
+
+// NewSessionRepository creates the session repository selected by driver: "sqlite" (the default) stores
+// sessions in the SQLite file at dsn, "postgres" in the PostgreSQL database at the dsn connection URL.
+// The pending schema migrations are applied first.
+func NewSessionRepository(driver, dsn string) (SessionRepository, error) {
+	db, driver, err := openSessionDatabase(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := migrateSessionDatabase(db, driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, m := range applied {
+		logging.Default().Info().Int("version", m.Version).Str("name", m.Name).Msg("Applied session store migration")
+	}
+	return newSQLSessionRepository(db, driver), nil
+}
+
+// MigrationReport describes a migration of the session store.
+type MigrationReport struct {
+	Applied          []migrate.Migration // Schema migrations applied
+	SchemaVersion    int                 // Schema version of the store after migrating
+	UpgradedSessions int                 // Stored sessions rewritten in the current session format
+}
+
+// MigrateSessionStore applies the pending schema migrations of the session store selected by driver and
+// dsn, as NewSessionRepository does, then rewrites the sessions stored in an older session format.
+func MigrateSessionStore(driver, dsn string) (*MigrationReport, error) {
+	db, driver, err := openSessionDatabase(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	report := &MigrationReport{}
+	report.Applied, err = migrateSessionDatabase(db, driver)
+	if err != nil {
+		return report, err
+	}
+	migrations, _ := migrate.Load(sessionMigrations, "migrations/"+driver)
+	report.SchemaVersion = migrate.Latest(migrations)
+	report.UpgradedSessions, err = newSQLSessionRepository(db, driver).upgradeSessions()
+	return report, err
+}
+
+func newSQLSessionRepository(db *sql.DB, driver string) *sqlSessionRepository {
+	if driver == "postgres" {
+		return &sqlSessionRepository{
+			db: db,
+			upsertQuery: `INSERT INTO refinement_sessions (id, phase, created_at, updated_at, data) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (id) DO UPDATE SET phase = excluded.phase, updated_at = excluded.updated_at, data = excluded.data`,
+			getQuery: `SELECT data FROM refinement_sessions WHERE id = $1`,
+		}
+	}
+	return &sqlSessionRepository{
+		db: db,
+		upsertQuery: `INSERT INTO refinement_sessions (id, phase, created_at, updated_at, data) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET phase = excluded.phase, updated_at = excluded.updated_at, data = excluded.data`,
+		getQuery: `SELECT data FROM refinement_sessions WHERE id = ?`,
+	}
+}
+
+// openSessionDatabase opens the session database selected by driver and dsn, and returns it with the
+// name of its driver.
+func openSessionDatabase(driver, dsn string) (*sql.DB, string, error) {
+	switch driver {
+	case "", "sqlite":
+		if dsn == "" {
+			dsn = "data/sessions.db"
+		}
+		if err := os.MkdirAll(filepath.Dir(dsn), 0755); err != nil {
+			return nil, "", fmt.Errorf("failed to create session database directory: %w", err)
+		}
+		db, err := sql.Open("sqlite", dsn)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open session database: %w", err)
+		}
+		db.SetMaxOpenConns(1) // SQLite allows a single writer
+		return db, "sqlite", nil
+	case "postgres":
+		if dsn == "" {
+			return nil, "", fmt.Errorf("SESSION_STORE_DSN must be set for the postgres session store")
+		}
+		db, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open session database: %w", err)
+		}
+		return db, "postgres", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported session store %q", driver)
+	}
+}
+
+// migrateSessionDatabase applies the pending migrations of a session database.
+func migrateSessionDatabase(db *sql.DB, driver string) ([]migrate.Migration, error) {
+	migrations, err := migrate.Load(sessionMigrations, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := migrate.Up(db, driver, migrations)
+	if err != nil {
+		return applied, fmt.Errorf("failed to migrate session database: %w", err)
+	}
+	return applied, nil
+}
+
+// Save inserts a session or replaces the stored one with the same ID. It waits while the stores are
+// paused for a backup.
+func (r *sqlSessionRepository) Save(session *domain.RefinementSession) error {
+	data, err := domain.EncodeSession(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	defer quiesce.Hold()()
+	if _, err := r.db.Exec(r.upsertQuery, session.ID, string(session.Phase), session.CreatedAt, time.Now(), string(data)); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// Get returns the stored session with the given ID.
+func (r *sqlSessionRepository) Get(id string) (*domain.RefinementSession, error) {
+	var data string
+	if err := r.db.QueryRow(r.getQuery, id).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to load session %s: %w", id, err)
+	}
+	session, _, err := domain.DecodeSession([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session %s: %w", id, err)
+	}
+	return session, nil
+}
+
+// List returns all stored sessions, oldest first.
+func (r *sqlSessionRepository) List() ([]*domain.RefinementSession, error) {
+	rows, err := r.db.Query(`SELECT data FROM refinement_sessions ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.RefinementSession
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		session, _, err := domain.DecodeSession([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		result = append(result, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return result, nil
+}
+
+// upgradeSessions rewrites the stored sessions of an older session format, and returns their number.
+// Sessions are upgraded on load anyway; rewriting them lets the upgrades be dropped in a later release.
+func (r *sqlSessionRepository) upgradeSessions() (int, error) {
+	rows, err := r.db.Query(`SELECT data FROM refinement_sessions`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var upgraded []*domain.RefinementSession
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to read session: %w", err)
+		}
+		session, ok, err := domain.DecodeSession([]byte(data))
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		if ok {
+			upgraded = append(upgraded, session)
+		}
+	}
+	// Closed before saving, as SQLite holds a single connection
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for i, session := range upgraded {
+		if err := r.Save(session); err != nil {
+			return i, err
+		}
+	}
+	return len(upgraded), nil
+}
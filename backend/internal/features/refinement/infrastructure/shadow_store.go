@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/jsonl"
+)
+
+// ShadowRunStore defines the interface for persisting shadow model runs.
+type ShadowRunStore interface {
+	Append(run domain.ShadowRun) error
+	List(shadowModel string) ([]domain.ShadowRun, error)
+}
+
+// jsonlShadowRunStore appends shadow runs to a JSON Lines file.
+type jsonlShadowRunStore struct {
+	runs *jsonl.Store[domain.ShadowRun]
+}
+
+// NewJSONLShadowRunStore creates a store backed by the given JSON Lines file.
+func NewJSONLShadowRunStore(path string) ShadowRunStore {
+	return &jsonlShadowRunStore{runs: jsonl.NewStore[domain.ShadowRun](path, "shadow run")}
+}
+
+// Append writes a single shadow run.
+func (s *jsonlShadowRunStore) Append(run domain.ShadowRun) error {
+	return s.runs.Append(run)
+}
+
+// List returns the shadow runs of a shadow model, or of every model when empty, oldest first.
+func (s *jsonlShadowRunStore) List(shadowModel string) ([]domain.ShadowRun, error) {
+	return s.runs.List(func(run domain.ShadowRun) bool {
+		return shadowModel == "" || run.ShadowModel == shadowModel
+	})
+}
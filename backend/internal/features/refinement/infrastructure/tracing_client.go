@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"context"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+
+	"sofa-commander/backend/internal/tracing"
+)
+
+// tracingClient decorates an OpenAIClient with a span per provider call.
+type tracingClient struct {
+	OpenAIClient
+}
+
+// NewTracingClient wraps a client so each of its calls is traced as a child of the context's span. It
+// wraps the mirroring client rather than the other way around, so runs keep streaming.
+func NewTracingClient(client OpenAIClient) OpenAIClient {
+	if client == nil || !tracing.Configured() {
+		return client
+	}
+	return &tracingClient{OpenAIClient: client}
+}
+
+// GetOrCreateAssistant traces the assistant lookup.
+func (c *tracingClient) GetOrCreateAssistant(ctx context.Context, name, instructions, model string) (string, error) {
+	ctx, span := tracing.Start(ctx, "openai.GetOrCreateAssistant", attribute.String("ai.assistant.name", name), attribute.String("ai.model", model))
+	assistantID, err := c.OpenAIClient.GetOrCreateAssistant(ctx, name, instructions, model)
+	span.SetAttributes(attribute.String("ai.assistant.id", assistantID))
+	tracing.End(span, err)
+	return assistantID, err
+}
+
+// CreateThread traces the thread creation.
+func (c *tracingClient) CreateThread(ctx context.Context, metadata map[string]string) (string, error) {
+	ctx, span := tracing.Start(ctx, "openai.CreateThread", metadataAttributes(metadata)...)
+	threadID, err := c.OpenAIClient.CreateThread(ctx, metadata)
+	span.SetAttributes(attribute.String("ai.thread.id", threadID))
+	tracing.End(span, err)
+	return threadID, err
+}
+
+// AddMessageToThread traces adding the message. The content is left out of the span, only its length
+// is recorded.
+func (c *tracingClient) AddMessageToThread(ctx context.Context, threadID, content string) error {
+	ctx, span := tracing.Start(ctx, "openai.AddMessageToThread", attribute.String("ai.thread.id", threadID), attribute.Int("ai.message.length", len(content)))
+	err := c.OpenAIClient.AddMessageToThread(ctx, threadID, content)
+	tracing.End(span, err)
+	return err
+}
+
+// RunAssistant traces the run like RunAssistantWithModel.
+func (c *tracingClient) RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error) {
+	return c.RunAssistantWithModel(ctx, threadID, assistantID, "", metadata, tools, nil)
+}
+
+// RunAssistantWithModel traces the run until it completes, including its polls, and its token usage.
+func (c *tracingClient) RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error) {
+	attrs := append(metadataAttributes(metadata), attribute.String("ai.thread.id", threadID), attribute.String("ai.assistant.id", assistantID), attribute.String("ai.model", model))
+	ctx, span := tracing.Start(ctx, "openai.RunAssistant", attrs...)
+	result, err := c.OpenAIClient.RunAssistantWithModel(ctx, threadID, assistantID, model, metadata, tools, schema)
+	if result != nil {
+		span.SetAttributes(
+			attribute.String("ai.run.id", result.RunID),
+			attribute.String("ai.run.model", result.Model),
+			attribute.Int("ai.usage.prompt_tokens", result.PromptTokens),
+			attribute.Int("ai.usage.completion_tokens", result.CompletionTokens),
+		)
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
+// CancelActiveRuns traces the cancellation.
+func (c *tracingClient) CancelActiveRuns(ctx context.Context, threadID string) error {
+	ctx, span := tracing.Start(ctx, "openai.CancelActiveRuns", attribute.String("ai.thread.id", threadID))
+	err := c.OpenAIClient.CancelActiveRuns(ctx, threadID)
+	tracing.End(span, err)
+	return err
+}
+
+// GetAssistantResponse traces fetching the assistant messages.
+func (c *tracingClient) GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error) {
+	ctx, span := tracing.Start(ctx, "openai.GetAssistantResponse", attribute.String("ai.thread.id", threadID))
+	messages, err := c.OpenAIClient.GetAssistantResponse(ctx, threadID)
+	tracing.End(span, err)
+	return messages, err
+}
+
+// ListThreadMessages traces listing the thread's messages.
+func (c *tracingClient) ListThreadMessages(ctx context.Context, threadID string) ([]openai.Message, error) {
+	ctx, span := tracing.Start(ctx, "openai.ListThreadMessages", attribute.String("ai.thread.id", threadID))
+	messages, err := c.OpenAIClient.ListThreadMessages(ctx, threadID)
+	span.SetAttributes(attribute.Int("ai.thread.messages", len(messages)))
+	tracing.End(span, err)
+	return messages, err
+}
+
+// Complete traces the completion.
+func (c *tracingClient) Complete(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+	ctx, span := tracing.Start(ctx, "openai.Complete", attribute.String("ai.model", model))
+	content, err := c.OpenAIClient.Complete(ctx, model, systemPrompt, userPrompt)
+	tracing.End(span, err)
+	return content, err
+}
+
+// CompleteWithUsage traces the completion and its token usage.
+func (c *tracingClient) CompleteWithUsage(ctx context.Context, model, systemPrompt, userPrompt string) (string, *RunResult, error) {
+	ctx, span := tracing.Start(ctx, "openai.Complete", attribute.String("ai.model", model))
+	content, result, err := c.OpenAIClient.CompleteWithUsage(ctx, model, systemPrompt, userPrompt)
+	if result != nil {
+		span.SetAttributes(attribute.Int("ai.usage.prompt_tokens", result.PromptTokens), attribute.Int("ai.usage.completion_tokens", result.CompletionTokens))
+	}
+	tracing.End(span, err)
+	return content, result, err
+}
+
+// metadataAttributes returns the run or thread metadata, e.g. the session and operation, as span attributes.
+func metadataAttributes(metadata map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(metadata))
+	for key, value := range metadata {
+		attrs = append(attrs, attribute.String("ai.metadata."+key, value))
+	}
+	return attrs
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// TranscriptEvent is a single message or run status mirrored from a thread.
+type TranscriptEvent struct {
+	Type      string    `json:"type"` // "user_message", "assistant_message", "run_started", "run_status", "delta", "run_completed", "run_failed"
+	ThreadID  string    `json:"thread_id"`
+	Content   string    `json:"content,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TranscriptHub fans out transcript events to subscribers of a thread.
+type TranscriptHub interface {
+	Publish(event TranscriptEvent)
+	Subscribe(threadID string) (<-chan TranscriptEvent, func())
+}
+
+// transcriptHub is an in-memory implementation of TranscriptHub.
+type transcriptHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan TranscriptEvent]struct{}
+}
+
+// NewTranscriptHub creates a new TranscriptHub.
+func NewTranscriptHub() TranscriptHub {
+	return &transcriptHub{subscribers: make(map[string]map[chan TranscriptEvent]struct{})}
+}
+
+// Publish delivers the event to all subscribers of its thread, dropping it for slow subscribers.
+func (h *transcriptHub) Publish(event TranscriptEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers[event.ThreadID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber for a thread. The returned function unsubscribes.
+func (h *transcriptHub) Subscribe(threadID string) (<-chan TranscriptEvent, func()) {
+	ch := make(chan TranscriptEvent, 256) // Streamed runs publish an event per output delta
+	h.mu.Lock()
+	if h.subscribers[threadID] == nil {
+		h.subscribers[threadID] = make(map[chan TranscriptEvent]struct{})
+	}
+	h.subscribers[threadID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[threadID][ch]; !ok {
+			return
+		}
+		delete(h.subscribers[threadID], ch)
+		if len(h.subscribers[threadID]) == 0 {
+			delete(h.subscribers, threadID)
+		}
+		close(ch)
+	}
+}
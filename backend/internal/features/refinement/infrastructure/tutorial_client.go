@@ -0,0 +1,168 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// tutorialModel is reported as the model of scripted tutorial runs.
+const tutorialModel = "tutorial-mock"
+
+// tutorialQuestionRounds are the scripted questions of each questioning round, for the tutorial roles.
+// Rounds past the last one repeat it.
+var tutorialQuestionRounds = []string{
+	`[{"role": "ProductManager", "prompt": ["收藏的路線是否需要跨裝置同步？", "每位使用者最多可以收藏幾條路線？"]},
+{"role": "Designer", "prompt": ["收藏按鈕要放在查詢結果頁還是路線詳情頁？"]},
+{"role": "Backend", "prompt": ["路線資料異動（例如站點停駛）時，收藏要如何處理？"]}]`,
+	`[{"role": "ProductManager", "prompt": ["收藏清單是否需要支援自訂名稱，例如「上班」、「回家」？"]},
+{"role": "Designer", "prompt": ["收藏清單超過一頁時，要依使用頻率還是收藏時間排序？"]},
+{"role": "Backend", "prompt": ["未登入的使用者可以收藏嗎？登入後是否要合併本機收藏？"]}]`,
+}
+
+// tutorialSuggestions are the scripted suggestions of the tutorial.
+const tutorialSuggestions = `[{"role": "ProductManager", "prompt": ["將收藏上限訂為 20 條，並在達到上限時提示使用者整理"]},
+{"role": "Designer", "prompt": ["在查詢結果與路線詳情頁都提供星號收藏按鈕，並支援長按重新命名"]},
+{"role": "Backend", "prompt": ["路線停駛時保留收藏並標示「已停駛」，避免使用者的收藏無預警消失"]}]`
+
+// tutorialFinalOutput is the scripted final story of the tutorial.
+const tutorialFinalOutput = `【用戶故事】
+身為通勤族，我想要在 App 中收藏最多 20 條常用路線並自訂名稱，且在不同裝置間同步，以便每天通勤時一鍵查詢即時資訊。
+
+【驗收標準】
+1. 使用者可在查詢結果頁與路線詳情頁點擊星號收藏路線，收藏後於「我的收藏」立即可見
+2. 使用者可為收藏的路線自訂名稱，名稱長度上限 20 字
+3. 收藏達 20 條時顯示提示，且無法再新增，直到移除既有收藏
+4. 路線停駛時，收藏仍保留並標示「已停駛」
+5. 登入後，收藏於 5 秒內同步到使用者的其他裝置`
+
+// tutorialThread is the state of a scripted tutorial thread.
+type tutorialThread struct {
+	messages       []openai.Message
+	questionRounds int
+}
+
+// tutorialClient is a scripted OpenAIClient for tutorial sessions. It never calls a provider: each run
+// answers with the scripted output of the operation in the run's metadata.
+type tutorialClient struct {
+	mu      sync.Mutex
+	threads map[string]*tutorialThread
+	nextID  int
+}
+
+// NewTutorialClient creates a scripted client for tutorial sessions.
+func NewTutorialClient() OpenAIClient {
+	return &tutorialClient{threads: make(map[string]*tutorialThread)}
+}
+
+// GetOrCreateAssistant returns a fixed assistant ID.
+func (c *tutorialClient) GetOrCreateAssistant(ctx context.Context, name, instructions, model string) (string, error) {
+	return "asst_tutorial", nil
+}
+
+// CreateThread creates an in-memory thread.
+func (c *tutorialClient) CreateThread(ctx context.Context, metadata map[string]string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	threadID := fmt.Sprintf("thread_tutorial_%d", c.nextID)
+	c.threads[threadID] = &tutorialThread{}
+	return threadID, nil
+}
+
+// AddMessageToThread records a user message on the thread.
+func (c *tutorialClient) AddMessageToThread(ctx context.Context, threadID, content string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thread, ok := c.threads[threadID]
+	if !ok {
+		return fmt.Errorf("tutorial thread %s not found", threadID)
+	}
+	thread.messages = append(thread.messages, tutorialMessage(threadID, "user", content))
+	return nil
+}
+
+// RunAssistant appends the scripted output of the run's operation to the thread.
+func (c *tutorialClient) RunAssistant(ctx context.Context, threadID, assistantID string, metadata map[string]string, tools ToolExecutor) (*RunResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thread, ok := c.threads[threadID]
+	if !ok {
+		return nil, fmt.Errorf("tutorial thread %s not found", threadID)
+	}
+
+	var output string
+	switch strings.TrimSuffix(metadata["operation"], "_correction") {
+	case "submit_answers_and_get_suggestions", "prefetch_suggestions":
+		output = tutorialSuggestions
+	case "finalize":
+		output = tutorialFinalOutput
+	default: // start, submit_answers_and_continue, accept_suggestions
+		output = tutorialQuestionRounds[min(thread.questionRounds, len(tutorialQuestionRounds)-1)]
+		thread.questionRounds++
+	}
+	thread.messages = append(thread.messages, tutorialMessage(threadID, "assistant", output))
+	c.nextID++
+	return &RunResult{RunID: fmt.Sprintf("run_tutorial_%d", c.nextID), Model: tutorialModel}, nil
+}
+
+// RunAssistantWithModel runs the script like RunAssistant; the model and schema are ignored.
+func (c *tutorialClient) RunAssistantWithModel(ctx context.Context, threadID, assistantID, model string, metadata map[string]string, tools ToolExecutor, schema *ResponseSchema) (*RunResult, error) {
+	return c.RunAssistant(ctx, threadID, assistantID, metadata, tools)
+}
+
+// CancelActiveRuns does nothing: scripted runs finish immediately.
+func (c *tutorialClient) CancelActiveRuns(ctx context.Context, threadID string) error {
+	return nil
+}
+
+// GetAssistantResponse returns the assistant messages of the thread, oldest first.
+func (c *tutorialClient) GetAssistantResponse(ctx context.Context, threadID string) ([]openai.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thread, ok := c.threads[threadID]
+	if !ok {
+		return nil, fmt.Errorf("tutorial thread %s not found", threadID)
+	}
+	var assistantMessages []openai.Message
+	for _, msg := range thread.messages {
+		if msg.Role == "assistant" {
+			assistantMessages = append(assistantMessages, msg)
+		}
+	}
+	return assistantMessages, nil
+}
+
+// ListThreadMessages returns every message of the thread, oldest first.
+func (c *tutorialClient) ListThreadMessages(ctx context.Context, threadID string) ([]openai.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thread, ok := c.threads[threadID]
+	if !ok {
+		return nil, fmt.Errorf("tutorial thread %s not found", threadID)
+	}
+	return append([]openai.Message{}, thread.messages...), nil
+}
+
+// Complete is not scripted; the tutorial only covers the refinement flow.
+func (c *tutorialClient) Complete(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+	return "", fmt.Errorf("this feature is not available in tutorial sessions")
+}
+
+// CompleteWithUsage is not scripted; the tutorial only covers the refinement flow.
+func (c *tutorialClient) CompleteWithUsage(ctx context.Context, model, systemPrompt, userPrompt string) (string, *RunResult, error) {
+	return "", nil, fmt.Errorf("this feature is not available in tutorial sessions")
+}
+
+func tutorialMessage(threadID, role, content string) openai.Message {
+	return openai.Message{
+		ThreadID:  threadID,
+		Role:      role,
+		CreatedAt: int(time.Now().Unix()),
+		Content:   []openai.MessageContent{{Type: "text", Text: &openai.MessageText{Value: content}}},
+	}
+}
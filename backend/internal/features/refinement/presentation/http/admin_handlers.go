@@ -0,0 +1,199 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/application"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// transcriptTicketTTL is how long a transcript ticket can be used to open the transcript WebSocket.
+const transcriptTicketTTL = 30 * time.Second
+
+// transcriptTicket lets a browser, which cannot set headers on WebSocket requests, open the transcript
+// of a session once without putting the admin token in the URL.
+type transcriptTicket struct {
+	sessionID string
+	expiresAt time.Time
+}
+
+// AdminHandler holds the dependencies of the admin-only refinement endpoints.
+type AdminHandler struct {
+	refinementService application.RefinementService
+	transcriptHub     infrastructure.TranscriptHub
+	adminToken        string
+	ticketsMu         sync.Mutex
+	tickets           map[string]transcriptTicket
+}
+
+// NewAdminHandler creates a new AdminHandler. An empty admin token disables the endpoints.
+func NewAdminHandler(refinementService application.RefinementService, transcriptHub infrastructure.TranscriptHub, adminToken string) *AdminHandler {
+	return &AdminHandler{
+		refinementService: refinementService,
+		transcriptHub:     transcriptHub,
+		adminToken:        adminToken,
+		tickets:           make(map[string]transcriptTicket),
+	}
+}
+
+// TranscriptTicketHandler issues a single-use ticket, valid for transcriptTicketTTL, to open the transcript
+// WebSocket of a session with the `ticket` query parameter.
+func (h *AdminHandler) TranscriptTicketHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	if _, ok := h.mirroredSession(c); !ok {
+		return
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate ticket: " + err.Error()})
+		return
+	}
+	ticket := hex.EncodeToString(b)
+	expiresAt := time.Now().Add(transcriptTicketTTL)
+
+	h.ticketsMu.Lock()
+	for id, t := range h.tickets {
+		if time.Now().After(t.expiresAt) {
+			delete(h.tickets, id)
+		}
+	}
+	h.tickets[ticket] = transcriptTicket{sessionID: c.Param("id"), expiresAt: expiresAt}
+	h.ticketsMu.Unlock()
+
+	c.JSON(http.StatusCreated, gin.H{"ticket": ticket, "expires_at": expiresAt})
+}
+
+// redeemTicket consumes the ticket of the request and reports whether it was issued for the session.
+func (h *AdminHandler) redeemTicket(c *gin.Context) bool {
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		return false
+	}
+	h.ticketsMu.Lock()
+	defer h.ticketsMu.Unlock()
+	t, ok := h.tickets[ticket]
+	delete(h.tickets, ticket)
+	return ok && t.sessionID == c.Param("id") && time.Now().Before(t.expiresAt)
+}
+
+// mirroredSession loads the session of the request, which must have been started with transcript
+// mirroring allowed, responding otherwise.
+func (h *AdminHandler) mirroredSession(c *gin.Context) (*domain.RefinementSession, bool) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !session.TranscriptMirroring {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Transcript mirroring is not enabled for this session"})
+		return nil, false
+	}
+	return session, true
+}
+
+// TranscriptWebSocketHandler mirrors a session's thread messages and assistant outputs over a WebSocket.
+// The session must have been started with transcript mirroring allowed. The request carries the admin
+// token, or a ticket from TranscriptTicketHandler when opened from a browser.
+func (h *AdminHandler) TranscriptWebSocketHandler(c *gin.Context) {
+	if !h.redeemTicket(c) && !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token or transcript ticket required"})
+		return
+	}
+	session, ok := h.mirroredSession(c)
+	if !ok {
+		return
+	}
+
+	events, unsubscribe := h.transcriptHub.Subscribe(session.ThreadID)
+	defer unsubscribe()
+
+	websocket.Handler(func(ws *websocket.Conn) {
+		// Detect the client closing the connection
+		closed := make(chan struct{})
+		go func() {
+			var discard string
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+			close(closed)
+		}()
+
+		for {
+			select {
+			case event := <-events:
+				if err := websocket.JSON.Send(ws, event); err != nil {
+					logging.From(c.Request.Context()).Warn().Err(err).Str("session_id", session.ID).Msg("Failed to send transcript event")
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}).ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *AdminHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// ListShadowRunsHandler returns the recorded shadow model runs, optionally filtered by the `model` query parameter.
+func (h *AdminHandler) ListShadowRunsHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	runs, err := h.refinementService.ListShadowRuns(c.Query("model"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list shadow runs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// SaveBankQuestionHandler adds a curated question to a product's question bank (the `workspace_id` query
+// parameter), or edits the question given by the `id` path parameter.
+func (h *AdminHandler) SaveBankQuestionHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.BankQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	question, err := h.refinementService.SaveBankQuestion(c.Query("workspace_id"), c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to save bank question: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteBankQuestionHandler removes a question from a product's question bank.
+func (h *AdminHandler) DeleteBankQuestionHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	if err := h.refinementService.DeleteBankQuestion(c.Query("workspace_id"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bank question deleted"})
+}
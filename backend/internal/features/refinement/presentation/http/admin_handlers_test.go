@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sofa-commander/backend/internal/features/refinement/application"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubSessions serves sessions with transcript mirroring allowed.
+type stubSessions struct {
+	application.RefinementService
+}
+
+func (stubSessions) GetSession(sessionID string) (*domain.RefinementSession, error) {
+	return &domain.RefinementSession{ID: sessionID, ThreadID: "thread-" + sessionID, TranscriptMirroring: true}, nil
+}
+
+func TestTranscriptTicket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewAdminHandler(stubSessions{}, infrastructure.NewTranscriptHub(), "admin")
+	router := gin.New()
+	router.GET("/sessions/:id/transcript", handler.TranscriptWebSocketHandler)
+	router.POST("/sessions/:id/transcript/ticket", handler.TranscriptTicketHandler)
+
+	issue := func(session, token string) (int, string) {
+		req := httptest.NewRequest(http.MethodPost, "/sessions/"+session+"/transcript/ticket", nil)
+		req.Header.Set("X-Admin-Token", token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var resp struct {
+			Ticket string `json:"ticket"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Ticket
+	}
+	// The WebSocket handshake needs a connection to hijack; a plain GET is rejected by it with 400 once
+	// the request is authorized
+	server := httptest.NewServer(router)
+	defer server.Close()
+	open := func(session, query string) int {
+		resp, err := http.Get(server.URL + "/sessions/" + session + "/transcript" + query)
+		if err != nil {
+			t.Fatalf("failed to open transcript: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status, _ := issue("s1", "guess"); status != http.StatusForbidden {
+		t.Fatalf("ticket issued without the admin token: status %d", status)
+	}
+	status, ticket := issue("s1", "admin")
+	if status != http.StatusCreated || ticket == "" {
+		t.Fatalf("ticket not issued: status %d", status)
+	}
+	tests := []struct {
+		name    string
+		session string
+		query   string
+		allowed bool
+	}{
+		{name: "admin token in the URL", session: "s1", query: "?token=admin", allowed: false},
+		{name: "ticket of another session", session: "s2", query: "?ticket=" + ticket, allowed: false},
+		{name: "unknown ticket", session: "s1", query: "?ticket=guess", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := open(tt.session, tt.query); (got != http.StatusForbidden) != tt.allowed {
+				t.Errorf("status = %d, want allowed %v", got, tt.allowed)
+			}
+		})
+	}
+
+	// The ticket was used up by the attempt on another session
+	if got := open("s1", "?ticket="+ticket); got != http.StatusForbidden {
+		t.Errorf("used ticket accepted: status %d", got)
+	}
+	_, ticket = issue("s1", "admin")
+	if got := open("s1", "?ticket="+ticket); got == http.StatusForbidden {
+		t.Errorf("valid ticket rejected")
+	}
+	if got := open("s1", "?ticket="+ticket); got != http.StatusForbidden {
+		t.Errorf("ticket accepted twice: status %d", got)
+	}
+}
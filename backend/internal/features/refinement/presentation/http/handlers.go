@@ -1,30 +1,60 @@
 package http
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
+	"sofa-commander/backend/internal/async"
 	"sofa-commander/backend/internal/config"
 	"sofa-commander/backend/internal/features/refinement/application"
 	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+	"sofa-commander/backend/internal/httpcache"
+	"sofa-commander/backend/internal/logging"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
 )
 
 // RefinementHandler holds the refinement service and app config service.
 type RefinementHandler struct {
 	refinementService application.RefinementService
 	appConfigService  config.AppConfigService
+	async             *async.Runner
 }
 
-// NewRefinementHandler creates a new RefinementHandler.
-func NewRefinementHandler(refinementService application.RefinementService, appConfigService config.AppConfigService) *RefinementHandler {
+// NewRefinementHandler creates a new RefinementHandler running its AI operations with the async runner.
+func NewRefinementHandler(refinementService application.RefinementService, appConfigService config.AppConfigService, asyncRunner *async.Runner) *RefinementHandler {
 	return &RefinementHandler{
 		refinementService: refinementService,
 		appConfigService:  appConfigService,
+		async:             asyncRunner,
 	}
 }
 
+// aiErrorStatus returns the status of a failed AI operation: 503 Service Unavailable when the provider
+// kept rate limiting or failing until the retries were exhausted, so the client can try again later,
+// 409 Conflict when the session's workflow does not allow the phase the operation moves it to or the
+// session cannot be resumed, and 403
+// Forbidden when the user is not a member of the session's workspace.
+func aiErrorStatus(err error) int {
+	var exhausted *infrastructure.RetryExhaustedError
+	if errors.As(err, &exhausted) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, domain.ErrNotWorkspaceMember) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, domain.ErrPhaseTransition) || errors.Is(err, domain.ErrNothingToUndo) || errors.Is(err, domain.ErrResumeUnsupported) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 // StartRefinementHandler handles the request to start a new refinement process.
 func (h *RefinementHandler) StartRefinementHandler(c *gin.Context) {
 	var req domain.RefinementRequest
@@ -33,23 +63,88 @@ func (h *RefinementHandler) StartRefinementHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if userID := c.GetHeader("X-User-ID"); userID != "" {
+		req.UserID = userID
+	}
+	if err := application.ValidateSessionType(req.SessionType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := application.PrepareChangeRequest(h.refinementService, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := application.ValidateOptionalPhases(req.OptionalPhases); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Load app config to get product context and role prompts
 	appConfig, err := h.appConfigService.LoadAppConfig()
 	if err != nil {
-		log.Println("[ERROR] Failed to load app config:", err)
+		logging.From(c.Request.Context()).Error().Err(err).Msg("Failed to load app config")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
 		return
 	}
 
 	// Start a new session
-	session, err := h.refinementService.StartSession(&req, appConfig.ProductContext, appConfig.RolePrompts, appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	h.async.RespondWithinBudget(c, "start", func(ctx context.Context) (int, any) {
+		session, err := application.StartWithConfig(ctx, h.refinementService, &req, appConfig)
+		if err != nil {
+			return aiErrorStatus(err), gin.H{"error": "Failed to start refinement session: " + err.Error()}
+		}
+		return http.StatusOK, h.sessionResponse(session)
+	})
+}
+
+// RolePreviewHandler handles previewing the questions a role would ask about a draft story, using the
+// role's prompt of the given workspace and project.
+func (h *RefinementHandler) RolePreviewHandler(c *gin.Context) {
+	var req domain.RolePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = c.GetHeader("X-User-ID")
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	appConfig, err = h.refinementService.ConfigFor(req.WorkspaceID, req.ProjectID, appConfig)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := c.Param("role")
+	rolePrompt, ok := appConfig.RolePromptsWithExemplars()[role]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown role: " + role})
+		return
+	}
+	preview, err := h.refinementService.PreviewRole(c.Request.Context(), &req, role, rolePrompt, appConfig.ProductContext)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start refinement session: " + err.Error()})
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to preview role: " + err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, preview)
+}
 
-	c.JSON(http.StatusOK, session)
+// StartTutorialHandler starts a guided tutorial session. Tutorial sessions use a scripted provider and
+// carry an explanation of each step in their responses.
+func (h *RefinementHandler) StartTutorialHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.From(c.Request.Context()).Error().Err(err).Msg("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	session, err := application.StartTutorial(c.Request.Context(), h.refinementService, c.GetHeader("X-User-ID"), appConfig)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to start tutorial session: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
 }
 
 // SubmitAnswersAndContinueHandler handles the request to submit answers and continue questioning.
@@ -64,19 +159,79 @@ func (h *RefinementHandler) SubmitAnswersAndContinueHandler(c *gin.Context) {
 	// Load app config for question prompts
 	appConfig, err := h.appConfigService.LoadAppConfig()
 	if err != nil {
-		log.Println("[ERROR] Failed to load app config:", err)
+		logging.From(c.Request.Context()).Error().Err(err).Str("session_id", req.SessionID).Msg("Failed to load app config")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
 		return
 	}
 
 	// Submit answers and continue
-	session, err := h.refinementService.SubmitAnswersAndContinue(req.SessionID, req.Answers, req.AdditionalInfo, appConfig.RolePrompts, appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	appConfig = application.ConfigForSession(h.refinementService, req.SessionID, appConfig)
+	h.async.RespondWithinBudget(c, "submit_answers_and_continue", func(ctx context.Context) (int, any) {
+		session, err := h.refinementService.SubmitAnswersAndContinue(ctx, req.SessionID, req.Answers, req.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+		if err != nil {
+			return aiErrorStatus(err), gin.H{"error": "Failed to submit answers and continue: " + err.Error()}
+		}
+		return http.StatusOK, h.sessionResponse(session)
+	})
+}
+
+// BulkAnswersHandler maps a round's answers written as one Markdown or YAML document to the current
+// questions, and submits them when asked to. The document is sent either as a JSON BulkAnswersRequest
+// or as the raw request body, with the `format` and `submit` query parameters.
+func (h *RefinementHandler) BulkAnswersHandler(c *gin.Context) {
+	var req domain.BulkAnswersRequest
+	if c.ContentType() == "application/json" {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil || len(body) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The request body must contain the answers"})
+			return
+		}
+		req = domain.BulkAnswersRequest{Content: string(body), Format: c.Query("format"), Submit: c.Query("submit")}
+		if req.Format == "" && strings.Contains(c.ContentType(), "yaml") {
+			req.Format = "yaml"
+		} else if req.Format == "" && c.ContentType() == "text/markdown" {
+			req.Format = "markdown"
+		}
+	}
+
+	sessionID := c.Param("id")
+	parsed, err := h.refinementService.ParseBulkAnswers(c.Request.Context(), sessionID, req.Content, req.Format)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit answers and continue: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse answers: " + err.Error()})
+		return
+	}
+	if req.Submit == "" {
+		c.JSON(http.StatusOK, parsed)
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.From(c.Request.Context()).Error().Err(err).Str("session_id", sessionID).Msg("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	appConfig = application.ConfigForSession(h.refinementService, sessionID, appConfig)
+	var session *domain.RefinementSession
+	switch req.Submit {
+	case "continue":
+		session, err = h.refinementService.SubmitAnswersAndContinue(c.Request.Context(), sessionID, parsed.Answers, req.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	case "suggestions":
+		session, err = h.refinementService.SubmitAnswersAndGetSuggestions(c.Request.Context(), sessionID, parsed.Answers, req.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "submit must be \"continue\" or \"suggestions\""})
+		return
+	}
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to submit answers: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"answers": parsed, "session": h.sessionResponse(session)})
 }
 
 // SubmitAnswersAndGetSuggestionsHandler handles the request to submit answers and get suggestions.
@@ -91,19 +246,47 @@ func (h *RefinementHandler) SubmitAnswersAndGetSuggestionsHandler(c *gin.Context
 	// Load app config for suggestion prompts
 	appConfig, err := h.appConfigService.LoadAppConfig()
 	if err != nil {
-		log.Println("[ERROR] Failed to load app config:", err)
+		logging.From(c.Request.Context()).Error().Err(err).Str("session_id", req.SessionID).Msg("Failed to load app config")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
 		return
 	}
 
 	// Submit answers and get suggestions
-	session, err := h.refinementService.SubmitAnswersAndGetSuggestions(req.SessionID, req.Answers, req.AdditionalInfo, appConfig.RolePrompts, appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	appConfig = application.ConfigForSession(h.refinementService, req.SessionID, appConfig)
+	h.async.RespondWithinBudget(c, "submit_answers_and_get_suggestions", func(ctx context.Context) (int, any) {
+		session, err := h.refinementService.SubmitAnswersAndGetSuggestions(ctx, req.SessionID, req.Answers, req.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+		if err != nil {
+			return aiErrorStatus(err), gin.H{"error": "Failed to submit answers and get suggestions: " + err.Error()}
+		}
+		return http.StatusOK, h.sessionResponse(session)
+	})
+}
+
+// PrefetchSuggestionsHandler starts generating suggestions in the background once the PM has answered
+// the round, when the experimental prefetch is enabled and suggestions are predicted to be asked for next.
+func (h *RefinementHandler) PrefetchSuggestionsHandler(c *gin.Context) {
+	var req domain.SubmitAnswersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	appConfig, err := h.appConfigService.LoadAppConfig()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit answers and get suggestions: " + err.Error()})
+		logging.From(c.Request.Context()).Error().Err(err).Str("session_id", req.SessionID).Msg("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
 		return
 	}
-
-	c.JSON(http.StatusOK, session)
+	if !appConfig.Experimental.PrefetchSuggestions {
+		c.JSON(http.StatusOK, gin.H{"prefetching": false})
+		return
+	}
+	appConfig = application.ConfigForSession(h.refinementService, c.Param("id"), appConfig)
+	prefetching, err := h.refinementService.PrefetchSuggestions(c.Request.Context(), c.Param("id"), req.Answers, req.AdditionalInfo, appConfig.RolePromptsWithExemplars(), appConfig.PhasePrompts, appConfig.PhaseFormatExamples)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"prefetching": prefetching})
 }
 
 // AcceptSuggestionsHandler handles accepting suggestions and starting a new refinement round.
@@ -113,12 +296,13 @@ func (h *RefinementHandler) AcceptSuggestionsHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	session, prevResult, err := h.refinementService.AcceptSuggestions(req.SessionID, req.AcceptedSuggestions, req.NextPhase, req.AdditionalInfo)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept suggestions: " + err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"session": session, "previous_result": prevResult})
+	h.async.RespondWithinBudget(c, "accept_suggestions", func(ctx context.Context) (int, any) {
+		session, prevResult, err := h.refinementService.AcceptSuggestions(ctx, req.SessionID, req.AcceptedSuggestions, req.NextPhase, req.AdditionalInfo)
+		if err != nil {
+			return aiErrorStatus(err), gin.H{"error": "Failed to accept suggestions: " + err.Error()}
+		}
+		return http.StatusOK, gin.H{"session": h.sessionResponse(session), "previous_result": prevResult}
+	})
 }
 
 // FinalizeHandler handles generating the final user story and AC.
@@ -128,10 +312,414 @@ func (h *RefinementHandler) FinalizeHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	userStory, ac, rawAI, err := h.refinementService.Finalize(req.SessionID, req.CurrentPhase, req.CurrentAnswers, req.CurrentSuggestions, req.ModificationSuggestion)
+	if err := application.ValidateFormat(req.Format); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.async.RespondWithinBudget(c, "finalize", func(ctx context.Context) (int, any) {
+		userStory, ac, rawAI, err := h.refinementService.Finalize(ctx, req.SessionID, req.CurrentPhase, req.CurrentAnswers, req.CurrentSuggestions, req.ModificationSuggestion)
+		if err != nil {
+			return aiErrorStatus(err), gin.H{"error": "Failed to finalize: " + err.Error()}
+		}
+		var gherkin *domain.GherkinFeature
+		if req.Format == domain.FormatGherkin {
+			if gherkin, err = h.refinementService.WriteGherkin(ctx, req.SessionID); err != nil {
+				return aiErrorStatus(err), gin.H{"error": "Failed to write gherkin scenarios: " + err.Error()}
+			}
+		}
+
+		resp := domain.FinalizeResponse{UserStory: userStory, AC: ac, RawAI: rawAI, LintFindings: []domain.LintFinding{}}
+		if appConfig, err := h.appConfigService.LoadAppConfig(); err != nil {
+			logging.From(ctx).Warn().Err(err).Str("session_id", req.SessionID).Msg("Skipping style lint, failed to load app config")
+		} else {
+			resp.LintFindings = application.LintStory(userStory, ac, appConfig.StyleLint)
+		}
+		if session, err := h.refinementService.GetSession(req.SessionID); err == nil {
+			resp.Tutorial = domain.TutorialAnnotation(session)
+			resp.Endpoints = session.EndpointStubs
+			resp.Warnings = session.Warnings
+		}
+		if gherkin != nil {
+			resp.Gherkin = gherkin
+			resp.FeatureURL = "/api/refine/sessions/" + req.SessionID + "/gherkin.feature"
+		}
+		return http.StatusOK, resp
+	})
+}
+
+// CheckAnswerHandler handles the optional "check my answer" coaching call.
+func (h *RefinementHandler) CheckAnswerHandler(c *gin.Context) {
+	var req domain.CheckAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hint, err := h.refinementService.CheckAnswer(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to check answer: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hint)
+}
+
+// TranslateHandler handles translating the finalized story and AC into another language.
+func (h *RefinementHandler) TranslateHandler(c *gin.Context) {
+	var req domain.TranslateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	translated, err := h.refinementService.Translate(c.Request.Context(), c.Param("id"), req.TargetLanguage)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to translate: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, translated)
+}
+
+// PolishHandler handles a message of the polish chat of a finalized session, returning the assistant's
+// reply and the edited draft.
+func (h *RefinementHandler) PolishHandler(c *gin.Context) {
+	var req domain.PolishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := h.refinementService.Polish(c.Request.Context(), c.Param("id"), req.Message)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to polish: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ApplyPolishHandler makes the polish draft of a session its final output.
+func (h *RefinementHandler) ApplyPolishHandler(c *gin.Context) {
+	session, err := h.refinementService.ApplyPolish(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to apply polish draft: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
+}
+
+// ConsistencyHandler handles checking the story of a session for contradictions with the previously
+// finalized stories of the same product, linking each conflict to its session.
+func (h *RefinementHandler) ConsistencyHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	report, err := h.refinementService.CheckConsistency(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to check consistency: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report.WithLinks(appConfig.SessionURL))
+}
+
+// ProposeEndpointsHandler handles (re)generating the endpoint stubs of a finalized story.
+func (h *RefinementHandler) ProposeEndpointsHandler(c *gin.Context) {
+	stubs, err := h.refinementService.ProposeEndpoints(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to propose endpoints: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"endpoints": stubs})
+}
+
+// RunOptionalPhaseHandler handles running an optional phase, e.g. "security", on a finalized session.
+func (h *RefinementHandler) RunOptionalPhaseHandler(c *gin.Context) {
+	session, err := h.refinementService.RunOptionalPhase(c.Request.Context(), c.Param("id"), c.Param("phase"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to run optional phase: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
+}
+
+// AdvancePhaseHandler handles moving a session to a custom phase of its workflow, e.g. "ESTIMATION".
+func (h *RefinementHandler) AdvancePhaseHandler(c *gin.Context) {
+	var req domain.AdvancePhaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	session, err := h.refinementService.AdvancePhase(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to advance phase: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
+}
+
+// GherkinHandler handles (re)writing the finalized AC of a session as Gherkin scenarios.
+func (h *RefinementHandler) GherkinHandler(c *gin.Context) {
+	feature, err := h.refinementService.WriteGherkin(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to write gherkin scenarios: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, feature)
+}
+
+// TestCasesHandler handles (re)deriving test cases for QA from the finalized story and AC of a session.
+func (h *RefinementHandler) TestCasesHandler(c *gin.Context) {
+	suite, err := h.refinementService.WriteTestCases(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to write test cases: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, suite)
+}
+
+// FeatureFileHandler handles downloading the Gherkin scenarios of a session as a .feature file.
+func (h *RefinementHandler) FeatureFileHandler(c *gin.Context) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if session.Gherkin == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session has no gherkin scenarios; finalize with the gherkin format first"})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+session.ID+`.feature"`)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(session.Gherkin.File()))
+}
+
+// OpenAPIHandler handles exporting the endpoint stubs of a session as an OpenAPI fragment, in JSON
+// or, with ?format=yaml, in YAML.
+func (h *RefinementHandler) OpenAPIHandler(c *gin.Context) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	fragment := domain.OpenAPIFragment(session)
+	if c.Query("format") == "yaml" {
+		body, err := yaml.Marshal(fragment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render OpenAPI fragment: " + err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", body)
+		return
+	}
+	c.JSON(http.StatusOK, fragment)
+}
+
+// CheckTerminologyHandler handles flagging inconsistent terminology in the finalized output.
+func (h *RefinementHandler) CheckTerminologyHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	findings, err := h.refinementService.CheckTerminology(c.Param("id"), appConfig.Glossary)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check terminology: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"findings": findings})
+}
+
+// ApplyTermCorrectionsHandler handles auto-correcting terminology in the finalized output.
+func (h *RefinementHandler) ApplyTermCorrectionsHandler(c *gin.Context) {
+	var req domain.ApplyTermCorrectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	session, err := h.refinementService.ApplyTermCorrections(c.Param("id"), appConfig.Glossary, req.Corrections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply terminology corrections: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.FinalizeResponse{UserStory: session.FinalUserStory, AC: session.FinalAC, LintFindings: []domain.LintFinding{}})
+}
+
+// ListMemoryHandler returns the long-term memory of a product (the `workspace_id` query parameter).
+func (h *RefinementHandler) ListMemoryHandler(c *gin.Context) {
+	items, err := h.refinementService.ListMemory(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list memory: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// DeleteMemoryHandler removes an item from a product's long-term memory.
+func (h *RefinementHandler) DeleteMemoryHandler(c *gin.Context) {
+	if err := h.refinementService.DeleteMemory(c.Query("workspace_id"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Memory item deleted"})
+}
+
+// ListQuestionBankHandler returns the question bank of a product (the `workspace_id` query parameter),
+// most asked first. With `format=markdown` it returns the bank as an onboarding document.
+func (h *RefinementHandler) ListQuestionBankHandler(c *gin.Context) {
+	questions, err := h.refinementService.ListQuestionBank(c.Query("workspace_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to finalize: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list question bank: " + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, domain.FinalizeResponse{UserStory: userStory, AC: ac, RawAI: rawAI})
+	if c.Query("format") == "markdown" {
+		var b strings.Builder
+		b.WriteString("# Frequently asked questions\n")
+		for _, q := range questions {
+			if q.CanonicalAnswer == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", q.Question, q.CanonicalAnswer)
+		}
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(b.String()))
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// RerefineHandler starts a new session for an earlier session's story, focused on what changed since.
+func (h *RefinementHandler) RerefineHandler(c *gin.Context) {
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.From(c.Request.Context()).Error().Err(err).Str("session_id", c.Param("id")).Msg("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+	session, err := application.Rerefine(c.Request.Context(), h.refinementService, c.Param("id"), c.GetHeader("X-User-ID"), appConfig)
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to re-refine session: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
+}
+
+// ListSessionsHandler returns session summaries, newest first, optionally filtered by the
+// `workspace_id` and `user_id` query parameters and by `status` ("in_progress" or "completed").
+func (h *RefinementHandler) ListSessionsHandler(c *gin.Context) {
+	workspaceID, workspaceSet := c.GetQuery("workspace_id")
+	projectID, projectSet := c.GetQuery("project_id")
+	userID, userSet := c.GetQuery("user_id")
+	status := c.Query("status")
+	if status != "" && status != "in_progress" && status != "completed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be in_progress or completed"})
+		return
+	}
+	summaries := []domain.SessionSummary{}
+	for _, session := range h.refinementService.ListSessions() {
+		if (workspaceSet && session.WorkspaceID != workspaceID) || (projectSet && session.ProjectID != projectID) || (userSet && session.UserID != userID) {
+			continue
+		}
+		if finalized := session.FinalizedAt != nil; (status == "in_progress" && finalized) || (status == "completed" && !finalized) {
+			continue
+		}
+		summaries = append(summaries, application.Summarize(session))
+	}
+	httpcache.JSON(c, summaries)
+}
+
+// GetSessionHandler returns a session.
+func (h *RefinementHandler) GetSessionHandler(c *gin.Context) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	httpcache.JSON(c, h.sessionResponse(session))
+}
+
+// ResumeSessionHandler recovers a session a backend restart interrupted, from the session store and the
+// session's thread.
+func (h *RefinementHandler) ResumeSessionHandler(c *gin.Context) {
+	session, report, err := h.refinementService.Resume(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to resume session: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.ResumeResponse{SessionResponse: h.sessionResponse(session), Resume: report})
+}
+
+// ForkSessionHandler copies a session into a new session on a new thread, to explore another direction
+// of the refinement without losing the original.
+func (h *RefinementHandler) ForkSessionHandler(c *gin.Context) {
+	session, err := h.refinementService.Fork(c.Request.Context(), c.Param("id"), c.GetHeader("X-User-ID"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to fork session: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, h.sessionResponse(session))
+}
+
+// UndoRoundHandler reverts a session to the questions or suggestions it had before its latest round.
+func (h *RefinementHandler) UndoRoundHandler(c *gin.Context) {
+	session, err := h.refinementService.UndoRound(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(aiErrorStatus(err), gin.H{"error": "Failed to undo round: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.sessionResponse(session))
+}
+
+// SessionSummaryHandler returns the summary of a session.
+func (h *RefinementHandler) SessionSummaryHandler(c *gin.Context) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	httpcache.JSON(c, application.Summarize(session))
+}
+
+// SessionTimingHandler returns the human and AI time of a session per phase.
+func (h *RefinementHandler) SessionTimingHandler(c *gin.Context) {
+	timing, err := h.refinementService.SessionTiming(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, timing)
+}
+
+// SessionDiagnosticsHandler reports the health of a session: its thread size, recent runs, parse
+// failures and whether its lock looks stuck.
+func (h *RefinementHandler) SessionDiagnosticsHandler(c *gin.Context) {
+	diagnostics, err := h.refinementService.Diagnostics(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diagnostics)
+}
+
+// PromptDriftHandler reports how far the configured prompts of each session are from what its assistant
+// has in context, the most drifted sessions first.
+func (h *RefinementHandler) PromptDriftHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.refinementService.PromptDrift())
+}
+
+// TimingReportHandler aggregates session timings, optionally filtered by the `workspace_id` and
+// `user_id` query parameters.
+func (h *RefinementHandler) TimingReportHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.refinementService.TimingReport(c.Query("workspace_id"), c.Query("user_id")))
+}
+
+// sessionResponse wraps a session for an API response, with the round laid out using the configured
+// role display metadata and prior answers linked to their sessions.
+func (h *RefinementHandler) sessionResponse(session *domain.RefinementSession) domain.SessionResponse {
+	resp := domain.NewSessionResponse(session)
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", session.ID).Msg("Skipping role display metadata, failed to load app config")
+		return resp.WithRoleDisplay(nil)
+	}
+	return resp.WithRoleDisplay(appConfig.RoleDisplay).WithPriorAnswerLinks(appConfig.SessionURL)
 }
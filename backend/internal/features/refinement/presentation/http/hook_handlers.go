@@ -0,0 +1,102 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/features/refinement/application"
+	"sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HookHandler serves the generic inbound webhook used by automation tools such as Zapier, n8n or Make.
+type HookHandler struct {
+	refinementService application.RefinementService
+	appConfigService  config.AppConfigService
+	hookToken         string
+}
+
+// NewHookHandler creates a new HookHandler. An empty hook token disables the endpoint.
+func NewHookHandler(refinementService application.RefinementService, appConfigService config.AppConfigService, hookToken string) *HookHandler {
+	return &HookHandler{
+		refinementService: refinementService,
+		appConfigService:  appConfigService,
+		hookToken:         hookToken,
+	}
+}
+
+// RefineHookRequest is the payload accepted by the refine webhook.
+type RefineHookRequest struct {
+	Story        string   `json:"story" binding:"required"`
+	Roles        []string `json:"roles,omitempty"` // Defaults to all configured roles
+	WorkspaceID  string   `json:"workspace_id,omitempty"`
+	UserID       string   `json:"user_id,omitempty"`
+	TargetRounds int      `json:"target_rounds,omitempty"`
+}
+
+// RefineHookResponse is returned by the refine webhook.
+type RefineHookResponse struct {
+	SessionID  string            `json:"session_id"`
+	SessionURL string            `json:"session_url,omitempty"`
+	Questions  []domain.Question `json:"questions"`
+}
+
+// RefineHookHandler starts a refinement session from a story payload and returns a link to it.
+// The token is passed as a Bearer token or the X-Hook-Token header, never in the URL, which ends up in
+// access logs.
+func (h *HookHandler) RefineHookHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Valid hook token required"})
+		return
+	}
+
+	var req RefineHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appConfig, err := h.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.From(c.Request.Context()).Error().Err(err).Msg("Failed to load app config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app config: " + err.Error()})
+		return
+	}
+
+	roles := req.Roles
+	if len(roles) == 0 {
+		roles = appConfig.RoleNames()
+	}
+	session, err := application.StartWithConfig(c.Request.Context(), h.refinementService, &domain.RefinementRequest{
+		InitialUserStory: req.Story,
+		SelectedRoles:    roles,
+		WorkspaceID:      req.WorkspaceID,
+		UserID:           req.UserID,
+		TargetRounds:     req.TargetRounds,
+	}, appConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start refinement session: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RefineHookResponse{
+		SessionID:  session.ID,
+		SessionURL: appConfig.SessionURL(session.ID),
+		Questions:  session.Questions,
+	})
+}
+
+func (h *HookHandler) authorized(c *gin.Context) bool {
+	if h.hookToken == "" {
+		return false
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		token = c.GetHeader("X-Hook-Token")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.hookToken)) == 1
+}
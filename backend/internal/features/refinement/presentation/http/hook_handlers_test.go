@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRefineHookToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		hookToken string
+		query     string
+		headers   map[string]string
+		want      bool
+	}{
+		{name: "bearer token", hookToken: "hook", headers: map[string]string{"Authorization": "Bearer hook"}, want: true},
+		{name: "hook token header", hookToken: "hook", headers: map[string]string{"X-Hook-Token": "hook"}, want: true},
+		{name: "wrong token", hookToken: "hook", headers: map[string]string{"X-Hook-Token": "guess"}},
+		{name: "token in the URL", hookToken: "hook", query: "?token=hook"},
+		{name: "no token", hookToken: "hook"},
+		{name: "hook disabled", headers: map[string]string{"X-Hook-Token": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/hooks/refine"+tt.query, strings.NewReader(`{"story":"As a PM"}`))
+			for name, value := range tt.headers {
+				c.Request.Header.Set(name, value)
+			}
+			h := NewHookHandler(nil, nil, tt.hookToken)
+			if got := h.authorized(c); got != tt.want {
+				t.Errorf("authorized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefineHookRejectsUnauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/hooks/refine", NewHookHandler(nil, nil, "hook").RefineHookHandler)
+	req := httptest.NewRequest(http.MethodPost, "/api/hooks/refine?token=hook", strings.NewReader(`{"story":"As a PM"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
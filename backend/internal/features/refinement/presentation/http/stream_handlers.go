@@ -0,0 +1,75 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"sofa-commander/backend/internal/features/refinement/application"
+	"sofa-commander/backend/internal/features/refinement/infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamKeepAlive is how often a comment is sent on an idle stream so proxies keep it open.
+const streamKeepAlive = 15 * time.Second
+
+// StreamHandler serves the live progress of refinement sessions as Server-Sent Events.
+type StreamHandler struct {
+	refinementService application.RefinementService
+	transcriptHub     infrastructure.TranscriptHub
+}
+
+// NewStreamHandler creates a new StreamHandler.
+func NewStreamHandler(refinementService application.RefinementService, transcriptHub infrastructure.TranscriptHub) *StreamHandler {
+	return &StreamHandler{
+		refinementService: refinementService,
+		transcriptHub:     transcriptHub,
+	}
+}
+
+// SessionStreamHandler streams a session's run activity while questions and suggestions are being
+// generated: run_started, run_status, delta (incremental output), assistant_message, run_completed and
+// run_failed events. The stream starts with a "session" event carrying the session's current phase.
+// The messages sent to the assistant, which hold the full prompts and answers, are not streamed; those
+// are mirrored to admins only, see AdminHandler. A session started by a user streams to that user only.
+func (h *StreamHandler) SessionStreamHandler(c *gin.Context) {
+	session, err := h.refinementService.GetSession(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if session.UserID != "" && c.GetHeader("X-User-ID") != session.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the user who started the session can stream it"})
+		return
+	}
+
+	events, unsubscribe := h.transcriptHub.Subscribe(session.ThreadID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.SSEvent("session", gin.H{"session_id": session.ID, "phase": session.Phase})
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if event.Type != "user_message" {
+				c.SSEvent(event.Type, event)
+			}
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		case <-c.Request.Context().Done():
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	"sofa-commander/backend/internal/features/report/domain"
+	usageapp "sofa-commander/backend/internal/features/usage/application"
+	usagedomain "sofa-commander/backend/internal/features/usage/domain"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	"sofa-commander/backend/internal/xlsx"
+)
+
+// ErrInvalidReport is returned for report requests with an invalid month or format.
+var ErrInvalidReport = errors.New("invalid report request")
+
+// usageColumns are the columns of exported usage reports.
+var usageColumns = []string{"month", "workspace_id", "workspace_name", "user_id", "sessions", "rounds", "prompt_tokens", "completion_tokens", "total_tokens", "estimated_cost_usd", "unpriced_tokens", "finalized_stories"}
+
+// ReportService defines the interface for the administrative reports of an instance.
+type ReportService interface {
+	MonthlyUsage(month string) (*domain.UsageReport, error)
+	ExportUsage(w io.Writer, report *domain.UsageReport, format string) (string, error)
+}
+
+// reportService is the implementation of ReportService.
+type reportService struct {
+	refinementService refinementapp.RefinementService
+	usageService      usageapp.UsageService
+	workspaceService  workspaceapp.WorkspaceService
+	appConfigService  config.AppConfigService
+}
+
+// NewReportService creates a new instance of reportService.
+func NewReportService(refinementService refinementapp.RefinementService, usageService usageapp.UsageService, workspaceService workspaceapp.WorkspaceService, appConfigService config.AppConfigService) ReportService {
+	return &reportService{refinementService: refinementService, usageService: usageService, workspaceService: workspaceService, appConfigService: appConfigService}
+}
+
+// MonthlyUsage adds up the sessions, rounds, token usage, estimated cost and finalized stories of a
+// month, "2006-01" in UTC, per workspace and user. An empty month selects the current one.
+func (s *reportService) MonthlyUsage(month string) (*domain.UsageReport, error) {
+	since := time.Now().UTC()
+	since = time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if month != "" {
+		var err error
+		if since, err = time.Parse("2006-01", month); err != nil {
+			return nil, fmt.Errorf("%w: month %q, expected YYYY-MM", ErrInvalidReport, month)
+		}
+	}
+	until := since.AddDate(0, 1, 0)
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := s.workspaceService.ListWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(workspaces))
+	for _, w := range workspaces {
+		names[w.ID] = w.Name
+	}
+
+	rows := make(map[[2]string]*domain.UsageRow)
+	row := func(workspaceID, userID string) *domain.UsageRow {
+		key := [2]string{workspaceID, userID}
+		if rows[key] == nil {
+			rows[key] = &domain.UsageRow{WorkspaceID: workspaceID, WorkspaceName: names[workspaceID], UserID: userID}
+		}
+		return rows[key]
+	}
+	within := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	attributions, err := s.usageService.ListAttributions(usagedomain.AttributionFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	for _, a := range attributions {
+		if !within(a.CreatedAt) {
+			continue
+		}
+		r := row(a.WorkspaceID, a.UserID)
+		r.PromptTokens += a.PromptTokens
+		r.CompletionTokens += a.CompletionTokens
+		r.TotalTokens += a.TotalTokens
+		if price, ok := appConfig.ModelPrices[a.Model]; ok {
+			r.EstimatedCost += price.Cost(a.PromptTokens, a.CompletionTokens)
+		} else {
+			r.UnpricedTokens += a.TotalTokens
+		}
+	}
+	for _, session := range s.refinementService.ListSessions() {
+		if within(session.CreatedAt) {
+			r := row(session.WorkspaceID, session.UserID)
+			r.Sessions++
+			r.Rounds += session.CurrentRound
+		}
+		if session.FinalizedAt != nil && within(*session.FinalizedAt) {
+			row(session.WorkspaceID, session.UserID).FinalizedStories++
+		}
+	}
+
+	report := &domain.UsageReport{Month: since.Format("2006-01"), Since: since, Until: until, Rows: []domain.UsageRow{}}
+	for _, r := range rows {
+		r.EstimatedCost = math.Round(r.EstimatedCost*100) / 100
+		report.Rows = append(report.Rows, *r)
+		t := &report.Total
+		t.Sessions += r.Sessions
+		t.Rounds += r.Rounds
+		t.PromptTokens += r.PromptTokens
+		t.CompletionTokens += r.CompletionTokens
+		t.TotalTokens += r.TotalTokens
+		t.EstimatedCost += r.EstimatedCost
+		t.UnpricedTokens += r.UnpricedTokens
+		t.FinalizedStories += r.FinalizedStories
+	}
+	report.Total.EstimatedCost = math.Round(report.Total.EstimatedCost*100) / 100
+	slices.SortFunc(report.Rows, func(a, b domain.UsageRow) int {
+		return cmp.Or(cmp.Compare(a.WorkspaceName, b.WorkspaceName), cmp.Compare(a.WorkspaceID, b.WorkspaceID), cmp.Compare(a.UserID, b.UserID))
+	})
+	return report, nil
+}
+
+// ExportUsage writes a usage report as "csv", "xlsx" or "json", and returns its content type. The
+// exported rows end with the total.
+func (s *reportService) ExportUsage(w io.Writer, report *domain.UsageReport, format string) (string, error) {
+	var rows [][]any
+	for _, r := range append(report.Rows, report.Total) {
+		rows = append(rows, []any{report.Month, r.WorkspaceID, r.WorkspaceName, r.UserID, r.Sessions, r.Rounds, r.PromptTokens, r.CompletionTokens, r.TotalTokens, r.EstimatedCost, r.UnpricedTokens, r.FinalizedStories})
+	}
+	rows[len(rows)-1][1] = "TOTAL"
+
+	switch format {
+	case "", "csv":
+		// The BOM lets Excel read the workspace names as UTF-8
+		if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+			return "", err
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(usageColumns)
+		for _, row := range rows {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				switch v := cell.(type) {
+				case float64:
+					record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+				case string:
+					record[i] = csvText(v)
+				default:
+					record[i] = fmt.Sprint(cell)
+				}
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		return "text/csv; charset=utf-8", cw.Error()
+	case "xlsx":
+		return xlsx.ContentType, xlsx.Write(w, "Usage "+report.Month, usageColumns, rows)
+	case "json":
+		return "application/json; charset=utf-8", json.NewEncoder(w).Encode(report)
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q, expected \"csv\", \"xlsx\" or \"json\"", ErrInvalidReport, format)
+	}
+}
+
+// csvText escapes a text cell starting like a formula, e.g. a workspace named "=HYPERLINK(...)", so
+// spreadsheets opening the CSV show it as text instead of evaluating it.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// UsageReportFilter selects the month and format of a usage report.
+type UsageReportFilter struct {
+	Month  string `form:"month"`  // "2006-01", defaults to the current month (UTC)
+	Format string `form:"format"` // "csv" (default), "xlsx" or "json"
+}
+
+// UsageReport is the usage of a month per workspace and user, for finance and management reporting.
+type UsageReport struct {
+	Month string     `json:"month"`
+	Since time.Time  `json:"since"`
+	Until time.Time  `json:"until"`
+	Rows  []UsageRow `json:"rows"`
+	Total UsageRow   `json:"total"`
+}
+
+// UsageRow is the usage of a user within a workspace. Sessions and rounds count the sessions started in
+// the month; finalized stories those finalized in it; tokens the provider runs of the month.
+type UsageRow struct {
+	WorkspaceID      string  `json:"workspace_id"`
+	WorkspaceName    string  `json:"workspace_name"`
+	UserID           string  `json:"user_id"`
+	Sessions         int     `json:"sessions"`
+	Rounds           int     `json:"rounds"` // Questioning rounds
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`  // USD, from the model prices of the app config
+	UnpricedTokens   int     `json:"unpriced_tokens"` // Tokens of models without a price, left out of the cost
+	FinalizedStories int     `json:"finalized_stories"`
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"sofa-commander/backend/internal/features/report/application"
+	"sofa-commander/backend/internal/features/report/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler holds the report service and the admin token required to use it.
+type ReportHandler struct {
+	reportService application.ReportService
+	adminToken    string
+}
+
+// NewReportHandler creates a new ReportHandler. An empty admin token disables the endpoints.
+func NewReportHandler(reportService application.ReportService, adminToken string) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		adminToken:    adminToken,
+	}
+}
+
+func (h *ReportHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// UsageReportHandler downloads the usage report of a month per workspace and user, as CSV, XLSX or JSON.
+func (h *ReportHandler) UsageReportHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var filter domain.UsageReportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	report, err := h.reportService.MonthlyUsage(filter.Month)
+	var buf bytes.Buffer
+	contentType := ""
+	if err == nil {
+		contentType, err = h.reportService.ExportUsage(&buf, report, filter.Format)
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, application.ErrInvalidReport) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": "Failed to export usage report: " + err.Error()})
+		return
+	}
+	if filter.Format != "json" {
+		extension := filter.Format
+		if extension == "" {
+			extension = "csv"
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s.%s"`, report.Month, extension))
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
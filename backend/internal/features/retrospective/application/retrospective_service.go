@@ -0,0 +1,234 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	backlogapp "sofa-commander/backend/internal/features/backlog/application"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/retrospective/domain"
+	"sofa-commander/backend/internal/features/retrospective/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+const (
+	defaultSprintDays = 14
+	// scheduleInterval is how often the sprint schedule checks whether a report is due.
+	scheduleInterval = time.Hour
+)
+
+// RetrospectiveService defines the interface for generating retrospective insights reports from
+// refinement sessions.
+type RetrospectiveService interface {
+	Generate(ctx context.Context, req *domain.GenerateRequest) (*domain.Report, error)
+	List() ([]domain.Report, error)
+	Latest() (*domain.Report, error)
+	Start()
+}
+
+// retrospectiveService is the implementation of RetrospectiveService.
+type retrospectiveService struct {
+	refinementService refinementapp.RefinementService
+	backlogService    backlogapp.BacklogService
+	appConfigService  config.AppConfigService
+	store             infrastructure.ReportStore
+	startedAt         time.Time
+}
+
+// NewRetrospectiveService creates a new instance of retrospectiveService.
+func NewRetrospectiveService(refinementService refinementapp.RefinementService, backlogService backlogapp.BacklogService, appConfigService config.AppConfigService, store infrastructure.ReportStore) RetrospectiveService {
+	return &retrospectiveService{refinementService: refinementService, backlogService: backlogService, appConfigService: appConfigService, store: store}
+}
+
+// Generate analyzes the sessions of a sprint backlog, or of the last sprint, and records the report.
+func (s *retrospectiveService) Generate(ctx context.Context, req *domain.GenerateRequest) (*domain.Report, error) {
+	return s.generate(ctx, req, false)
+}
+
+// List returns all recorded reports, newest first.
+func (s *retrospectiveService) List() ([]domain.Report, error) {
+	reports, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].GeneratedAt.After(reports[j].GeneratedAt) })
+	return reports, nil
+}
+
+// Latest returns the most recent report.
+func (s *retrospectiveService) Latest() (*domain.Report, error) {
+	reports, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("no retrospective report has been generated yet")
+	}
+	return &reports[0], nil
+}
+
+// Start generates a report on the last sprint in the background whenever a sprint has passed since
+// the latest scheduled report, or since the start for the first one. The schedule is disabled while
+// no sprint length is configured.
+func (s *retrospectiveService) Start() {
+	s.startedAt = time.Now()
+	go func() {
+		for {
+			s.runScheduled()
+			time.Sleep(scheduleInterval)
+		}
+	}()
+}
+
+// runScheduled generates the scheduled report if one is due.
+func (s *retrospectiveService) runScheduled() {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Skipping retrospective schedule, failed to load app config")
+		return
+	}
+	days := appConfig.Retrospective.SprintDays
+	if days <= 0 {
+		return
+	}
+	reports, err := s.store.List()
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Skipping retrospective schedule")
+		return
+	}
+	due := time.Now().AddDate(0, 0, -days)
+	if s.startedAt.After(due) {
+		return
+	}
+	for _, report := range reports {
+		if report.Scheduled && report.GeneratedAt.After(due) {
+			return
+		}
+	}
+	if _, err := s.generate(context.Background(), &domain.GenerateRequest{Days: days}, true); err != nil {
+		logging.Default().Warn().Err(err).Msg("Failed to generate scheduled retrospective report")
+	}
+}
+
+func (s *retrospectiveService) generate(ctx context.Context, req *domain.GenerateRequest, scheduled bool) (*domain.Report, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	workspaceID := req.WorkspaceID
+	if workspaceID == "" {
+		workspaceID = appConfig.Retrospective.WorkspaceID
+	}
+
+	now := time.Now()
+	report := &domain.Report{
+		ID:          fmt.Sprintf("retro-%d", now.UnixNano()),
+		GeneratedAt: now,
+		Until:       now,
+		BacklogID:   req.BacklogID,
+		WorkspaceID: workspaceID,
+		Scheduled:   scheduled,
+	}
+	sessions, err := s.selectSessions(req, appConfig.Retrospective.SprintDays, workspaceID, report)
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []string
+	tallies := make(map[string]refinementdomain.QuestionTally)
+	for _, session := range sessions {
+		questions = append(questions, session.AskedQuestions...)
+		for role, tally := range session.RoleQuestionStats {
+			total := tallies[role]
+			total.Asked += tally.Asked
+			total.Unanswered += tally.Unanswered
+			tallies[role] = total
+		}
+	}
+	report.SessionCount = len(sessions)
+	report.QuestionCount = len(questions)
+	report.UnansweredRoles = unansweredRoles(tallies)
+
+	insights, err := s.refinementService.ClassifyQuestions(ctx, workspaceID, questions)
+	if err != nil {
+		return nil, err
+	}
+	report.Themes, report.MissingInfo = insights.Themes, insights.MissingInfo
+
+	if err := s.store.Append(*report); err != nil {
+		return nil, fmt.Errorf("failed to record retrospective report: %w", err)
+	}
+	return report, nil
+}
+
+// selectSessions returns the sessions of the requested backlog, or the sessions started in the
+// requested window, and sets the report's time range accordingly.
+func (s *retrospectiveService) selectSessions(req *domain.GenerateRequest, sprintDays int, workspaceID string, report *domain.Report) ([]*refinementdomain.RefinementSession, error) {
+	var sessions []*refinementdomain.RefinementSession
+	if req.BacklogID != "" {
+		backlog, err := s.backlogService.GetBacklog(req.BacklogID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sessionID := range backlog.SessionIDs {
+			session, err := s.refinementService.GetSession(sessionID)
+			if err != nil {
+				continue // Sessions do not outlive a restart
+			}
+			sessions = append(sessions, session)
+			if report.Since.IsZero() || session.CreatedAt.Before(report.Since) {
+				report.Since = session.CreatedAt
+			}
+		}
+		return sessions, nil
+	}
+
+	days := req.Days
+	if days <= 0 {
+		days = sprintDays
+	}
+	if days <= 0 {
+		days = defaultSprintDays
+	}
+	report.Since = report.Until.AddDate(0, 0, -days)
+	for _, session := range s.refinementService.ListSessions() {
+		if session.IsTutorial() || (workspaceID != "" && session.WorkspaceID != workspaceID) {
+			continue
+		}
+		if session.CreatedAt.Before(report.Since) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// unansweredRoles ranks roles by the share of their questions left unanswered, highest first.
+func unansweredRoles(tallies map[string]refinementdomain.QuestionTally) []domain.RoleUnanswered {
+	result := []domain.RoleUnanswered{}
+	for role, tally := range tallies {
+		if tally.Asked == 0 {
+			continue
+		}
+		result = append(result, domain.RoleUnanswered{
+			Role:       role,
+			Asked:      tally.Asked,
+			Unanswered: tally.Unanswered,
+			Rate:       float64(tally.Unanswered) / float64(tally.Asked),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rate != result[j].Rate {
+			return result[i].Rate > result[j].Rate
+		}
+		if result[i].Unanswered != result[j].Unanswered {
+			return result[i].Unanswered > result[j].Unanswered
+		}
+		return result[i].Role < result[j].Role
+	})
+	return result
+}
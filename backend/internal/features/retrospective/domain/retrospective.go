@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// Report is a retrospective insights report on the refinement sessions of one sprint.
+type Report struct {
+	ID              string                          `json:"id"`
+	GeneratedAt     time.Time                       `json:"generated_at"`
+	Since           time.Time                       `json:"since"`
+	Until           time.Time                       `json:"until"`
+	BacklogID       string                          `json:"backlog_id,omitempty"` // Set when the report covers a sprint backlog instead of a time window
+	WorkspaceID     string                          `json:"workspace_id,omitempty"`
+	Scheduled       bool                            `json:"scheduled"` // Generated by the sprint schedule rather than on request
+	SessionCount    int                             `json:"session_count"`
+	QuestionCount   int                             `json:"question_count"`
+	Themes          []refinementdomain.InsightCount `json:"themes"`       // Most common question themes
+	MissingInfo     []refinementdomain.InsightCount `json:"missing_info"` // Information most often missing from the initial stories
+	UnansweredRoles []RoleUnanswered                `json:"unanswered_roles"`
+}
+
+// RoleUnanswered reports how often the PM left a role's questions unanswered.
+type RoleUnanswered struct {
+	Role       string  `json:"role"`
+	Asked      int     `json:"asked"`
+	Unanswered int     `json:"unanswered"`
+	Rate       float64 `json:"rate"` // Unanswered / Asked
+}
+
+// GenerateRequest selects the sessions a report is generated for. Without a backlog, the sessions
+// started in the last Days days are analyzed.
+type GenerateRequest struct {
+	BacklogID   string `json:"backlog_id,omitempty"`
+	Days        int    `json:"days,omitempty"` // Defaults to the configured sprint length, or 14
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"sofa-commander/backend/internal/features/retrospective/domain"
+	"sofa-commander/backend/internal/jsonl"
+)
+
+// ReportStore defines the interface for persisting retrospective reports.
+type ReportStore interface {
+	Append(report domain.Report) error
+	List() ([]domain.Report, error)
+}
+
+// jsonlReportStore appends reports to a JSON Lines file.
+type jsonlReportStore struct {
+	reports *jsonl.Store[domain.Report]
+}
+
+// NewJSONLReportStore creates a store backed by the given JSON Lines file.
+func NewJSONLReportStore(path string) ReportStore {
+	return &jsonlReportStore{reports: jsonl.NewStore[domain.Report](path, "report")}
+}
+
+// Append writes a single report.
+func (s *jsonlReportStore) Append(report domain.Report) error {
+	return s.reports.Append(report)
+}
+
+// List returns all reports, oldest first.
+func (s *jsonlReportStore) List() ([]domain.Report, error) {
+	return s.reports.List(nil)
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/retrospective/application"
+	"sofa-commander/backend/internal/features/retrospective/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetrospectiveHandler holds the retrospective service.
+type RetrospectiveHandler struct {
+	retrospectiveService application.RetrospectiveService
+}
+
+// NewRetrospectiveHandler creates a new RetrospectiveHandler.
+func NewRetrospectiveHandler(retrospectiveService application.RetrospectiveService) *RetrospectiveHandler {
+	return &RetrospectiveHandler{
+		retrospectiveService: retrospectiveService,
+	}
+}
+
+// GenerateHandler generates a retrospective report now. The body is optional; without it the last
+// sprint is analyzed.
+func (h *RetrospectiveHandler) GenerateHandler(c *gin.Context) {
+	var req domain.GenerateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	report, err := h.retrospectiveService.Generate(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate retrospective report: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// ListHandler returns all retrospective reports, newest first.
+func (h *RetrospectiveHandler) ListHandler(c *gin.Context) {
+	reports, err := h.retrospectiveService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list retrospective reports: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// LatestHandler returns the most recent retrospective report.
+func (h *RetrospectiveHandler) LatestHandler(c *gin.Context) {
+	report, err := h.retrospectiveService.Latest()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/scoring/domain"
+	"sofa-commander/backend/internal/features/scoring/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// ScoringService defines the interface for scoring finalized stories against the rubric and
+// reporting the quality trend.
+type ScoringService interface {
+	HandleEvent(event events.Event)
+	Score(ctx context.Context, sessionID string) (*refinementdomain.StoryScore, error)
+	Trend(filter domain.TrendFilter) ([]domain.TrendPoint, error)
+}
+
+// scoringService is the implementation of ScoringService.
+type scoringService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+	store             infrastructure.ScoreStore
+}
+
+// NewScoringService creates a new instance of scoringService.
+func NewScoringService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService, store infrastructure.ScoreStore) ScoringService {
+	return &scoringService{refinementService: refinementService, appConfigService: appConfigService, store: store}
+}
+
+// HandleEvent scores every finalized story when a rubric is configured.
+func (s *scoringService) HandleEvent(event events.Event) {
+	if event.Type != events.SessionFinalized {
+		return
+	}
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", event.SessionID).Msg("Skipping story scoring, failed to load app config")
+		return
+	}
+	if len(appConfig.ScoringRubric.Criteria) == 0 {
+		return
+	}
+	if _, err := s.Score(context.Background(), event.SessionID); err != nil {
+		logging.Default().Warn().Err(err).Str("session_id", event.SessionID).Msg("Failed to score story")
+	}
+}
+
+// Score scores the finalized story of a session against the rubric and records the score.
+func (s *scoringService) Score(ctx context.Context, sessionID string) (*refinementdomain.StoryScore, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	score, err := s.refinementService.ScoreStory(ctx, sessionID, appConfig.ScoringRubric)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.refinementService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	record := domain.ScoreRecord{SessionID: session.ID, WorkspaceID: session.WorkspaceID, UserID: session.UserID, StoryScore: *score}
+	if err := s.store.Append(record); err != nil {
+		return nil, fmt.Errorf("failed to record score: %w", err)
+	}
+	return score, nil
+}
+
+// Trend aggregates the recorded scores per period, oldest period first. When a session was scored
+// more than once in a period, only its latest score counts.
+func (s *scoringService) Trend(filter domain.TrendFilter) ([]domain.TrendPoint, error) {
+	records, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	type bucket struct {
+		point    domain.TrendPoint
+		sessions map[string]domain.ScoreRecord
+	}
+	buckets := map[string]*bucket{}
+	for _, record := range records {
+		if filter.WorkspaceID != "" && record.WorkspaceID != filter.WorkspaceID {
+			continue
+		}
+		label, start := periodOf(record.ScoredAt, filter.Period)
+		b, ok := buckets[label]
+		if !ok {
+			b = &bucket{point: domain.TrendPoint{Period: label, Start: start}, sessions: map[string]domain.ScoreRecord{}}
+			buckets[label] = b
+		}
+		b.sessions[record.SessionID] = record // Records are oldest first, so the latest score wins
+	}
+
+	result := make([]domain.TrendPoint, 0, len(buckets))
+	for _, b := range buckets {
+		point := b.point
+		point.Criteria = map[string]float64{}
+		criterionCounts := map[string]int{}
+		var sum float64
+		for _, record := range b.sessions {
+			if point.Count == 0 || record.Total < point.Min {
+				point.Min = record.Total
+			}
+			if point.Count == 0 || record.Total > point.Max {
+				point.Max = record.Total
+			}
+			point.Count++
+			sum += record.Total
+			for _, c := range record.Criteria {
+				point.Criteria[c.Name] += float64(c.Score)
+				criterionCounts[c.Name]++
+			}
+		}
+		point.Average = round1(sum / float64(point.Count))
+		for name, total := range point.Criteria {
+			point.Criteria[name] = round1(total / float64(criterionCounts[name]))
+		}
+		result = append(result, point)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result, nil
+}
+
+// periodOf returns the label and start of the period containing t.
+func periodOf(t time.Time, period string) (string, time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "day":
+		return day.Format("2006-01-02"), day
+	case "month":
+		return day.Format("2006-01"), time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		year, week := t.ISOWeek()
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return fmt.Sprintf("%d-W%02d", year, week), monday
+	}
+}
+
+// round1 rounds to one decimal.
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
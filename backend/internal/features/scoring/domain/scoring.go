@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// ScoreRecord is a stored score of a finalized story, kept for the quality trend report.
+type ScoreRecord struct {
+	SessionID   string `json:"session_id"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	refinementdomain.StoryScore
+}
+
+// TrendFilter narrows down the quality trend report. Empty fields match everything.
+type TrendFilter struct {
+	WorkspaceID string `form:"workspace_id"`
+	Period      string `form:"period"` // "day", "week" (default) or "month"
+}
+
+// TrendPoint aggregates the scores of one period.
+type TrendPoint struct {
+	Period   string             `json:"period"` // e.g. "2024-W07", "2024-02" or "2024-02-14"
+	Start    time.Time          `json:"start"`
+	Count    int                `json:"count"`
+	Average  float64            `json:"average"`
+	Min      float64            `json:"min"`
+	Max      float64            `json:"max"`
+	Criteria map[string]float64 `json:"criteria"` // Average score per criterion
+}
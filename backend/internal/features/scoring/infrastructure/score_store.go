@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"sofa-commander/backend/internal/features/scoring/domain"
+	"sofa-commander/backend/internal/jsonl"
+)
+
+// ScoreStore defines the interface for persisting story scores.
+type ScoreStore interface {
+	Append(record domain.ScoreRecord) error
+	List() ([]domain.ScoreRecord, error)
+}
+
+// jsonlScoreStore appends scores to a JSON Lines file.
+type jsonlScoreStore struct {
+	records *jsonl.Store[domain.ScoreRecord]
+}
+
+// NewJSONLScoreStore creates a store backed by the given JSON Lines file.
+func NewJSONLScoreStore(path string) ScoreStore {
+	return &jsonlScoreStore{records: jsonl.NewStore[domain.ScoreRecord](path, "score")}
+}
+
+// Append writes a single score record.
+func (s *jsonlScoreStore) Append(record domain.ScoreRecord) error {
+	return s.records.Append(record)
+}
+
+// List returns all score records, oldest first.
+func (s *jsonlScoreStore) List() ([]domain.ScoreRecord, error) {
+	return s.records.List(nil)
+}
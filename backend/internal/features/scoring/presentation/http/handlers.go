@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/scoring/application"
+	"sofa-commander/backend/internal/features/scoring/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoringHandler holds the scoring service.
+type ScoringHandler struct {
+	scoringService application.ScoringService
+}
+
+// NewScoringHandler creates a new ScoringHandler.
+func NewScoringHandler(scoringService application.ScoringService) *ScoringHandler {
+	return &ScoringHandler{
+		scoringService: scoringService,
+	}
+}
+
+// ScoreHandler (re)scores the finalized story of a session against the current rubric.
+func (h *ScoringHandler) ScoreHandler(c *gin.Context) {
+	score, err := h.scoringService.Score(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to score story: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, score)
+}
+
+// TrendHandler returns the quality trend report, filtered by query parameters.
+func (h *ScoringHandler) TrendHandler(c *gin.Context) {
+	var filter domain.TrendFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	trend, err := h.scoringService.Trend(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build quality trend: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, trend)
+}
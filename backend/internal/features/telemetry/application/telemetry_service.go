@@ -0,0 +1,173 @@
+package application
+
+import (
+	"maps"
+	"runtime"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	"sofa-commander/backend/internal/features/telemetry/domain"
+	"sofa-commander/backend/internal/features/telemetry/infrastructure"
+	"sofa-commander/backend/internal/logging"
+)
+
+// scheduleInterval is how often the schedule checks whether a report is due.
+const scheduleInterval = time.Hour
+
+// TelemetryService defines the interface for the opt-in usage telemetry. Usage is counted in memory
+// whether or not telemetry is enabled, so the next report can be inspected before opting in; it is
+// only sent once enabled.
+type TelemetryService interface {
+	RecordRequest(route string, status int)
+	HandleEvent(event events.Event)
+	Preview() (*domain.Preview, error)
+	Start()
+}
+
+// telemetryService is the implementation of TelemetryService.
+type telemetryService struct {
+	appConfigService config.AppConfigService
+	instanceIDPath   string
+
+	mu       sync.Mutex
+	since    time.Time
+	lastSent *time.Time
+	features map[string]domain.FeatureUsage
+	events   map[string]int64
+}
+
+// NewTelemetryService creates a new instance of telemetryService keeping the instance ID at
+// instanceIDPath.
+func NewTelemetryService(appConfigService config.AppConfigService, instanceIDPath string) TelemetryService {
+	return &telemetryService{
+		appConfigService: appConfigService,
+		instanceIDPath:   instanceIDPath,
+		since:            time.Now(),
+		features:         make(map[string]domain.FeatureUsage),
+		events:           make(map[string]int64),
+	}
+}
+
+// RecordRequest counts a request to an API route, e.g. "POST /api/refine/start", as an error when it
+// failed with a server error.
+func (s *telemetryService) RecordRequest(route string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := s.features[route]
+	usage.Requests++
+	if status >= 500 {
+		usage.Errors++
+	}
+	s.features[route] = usage
+}
+
+// HandleEvent counts a domain event by type; nothing else of the event is kept.
+func (s *telemetryService) HandleEvent(event events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[event.Type]++
+}
+
+// Preview returns the telemetry setting and the report that would be sent next.
+func (s *telemetryService) Preview() (*domain.Preview, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.report()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	lastSent := s.lastSent
+	s.mu.Unlock()
+	return &domain.Preview{
+		Enabled:    appConfig.Telemetry.Enabled,
+		Endpoint:   appConfig.Telemetry.Endpoint,
+		LastSentAt: lastSent,
+		NextReport: *report,
+	}, nil
+}
+
+// Start sends a report in the background every interval while telemetry is enabled with an endpoint.
+func (s *telemetryService) Start() {
+	go func() {
+		for {
+			time.Sleep(scheduleInterval)
+			s.runScheduled()
+		}
+	}()
+}
+
+// runScheduled sends the report if one is due, and starts counting anew once it was sent.
+func (s *telemetryService) runScheduled() {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Skipping telemetry, failed to load app config")
+		return
+	}
+	telemetry := appConfig.Telemetry
+	if !telemetry.Enabled || telemetry.Endpoint == "" {
+		return
+	}
+	hours := telemetry.IntervalHours
+	if hours <= 0 {
+		hours = domain.DefaultIntervalHours
+	}
+	s.mu.Lock()
+	due := time.Since(s.since) >= time.Duration(hours)*time.Hour
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	report, err := s.report()
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Skipping telemetry")
+		return
+	}
+	if err := infrastructure.PostReport(telemetry.Endpoint, report); err != nil {
+		logging.Default().Warn().Err(err).Msg("Failed to send telemetry")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Keep what was counted while the report was sent for the next one
+	for route, sent := range report.Features {
+		usage := s.features[route]
+		usage.Requests -= sent.Requests
+		usage.Errors -= sent.Errors
+		if usage.Requests == 0 {
+			delete(s.features, route)
+		} else {
+			s.features[route] = usage
+		}
+	}
+	for eventType, sent := range report.Events {
+		if s.events[eventType] -= sent; s.events[eventType] == 0 {
+			delete(s.events, eventType)
+		}
+	}
+	s.since = report.Until
+	s.lastSent = &report.Until
+}
+
+// report builds the report of the usage counted so far.
+func (s *telemetryService) report() (*domain.Report, error) {
+	instanceID, err := infrastructure.LoadInstanceID(s.instanceIDPath)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &domain.Report{
+		InstanceID: instanceID,
+		Runtime:    runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH,
+		Since:      s.since,
+		Until:      time.Now(),
+		Features:   maps.Clone(s.features),
+		Events:     maps.Clone(s.events),
+	}, nil
+}
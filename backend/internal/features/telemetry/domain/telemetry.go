@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// DefaultIntervalHours is how often reports are sent unless configured.
+const DefaultIntervalHours = 24
+
+// Report is the anonymized, aggregate usage of an instance over a period, exactly as it is sent.
+type Report struct {
+	InstanceID string                  `json:"instance_id"` // Random, generated once per instance
+	Runtime    string                  `json:"runtime"`     // e.g. "go1.24.1 linux/amd64"
+	Since      time.Time               `json:"since"`
+	Until      time.Time               `json:"until"`
+	Features   map[string]FeatureUsage `json:"features"` // Keyed by API route, e.g. "POST /api/refine/start"
+	Events     map[string]int64        `json:"events"`   // Domain events by type, e.g. "session.finalized"
+}
+
+// FeatureUsage counts the requests to an API route, and those that failed with a server error.
+type FeatureUsage struct {
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+}
+
+// Preview is the telemetry setting of an instance and the report it would send next.
+type Preview struct {
+	Enabled    bool       `json:"enabled"`
+	Endpoint   string     `json:"endpoint,omitempty"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	NextReport Report     `json:"next_report"`
+}
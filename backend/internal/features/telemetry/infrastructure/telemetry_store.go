@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sofa-commander/backend/internal/features/telemetry/domain"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// LoadInstanceID returns the random ID of the instance stored at path, generating it on first use.
+func LoadInstanceID(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read instance ID: %w", err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write instance ID: %w", err)
+	}
+	return id, nil
+}
+
+// PostReport posts a report as JSON to the telemetry endpoint.
+func PostReport(endpoint string, report *domain.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry report: %w", err)
+	}
+	resp, err := httpClient.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to post telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
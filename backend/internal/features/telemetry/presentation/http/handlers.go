@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/telemetry/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TelemetryHandler holds the telemetry service.
+type TelemetryHandler struct {
+	telemetryService application.TelemetryService
+}
+
+// NewTelemetryHandler creates a new TelemetryHandler.
+func NewTelemetryHandler(telemetryService application.TelemetryService) *TelemetryHandler {
+	return &TelemetryHandler{
+		telemetryService: telemetryService,
+	}
+}
+
+// PreviewHandler shows whether telemetry is enabled and exactly what the next report would send.
+func (h *TelemetryHandler) PreviewHandler(c *gin.Context) {
+	preview, err := h.telemetryService.Preview()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview telemetry: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// Middleware counts every request by its route template, never its path or parameters. Requests that
+// match no route are not counted.
+func Middleware(telemetryService application.TelemetryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if route := c.FullPath(); route != "" {
+			telemetryService.RecordRequest(c.Request.Method+" "+route, c.Writer.Status())
+		}
+	}
+}
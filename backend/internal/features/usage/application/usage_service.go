@@ -0,0 +1,37 @@
+package application
+
+import (
+	"time"
+
+	"sofa-commander/backend/internal/features/usage/domain"
+	"sofa-commander/backend/internal/features/usage/infrastructure"
+)
+
+// UsageService defines the interface for recording and querying usage attribution.
+type UsageService interface {
+	RecordRun(attribution domain.Attribution) error
+	ListAttributions(filter domain.AttributionFilter) ([]domain.Attribution, error)
+}
+
+// usageService is the implementation of UsageService.
+type usageService struct {
+	store infrastructure.AttributionStore
+}
+
+// NewUsageService creates a new instance of usageService.
+func NewUsageService(store infrastructure.AttributionStore) UsageService {
+	return &usageService{store: store}
+}
+
+// RecordRun stores the attribution of a provider run.
+func (s *usageService) RecordRun(attribution domain.Attribution) error {
+	if attribution.CreatedAt.IsZero() {
+		attribution.CreatedAt = time.Now()
+	}
+	return s.store.Append(attribution)
+}
+
+// ListAttributions returns the recorded attributions matching the filter.
+func (s *usageService) ListAttributions(filter domain.AttributionFilter) ([]domain.Attribution, error) {
+	return s.store.List(filter)
+}
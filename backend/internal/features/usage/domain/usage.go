@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Attribution maps a provider-side run to the workspace, user and session that caused it,
+// so provider usage dashboards can be reconciled against internal attribution.
+type Attribution struct {
+	RunID            string    `json:"run_id"`
+	ThreadID         string    `json:"thread_id"`
+	SessionID        string    `json:"session_id"`
+	WorkspaceID      string    `json:"workspace_id,omitempty"`
+	UserID           string    `json:"user_id,omitempty"`
+	Operation        string    `json:"operation"`
+	Model            string    `json:"model,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AttributionFilter narrows down attribution queries. Empty fields match everything.
+type AttributionFilter struct {
+	SessionID   string `form:"session_id"`
+	WorkspaceID string `form:"workspace_id"`
+	UserID      string `form:"user_id"`
+}
+
+// Matches reports whether the attribution satisfies the filter.
+func (f AttributionFilter) Matches(a Attribution) bool {
+	return (f.SessionID == "" || f.SessionID == a.SessionID) &&
+		(f.WorkspaceID == "" || f.WorkspaceID == a.WorkspaceID) &&
+		(f.UserID == "" || f.UserID == a.UserID)
+}
@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"sofa-commander/backend/internal/features/usage/domain"
+	"sofa-commander/backend/internal/jsonl"
+)
+
+// AttributionStore defines the interface for persisting usage attributions.
+type AttributionStore interface {
+	Append(attribution domain.Attribution) error
+	List(filter domain.AttributionFilter) ([]domain.Attribution, error)
+}
+
+// jsonlAttributionStore appends attributions to a JSON Lines file.
+type jsonlAttributionStore struct {
+	attributions *jsonl.Store[domain.Attribution]
+}
+
+// NewJSONLAttributionStore creates a store backed by the given JSON Lines file.
+func NewJSONLAttributionStore(path string) AttributionStore {
+	return &jsonlAttributionStore{attributions: jsonl.NewStore[domain.Attribution](path, "attribution")}
+}
+
+// Append writes a single attribution record.
+func (s *jsonlAttributionStore) Append(attribution domain.Attribution) error {
+	return s.attributions.Append(attribution)
+}
+
+// List returns all attributions matching the filter, oldest first.
+func (s *jsonlAttributionStore) List(filter domain.AttributionFilter) ([]domain.Attribution, error) {
+	return s.attributions.List(filter.Matches)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/usage/application"
+	"sofa-commander/backend/internal/features/usage/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler holds the usage service.
+type UsageHandler struct {
+	usageService application.UsageService
+}
+
+// NewUsageHandler creates a new UsageHandler.
+func NewUsageHandler(usageService application.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// ListAttributionsHandler handles listing usage attributions, filtered by query parameters.
+func (h *UsageHandler) ListAttributionsHandler(c *gin.Context) {
+	var filter domain.AttributionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	attributions, err := h.usageService.ListAttributions(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list usage attributions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attributions)
+}
@@ -0,0 +1,290 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/features/user/domain"
+	"sofa-commander/backend/internal/features/user/infrastructure"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+)
+
+// UserService defines the interface for the user accounts provisioned by the identity provider and their
+// workspace memberships.
+type UserService interface {
+	ListUsers() ([]domain.User, error)
+	GetUser(id string) (*domain.User, error)
+	CreateUser(user *domain.User) (*domain.User, error)
+	UpdateUser(id string, update func(user *domain.User) error) (*domain.User, error)
+	DeleteUser(id string) error
+	Members(workspaceID string) ([]domain.User, error)
+	SetMembers(workspaceID string, userIDs []string, member bool) error
+	ReplaceMembers(workspaceID string, userIDs []string) error
+	CanUseWorkspace(userName, workspaceID string) bool
+	Deactivated(userName string) bool
+}
+
+// userService is the implementation of UserService. mu serializes the changes, which read and write
+// the users as a whole.
+type userService struct {
+	repo             infrastructure.UserRepository
+	workspaceService workspaceapp.WorkspaceService
+	mu               sync.Mutex
+}
+
+// NewUserService creates a new instance of userService.
+func NewUserService(repo infrastructure.UserRepository, workspaceService workspaceapp.WorkspaceService) UserService {
+	return &userService{repo: repo, workspaceService: workspaceService}
+}
+
+// ListUsers returns all users that were not deleted.
+func (s *userService) ListUsers() ([]domain.User, error) {
+	users, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(users, func(u domain.User) bool { return u.DeletedAt != nil }), nil
+}
+
+// GetUser returns a single user. Deleted users are not found.
+func (s *userService) GetUser(id string) (*domain.User, error) {
+	user, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletedAt != nil {
+		return nil, fmt.Errorf("user %s not found", id)
+	}
+	return user, nil
+}
+
+// CreateUser creates a user with a new ID. User names are unique, ignoring case; a deleted user with the
+// user name is replaced.
+func (s *userService) CreateUser(user *domain.User) (*domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.UserName = strings.TrimSpace(user.UserName)
+	if user.UserName == "" {
+		return nil, fmt.Errorf("user name is required")
+	}
+	if err := s.checkUserName(user.UserName, ""); err != nil {
+		return nil, err
+	}
+	if err := s.removeDeleted(user.UserName); err != nil {
+		return nil, err
+	}
+	id, err := newUserID()
+	if err != nil {
+		return nil, err
+	}
+	user.ID = id
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	if user.WorkspaceIDs == nil {
+		user.WorkspaceIDs = []string{}
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUser applies an update to a user and stores it. The ID and creation time cannot be changed.
+func (s *userService) UpdateUser(id string, update func(user *domain.User) error) (*domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := user.CreatedAt
+	if err := update(user); err != nil {
+		return nil, err
+	}
+	user.ID, user.CreatedAt, user.UpdatedAt = id, createdAt, time.Now()
+	user.UserName = strings.TrimSpace(user.UserName)
+	if user.UserName == "" {
+		return nil, fmt.Errorf("user name is required")
+	}
+	if err := s.checkUserName(user.UserName, id); err != nil {
+		return nil, err
+	}
+	if err := s.removeDeleted(user.UserName); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser deprovisions a user: they are no longer listed or found and lose their memberships, but are
+// kept as inactive, so their API keys and tokens stay rejected.
+func (s *userService) DeleteUser(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	user.Active = false
+	user.WorkspaceIDs = []string{}
+	user.UpdatedAt, user.DeletedAt = now, &now
+	return s.repo.Save(user)
+}
+
+// Members returns the members of a workspace.
+func (s *userService) Members(workspaceID string) ([]domain.User, error) {
+	users, err := s.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	members := []domain.User{}
+	for _, user := range users {
+		if slices.Contains(user.WorkspaceIDs, workspaceID) {
+			members = append(members, user)
+		}
+	}
+	return members, nil
+}
+
+// SetMembers adds users to a workspace, or removes them when member is false.
+func (s *userService) SetMembers(workspaceID string, userIDs []string, member bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.workspaceService.GetWorkspace(workspaceID); err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		user, err := s.GetUser(id)
+		if err != nil {
+			return err
+		}
+		if setMembership(user, workspaceID, member) {
+			if err := s.repo.Save(user); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReplaceMembers makes the given users the only members of a workspace.
+func (s *userService) ReplaceMembers(workspaceID string, userIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.workspaceService.GetWorkspace(workspaceID); err != nil {
+		return err
+	}
+	users, err := s.ListUsers()
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if !slices.ContainsFunc(users, func(u domain.User) bool { return u.ID == id }) {
+			return fmt.Errorf("user %s not found", id)
+		}
+	}
+	for i := range users {
+		if setMembership(&users[i], workspaceID, slices.Contains(userIDs, users[i].ID)) {
+			if err := s.repo.Save(&users[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CanUseWorkspace reports whether the user with the given user name may use a workspace. Workspaces
+// with members are restricted to their active members; workspaces without any are open to every user.
+func (s *userService) CanUseWorkspace(userName, workspaceID string) bool {
+	members, err := s.Members(workspaceID)
+	if err != nil {
+		return false
+	}
+	if len(members) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(members, func(u domain.User) bool {
+		return u.Active && strings.EqualFold(u.UserName, userName)
+	})
+}
+
+// Deactivated reports whether the user with the given user name was deactivated or deleted by the
+// identity provider. Users that were never provisioned are not.
+func (s *userService) Deactivated(userName string) bool {
+	users, err := s.repo.List()
+	if err != nil {
+		return false
+	}
+	deactivated := false
+	for _, user := range users {
+		if !strings.EqualFold(user.UserName, userName) {
+			continue
+		}
+		if user.DeletedAt == nil {
+			return !user.Active
+		}
+		deactivated = true
+	}
+	return deactivated
+}
+
+// removeDeleted removes the deleted user with the user name, if any, before the name is provisioned again.
+func (s *userService) removeDeleted(userName string) error {
+	users, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.DeletedAt != nil && strings.EqualFold(user.UserName, userName) {
+			return s.repo.Delete(user.ID)
+		}
+	}
+	return nil
+}
+
+// checkUserName fails when another user than the given one has the user name. Deleted users do not
+// count.
+func (s *userService) checkUserName(userName, id string) error {
+	users, err := s.ListUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.ID != id && strings.EqualFold(user.UserName, userName) {
+			return fmt.Errorf("%w: %s", domain.ErrUserExists, userName)
+		}
+	}
+	return nil
+}
+
+// setMembership adds the workspace to or removes it from the user's memberships and reports whether
+// they changed.
+func setMembership(user *domain.User, workspaceID string, member bool) bool {
+	i := slices.Index(user.WorkspaceIDs, workspaceID)
+	switch {
+	case member && i < 0:
+		user.WorkspaceIDs = append(user.WorkspaceIDs, workspaceID)
+	case !member && i >= 0:
+		user.WorkspaceIDs = slices.Delete(user.WorkspaceIDs, i, i+1)
+	default:
+		return false
+	}
+	user.UpdatedAt = time.Now()
+	return true
+}
+
+func newUserID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate user ID: %w", err)
+	}
+	return "usr-" + hex.EncodeToString(b), nil
+}
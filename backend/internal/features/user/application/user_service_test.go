@@ -0,0 +1,119 @@
+package application
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"sofa-commander/backend/internal/features/user/domain"
+	"sofa-commander/backend/internal/features/user/infrastructure"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+	workspaceinfra "sofa-commander/backend/internal/features/workspace/infrastructure"
+)
+
+func newTestUserService(t *testing.T) (UserService, workspaceapp.WorkspaceService) {
+	t.Helper()
+	dir := t.TempDir()
+	workspaces := workspaceapp.NewWorkspaceService(
+		workspaceinfra.NewJSONWorkspaceRepository(filepath.Join(dir, "workspaces.json")),
+		workspaceinfra.NewSecretCipher("passphrase"),
+	)
+	return NewUserService(infrastructure.NewJSONUserRepository(filepath.Join(dir, "users.json")), workspaces), workspaces
+}
+
+func createUser(t *testing.T, s UserService, userName string) *domain.User {
+	t.Helper()
+	user, err := s.CreateUser(&domain.User{UserName: userName, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestCanUseWorkspace(t *testing.T) {
+	s, workspaces := newTestUserService(t)
+	restricted, err := workspaces.CreateWorkspace(&workspacedomain.WorkspaceRequest{Name: "Payments"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := workspaces.CreateWorkspace(&workspacedomain.WorkspaceRequest{Name: "Sandbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := createUser(t, s, "alice@example.com")
+	carol := createUser(t, s, "carol@example.com")
+	createUser(t, s, "bob@example.com")
+	if err := s.SetMembers(restricted.ID, []string{alice.ID, carol.ID}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateUser(carol.ID, func(user *domain.User) error {
+		user.Active = false
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		userName    string
+		workspaceID string
+		want        bool
+	}{
+		{name: "member", userName: "alice@example.com", workspaceID: restricted.ID, want: true},
+		{name: "member in other case", userName: "Alice@Example.com", workspaceID: restricted.ID, want: true},
+		{name: "not a member", userName: "bob@example.com", workspaceID: restricted.ID, want: false},
+		{name: "not provisioned", userName: "mallory@example.com", workspaceID: restricted.ID, want: false},
+		{name: "deactivated member", userName: "carol@example.com", workspaceID: restricted.ID, want: false},
+		{name: "workspace without members", userName: "bob@example.com", workspaceID: open.ID, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.CanUseWorkspace(tt.userName, tt.workspaceID); got != tt.want {
+				t.Errorf("CanUseWorkspace(%q) = %v, want %v", tt.userName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	s, workspaces := newTestUserService(t)
+	workspace, err := workspaces.CreateWorkspace(&workspacedomain.WorkspaceRequest{Name: "Payments"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := createUser(t, s, "alice@example.com")
+	if err := s.SetMembers(workspace.ID, []string{user.ID}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteUser(user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetUser(user.ID); err == nil {
+		t.Error("GetUser() found the deleted user")
+	}
+	if members, _ := s.Members(workspace.ID); len(members) != 0 {
+		t.Errorf("Members() = %v, want the deleted user's membership dropped", members)
+	}
+	if !s.Deactivated("alice@example.com") {
+		t.Error("Deactivated() = false for the deleted user, want their credentials rejected")
+	}
+
+	// The user name can be provisioned again
+	again := createUser(t, s, "alice@example.com")
+	if again.ID == user.ID || s.Deactivated("alice@example.com") {
+		t.Errorf("provisioned again as %s, Deactivated() = %v; want a new active user", again.ID, s.Deactivated("alice@example.com"))
+	}
+}
+
+func TestCreateUserExists(t *testing.T) {
+	s, _ := newTestUserService(t)
+	createUser(t, s, "alice@example.com")
+	if _, err := s.CreateUser(&domain.User{UserName: "ALICE@example.com"}); !errors.Is(err, domain.ErrUserExists) {
+		t.Errorf("CreateUser() error = %v, want ErrUserExists", err)
+	}
+	if s.Deactivated("nobody@example.com") {
+		t.Error("Deactivated() = true for a user never provisioned")
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644).
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ScimUser is the SCIM representation of a user.
+type ScimUser struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Name        *ScimName    `json:"name,omitempty"`
+	Emails      []ScimEmail  `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"` // Defaults to true on create
+	Groups      []ScimMember `json:"groups,omitempty"` // Read-only: the workspaces of the user
+	Meta        *ScimMeta    `json:"meta,omitempty"`
+}
+
+// ScimName is the name of a SCIM user; only the formatted name is kept, as the display name fallback.
+type ScimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// ScimEmail is an email address of a SCIM user.
+type ScimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// ScimGroup is the SCIM representation of a workspace, whose members are its users.
+type ScimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id"`
+	DisplayName string       `json:"displayName"`
+	Members     []ScimMember `json:"members"`
+	Meta        *ScimMeta    `json:"meta,omitempty"`
+}
+
+// ScimMember references a user of a group, or a group of a user.
+type ScimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// ScimMeta is the resource metadata of a SCIM resource.
+type ScimMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// ScimListResponse is a page of SCIM resources.
+type ScimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// ScimPatchRequest is a SCIM PATCH request.
+type ScimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []ScimPatchOperation `json:"Operations"`
+}
+
+// ScimPatchOperation is an operation of a SCIM PATCH request. Without a path, the value is an object of
+// the attributes to change.
+type ScimPatchOperation struct {
+	Op    string          `json:"op"` // "add", "replace" or "remove", in any case
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ScimError is a SCIM error response.
+type ScimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"` // e.g. "uniqueness" or "invalidFilter"
+	Detail   string   `json:"detail"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUserExists is returned when creating a user whose user name is taken.
+var ErrUserExists = errors.New("user already exists")
+
+// User is a user account provisioned by the identity provider. Its user name is the X-User-ID of the
+// user's requests; deactivated and deleted users can no longer authenticate. Workspace memberships mirror
+// the identity provider's groups: a workspace with members can only be used by them.
+type User struct {
+	ID           string     `json:"id"`
+	UserName     string     `json:"user_name"`
+	ExternalID   string     `json:"external_id,omitempty"` // ID of the user at the identity provider
+	DisplayName  string     `json:"display_name,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Active       bool       `json:"active"`
+	WorkspaceIDs []string   `json:"workspace_ids"` // Workspaces the user is a member of
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"` // Deleted users are kept, inactive, so their credentials stay rejected
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/user/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// UserRepository defines the interface for user persistence.
+type UserRepository interface {
+	List() ([]domain.User, error)
+	Get(id string) (*domain.User, error)
+	Save(user *domain.User) error
+	Delete(id string) error
+}
+
+// jsonUserRepository stores users in a JSON file.
+type jsonUserRepository struct {
+	file *jsonfile.Store[[]domain.User]
+	mu   sync.Mutex
+}
+
+// NewJSONUserRepository creates a new repository backed by the given JSON file.
+func NewJSONUserRepository(path string) UserRepository {
+	return &jsonUserRepository{file: jsonfile.NewStore[[]domain.User](path, "users")}
+}
+
+// List returns all users.
+func (r *jsonUserRepository) List() ([]domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Get returns the user with the given ID.
+func (r *jsonUserRepository) Get(id string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].ID == id {
+			return &users[i], nil
+		}
+	}
+	return nil, fmt.Errorf("user %s not found", id)
+}
+
+// Save creates or replaces a user.
+func (r *jsonUserRepository) Save(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range users {
+		if users[i].ID == user.ID {
+			users[i] = *user
+			replaced = true
+		}
+	}
+	if !replaced {
+		users = append(users, *user)
+	}
+	return r.file.Store(users)
+}
+
+// Delete removes a user.
+func (r *jsonUserRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		if users[i].ID == id {
+			return r.file.Store(append(users[:i], users[i+1:]...))
+		}
+	}
+	return fmt.Errorf("user %s not found", id)
+}
@@ -0,0 +1,617 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sofa-commander/backend/internal/features/user/application"
+	"sofa-commander/backend/internal/features/user/domain"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scimContentType is the media type of SCIM requests and responses.
+const scimContentType = "application/scim+json"
+
+// maxPageSize caps the resources returned per list request.
+const maxPageSize = 200
+
+// filterPattern matches the equality filters identity providers use to look up a resource, e.g.
+// `userName eq "alice@example.com"`.
+var filterPattern = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// memberFilterPattern matches the member paths of group PATCH operations, e.g. `members[value eq "usr-1"]`.
+var memberFilterPattern = regexp.MustCompile(`(?i)^members\[value eq "([^"]*)"\]$`)
+
+// ScimHandler serves the SCIM 2.0 API identity providers provision users and workspace memberships
+// with. Workspaces are exposed as groups; they are created through the workspace and organization APIs.
+type ScimHandler struct {
+	userService      application.UserService
+	workspaceService workspaceapp.WorkspaceService
+	token            string
+}
+
+// NewScimHandler creates a new ScimHandler. Without a token, the SCIM API is disabled.
+func NewScimHandler(userService application.UserService, workspaceService workspaceapp.WorkspaceService, token string) *ScimHandler {
+	return &ScimHandler{
+		userService:      userService,
+		workspaceService: workspaceService,
+		token:            token,
+	}
+}
+
+// Middleware checks the bearer token of SCIM requests.
+func (h *ScimHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if h.token == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(bearer)), []byte(h.token)) != 1 {
+			scimError(c, http.StatusUnauthorized, "", "Invalid SCIM token")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ServiceProviderConfigHandler describes the SCIM features supported.
+func (h *ScimHandler) ServiceProviderConfigHandler(c *gin.Context) {
+	scimJSON(c, http.StatusOK, gin.H{
+		"schemas":        []string{domain.SchemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": maxPageSize},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the SCIM_TOKEN of the deployment",
+			"primary":     true,
+		}},
+	})
+}
+
+// ListUsersHandler lists users, optionally filtered by userName, externalId, id or emails.value.
+func (h *ScimHandler) ListUsersHandler(c *gin.Context) {
+	users, err := h.userService.ListUsers()
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to list users: "+err.Error())
+		return
+	}
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, ok := parseFilter(filter)
+		if !ok {
+			scimError(c, http.StatusBadRequest, "invalidFilter", "Unsupported filter: "+filter)
+			return
+		}
+		matched := []domain.User{}
+		for _, user := range users {
+			switch attr {
+			case "username":
+				ok = strings.EqualFold(user.UserName, value)
+			case "externalid":
+				ok = user.ExternalID == value
+			case "id":
+				ok = user.ID == value
+			case "emails", "emails.value":
+				ok = strings.EqualFold(user.Email, value)
+			default:
+				scimError(c, http.StatusBadRequest, "invalidFilter", "Unsupported filter attribute: "+attr)
+				return
+			}
+			if ok {
+				matched = append(matched, user)
+			}
+		}
+		users = matched
+	}
+	names := h.workspaceNames()
+	page, start := paginate(c, len(users))
+	resources := make([]domain.ScimUser, 0, len(page))
+	for _, i := range page {
+		resources = append(resources, toScimUser(c, &users[i], names))
+	}
+	scimJSON(c, http.StatusOK, listResponse(len(users), start, resources))
+}
+
+// GetUserHandler returns a user.
+func (h *ScimHandler) GetUserHandler(c *gin.Context) {
+	user, err := h.userService.GetUser(c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", err.Error())
+		return
+	}
+	scimJSON(c, http.StatusOK, toScimUser(c, user, h.workspaceNames()))
+}
+
+// CreateUserHandler provisions a user.
+func (h *ScimHandler) CreateUserHandler(c *gin.Context) {
+	var req domain.ScimUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	user := &domain.User{Active: true}
+	applyScimUser(user, &req)
+	user, err := h.userService.CreateUser(user)
+	if errors.Is(err, domain.ErrUserExists) {
+		scimError(c, http.StatusConflict, "uniqueness", err.Error())
+		return
+	}
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", "Failed to create user: "+err.Error())
+		return
+	}
+	scimJSON(c, http.StatusCreated, toScimUser(c, user, h.workspaceNames()))
+}
+
+// ReplaceUserHandler replaces the attributes of a user. Workspace memberships are managed through groups
+// and kept.
+func (h *ScimHandler) ReplaceUserHandler(c *gin.Context) {
+	var req domain.ScimUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	user, err := h.userService.UpdateUser(c.Param("id"), func(user *domain.User) error {
+		*user = domain.User{Active: true, WorkspaceIDs: user.WorkspaceIDs}
+		applyScimUser(user, &req)
+		return nil
+	})
+	h.respondUser(c, user, err)
+}
+
+// PatchUserHandler applies PATCH operations to a user, typically to deactivate or rename them.
+func (h *ScimHandler) PatchUserHandler(c *gin.Context) {
+	var req domain.ScimPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	user, err := h.userService.UpdateUser(c.Param("id"), func(user *domain.User) error {
+		for _, op := range req.Operations {
+			if err := patchUser(user, op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	h.respondUser(c, user, err)
+}
+
+// DeleteUserHandler deprovisions a user.
+func (h *ScimHandler) DeleteUserHandler(c *gin.Context) {
+	if err := h.userService.DeleteUser(c.Param("id")); err != nil {
+		scimError(c, http.StatusNotFound, "", err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondUser responds with an updated user, or the error of the update.
+func (h *ScimHandler) respondUser(c *gin.Context, user *domain.User, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserExists):
+		scimError(c, http.StatusConflict, "uniqueness", err.Error())
+	case err != nil && strings.HasSuffix(err.Error(), "not found"):
+		scimError(c, http.StatusNotFound, "", err.Error())
+	case err != nil:
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		scimJSON(c, http.StatusOK, toScimUser(c, user, h.workspaceNames()))
+	}
+}
+
+// ListGroupsHandler lists the workspaces as groups, optionally filtered by displayName or id.
+func (h *ScimHandler) ListGroupsHandler(c *gin.Context) {
+	workspaces, err := h.workspaceService.ListWorkspaces()
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to list workspaces: "+err.Error())
+		return
+	}
+	if filter := c.Query("filter"); filter != "" {
+		attr, value, ok := parseFilter(filter)
+		if !ok || (attr != "displayname" && attr != "id") {
+			scimError(c, http.StatusBadRequest, "invalidFilter", "Unsupported filter: "+filter)
+			return
+		}
+		matched := []workspacedomain.Workspace{}
+		for _, w := range workspaces {
+			if (attr == "id" && w.ID == value) || (attr == "displayname" && strings.EqualFold(w.Name, value)) {
+				matched = append(matched, w)
+			}
+		}
+		workspaces = matched
+	}
+	page, start := paginate(c, len(workspaces))
+	resources := make([]domain.ScimGroup, 0, len(page))
+	for _, i := range page {
+		group, err := h.toScimGroup(c, &workspaces[i])
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to list members: "+err.Error())
+			return
+		}
+		resources = append(resources, group)
+	}
+	scimJSON(c, http.StatusOK, listResponse(len(workspaces), start, resources))
+}
+
+// GetGroupHandler returns a workspace as a group.
+func (h *ScimHandler) GetGroupHandler(c *gin.Context) {
+	workspace, err := h.workspaceService.GetWorkspace(c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", err.Error())
+		return
+	}
+	h.respondGroup(c, workspace)
+}
+
+// CreateGroupHandler rejects group creation: groups are workspaces, provisioned with their AI provider
+// settings through the workspace and organization APIs.
+func (h *ScimHandler) CreateGroupHandler(c *gin.Context) {
+	scimError(c, http.StatusNotImplemented, "", "Groups are workspaces; create them through the workspace or organization API")
+}
+
+// ReplaceGroupHandler replaces the members of a workspace. The display name is the workspace's and kept.
+func (h *ScimHandler) ReplaceGroupHandler(c *gin.Context) {
+	var req domain.ScimGroup
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	workspace, err := h.workspaceService.GetWorkspace(c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", err.Error())
+		return
+	}
+	if err := h.userService.ReplaceMembers(workspace.ID, memberIDs(req.Members)); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	h.respondGroup(c, workspace)
+}
+
+// PatchGroupHandler adds, removes or replaces the members of a workspace.
+func (h *ScimHandler) PatchGroupHandler(c *gin.Context) {
+	var req domain.ScimPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	workspace, err := h.workspaceService.GetWorkspace(c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusNotFound, "", err.Error())
+		return
+	}
+	for _, op := range req.Operations {
+		if err := h.patchGroup(workspace.ID, op); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+	h.respondGroup(c, workspace)
+}
+
+// patchGroup applies a PATCH operation to the members of a workspace. Other attributes, e.g. the display
+// name, belong to the workspace and are ignored.
+func (h *ScimHandler) patchGroup(workspaceID string, op domain.ScimPatchOperation) error {
+	path := strings.TrimSpace(op.Path)
+	var members []domain.ScimMember
+	if m := memberFilterPattern.FindStringSubmatch(path); m != nil {
+		members, path = []domain.ScimMember{{Value: m[1]}}, "members"
+	} else if path == "" && len(op.Value) > 0 {
+		var attrs struct {
+			Members []domain.ScimMember `json:"members"`
+		}
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return fmt.Errorf("invalid value of %s operation: %w", op.Op, err)
+		}
+		if attrs.Members == nil {
+			return nil
+		}
+		members, path = attrs.Members, "members"
+	} else if len(op.Value) > 0 && strings.EqualFold(path, "members") {
+		if err := json.Unmarshal(op.Value, &members); err != nil {
+			return fmt.Errorf("invalid members of %s operation: %w", op.Op, err)
+		}
+	}
+	if !strings.EqualFold(path, "members") {
+		return nil
+	}
+	switch strings.ToLower(op.Op) {
+	case "add":
+		return h.userService.SetMembers(workspaceID, memberIDs(members), true)
+	case "remove":
+		if members == nil {
+			return h.userService.ReplaceMembers(workspaceID, nil)
+		}
+		return h.userService.SetMembers(workspaceID, memberIDs(members), false)
+	case "replace":
+		return h.userService.ReplaceMembers(workspaceID, memberIDs(members))
+	}
+	return fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// respondGroup responds with a workspace as a group.
+func (h *ScimHandler) respondGroup(c *gin.Context, workspace *workspacedomain.Workspace) {
+	group, err := h.toScimGroup(c, workspace)
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to list members: "+err.Error())
+		return
+	}
+	scimJSON(c, http.StatusOK, group)
+}
+
+// toScimGroup converts a workspace and its members to a SCIM group.
+func (h *ScimHandler) toScimGroup(c *gin.Context, workspace *workspacedomain.Workspace) (domain.ScimGroup, error) {
+	users, err := h.userService.Members(workspace.ID)
+	if err != nil {
+		return domain.ScimGroup{}, err
+	}
+	members := make([]domain.ScimMember, 0, len(users))
+	for _, user := range users {
+		members = append(members, domain.ScimMember{Value: user.ID, Display: user.UserName})
+	}
+	return domain.ScimGroup{
+		Schemas:     []string{domain.SchemaGroup},
+		ID:          workspace.ID,
+		DisplayName: workspace.Name,
+		Members:     members,
+		Meta:        &domain.ScimMeta{ResourceType: "Group", Location: location(c, "Groups", workspace.ID)},
+	}, nil
+}
+
+// workspaceNames returns the names of the workspaces by ID, for the groups of users.
+func (h *ScimHandler) workspaceNames() map[string]string {
+	names := make(map[string]string)
+	workspaces, err := h.workspaceService.ListWorkspaces()
+	if err != nil {
+		return names
+	}
+	for _, w := range workspaces {
+		names[w.ID] = w.Name
+	}
+	return names
+}
+
+// applyScimUser sets the attributes of a SCIM user on a user. The display name falls back to the
+// formatted name, and the email to the primary or first address.
+func applyScimUser(user *domain.User, req *domain.ScimUser) {
+	user.UserName = req.UserName
+	user.ExternalID = req.ExternalID
+	user.DisplayName = req.DisplayName
+	if user.DisplayName == "" && req.Name != nil {
+		user.DisplayName = strings.TrimSpace(req.Name.Formatted)
+		if user.DisplayName == "" {
+			user.DisplayName = strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName)
+		}
+	}
+	user.Email = primaryEmail(req.Emails)
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+}
+
+// patchUser applies a PATCH operation to a user. Unsupported attributes are ignored.
+func patchUser(user *domain.User, op domain.ScimPatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return fmt.Errorf("unsupported operation %q", op.Op)
+	}
+	if op.Path == "" {
+		if kind == "remove" {
+			return fmt.Errorf("remove operations require a path")
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return fmt.Errorf("invalid value of %s operation: %w", op.Op, err)
+		}
+		for attr, value := range attrs {
+			if err := setUserAttr(user, attr, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if kind == "remove" {
+		return setUserAttr(user, op.Path, nil)
+	}
+	return setUserAttr(user, op.Path, op.Value)
+}
+
+// setUserAttr sets an attribute of a user from its JSON value, or clears it for a nil value.
+func setUserAttr(user *domain.User, attr string, value json.RawMessage) error {
+	var text string
+	if value != nil && !strings.HasPrefix(strings.ToLower(attr), "emails") && !strings.EqualFold(attr, "active") && !strings.EqualFold(attr, "name") {
+		if err := json.Unmarshal(value, &text); err != nil {
+			return fmt.Errorf("invalid value of %s: %w", attr, err)
+		}
+	}
+	switch attr = strings.ToLower(attr); {
+	case attr == "active":
+		if value == nil {
+			return fmt.Errorf("active cannot be removed")
+		}
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		user.Active = active
+	case attr == "username":
+		user.UserName = text
+	case attr == "externalid":
+		user.ExternalID = text
+	case attr == "displayname", attr == "name.formatted":
+		user.DisplayName = text
+	case attr == "name":
+		var name domain.ScimName
+		if value != nil {
+			if err := json.Unmarshal(value, &name); err != nil {
+				return fmt.Errorf("invalid value of name: %w", err)
+			}
+		}
+		if user.DisplayName == "" || value == nil {
+			user.DisplayName = strings.TrimSpace(name.Formatted)
+		}
+	case strings.HasPrefix(attr, "emails"):
+		var emails []domain.ScimEmail
+		if value != nil && json.Unmarshal(value, &emails) != nil {
+			// e.g. `emails[type eq "work"].value` with the address itself
+			if err := json.Unmarshal(value, &text); err != nil {
+				return fmt.Errorf("invalid value of %s: %w", attr, err)
+			}
+			emails = []domain.ScimEmail{{Value: text}}
+		}
+		user.Email = primaryEmail(emails)
+	}
+	return nil
+}
+
+// parseBool parses a JSON boolean, or a string holding one as some identity providers send.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, fmt.Errorf("invalid value of active: %s", value)
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid value of active: %s", s)
+	}
+	return b, nil
+}
+
+// primaryEmail returns the primary address of a list, or the first one.
+func primaryEmail(emails []domain.ScimEmail) string {
+	for _, e := range emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+// toScimUser converts a user to its SCIM representation.
+func toScimUser(c *gin.Context, user *domain.User, workspaceNames map[string]string) domain.ScimUser {
+	active := user.Active
+	groups := make([]domain.ScimMember, 0, len(user.WorkspaceIDs))
+	for _, id := range user.WorkspaceIDs {
+		groups = append(groups, domain.ScimMember{Value: id, Display: workspaceNames[id]})
+	}
+	resp := domain.ScimUser{
+		Schemas:     []string{domain.SchemaUser},
+		ID:          user.ID,
+		ExternalID:  user.ExternalID,
+		UserName:    user.UserName,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &domain.ScimMeta{
+			ResourceType: "User",
+			Created:      &user.CreatedAt,
+			LastModified: &user.UpdatedAt,
+			Location:     location(c, "Users", user.ID),
+		},
+	}
+	if user.Email != "" {
+		resp.Emails = []domain.ScimEmail{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	return resp
+}
+
+// memberIDs returns the user IDs of group members.
+func memberIDs(members []domain.ScimMember) []string {
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.Value)
+	}
+	return ids
+}
+
+// parseFilter parses an equality filter into its lower-cased attribute and value.
+func parseFilter(filter string) (string, string, bool) {
+	m := filterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", false
+	}
+	value, err := strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		value = m[2]
+	}
+	return strings.ToLower(m[1]), value, true
+}
+
+// paginate returns the indexes of the requested page of n resources and its 1-based start index.
+func paginate(c *gin.Context, n int) ([]int, int) {
+	start, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || start < 1 {
+		start = 1
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(maxPageSize)))
+	if err != nil || count < 0 || count > maxPageSize {
+		count = maxPageSize
+	}
+	var page []int
+	for i := start - 1; i < n && len(page) < count; i++ {
+		page = append(page, i)
+	}
+	return page, start
+}
+
+// listResponse wraps a page of resources.
+func listResponse[T any](total, start int, resources []T) domain.ScimListResponse {
+	return domain.ScimListResponse{
+		Schemas:      []string{domain.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// location returns the URL of a SCIM resource on this server.
+func location(c *gin.Context, resourceType, id string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s/scim/v2/%s/%s", scheme, c.Request.Host, resourceType, id)
+}
+
+// scimJSON responds with a SCIM resource.
+func scimJSON(c *gin.Context, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, scimContentType, data)
+}
+
+// scimError responds with a SCIM error.
+func scimError(c *gin.Context, status int, scimType, detail string) {
+	scimJSON(c, status, domain.ScimError{
+		Schemas:  []string{domain.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"sofa-commander/backend/internal/features/user/application"
+	"sofa-commander/backend/internal/features/user/domain"
+	"sofa-commander/backend/internal/features/user/infrastructure"
+	workspaceapp "sofa-commander/backend/internal/features/workspace/application"
+	workspacedomain "sofa-commander/backend/internal/features/workspace/domain"
+	workspaceinfra "sofa-commander/backend/internal/features/workspace/infrastructure"
+)
+
+const testToken = "scim-token"
+
+func newTestRouter(t *testing.T) (*gin.Engine, workspaceapp.WorkspaceService) {
+	t.Helper()
+	dir := t.TempDir()
+	workspaces := workspaceapp.NewWorkspaceService(
+		workspaceinfra.NewJSONWorkspaceRepository(filepath.Join(dir, "workspaces.json")),
+		workspaceinfra.NewSecretCipher("passphrase"),
+	)
+	users := application.NewUserService(infrastructure.NewJSONUserRepository(filepath.Join(dir, "users.json")), workspaces)
+	handler := NewScimHandler(users, workspaces, testToken)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/scim/v2", handler.Middleware())
+	group.GET("/Users", handler.ListUsersHandler)
+	group.POST("/Users", handler.CreateUserHandler)
+	group.GET("/Users/:id", handler.GetUserHandler)
+	group.PATCH("/Users/:id", handler.PatchUserHandler)
+	group.DELETE("/Users/:id", handler.DeleteUserHandler)
+	group.GET("/Groups/:id", handler.GetGroupHandler)
+	group.PATCH("/Groups/:id", handler.PatchGroupHandler)
+	return router, workspaces
+}
+
+func scimRequest(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", scimContentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body, err)
+	}
+	return v
+}
+
+func TestScimToken(t *testing.T) {
+	router, _ := newTestRouter(t)
+	for _, header := range []string{"", "Bearer wrong", "Basic " + testToken} {
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q = %d, want 401", header, w.Code)
+		}
+	}
+	if w := scimRequest(router, http.MethodGet, "/scim/v2/Users", ""); w.Code != http.StatusOK {
+		t.Errorf("valid token = %d, want 200", w.Code)
+	}
+}
+
+func TestScimUsers(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := scimRequest(router, http.MethodPost, "/scim/v2/Users", `{"schemas":["`+domain.SchemaUser+`"],"userName":"alice@example.com","externalId":"ext-1","emails":[{"value":"alice@example.com","primary":true}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /Users = %d, want 201: %s", w.Code, w.Body)
+	}
+	created := decode[domain.ScimUser](t, w)
+	if created.ID == "" || created.Active == nil || !*created.Active {
+		t.Errorf("created user = %+v, want an ID and active", created)
+	}
+
+	if w := scimRequest(router, http.MethodPost, "/scim/v2/Users", `{"userName":"ALICE@example.com"}`); w.Code != http.StatusConflict {
+		t.Errorf("POST duplicate user = %d, want 409", w.Code)
+	}
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{filter: `userName eq "Alice@Example.com"`, want: 1},
+		{filter: `externalId eq "ext-1"`, want: 1},
+		{filter: `userName eq "bob@example.com"`, want: 0},
+	}
+	for _, tt := range tests {
+		w := scimRequest(router, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(tt.filter), "")
+		if got := decode[domain.ScimListResponse](t, w).TotalResults; got != tt.want {
+			t.Errorf("filter %s: totalResults = %d, want %d", tt.filter, got, tt.want)
+		}
+	}
+	if w := scimRequest(router, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`title co "x"`), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unsupported filter = %d, want 400", w.Code)
+	}
+
+	w = scimRequest(router, http.MethodPatch, "/scim/v2/Users/"+created.ID, `{"schemas":["`+domain.SchemaPatchOp+`"],"Operations":[{"op":"Replace","path":"active","value":false}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH /Users = %d, want 200: %s", w.Code, w.Body)
+	}
+	if patched := decode[domain.ScimUser](t, w); patched.Active == nil || *patched.Active {
+		t.Errorf("patched user active = %v, want false", patched.Active)
+	}
+
+	if w := scimRequest(router, http.MethodDelete, "/scim/v2/Users/"+created.ID, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /Users = %d, want 204", w.Code)
+	}
+	if w := scimRequest(router, http.MethodGet, "/scim/v2/Users/"+created.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET deleted user = %d, want 404", w.Code)
+	}
+}
+
+func TestScimGroupMembers(t *testing.T) {
+	router, workspaces := newTestRouter(t)
+	workspace, err := workspaces.CreateWorkspace(&workspacedomain.WorkspaceRequest{Name: "Payments"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, userName := range []string{"alice@example.com", "bob@example.com"} {
+		w := scimRequest(router, http.MethodPost, "/scim/v2/Users", `{"userName":"`+userName+`"}`)
+		ids = append(ids, decode[domain.ScimUser](t, w).ID)
+	}
+
+	tests := []struct {
+		name string
+		op   string
+		want []string
+	}{
+		{name: "add", op: `{"op":"add","path":"members","value":[{"value":"` + ids[0] + `"},{"value":"` + ids[1] + `"}]}`, want: ids},
+		{name: "remove by filter", op: `{"op":"remove","path":"members[value eq \"` + ids[0] + `\"]"}`, want: ids[1:]},
+		{name: "replace", op: `{"op":"replace","path":"members","value":[{"value":"` + ids[0] + `"}]}`, want: ids[:1]},
+		{name: "remove all", op: `{"op":"remove","path":"members"}`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := scimRequest(router, http.MethodPatch, "/scim/v2/Groups/"+workspace.ID, `{"schemas":["`+domain.SchemaPatchOp+`"],"Operations":[`+tt.op+`]}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("PATCH /Groups = %d, want 200: %s", w.Code, w.Body)
+			}
+			group := decode[domain.ScimGroup](t, w)
+			var got []string
+			for _, member := range group.Members {
+				got = append(got, member.Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("members = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if w := scimRequest(router, http.MethodPatch, "/scim/v2/Groups/"+workspace.ID, `{"Operations":[{"op":"add","path":"members","value":[{"value":"usr-unknown"}]}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PATCH unknown member = %d, want 400", w.Code)
+	}
+	if w := scimRequest(router, http.MethodGet, "/scim/v2/Groups/ws-unknown", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown group = %d, want 404", w.Code)
+	}
+}
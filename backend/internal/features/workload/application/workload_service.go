@@ -0,0 +1,138 @@
+package application
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"sofa-commander/backend/internal/config"
+	configdomain "sofa-commander/backend/internal/features/config/domain"
+	refinementapp "sofa-commander/backend/internal/features/refinement/application"
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+	"sofa-commander/backend/internal/features/workload/domain"
+)
+
+// Waits after which a pending item is raised to normal and high priority.
+const (
+	normalAfter = 24 * time.Hour
+	highAfter   = 72 * time.Hour
+)
+
+// priorityRanks orders the pending items, most urgent first.
+var priorityRanks = map[string]int{domain.PriorityHigh: 0, domain.PriorityNormal: 1, domain.PriorityLow: 2}
+
+// WorkloadService defines the interface for listing the sessions awaiting a user's input.
+type WorkloadService interface {
+	Pending(userID string) ([]domain.PendingItem, error)
+}
+
+// workloadService is the implementation of WorkloadService.
+type workloadService struct {
+	refinementService refinementapp.RefinementService
+	appConfigService  config.AppConfigService
+}
+
+// NewWorkloadService creates a new instance of workloadService.
+func NewWorkloadService(refinementService refinementapp.RefinementService, appConfigService config.AppConfigService) WorkloadService {
+	return &workloadService{refinementService: refinementService, appConfigService: appConfigService}
+}
+
+// Pending lists the sessions where it is the user's turn: their own sessions awaiting answers, a review
+// of suggestions or changes requested by reviewers, and the finalized stories awaiting their sign-off as
+// a designated reviewer. The most urgent come first, the longest waiting first within a priority.
+func (s *workloadService) Pending(userID string) ([]domain.PendingItem, error) {
+	appConfig, err := s.appConfigService.LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+	reviewer := false
+	for _, r := range appConfig.Approval.Reviewers {
+		reviewer = reviewer || r.ID == userID
+	}
+
+	now := time.Now()
+	items := []domain.PendingItem{}
+	for _, session := range s.refinementService.ListSessions() {
+		if session.IsTutorial() {
+			continue
+		}
+		if session.UserID == userID {
+			if action, since := ownerAction(session); action != "" {
+				items = append(items, pendingItem(appConfig, session, action, since, now))
+			}
+		}
+		if reviewer && awaitsDecision(session, userID) {
+			items = append(items, pendingItem(appConfig, session, domain.ActionApprove, *session.FinalizedAt, now))
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if ri, rj := priorityRanks[items[i].Priority], priorityRanks[items[j].Priority]; ri != rj {
+			return ri < rj
+		}
+		return items[i].WaitingSince.Before(items[j].WaitingSince)
+	})
+	return items, nil
+}
+
+// ownerAction returns the action a session awaits from its owner and since when, or an empty action.
+func ownerAction(session *refinementdomain.RefinementSession) (string, time.Time) {
+	since := session.LastActivityAt
+	if since.IsZero() {
+		since = session.CreatedAt
+	}
+	if session.FinalizedAt != nil {
+		if session.ApprovalStatus != refinementdomain.ApprovalChangesRequested {
+			return "", time.Time{}
+		}
+		for _, d := range session.Approvals {
+			if d.Decision == refinementdomain.ApprovalChangesRequested && d.DecidedAt.After(since) {
+				since = d.DecidedAt
+			}
+		}
+		return domain.ActionAddressChanges, since
+	}
+	switch {
+	case session.Phase == refinementdomain.PhaseQuestioning && len(session.Questions) > 0:
+		return domain.ActionAnswerQuestions, since
+	case session.Phase == refinementdomain.PhaseSuggesting && len(session.Suggestions) > 0:
+		return domain.ActionReviewSuggestions, since
+	}
+	return "", time.Time{}
+}
+
+// awaitsDecision reports whether a finalized story awaits the reviewer's sign-off.
+func awaitsDecision(session *refinementdomain.RefinementSession, reviewerID string) bool {
+	if session.FinalizedAt == nil || session.ApprovalStatus != refinementdomain.ApprovalPending {
+		return false
+	}
+	for _, d := range session.Approvals {
+		if d.ReviewerID == reviewerID {
+			return false
+		}
+	}
+	return true
+}
+
+// pendingItem builds the pending item of a session. Items turn normal after a day and high after three;
+// reviews, which block delivery, start one step higher.
+func pendingItem(appConfig *configdomain.AppConfig, session *refinementdomain.RefinementSession, action string, since, now time.Time) domain.PendingItem {
+	age := now.Sub(since)
+	if action == domain.ActionApprove || action == domain.ActionAddressChanges {
+		age += normalAfter
+	}
+	priority := domain.PriorityLow
+	switch {
+	case age >= highAfter:
+		priority = domain.PriorityHigh
+	case age >= normalAfter:
+		priority = domain.PriorityNormal
+	}
+	return domain.PendingItem{
+		Session:      refinementapp.Summarize(session),
+		Action:       action,
+		WaitingSince: since,
+		AgeHours:     math.Round(now.Sub(since).Hours()*10) / 10,
+		Priority:     priority,
+		URL:          appConfig.SessionURL(session.ID),
+	}
+}
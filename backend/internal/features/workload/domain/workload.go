@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	refinementdomain "sofa-commander/backend/internal/features/refinement/domain"
+)
+
+// Actions a session awaits from a user.
+const (
+	ActionAnswerQuestions   = "answer_questions"   // The owner has an unanswered questioning round
+	ActionReviewSuggestions = "review_suggestions" // The owner has suggestions to accept or skip
+	ActionAddressChanges    = "address_changes"    // A reviewer requested changes to the owner's story
+	ActionApprove           = "approve"            // The reviewer has yet to decide on a finalized story
+)
+
+// Priorities of pending items, from the age of the wait and the action.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// PendingItem is a session awaiting a user's input.
+type PendingItem struct {
+	Session      refinementdomain.SessionSummary `json:"session"`
+	Action       string                          `json:"action"`
+	WaitingSince time.Time                       `json:"waiting_since"`
+	AgeHours     float64                         `json:"age_hours"`
+	Priority     string                          `json:"priority"`
+	URL          string                          `json:"url,omitempty"` // Link to the session in the web UI, set with a public base URL
+}
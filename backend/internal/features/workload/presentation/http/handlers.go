@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+
+	"sofa-commander/backend/internal/features/workload/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkloadHandler holds the workload service.
+type WorkloadHandler struct {
+	workloadService application.WorkloadService
+}
+
+// NewWorkloadHandler creates a new WorkloadHandler.
+func NewWorkloadHandler(workloadService application.WorkloadService) *WorkloadHandler {
+	return &WorkloadHandler{
+		workloadService: workloadService,
+	}
+}
+
+// PendingHandler lists the sessions awaiting the input of the user in the X-User-ID header.
+func (h *WorkloadHandler) PendingHandler(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-User-ID header is required"})
+		return
+	}
+	items, err := h.workloadService.Pending(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list pending sessions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"sofa-commander/backend/internal/features/workspace/domain"
+	"sofa-commander/backend/internal/features/workspace/infrastructure"
+)
+
+// DefaultProvider is used when a workspace does not specify a provider.
+const DefaultProvider = "openai"
+
+// WorkspaceService defines the interface for the workspace application service.
+type WorkspaceService interface {
+	ListWorkspaces() ([]domain.Workspace, error)
+	GetWorkspace(id string) (*domain.Workspace, error)
+	CreateWorkspace(req *domain.WorkspaceRequest) (*domain.Workspace, error)
+	UpdateWorkspace(id string, req *domain.WorkspaceRequest) (*domain.Workspace, error)
+	DeleteWorkspace(id string) error
+	SetOrganization(id, organizationID string) (*domain.Workspace, error)
+	ResolveProvider(id string) (*domain.ResolvedProvider, error)
+}
+
+// workspaceService is the implementation of WorkspaceService.
+type workspaceService struct {
+	repo   infrastructure.WorkspaceRepository
+	cipher infrastructure.SecretCipher
+}
+
+// NewWorkspaceService creates a new instance of workspaceService.
+func NewWorkspaceService(repo infrastructure.WorkspaceRepository, cipher infrastructure.SecretCipher) WorkspaceService {
+	return &workspaceService{repo: repo, cipher: cipher}
+}
+
+// ListWorkspaces returns all workspaces.
+func (s *workspaceService) ListWorkspaces() ([]domain.Workspace, error) {
+	return s.repo.List()
+}
+
+// GetWorkspace returns a single workspace.
+func (s *workspaceService) GetWorkspace(id string) (*domain.Workspace, error) {
+	return s.repo.Get(id)
+}
+
+// CreateWorkspace creates a workspace and encrypts its API key.
+func (s *workspaceService) CreateWorkspace(req *domain.WorkspaceRequest) (*domain.Workspace, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("workspace name is required")
+	}
+	id, err := newWorkspaceID()
+	if err != nil {
+		return nil, err
+	}
+	workspace := &domain.Workspace{ID: id}
+	if err := s.apply(workspace, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// UpdateWorkspace updates a workspace; the fields left empty, the API key included, keep their stored value.
+func (s *workspaceService) UpdateWorkspace(id string, req *domain.WorkspaceRequest) (*domain.Workspace, error) {
+	workspace, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(workspace, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// DeleteWorkspace removes a workspace.
+func (s *workspaceService) DeleteWorkspace(id string) error {
+	return s.repo.Delete(id)
+}
+
+// SetOrganization groups a workspace under an organization, or ungroups it with an empty organization ID.
+func (s *workspaceService) SetOrganization(id, organizationID string) (*domain.Workspace, error) {
+	workspace, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	workspace.OrganizationID = organizationID
+	if err := s.repo.Save(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// ResolveProvider returns the decrypted provider configuration of a workspace.
+func (s *workspaceService) ResolveProvider(id string) (*domain.ResolvedProvider, error) {
+	workspace, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &domain.ResolvedProvider{
+		Provider:     workspace.Provider.Provider,
+		Organization: workspace.Provider.Organization,
+		BaseURL:      workspace.Provider.BaseURL,
+		Model:        workspace.Provider.DefaultModel,
+	}
+	if workspace.Provider.EncryptedAPIKey != "" {
+		apiKey, err := s.cipher.Decrypt(workspace.Provider.EncryptedAPIKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key of workspace %s: %w", id, err)
+		}
+		resolved.APIKey = apiKey
+	}
+	return resolved, nil
+}
+
+// apply sets the fields given in the request, keeping the current value of the ones left empty.
+func (s *workspaceService) apply(workspace *domain.Workspace, req *domain.WorkspaceRequest) error {
+	if strings.TrimSpace(req.Name) != "" {
+		workspace.Name = req.Name
+	}
+	if req.Provider != "" {
+		workspace.Provider.Provider = req.Provider
+	}
+	if workspace.Provider.Provider == "" {
+		workspace.Provider.Provider = DefaultProvider
+	}
+	if req.Organization != "" {
+		workspace.Provider.Organization = req.Organization
+	}
+	if req.BaseURL != "" {
+		workspace.Provider.BaseURL = req.BaseURL
+	}
+	if req.DefaultModel != "" {
+		workspace.Provider.DefaultModel = req.DefaultModel
+	}
+	if req.APIKey != "" {
+		encrypted, err := s.cipher.Encrypt(req.APIKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt API key: %w", err)
+		}
+		workspace.Provider.EncryptedAPIKey = encrypted
+	}
+	return nil
+}
+
+func newWorkspaceID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate workspace ID: %w", err)
+	}
+	return "ws-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+
+	"sofa-commander/backend/internal/features/workspace/domain"
+	"sofa-commander/backend/internal/features/workspace/infrastructure"
+)
+
+func newTestWorkspaceService(t *testing.T) WorkspaceService {
+	t.Helper()
+	return NewWorkspaceService(
+		infrastructure.NewJSONWorkspaceRepository(filepath.Join(t.TempDir(), "workspaces.json")),
+		infrastructure.NewSecretCipher("passphrase"),
+	)
+}
+
+func TestUpdateWorkspace(t *testing.T) {
+	created := domain.WorkspaceRequest{
+		Name:         "Payments",
+		Provider:     "ollama",
+		Organization: "org-1",
+		BaseURL:      "https://llm.internal",
+		DefaultModel: "llama3",
+		APIKey:       "key-1",
+	}
+	tests := []struct {
+		name   string
+		update domain.WorkspaceRequest
+		want   domain.ResolvedProvider
+	}{
+		{
+			name:   "empty request keeps every field",
+			update: domain.WorkspaceRequest{},
+			want:   domain.ResolvedProvider{Provider: "ollama", Organization: "org-1", BaseURL: "https://llm.internal", Model: "llama3", APIKey: "key-1"},
+		},
+		{
+			name:   "model only",
+			update: domain.WorkspaceRequest{DefaultModel: "llama3.1"},
+			want:   domain.ResolvedProvider{Provider: "ollama", Organization: "org-1", BaseURL: "https://llm.internal", Model: "llama3.1", APIKey: "key-1"},
+		},
+		{
+			name:   "provider and key",
+			update: domain.WorkspaceRequest{Provider: "claude", APIKey: "key-2"},
+			want:   domain.ResolvedProvider{Provider: "claude", Organization: "org-1", BaseURL: "https://llm.internal", Model: "llama3", APIKey: "key-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestWorkspaceService(t)
+			workspace, err := s.CreateWorkspace(&created)
+			if err != nil {
+				t.Fatalf("CreateWorkspace() error = %v", err)
+			}
+			if workspace.Provider.EncryptedAPIKey == "" || workspace.Provider.EncryptedAPIKey == created.APIKey {
+				t.Fatalf("API key is not stored encrypted: %q", workspace.Provider.EncryptedAPIKey)
+			}
+			if _, err := s.UpdateWorkspace(workspace.ID, &tt.update); err != nil {
+				t.Fatalf("UpdateWorkspace() error = %v", err)
+			}
+			resolved, err := s.ResolveProvider(workspace.ID)
+			if err != nil {
+				t.Fatalf("ResolveProvider() error = %v", err)
+			}
+			if *resolved != tt.want {
+				t.Errorf("ResolveProvider() = %+v, want %+v", *resolved, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          domain.WorkspaceRequest
+		wantProvider string
+		wantErr      bool
+	}{
+		{name: "default provider", req: domain.WorkspaceRequest{Name: "Payments"}, wantProvider: DefaultProvider},
+		{name: "given provider", req: domain.WorkspaceRequest{Name: "Payments", Provider: "gemini"}, wantProvider: "gemini"},
+		{name: "no name", req: domain.WorkspaceRequest{Name: "  "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace, err := newTestWorkspaceService(t).CreateWorkspace(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && workspace.Provider.Provider != tt.wantProvider {
+				t.Errorf("provider = %q, want %q", workspace.Provider.Provider, tt.wantProvider)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+// Workspace represents a team sharing the deployment with its own AI provider settings.
+type Workspace struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	OrganizationID string         `json:"organization_id,omitempty"` // Organization the workspace is grouped under
+	Provider       ProviderConfig `json:"provider"`
+}
+
+// ProviderConfig holds the AI provider settings of a workspace. The API key is stored encrypted.
+type ProviderConfig struct {
+	Provider        string `json:"provider"` // "openai", "gemini", "claude" or "ollama"
+	Organization    string `json:"organization,omitempty"`
+	BaseURL         string `json:"base_url,omitempty"` // Server of self-hosted providers, e.g. Ollama
+	DefaultModel    string `json:"default_model"`
+	EncryptedAPIKey string `json:"encrypted_api_key,omitempty"`
+}
+
+// ResolvedProvider is the decrypted provider configuration used to build an AI client.
+type ResolvedProvider struct {
+	Provider     string
+	Organization string
+	BaseURL      string
+	Model        string
+	APIKey       string
+}
+
+// WorkspaceRequest is the request structure for creating or updating a workspace. Fields left empty on
+// update keep their current value.
+type WorkspaceRequest struct {
+	Name         string `json:"name"`
+	Provider     string `json:"provider"`
+	Organization string `json:"organization,omitempty"`
+	BaseURL      string `json:"base_url,omitempty"`
+	DefaultModel string `json:"default_model"`
+	APIKey       string `json:"api_key,omitempty"` // Leave empty on update to keep the current key
+}
+
+// WorkspaceResponse is the API view of a workspace; the API key is never returned.
+type WorkspaceResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Provider       string `json:"provider"`
+	Organization   string `json:"organization,omitempty"`
+	BaseURL        string `json:"base_url,omitempty"`
+	DefaultModel   string `json:"default_model"`
+	HasAPIKey      bool   `json:"has_api_key"`
+}
+
+// ToResponse converts a workspace to its API view.
+func (w *Workspace) ToResponse() WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:             w.ID,
+		Name:           w.Name,
+		OrganizationID: w.OrganizationID,
+		Provider:       w.Provider.Provider,
+		Organization:   w.Provider.Organization,
+		BaseURL:        w.Provider.BaseURL,
+		DefaultModel:   w.Provider.DefaultModel,
+		HasAPIKey:      w.Provider.EncryptedAPIKey != "",
+	}
+}
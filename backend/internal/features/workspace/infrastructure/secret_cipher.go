@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// SecretCipher encrypts and decrypts secrets such as provider API keys.
+type SecretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// aesGCMCipher is an AES-256-GCM implementation of SecretCipher.
+type aesGCMCipher struct {
+	key []byte
+}
+
+// NewSecretCipher creates a cipher whose key is derived from the given passphrase.
+// An empty passphrase yields a cipher that refuses to encrypt or decrypt.
+func NewSecretCipher(passphrase string) SecretCipher {
+	if passphrase == "" {
+		return &aesGCMCipher{}
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	return &aesGCMCipher{key: key[:]}
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext.
+func (c *aesGCMCipher) Encrypt(plaintext string) (string, error) {
+	gcm, err := c.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt.
+func (c *aesGCMCipher) Decrypt(ciphertext string) (string, error) {
+	gcm, err := c.gcm()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("secret is too short")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func (c *aesGCMCipher) gcm() (cipher.AEAD, error) {
+	if c.key == nil {
+		return nil, fmt.Errorf("WORKSPACE_SECRET_KEY environment variable not set")
+	}
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	"sofa-commander/backend/internal/features/workspace/domain"
+	"sofa-commander/backend/internal/jsonfile"
+)
+
+// WorkspaceRepository defines the interface for workspace persistence.
+type WorkspaceRepository interface {
+	List() ([]domain.Workspace, error)
+	Get(id string) (*domain.Workspace, error)
+	Save(workspace *domain.Workspace) error
+	Delete(id string) error
+}
+
+// jsonWorkspaceRepository stores workspaces in a JSON file.
+type jsonWorkspaceRepository struct {
+	file *jsonfile.Store[[]domain.Workspace]
+	mu   sync.Mutex
+}
+
+// NewJSONWorkspaceRepository creates a new repository backed by the given JSON file.
+func NewJSONWorkspaceRepository(path string) WorkspaceRepository {
+	return &jsonWorkspaceRepository{file: jsonfile.NewStore[[]domain.Workspace](path, "workspaces")}
+}
+
+// List returns all workspaces.
+func (r *jsonWorkspaceRepository) List() ([]domain.Workspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Load()
+}
+
+// Get returns the workspace with the given ID.
+func (r *jsonWorkspaceRepository) Get(id string) (*domain.Workspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspaces, err := r.file.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range workspaces {
+		if workspaces[i].ID == id {
+			return &workspaces[i], nil
+		}
+	}
+	return nil, fmt.Errorf("workspace %s not found", id)
+}
+
+// Save creates or replaces a workspace.
+func (r *jsonWorkspaceRepository) Save(workspace *domain.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspaces, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range workspaces {
+		if workspaces[i].ID == workspace.ID {
+			workspaces[i] = *workspace
+			replaced = true
+		}
+	}
+	if !replaced {
+		workspaces = append(workspaces, *workspace)
+	}
+	return r.file.Store(workspaces)
+}
+
+// Delete removes a workspace.
+func (r *jsonWorkspaceRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workspaces, err := r.file.Load()
+	if err != nil {
+		return err
+	}
+	for i := range workspaces {
+		if workspaces[i].ID == id {
+			return r.file.Store(append(workspaces[:i], workspaces[i+1:]...))
+		}
+	}
+	return fmt.Errorf("workspace %s not found", id)
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"sofa-commander/backend/internal/features/workspace/application"
+	"sofa-commander/backend/internal/features/workspace/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler holds the workspace service and the admin token required to change workspaces.
+type WorkspaceHandler struct {
+	workspaceService application.WorkspaceService
+	adminToken       string
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler. Without an admin token, workspaces can only be read:
+// their provider settings decide where the stored API key is sent.
+func NewWorkspaceHandler(workspaceService application.WorkspaceService, adminToken string) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		adminToken:       adminToken,
+	}
+}
+
+func (h *WorkspaceHandler) authorized(c *gin.Context) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(h.adminToken)) == 1
+}
+
+// ListWorkspacesHandler handles listing all workspaces.
+func (h *WorkspaceHandler) ListWorkspacesHandler(c *gin.Context) {
+	workspaces, err := h.workspaceService.ListWorkspaces()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list workspaces: " + err.Error()})
+		return
+	}
+	resp := make([]domain.WorkspaceResponse, 0, len(workspaces))
+	for i := range workspaces {
+		resp = append(resp, workspaces[i].ToResponse())
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetWorkspaceHandler handles fetching a single workspace.
+func (h *WorkspaceHandler) GetWorkspaceHandler(c *gin.Context) {
+	workspace, err := h.workspaceService.GetWorkspace(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, workspace.ToResponse())
+}
+
+// CreateWorkspaceHandler handles creating a workspace.
+func (h *WorkspaceHandler) CreateWorkspaceHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	workspace, err := h.workspaceService.CreateWorkspace(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workspace: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, workspace.ToResponse())
+}
+
+// UpdateWorkspaceHandler handles updating a workspace.
+func (h *WorkspaceHandler) UpdateWorkspaceHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	workspace, err := h.workspaceService.UpdateWorkspace(c.Param("id"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, workspace.ToResponse())
+}
+
+// DeleteWorkspaceHandler handles deleting a workspace.
+func (h *WorkspaceHandler) DeleteWorkspaceHandler(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+		return
+	}
+	if err := h.workspaceService.DeleteWorkspace(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workspace: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace deleted successfully"})
+}
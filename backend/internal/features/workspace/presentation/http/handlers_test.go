@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sofa-commander/backend/internal/features/workspace/application"
+	"sofa-commander/backend/internal/features/workspace/infrastructure"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWorkspaceChangesRequireAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		adminToken string
+		header     string
+		wantStatus int
+	}{
+		{name: "admin token", adminToken: "admin", header: "admin", wantStatus: http.StatusCreated},
+		{name: "wrong token", adminToken: "admin", header: "guess", wantStatus: http.StatusForbidden},
+		{name: "no token", adminToken: "admin", wantStatus: http.StatusForbidden},
+		{name: "admin token not configured", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := application.NewWorkspaceService(
+				infrastructure.NewJSONWorkspaceRepository(filepath.Join(t.TempDir(), "workspaces.json")),
+				infrastructure.NewSecretCipher("passphrase"),
+			)
+			router := gin.New()
+			router.POST("/api/workspaces", NewWorkspaceHandler(service, tt.adminToken).CreateWorkspaceHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(`{"name":"Payments","provider":"ollama","base_url":"https://attacker.example"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-Admin-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"sofa-commander/backend/internal/jsonfile"
+	"sofa-commander/backend/internal/logging"
+)
+
+// Job statuses.
+const (
+	StatusPending = "pending"
+	StatusFailed  = "failed" // Gave up after MaxAttempts
+)
+
+const (
+	defaultMaxAttempts = 5
+	baseBackoff        = 5 * time.Second
+	pollInterval       = time.Second
+	failedRetention    = 7 * 24 * time.Hour // How long failed jobs are kept for inspection
+)
+
+// Job is a unit of background work persisted until it succeeds.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"run_at"` // Not run before this time, for backoff
+	CreatedAt   time.Time       `json:"created_at"`
+	FailedAt    *time.Time      `json:"failed_at,omitempty"`
+}
+
+// Handler processes the payload of a job; returning an error schedules a retry.
+type Handler func(payload json.RawMessage) error
+
+// Queue is a background job queue with retries.
+type Queue interface {
+	Register(jobType string, handler Handler)
+	Enqueue(jobType string, payload any) error
+	Start()
+}
+
+// fileQueue persists jobs in a JSON file so pending work survives restarts. Jobs are removed once
+// they succeed; jobs that exhaust their attempts are kept with the failed status for inspection, and
+// pruned after failedRetention.
+type fileQueue struct {
+	file     *jsonfile.Store[[]Job]
+	mu       sync.Mutex
+	handlers map[string]Handler
+	running  map[string]bool
+}
+
+// NewFileQueue creates a queue backed by the given JSON file.
+func NewFileQueue(path string) Queue {
+	return &fileQueue{file: jsonfile.NewStore[[]Job](path, "job queue"), handlers: make(map[string]Handler), running: make(map[string]bool)}
+}
+
+// Register sets the handler of a job type. Register handlers before calling Start.
+func (q *fileQueue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue persists a job to be run as soon as possible.
+func (q *fileQueue) Enqueue(jobType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+	now := time.Now()
+	job := Job{
+		ID:          fmt.Sprintf("job-%d", now.UnixNano()),
+		Type:        jobType,
+		Payload:     data,
+		Status:      StatusPending,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, err := q.file.Load()
+	if err != nil {
+		return err
+	}
+	return q.file.Store(append(jobs, job))
+}
+
+// Start runs due jobs in the background until the process exits.
+func (q *fileQueue) Start() {
+	go func() {
+		for {
+			q.runDue()
+			time.Sleep(pollInterval)
+		}
+	}()
+}
+
+// runDue starts every pending job whose time has come and that is not already running.
+func (q *fileQueue) runDue() {
+	q.mu.Lock()
+	jobs, err := q.file.Load()
+	if err != nil {
+		q.mu.Unlock()
+		logging.Default().Error().Err(err).Msg("Failed to load job queue")
+		return
+	}
+	now := time.Now()
+	if live := pruneFailed(jobs, now); len(live) < len(jobs) {
+		jobs = live
+		if err := q.file.Store(jobs); err != nil {
+			logging.Default().Error().Err(err).Msg("Failed to store job queue")
+		}
+	}
+	var due []Job
+	for _, job := range jobs {
+		if job.Status == StatusPending && !job.RunAt.After(now) && !q.running[job.ID] {
+			q.running[job.ID] = true
+			due = append(due, job)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, job := range due {
+		go q.run(job)
+	}
+}
+
+func (q *fileQueue) run(job Job) {
+	q.mu.Lock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.Unlock()
+
+	var runErr error
+	if !ok {
+		runErr = fmt.Errorf("no handler registered for job type %s", job.Type)
+	} else {
+		runErr = safeRun(handler, job.Payload)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.running, job.ID)
+	jobs, err := q.file.Load()
+	if err != nil {
+		logging.Default().Error().Err(err).Msg("Failed to load job queue")
+		return
+	}
+	for i := range jobs {
+		if jobs[i].ID != job.ID {
+			continue
+		}
+		if runErr == nil {
+			jobs = append(jobs[:i], jobs[i+1:]...)
+			break
+		}
+		jobs[i].Attempts++
+		jobs[i].LastError = runErr.Error()
+		if jobs[i].Attempts >= jobs[i].MaxAttempts {
+			now := time.Now()
+			jobs[i].Status = StatusFailed
+			jobs[i].FailedAt = &now
+			logging.Default().Error().Err(runErr).Str("job_id", job.ID).Str("job_type", job.Type).Int("attempts", jobs[i].Attempts).Msg("Job failed")
+		} else {
+			jobs[i].RunAt = time.Now().Add(baseBackoff << (jobs[i].Attempts - 1))
+			logging.Default().Warn().Err(runErr).Str("job_id", job.ID).Str("job_type", job.Type).Time("retry_at", jobs[i].RunAt).Msg("Job failed, retrying")
+		}
+		break
+	}
+	if err := q.file.Store(jobs); err != nil {
+		logging.Default().Error().Err(err).Msg("Failed to store job queue")
+	}
+}
+
+// pruneFailed returns the jobs without the failed ones kept longer than failedRetention.
+func pruneFailed(jobs []Job, now time.Time) []Job {
+	live := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		failedAt := job.FailedAt
+		if failedAt == nil {
+			failedAt = &job.RunAt // Failed before failure times were recorded
+		}
+		if job.Status == StatusFailed && now.Sub(*failedAt) > failedRetention {
+			continue
+		}
+		live = append(live, job)
+	}
+	return live
+}
+
+// safeRun runs a handler, turning a panic into an error so the job is retried.
+func safeRun(handler Handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+	return handler(payload)
+}
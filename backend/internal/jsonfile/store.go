@@ -0,0 +1,78 @@
+// Package jsonfile persists a value, typically the list of a repository's entities, as a JSON file.
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"sofa-commander/backend/internal/quiesce"
+)
+
+// Store keeps a value of type T in a JSON file. It does not serialize its callers, which hold their own
+// lock across a load, change and store.
+type Store[T any] struct {
+	path string
+	name string // What the file holds, e.g. "backlogs", for error messages
+}
+
+// NewStore creates a store backed by the given JSON file, holding what name describes.
+func NewStore[T any](path, name string) *Store[T] {
+	return &Store[T]{path: path, name: name}
+}
+
+// Load reads the value. Without a file yet, it is empty: an empty slice or map rather than nil, so it
+// is listed as [] or {}.
+func (s *Store[T]) Load() (T, error) {
+	var value T
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return empty[T](), nil
+	}
+	if err != nil {
+		return value, fmt.Errorf("failed to read %s file %s: %w", s.name, s.path, err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to unmarshal %s from %s: %w", s.name, s.path, err)
+	}
+	if reflect.ValueOf(&value).Elem().IsZero() {
+		return empty[T](), nil
+	}
+	return value, nil
+}
+
+// Store writes the value, through a temporary file so a crash mid-write never loses the previous one.
+// It waits while the stores are paused for a backup.
+func (s *Store[T]) Store(value T) error {
+	defer quiesce.Hold()()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", s.name, err)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", s.name, err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s to file %s: %w", s.name, tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace %s file %s: %w", s.name, s.path, err)
+	}
+	return nil
+}
+
+// empty returns the empty value of T, allocating slices and maps.
+func empty[T any]() T {
+	var value T
+	v := reflect.ValueOf(&value).Elem()
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	return value
+}
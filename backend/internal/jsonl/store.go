@@ -0,0 +1,110 @@
+// Package jsonl persists records as JSON Lines files, one JSON document per line.
+package jsonl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sofa-commander/backend/internal/quiesce"
+)
+
+// Store appends records of type T to a JSON Lines file.
+type Store[T any] struct {
+	path string
+	name string // What a record is, e.g. "score", for error messages
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the given JSON Lines file, holding records described by name.
+func NewStore[T any](path, name string) *Store[T] {
+	return &Store[T]{path: path, name: name}
+}
+
+// Append writes a single record. It waits while the stores are paused for a backup.
+func (s *Store[T]) Append(record T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer quiesce.Hold()()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", s.name, err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s file %s: %w", s.name, s.path, err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", s.name, err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// List returns the records keep accepts, or all of them when keep is nil, oldest first.
+func (s *Store[T]) List(keep func(record T) bool) ([]T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return []T{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s file %s: %w", s.name, s.path, err)
+	}
+	defer f.Close()
+
+	// Decoded as a stream, as records easily exceed a scanner's line limit
+	result := []T{}
+	decoder := json.NewDecoder(f)
+	for {
+		var record T
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s file %s: %w", s.name, s.path, err)
+		}
+		if keep == nil || keep(record) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// Rewrite replaces the records of the file, through a temporary file so it is never left half written.
+// It waits while the stores are paused for a backup.
+func (s *Store[T]) Rewrite(records []T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer quiesce.Hold()()
+
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", s.name, err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", s.name, err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s file %s: %w", s.name, tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace %s file %s: %w", s.name, s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the caller when it sets one.
+const RequestIDHeader = "X-Request-ID"
+
+// levelTags map the level tags of std log lines, e.g. "[WARN] ...", to their levels.
+var levelTags = []struct {
+	tag   string
+	level zerolog.Level
+}{
+	{"[DEBUG]", zerolog.DebugLevel},
+	{"[INFO]", zerolog.InfoLevel},
+	{"[WARN]", zerolog.WarnLevel},
+	{"[ERROR]", zerolog.ErrorLevel},
+}
+
+var (
+	logger  = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	prompts atomic.Bool
+)
+
+func init() {
+	zerolog.DefaultContextLogger = &logger
+}
+
+// Init configures the logger from the env: LOG_LEVEL ("debug", "info", "warn" or "error", "info" by
+// default), LOG_FORMAT ("json" for log aggregation, human-readable otherwise) and LOG_PROMPTS ("true" to
+// log full prompts and AI outputs at debug level; they contain business data). Lines of the std log
+// package are logged through it too, at the level of their tag.
+func Init() {
+	level, err := zerolog.ParseLevel(strings.ToLower(os.Getenv("LOG_LEVEL")))
+	if err != nil || level == zerolog.NoLevel {
+		level = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(level)
+	if os.Getenv("LOG_FORMAT") == "json" {
+		logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	} else {
+		logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.DateTime}).With().Timestamp().Logger()
+	}
+	prompts.Store(os.Getenv("LOG_PROMPTS") == "true")
+
+	log.SetFlags(0)
+	log.SetOutput(stdWriter{})
+}
+
+// Default returns the logger of code without a request context.
+func Default() *zerolog.Logger {
+	return &logger
+}
+
+// From returns the logger of a context, carrying the IDs added with Middleware and With.
+func From(ctx context.Context) *zerolog.Logger {
+	return zerolog.Ctx(ctx)
+}
+
+// With returns a context whose logger adds the given key-value pairs, e.g. "session_id" and
+// "thread_id", to every line.
+func With(ctx context.Context, keyValues ...string) context.Context {
+	fields := From(ctx).With()
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if keyValues[i+1] != "" {
+			fields = fields.Str(keyValues[i], keyValues[i+1])
+		}
+	}
+	l := fields.Logger()
+	return l.WithContext(ctx)
+}
+
+// Prompts reports whether full prompts and AI outputs may be logged.
+func Prompts() bool {
+	return prompts.Load()
+}
+
+// Middleware gives every request an ID, echoed in the X-Request-ID header, and a logger adding it and
+// the request's trace ID to every line logged with the request's context.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		ctx := With(c.Request.Context(), "request_id", requestID)
+		if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+			ctx = With(ctx, "trace_id", span.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random request ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// stdWriter logs the lines of the std log package.
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	level := zerolog.InfoLevel
+	for _, t := range levelTags {
+		if strings.HasPrefix(msg, t.tag) {
+			level = t.level
+			msg = strings.TrimSpace(strings.TrimPrefix(msg, t.tag))
+			break
+		}
+	}
+	logger.WithLevel(level).Msg(msg)
+	return len(p), nil
+}
@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain-text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPConfig configures an SMTP mailer.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// smtpMailer sends email through an SMTP server using PLAIN auth.
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer creates a new SMTP mailer.
+func NewSMTPMailer(cfg SMTPConfig) (Mailer, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, fmt.Errorf("smtp host and from address must be configured")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &smtpMailer{cfg: cfg}, nil
+}
+
+// Send sends a UTF-8 plain-text email.
+func (m *smtpMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.cfg.From + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"sofa-commander/backend/internal/logging"
+)
+
+// migrationFile matches the names of migration files: the version, then a description, e.g.
+// "0002_index_sessions_created_at.sql".
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// postgresLockID identifies the advisory lock held while migrating a PostgreSQL database, so instances
+// starting together, e.g. during a blue/green deploy, apply each migration once.
+const postgresLockID = 4_675_300_918
+
+// Migration is a versioned change to a database schema.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Load returns the migrations in dir of fsys, ordered by version. Versions must be unique.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var migrations []Migration
+	for _, entry := range entries {
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: m[2], SQL: string(data)})
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migration version %d is defined twice", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Up applies the migrations newer than the schema version of a database, each in a transaction
+// recording its version in the schema_migrations table, and returns the applied ones. driver is
+// "sqlite" or "postgres". A database migrated by a newer release is left as is with a warning, so a
+// rollback to this release can still start: migrations only make backward-compatible changes.
+func Up(db *sql.DB, driver string, migrations []Migration) ([]Migration, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	insert := `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+	if driver == "postgres" {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresLockID); err != nil {
+			return nil, fmt.Errorf("failed to lock database for migrations: %w", err)
+		}
+		defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, postgresLockID)
+		insert = `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`
+	}
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if latest := Latest(migrations); current > latest {
+		logging.Default().Warn().Int("schema_version", current).Int("latest_known", latest).Msg("Database schema is newer than this release, starting without migrating")
+		return nil, nil
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+		}
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			tx.Rollback()
+			return applied, fmt.Errorf("failed to apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, insert, m.Version, m.Name, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return applied, fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return applied, fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Latest returns the version of the newest migration, 0 without migrations.
+func Latest(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        fstest.MapFS
+		wantVersions []int
+		wantErr      bool
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"migrations/0010_add_index.sql":      {Data: []byte("CREATE INDEX i ON t (a);")},
+				"migrations/0002_create_table.sql":   {Data: []byte("CREATE TABLE t (a TEXT);")},
+				"migrations/0001_initial.sql":        {Data: []byte("SELECT 1;")},
+				"migrations/README.md":               {Data: []byte("not a migration")},
+				"migrations/0003_nested/ignored.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantVersions: []int{1, 2, 10},
+		},
+		{
+			name:  "empty directory",
+			files: fstest.MapFS{"migrations/README.md": {Data: []byte("")}},
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"migrations/0001_initial.sql": {Data: []byte("SELECT 1;")},
+				"migrations/1_again.sql":      {Data: []byte("SELECT 1;")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing directory",
+			files:   fstest.MapFS{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files, "migrations")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(migrations) != len(tt.wantVersions) {
+				t.Fatalf("Load() = %d migrations, want %d", len(migrations), len(tt.wantVersions))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.wantVersions[i] {
+					t.Errorf("migration %d has version %d, want %d", i, m.Version, tt.wantVersions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUp(t *testing.T) {
+	first := []Migration{
+		{Version: 1, Name: "create_items", SQL: "CREATE TABLE items (id TEXT PRIMARY KEY);"},
+		{Version: 2, Name: "add_title", SQL: "ALTER TABLE items ADD COLUMN title TEXT;"},
+	}
+	tests := []struct {
+		name        string
+		applied     []Migration // Migrations applied before the tested run
+		migrations  []Migration
+		wantApplied []int
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			name:        "empty database",
+			migrations:  first,
+			wantApplied: []int{1, 2},
+			wantVersion: 2,
+		},
+		{
+			name:        "up to date",
+			applied:     first,
+			migrations:  first,
+			wantVersion: 2,
+		},
+		{
+			name:        "new migration",
+			applied:     first[:1],
+			migrations:  first,
+			wantApplied: []int{2},
+			wantVersion: 2,
+		},
+		{
+			name:        "schema of a newer release",
+			applied:     first,
+			migrations:  first[:1],
+			wantVersion: 2,
+		},
+		{
+			name:    "failing migration",
+			applied: first,
+			migrations: append(first[:2:2],
+				Migration{Version: 3, Name: "broken", SQL: "ALTER TABLE missing ADD COLUMN x TEXT;"}),
+			wantVersion: 2,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			defer db.Close()
+			if len(tt.applied) > 0 {
+				if _, err := Up(db, "sqlite", tt.applied); err != nil {
+					t.Fatalf("failed to prepare database: %v", err)
+				}
+			}
+
+			applied, err := Up(db, "sqlite", tt.migrations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Up() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(applied) != len(tt.wantApplied) {
+				t.Fatalf("Up() applied %d migrations, want %d", len(applied), len(tt.wantApplied))
+			}
+			for i, m := range applied {
+				if m.Version != tt.wantApplied[i] {
+					t.Errorf("applied migration %d has version %d, want %d", i, m.Version, tt.wantApplied[i])
+				}
+			}
+			var version int
+			if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+				t.Fatalf("failed to read schema version: %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("schema version = %d, want %d", version, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package offline
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrOffline is returned by AI operations while the backend is in offline mode.
+var ErrOffline = errors.New("AI operations are disabled in offline mode")
+
+var (
+	forced atomic.Bool
+	source atomic.Pointer[func() bool]
+)
+
+// Force turns offline mode on regardless of the configured source, e.g. from the OFFLINE_MODE env var.
+func Force(on bool) {
+	forced.Store(on)
+}
+
+// SetSource sets the function consulted on every check, so offline mode can be switched at runtime.
+func SetSource(fn func() bool) {
+	source.Store(&fn)
+}
+
+// Enabled reports whether the backend is in offline mode: sessions can be viewed, searched and
+// exported, but no AI provider is called.
+func Enabled() bool {
+	if forced.Load() {
+		return true
+	}
+	if fn := source.Load(); fn != nil {
+		return (*fn)()
+	}
+	return false
+}
+
+// Check returns ErrOffline in offline mode.
+func Check() error {
+	if Enabled() {
+		return ErrOffline
+	}
+	return nil
+}
+
+// Middleware rejects requests to AI endpoints with 503 Service Unavailable in offline mode.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if Enabled() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": ErrOffline.Error(), "offline": true})
+			return
+		}
+		c.Next()
+	}
+}
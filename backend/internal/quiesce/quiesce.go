@@ -0,0 +1,22 @@
+// Package quiesce pauses the writes of the stores, so they can be read as one consistent snapshot.
+package quiesce
+
+import "sync"
+
+// gate is held for reading by every store write in flight, and for writing while the stores are paused.
+var gate sync.RWMutex
+
+// Pause waits for the store writes in flight to finish and holds new ones until the returned function
+// is called, so the stores can be read as one consistent snapshot. Requests and background work keep
+// running until they write. Writing to a store while paused deadlocks.
+func Pause() func() {
+	gate.Lock()
+	return gate.Unlock
+}
+
+// Hold waits while the stores are paused, and keeps them from being paused until the returned function
+// is called. Stores hold it around each write, so a snapshot never sees a write half done.
+func Hold() func() {
+	gate.RLock()
+	return gate.RUnlock
+}
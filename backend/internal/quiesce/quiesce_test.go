@@ -0,0 +1,39 @@
+package quiesce
+
+import (
+	"testing"
+	"time"
+)
+
+// returned waits a little for a function started in the background to return.
+func returned(done <-chan func()) (func(), bool) {
+	select {
+	case fn := <-done:
+		return fn, true
+	case <-time.After(100 * time.Millisecond):
+		return nil, false
+	}
+}
+
+func TestPause(t *testing.T) {
+	release := Hold()
+	paused := make(chan func(), 1)
+	go func() { paused <- Pause() }()
+	if _, ok := returned(paused); ok {
+		t.Fatal("Pause() returned while a write was in flight")
+	}
+	release()
+	resume := <-paused
+
+	held := make(chan func(), 1)
+	go func() { held <- Hold() }()
+	if _, ok := returned(held); ok {
+		t.Fatal("Hold() returned while paused")
+	}
+	resume()
+	release, ok := returned(held)
+	if !ok {
+		t.Fatal("Hold() did not return once resumed")
+	}
+	release()
+}
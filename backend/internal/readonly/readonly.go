@@ -0,0 +1,41 @@
+package readonly
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowedMethods are the methods a read-only instance still serves.
+const allowedMethods = "GET, HEAD, OPTIONS"
+
+var enabled atomic.Bool
+
+// Enable puts the instance in read-only mode, e.g. from the READ_ONLY env var. Read-only instances
+// share the store of the interactive instances and serve reporting and search traffic only.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether the instance is in read-only mode.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Middleware rejects every mutating request with 405 Method Not Allowed in read-only mode.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			c.Header("Allow", allowedMethods)
+			c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"error": "This instance is read-only", "read_only": true})
+		}
+	}
+}
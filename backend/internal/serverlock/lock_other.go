@@ -0,0 +1,10 @@
+//go:build !unix
+
+package serverlock
+
+import "os"
+
+// lock does not lock the file on platforms without flock; the server runs on Linux in production.
+func lock(f *os.File) error {
+	return nil
+}
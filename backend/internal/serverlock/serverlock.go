@@ -0,0 +1,34 @@
+// Package serverlock tells maintenance commands whether the server is running, through a lock file the
+// server holds while it runs. The lock is released by the operating system when the server exits, so
+// a crashed server never leaves it behind.
+package serverlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Path is the lock file, in the data directory so commands run in another container see it too.
+const Path = "data/server.lock"
+
+// ErrRunning is returned when the lock is held by a running server.
+var ErrRunning = errors.New("the server is running")
+
+// Acquire takes the lock at path and returns the function releasing it. It fails with ErrRunning while
+// another process holds it.
+func Acquire(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() { f.Close() }, nil
+}
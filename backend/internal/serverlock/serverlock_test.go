@@ -0,0 +1,26 @@
+//go:build unix
+
+package serverlock
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "server.lock")
+	release, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire(path); !errors.Is(err, ErrRunning) {
+		t.Fatalf("Acquire() while held, error = %v, want ErrRunning", err)
+	}
+	release()
+	release, err = Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() once released, error = %v", err)
+	}
+	release()
+}
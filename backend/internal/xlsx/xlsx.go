@@ -0,0 +1,103 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ContentType is the media type of XLSX workbooks.
+const ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// parts are the fixed parts of a single-sheet workbook, besides the sheet itself.
+var parts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	// Style 1 is the bold header row
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="1"><fill><patternFill patternType="none"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="2"><xf/><xf fontId="1" applyFont="1"/></cellXfs></styleSheet>`},
+}
+
+// Write writes a workbook of a single sheet holding a bold header row and the rows. Cells may be
+// strings, ints or float64s; other values are written as their fmt representation.
+func Write(w io.Writer, sheet string, header []string, rows [][]any) error {
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		if err := writePart(zw, part.name, []byte(part.body)); err != nil {
+			return err
+		}
+	}
+
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&workbook, []byte(sheet))
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	if err := writePart(zw, "xl/workbook.xml", workbook.Bytes()); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	data.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	headerRow := make([]any, len(header))
+	for i, h := range header {
+		headerRow[i] = h
+	}
+	writeRow(&data, 1, headerRow, ` s="1"`)
+	for i, row := range rows {
+		writeRow(&data, i+2, row, "")
+	}
+	data.WriteString(`</sheetData></worksheet>`)
+	if err := writePart(zw, "xl/worksheets/sheet1.xml", data.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeRow writes a row of cells with the given style attribute.
+func writeRow(buf *bytes.Buffer, n int, cells []any, style string) {
+	fmt.Fprintf(buf, `<row r="%d">`, n)
+	for i, cell := range cells {
+		ref := column(i) + strconv.Itoa(n)
+		switch v := cell.(type) {
+		case int:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+		case float64:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			xml.EscapeText(buf, []byte(fmt.Sprint(v)))
+			buf.WriteString(`</t></is></c>`)
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// column returns the letters of a zero-based column index, e.g. "A", "Z", "AA".
+func column(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// writePart adds a part to the workbook package.
+func writePart(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
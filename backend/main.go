@@ -1,14 +1,80 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
+	"strconv"
 
+	"sofa-commander/backend/internal/async"
+	"sofa-commander/backend/internal/chaos"
+	"sofa-commander/backend/internal/compress"
 	"sofa-commander/backend/internal/config"
+	"sofa-commander/backend/internal/events"
+	agenttools_app "sofa-commander/backend/internal/features/agenttools/application"
+	approval_app "sofa-commander/backend/internal/features/approval/application"
+	approval_http "sofa-commander/backend/internal/features/approval/presentation/http"
+	auth_app "sofa-commander/backend/internal/features/auth/application"
+	auth_http "sofa-commander/backend/internal/features/auth/presentation/http"
+	backlog_app "sofa-commander/backend/internal/features/backlog/application"
+	backlog_infra "sofa-commander/backend/internal/features/backlog/infrastructure"
+	backlog_http "sofa-commander/backend/internal/features/backlog/presentation/http"
+	backup_app "sofa-commander/backend/internal/features/backup/application"
+	backup_http "sofa-commander/backend/internal/features/backup/presentation/http"
 	config_http "sofa-commander/backend/internal/features/config/presentation/http"
+	email_app "sofa-commander/backend/internal/features/email/application"
+	email_http "sofa-commander/backend/internal/features/email/presentation/http"
+	export_app "sofa-commander/backend/internal/features/export/application"
+	export_http "sofa-commander/backend/internal/features/export/presentation/http"
+	gitlab_app "sofa-commander/backend/internal/features/gitlab/application"
+	gitlab_http "sofa-commander/backend/internal/features/gitlab/presentation/http"
+	jira_app "sofa-commander/backend/internal/features/jira/application"
+	jira_http "sofa-commander/backend/internal/features/jira/presentation/http"
+	marketplace_app "sofa-commander/backend/internal/features/marketplace/application"
+	marketplace_infra "sofa-commander/backend/internal/features/marketplace/infrastructure"
+	marketplace_http "sofa-commander/backend/internal/features/marketplace/presentation/http"
+	mcp_app "sofa-commander/backend/internal/features/mcp/application"
+	mcp_http "sofa-commander/backend/internal/features/mcp/presentation/http"
+	notification_app "sofa-commander/backend/internal/features/notification/application"
+	notification_infra "sofa-commander/backend/internal/features/notification/infrastructure"
+	notification_http "sofa-commander/backend/internal/features/notification/presentation/http"
+	organization_app "sofa-commander/backend/internal/features/organization/application"
+	organization_infra "sofa-commander/backend/internal/features/organization/infrastructure"
+	organization_http "sofa-commander/backend/internal/features/organization/presentation/http"
+	project_app "sofa-commander/backend/internal/features/project/application"
+	project_infra "sofa-commander/backend/internal/features/project/infrastructure"
+	project_http "sofa-commander/backend/internal/features/project/presentation/http"
 	"sofa-commander/backend/internal/features/refinement/application"
 	"sofa-commander/backend/internal/features/refinement/infrastructure"
 	refinement_http "sofa-commander/backend/internal/features/refinement/presentation/http"
+	report_app "sofa-commander/backend/internal/features/report/application"
+	report_http "sofa-commander/backend/internal/features/report/presentation/http"
+	retrospective_app "sofa-commander/backend/internal/features/retrospective/application"
+	retrospective_infra "sofa-commander/backend/internal/features/retrospective/infrastructure"
+	retrospective_http "sofa-commander/backend/internal/features/retrospective/presentation/http"
+	scoring_app "sofa-commander/backend/internal/features/scoring/application"
+	scoring_infra "sofa-commander/backend/internal/features/scoring/infrastructure"
+	scoring_http "sofa-commander/backend/internal/features/scoring/presentation/http"
+	telemetry_app "sofa-commander/backend/internal/features/telemetry/application"
+	telemetry_http "sofa-commander/backend/internal/features/telemetry/presentation/http"
+	usage_app "sofa-commander/backend/internal/features/usage/application"
+	usage_infra "sofa-commander/backend/internal/features/usage/infrastructure"
+	usage_http "sofa-commander/backend/internal/features/usage/presentation/http"
+	user_app "sofa-commander/backend/internal/features/user/application"
+	user_infra "sofa-commander/backend/internal/features/user/infrastructure"
+	user_http "sofa-commander/backend/internal/features/user/presentation/http"
+	workload_app "sofa-commander/backend/internal/features/workload/application"
+	workload_http "sofa-commander/backend/internal/features/workload/presentation/http"
+	workspace_app "sofa-commander/backend/internal/features/workspace/application"
+	workspace_infra "sofa-commander/backend/internal/features/workspace/infrastructure"
+	workspace_http "sofa-commander/backend/internal/features/workspace/presentation/http"
+	"sofa-commander/backend/internal/jobs"
+	"sofa-commander/backend/internal/logging"
+	"sofa-commander/backend/internal/offline"
+	"sofa-commander/backend/internal/quiesce"
+	"sofa-commander/backend/internal/readonly"
+	"sofa-commander/backend/internal/serverlock"
+	"sofa-commander/backend/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -17,45 +83,416 @@ import (
 func main() {
 	// Load .env file
 	err := godotenv.Load()
+	logging.Init() // After loading .env, which may set LOG_LEVEL, LOG_FORMAT and LOG_PROMPTS
 	if err != nil {
-		log.Println("No .env file found, using environment variables")
+		logging.Default().Info().Msg("No .env file found, using environment variables")
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	// Held while the server runs, so restoring a backup refuses to run alongside it
+	if release, err := serverlock.Acquire(serverlock.Path); err != nil {
+		logging.Default().Warn().Err(err).Str("path", serverlock.Path).Msg("Failed to take the server lock")
+	} else {
+		defer release()
+	}
+
+	// Read-only replicas serve dashboards and search against the shared store; every mutating
+	// endpoint returns 405 so they never compete with interactive refinement traffic.
+	readonly.Enable(os.Getenv("READ_ONLY") == "true")
+
+	// Staging only: requests can ask for AI latency, rate limits or malformed responses with the
+	// X-Chaos-* headers, to verify retries and timeouts end-to-end.
+	chaos.Enable(os.Getenv("CHAOS_TESTING") == "true")
+	if chaos.Enabled() {
+		logging.Default().Warn().Msg("Chaos testing enabled, requests can inject AI faults with X-Chaos-* headers")
 	}
 
+	// Spans of requests, refinement operations and provider calls are exported over OTLP/HTTP when
+	// OTEL_EXPORTER_OTLP_ENDPOINT is set; the other OTEL_* env vars configure the exporter and sampler.
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		logging.Default().Warn().Err(err).Msg("Tracing disabled")
+	}
+	defer shutdownTracing(context.Background())
+
 	r := gin.Default()
+	r.Use(tracing.Middleware())
+	r.Use(logging.Middleware()) // After tracing, so lines carry the request's trace ID
+	r.Use(compress.Middleware())
+	r.Use(readonly.Middleware())
+	r.Use(chaos.Middleware())
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"message": "pong",
+			"message":   "pong",
+			"offline":   offline.Enabled(),
+			"read_only": readonly.Enabled(),
 		})
 	})
 
-	// Initialize OpenAI client
-	openaiClient, err := infrastructure.NewOpenAIClient()
+	// Offline mode keeps sessions viewable and exportable while AI operations are disabled. OFFLINE_MODE
+	// forces it; otherwise it follows the offline_mode app config setting.
+	appConfigService := config.NewAppConfigService("config/app_config.json", "data/app_config_versions.jsonl")
+	offline.Force(os.Getenv("OFFLINE_MODE") == "true")
+	offline.SetSource(func() bool {
+		appConfig, err := appConfigService.LoadAppConfig()
+		return err == nil && appConfig.OfflineMode
+	})
+
+	// Initialize the default AI client: OpenAI, or the provider named by AI_PROVIDER ("gemini", "claude"
+	// or "ollama") configured by AI_API_KEY, AI_MODEL and AI_BASE_URL
+	transcriptHub := infrastructure.NewTranscriptHub()
+	clientFactory := infrastructure.NewOpenAIClientFactory(transcriptHub)
+	var openaiClient infrastructure.OpenAIClient
+	if provider := os.Getenv("AI_PROVIDER"); provider != "" && provider != "openai" {
+		openaiClient, err = clientFactory.CreateOpenAIClient(infrastructure.AIConfig{
+			Provider: provider,
+			APIKey:   os.Getenv("AI_API_KEY"),
+			Model:    os.Getenv("AI_MODEL"),
+			Options:  map[string]string{"base_url": os.Getenv("AI_BASE_URL")},
+		})
+	} else if openaiClient, err = infrastructure.NewOpenAIClient(); err == nil {
+		openaiClient = infrastructure.NewTracingClient(infrastructure.NewMirroringClient(openaiClient, transcriptHub))
+	}
 	if err != nil {
-		log.Fatalf("Failed to create OpenAI client: %v", err)
+		if !offline.Enabled() {
+			logging.Default().Fatal().Err(err).Msg("Failed to create AI client")
+		}
+		logging.Default().Warn().Err(err).Msg("Starting in offline mode without an AI client")
 	}
 
 	// Initialize services
-	refinementService := application.NewRefinementService(openaiClient)
-	appConfigService := config.NewAppConfigService("config/app_config.json")
+	eventBus := events.NewBus()
+	eventBus.Subscribe("*", events.NewLog("data/events.jsonl").HandleEvent)
+	// Usage is counted for the opt-in telemetry, which only sends it once enabled in the app config
+	telemetryService := telemetry_app.NewTelemetryService(appConfigService, "data/telemetry_instance_id")
+	eventBus.Subscribe("*", telemetryService.HandleEvent)
+	r.Use(telemetry_http.Middleware(telemetryService))
+	telemetryService.Start()
+	projectService := project_app.NewProjectService(project_infra.NewJSONProjectRepository("config/projects.json"))
+	workspaceService := workspace_app.NewWorkspaceService(
+		workspace_infra.NewJSONWorkspaceRepository("config/workspaces.json"),
+		workspace_infra.NewSecretCipher(os.Getenv("WORKSPACE_SECRET_KEY")),
+	)
+	userService := user_app.NewUserService(user_infra.NewJSONUserRepository("data/users.json"), workspaceService)
+
+	// API keys and JWT bearer tokens authenticate the API once enabled in the auth app config; users
+	// deactivated over SCIM are rejected. Hooks, MCP and admin routes check their own tokens.
+	authService := auth_app.NewAuthService(appConfigService, userService)
+	r.Use(auth_http.Middleware(authService, "/api/hooks/", "/api/mcp", "/api/admin/"))
+
+	usageService := usage_app.NewUsageService(usage_infra.NewJSONLAttributionStore("data/usage_attributions.jsonl"))
+	// SESSION_STORE selects "sqlite" (default, file at SESSION_STORE_DSN or data/sessions.db) or "postgres"
+	sessionRepository, err := infrastructure.NewSessionRepository(os.Getenv("SESSION_STORE"), os.Getenv("SESSION_STORE_DSN"))
+	if err != nil {
+		logging.Default().Fatal().Err(err).Msg("Failed to open session store")
+	}
+	refinementService := application.NewTracedService(application.NewRefinementService(openaiClient, clientFactory, workspaceService, userService, projectService, usageService, eventBus, agenttools_app.NewToolExecutor(appConfigService), infrastructure.NewJSONMemoryStore("data/product_memory.json"), infrastructure.NewJSONQuestionBankStore("data/question_bank.json"), infrastructure.NewJSONLShadowRunStore("data/shadow_runs.jsonl"), sessionRepository))
+	exportService := export_app.NewExportService(refinementService, appConfigService)
+	jiraService := jira_app.NewJiraService(refinementService, exportService, appConfigService)
+	eventBus.Subscribe(events.SessionFinalized, jiraService.HandleEvent)
+	approvalService := approval_app.NewApprovalService(refinementService, appConfigService, eventBus)
+	eventBus.Subscribe(events.SessionFinalized, approvalService.HandleEvent)
+	scoringService := scoring_app.NewScoringService(refinementService, appConfigService, scoring_infra.NewJSONLScoreStore("data/story_scores.jsonl"))
+	eventBus.Subscribe(events.SessionFinalized, scoringService.HandleEvent)
+	jobQueue := jobs.NewFileQueue("data/jobs.json")
+	notificationService := notification_app.NewNotificationService(notification_infra.NewJSONPreferencesRepository("data/notification_preferences.json"), appConfigService, jobQueue)
+	for _, eventType := range []string{events.SessionFinalized, events.ReviewRequested, events.RunFailed, events.SessionApproved, events.ChangesRequested} {
+		eventBus.Subscribe(eventType, notificationService.HandleEvent)
+	}
+	// EVENT_BROKER forwards every event to "kafka" or "nats" at EVENT_BROKER_URL, on EVENT_BROKER_TOPIC
+	if kind := os.Getenv("EVENT_BROKER"); kind != "" {
+		broker, err := events.NewBroker(kind, os.Getenv("EVENT_BROKER_URL"), os.Getenv("EVENT_BROKER_TOPIC"))
+		if err != nil {
+			logging.Default().Fatal().Err(err).Msg("Failed to create event broker")
+		}
+		eventBus.Subscribe("*", events.NewQueuedPublisher(broker, jobQueue).Publish)
+	}
+	if !readonly.Enabled() {
+		jobQueue.Start()
+	}
+	gitLabService := gitlab_app.NewGitLabService(refinementService, exportService, appConfigService)
+	backlogService := backlog_app.NewBacklogService(backlog_infra.NewJSONBacklogRepository("data/backlogs.json"), refinementService, jiraService, gitLabService)
+	workloadService := workload_app.NewWorkloadService(refinementService, appConfigService)
+	organizationService := organization_app.NewOrganizationService(organization_infra.NewJSONOrganizationRepository("config/organizations.json"), workspaceService, refinementService, usageService, appConfigService)
+	retrospectiveService := retrospective_app.NewRetrospectiveService(refinementService, backlogService, appConfigService, retrospective_infra.NewJSONLReportStore("data/retrospectives.jsonl"))
+	if !readonly.Enabled() {
+		retrospectiveService.Start()
+	}
+	emailService := email_app.NewEmailService(refinementService, appConfigService)
+	backupService := backup_app.NewBackupService(sessionRepository, backupDirs, quiesce.Pause)
+	reportService := report_app.NewReportService(refinementService, usageService, workspaceService, appConfigService)
+
+	// Operations outlasting their request keep running as pending operations or async jobs, shared by
+	// the refinement and backlog routes
+	asyncWorkers, _ := strconv.Atoi(os.Getenv("ASYNC_WORKERS"))
+	asyncRunner := async.NewRunner(appConfigService, asyncWorkers)
 
 	// Refinement API routes
 	refineGroup := r.Group("/api/refine")
 	{
-		handler := refinement_http.NewRefinementHandler(refinementService, appConfigService)
-		refineGroup.POST("/start", handler.StartRefinementHandler)
-		refineGroup.POST("/submit_answers_and_continue", handler.SubmitAnswersAndContinueHandler)
-		refineGroup.POST("/submit_answers_and_get_suggestions", handler.SubmitAnswersAndGetSuggestionsHandler)
-		refineGroup.POST("/accept_suggestions", handler.AcceptSuggestionsHandler)
-		refineGroup.POST("/finalize", handler.FinalizeHandler)
+		handler := refinement_http.NewRefinementHandler(refinementService, appConfigService, asyncRunner)
+		refineGroup.POST("/start", offline.Middleware(), handler.StartRefinementHandler)
+		refineGroup.POST("/start_tutorial", handler.StartTutorialHandler)
+		refineGroup.POST("/roles/:role/preview", offline.Middleware(), handler.RolePreviewHandler)
+		refineGroup.POST("/submit_answers_and_continue", offline.Middleware(), handler.SubmitAnswersAndContinueHandler)
+		refineGroup.POST("/submit_answers_and_get_suggestions", offline.Middleware(), handler.SubmitAnswersAndGetSuggestionsHandler)
+		refineGroup.POST("/sessions/:id/answers/bulk", offline.Middleware(), handler.BulkAnswersHandler)
+		refineGroup.POST("/sessions/:id/prefetch_suggestions", offline.Middleware(), handler.PrefetchSuggestionsHandler)
+		refineGroup.POST("/accept_suggestions", offline.Middleware(), handler.AcceptSuggestionsHandler)
+		refineGroup.POST("/finalize", offline.Middleware(), handler.FinalizeHandler)
+		refineGroup.POST("/check_answer", offline.Middleware(), handler.CheckAnswerHandler)
+		refineGroup.POST("/sessions/:id/translate", offline.Middleware(), handler.TranslateHandler)
+		refineGroup.POST("/sessions/:id/polish", offline.Middleware(), handler.PolishHandler)
+		refineGroup.POST("/sessions/:id/polish/apply", handler.ApplyPolishHandler)
+		refineGroup.POST("/sessions/:id/endpoints", offline.Middleware(), handler.ProposeEndpointsHandler)
+		refineGroup.POST("/sessions/:id/consistency", offline.Middleware(), handler.ConsistencyHandler)
+		refineGroup.GET("/sessions/:id/openapi", handler.OpenAPIHandler)
+		refineGroup.POST("/sessions/:id/gherkin", offline.Middleware(), handler.GherkinHandler)
+		refineGroup.GET("/sessions/:id/gherkin.feature", handler.FeatureFileHandler)
+		refineGroup.POST("/sessions/:id/test_cases", offline.Middleware(), handler.TestCasesHandler)
+		refineGroup.POST("/sessions/:id/phases/:phase", offline.Middleware(), handler.RunOptionalPhaseHandler)
+		refineGroup.POST("/sessions/:id/advance", offline.Middleware(), handler.AdvancePhaseHandler)
+		refineGroup.GET("/sessions/:id/terminology", handler.CheckTerminologyHandler)
+		refineGroup.POST("/sessions/:id/terminology/apply", handler.ApplyTermCorrectionsHandler)
+		refineGroup.GET("/sessions", handler.ListSessionsHandler)
+		refineGroup.GET("/sessions/:id", handler.GetSessionHandler)
+		refineGroup.GET("/sessions/:id/summary", handler.SessionSummaryHandler)
+		refineGroup.GET("/sessions/:id/timing", handler.SessionTimingHandler)
+		refineGroup.GET("/sessions/:id/diagnostics", handler.SessionDiagnosticsHandler)
+		refineGroup.GET("/timing", handler.TimingReportHandler)
+		refineGroup.GET("/prompt_drift", handler.PromptDriftHandler)
+		refineGroup.GET("/pending/:id", asyncRunner.PendingResultHandler)
+		refineGroup.GET("/jobs/:id", asyncRunner.GetJobHandler)
+		refineGroup.POST("/sessions/:id/rerefine", offline.Middleware(), handler.RerefineHandler)
+		refineGroup.POST("/sessions/:id/resume", offline.Middleware(), handler.ResumeSessionHandler)
+		refineGroup.POST("/sessions/:id/fork", offline.Middleware(), handler.ForkSessionHandler)
+		refineGroup.POST("/sessions/:id/undo", offline.Middleware(), handler.UndoRoundHandler)
+		refineGroup.GET("/memory", handler.ListMemoryHandler)
+		refineGroup.DELETE("/memory/:id", handler.DeleteMemoryHandler)
+		refineGroup.GET("/question_bank", handler.ListQuestionBankHandler)
+	}
+
+	// Live run progress over Server-Sent Events
+	refineGroup.GET("/sessions/:id/stream", refinement_http.NewStreamHandler(refinementService, transcriptHub).SessionStreamHandler)
+
+	// Export API routes
+	{
+		handler := export_http.NewExportHandler(exportService)
+		refineGroup.GET("/sessions/:id/export", handler.ExportSessionHandler)
+		refineGroup.GET("/sessions/:id/export/markdown", handler.TranscriptHandler)
+		r.GET("/api/config/export_templates", handler.ListTemplatesHandler)
+		r.PUT("/api/config/export_templates/:name", handler.SaveTemplateHandler)
+		r.DELETE("/api/config/export_templates/:name", handler.DeleteTemplateHandler)
 	}
 
+	// Jira API routes
+	{
+		handler := jira_http.NewJiraHandler(jiraService)
+		refineGroup.POST("/sessions/:id/jira/sync", handler.SyncHandler)
+		refineGroup.POST("/sessions/:id/export/jira", handler.SyncHandler)
+		refineGroup.POST("/sessions/:id/jira/link", handler.LinkHandler)
+		refineGroup.POST("/sessions/:id/jira/pull_comments", handler.PullCommentsHandler)
+	}
+
+	// GitLab API routes
+	{
+		handler := gitlab_http.NewGitLabHandler(gitLabService)
+		refineGroup.POST("/sessions/:id/gitlab/export", handler.ExportHandler)
+		r.POST("/api/hooks/gitlab", handler.WebhookHandler)
+	}
+
+	// Approval API routes
+	{
+		handler := approval_http.NewApprovalHandler(approvalService, os.Getenv("ADMIN_TOKEN"))
+		refineGroup.GET("/sessions/:id/approval", handler.GetApprovalHandler)
+		refineGroup.POST("/sessions/:id/approval/approve", handler.ApproveHandler)
+		refineGroup.POST("/sessions/:id/approval/request_changes", handler.RequestChangesHandler)
+		refineGroup.GET("/sessions/:id/comments", handler.ListCommentsHandler)
+		refineGroup.POST("/sessions/:id/comments", handler.AddCommentHandler)
+		refineGroup.DELETE("/sessions/:id/comments/:commentId", handler.DeleteCommentHandler)
+		refineGroup.POST("/sessions/:id/comments/submit", handler.SubmitCommentsHandler)
+	}
+
+	// Scoring API routes
+	{
+		handler := scoring_http.NewScoringHandler(scoringService)
+		refineGroup.POST("/sessions/:id/score", offline.Middleware(), handler.ScoreHandler)
+		r.GET("/api/scores/trend", handler.TrendHandler)
+	}
+
+	// Generic webhook for automation tools
+	r.POST("/api/hooks/refine", offline.Middleware(), refinement_http.NewHookHandler(refinementService, appConfigService, os.Getenv("HOOK_TOKEN")).RefineHookHandler)
+
+	// MCP server for AI IDEs and agent frameworks
+	r.POST("/api/mcp", mcp_http.NewMCPHandler(mcp_app.NewMCPService(refinementService, appConfigService), os.Getenv("MCP_TOKEN")).MessageHandler)
+
+	// Inbound email routes
+	r.POST("/api/hooks/email", offline.Middleware(), email_http.NewEmailHandler(emailService).InboundHandler)
+
 	// Config API routes
 	configGroup := r.Group("/api/config")
 	{
 		configGroup.GET("/app", config_http.NewAppConfigHandler(appConfigService).GetAppConfigHandler)
 		configGroup.POST("/app", config_http.NewAppConfigHandler(appConfigService).SaveAppConfigHandler)
+		configGroup.GET("/app/versions", config_http.NewAppConfigHandler(appConfigService).ListAppConfigVersionsHandler)
+		configGroup.GET("/app/versions/:version", config_http.NewAppConfigHandler(appConfigService).GetAppConfigVersionHandler)
+		configGroup.POST("/app/rollback/:version", config_http.NewAppConfigHandler(appConfigService).RollbackAppConfigHandler)
+		configGroup.GET("/roles/:role/exemplars", config_http.NewAppConfigHandler(appConfigService).ListRoleExemplarsHandler)
+		configGroup.POST("/roles/:role/exemplars", config_http.NewAppConfigHandler(appConfigService).AddRoleExemplarHandler)
+		configGroup.DELETE("/roles/:role/exemplars/:exemplarId", config_http.NewAppConfigHandler(appConfigService).DeleteRoleExemplarHandler)
+		configGroup.GET("/phases/:phase/examples", config_http.NewAppConfigHandler(appConfigService).ListPhaseFormatExamplesHandler)
+		configGroup.POST("/phases/:phase/examples", config_http.NewAppConfigHandler(appConfigService).AddPhaseFormatExampleHandler)
+		configGroup.PUT("/phases/:phase/examples/:role", config_http.NewAppConfigHandler(appConfigService).UpdatePhaseFormatExampleHandler)
+		configGroup.DELETE("/phases/:phase/examples/:role", config_http.NewAppConfigHandler(appConfigService).DeletePhaseFormatExampleHandler)
+	}
+
+	// Admin API routes
+	adminGroup := r.Group("/api/admin")
+	{
+		authHandler := auth_http.NewAuthHandler(authService, os.Getenv("ADMIN_TOKEN"))
+		adminGroup.GET("/api_keys", authHandler.ListKeysHandler)
+		adminGroup.POST("/api_keys", authHandler.IssueKeyHandler)
+		adminGroup.DELETE("/api_keys/:id", authHandler.RevokeKeyHandler)
+		adminGroup.GET("/auth", authHandler.GetSettingsHandler)
+		adminGroup.PUT("/auth", authHandler.SaveSettingsHandler)
+
+		handler := refinement_http.NewAdminHandler(refinementService, transcriptHub, os.Getenv("ADMIN_TOKEN"))
+		adminGroup.GET("/sessions/:id/transcript", handler.TranscriptWebSocketHandler)
+		adminGroup.POST("/sessions/:id/transcript/ticket", handler.TranscriptTicketHandler)
+		adminGroup.GET("/shadow_runs", handler.ListShadowRunsHandler)
+		adminGroup.POST("/question_bank", handler.SaveBankQuestionHandler)
+		adminGroup.PUT("/question_bank/:id", handler.SaveBankQuestionHandler)
+		adminGroup.DELETE("/question_bank/:id", handler.DeleteBankQuestionHandler)
+
+		backupHandler := backup_http.NewBackupHandler(backupService, os.Getenv("ADMIN_TOKEN"))
+		adminGroup.GET("/backup", backupHandler.CreateBackupHandler)
+		adminGroup.POST("/backup/verify", backupHandler.VerifyBackupHandler)
+
+		reportHandler := report_http.NewReportHandler(reportService, os.Getenv("ADMIN_TOKEN"))
+		adminGroup.GET("/reports/usage", reportHandler.UsageReportHandler)
+
+		approvalHandler := approval_http.NewApprovalHandler(approvalService, os.Getenv("ADMIN_TOKEN"))
+		adminGroup.GET("/approval/reviewers", approvalHandler.GetReviewersHandler)
+		adminGroup.PUT("/approval/reviewers", approvalHandler.SaveReviewersHandler)
+	}
+
+	// Workspace API routes
+	workspaceGroup := r.Group("/api/workspaces")
+	{
+		handler := workspace_http.NewWorkspaceHandler(workspaceService, os.Getenv("ADMIN_TOKEN"))
+		workspaceGroup.GET("", handler.ListWorkspacesHandler)
+		workspaceGroup.POST("", handler.CreateWorkspaceHandler)
+		workspaceGroup.GET("/:id", handler.GetWorkspaceHandler)
+		workspaceGroup.PUT("/:id", handler.UpdateWorkspaceHandler)
+		workspaceGroup.DELETE("/:id", handler.DeleteWorkspaceHandler)
+	}
+
+	// SCIM API routes, authenticated by SCIM_TOKEN rather than the API auth
+	scimGroup := r.Group("/scim/v2")
+	{
+		handler := user_http.NewScimHandler(userService, workspaceService, os.Getenv("SCIM_TOKEN"))
+		scimGroup.Use(handler.Middleware())
+		scimGroup.GET("/ServiceProviderConfig", handler.ServiceProviderConfigHandler)
+		scimGroup.GET("/Users", handler.ListUsersHandler)
+		scimGroup.POST("/Users", handler.CreateUserHandler)
+		scimGroup.GET("/Users/:id", handler.GetUserHandler)
+		scimGroup.PUT("/Users/:id", handler.ReplaceUserHandler)
+		scimGroup.PATCH("/Users/:id", handler.PatchUserHandler)
+		scimGroup.DELETE("/Users/:id", handler.DeleteUserHandler)
+		scimGroup.GET("/Groups", handler.ListGroupsHandler)
+		scimGroup.POST("/Groups", handler.CreateGroupHandler)
+		scimGroup.GET("/Groups/:id", handler.GetGroupHandler)
+		scimGroup.PUT("/Groups/:id", handler.ReplaceGroupHandler)
+		scimGroup.PATCH("/Groups/:id", handler.PatchGroupHandler)
+	}
+
+	// Organization API routes
+	orgGroup := r.Group("/api/orgs")
+	{
+		handler := organization_http.NewOrganizationHandler(organizationService, os.Getenv("ADMIN_TOKEN"))
+		orgGroup.GET("", handler.ListOrganizationsHandler)
+		orgGroup.POST("", handler.CreateOrganizationHandler)
+		orgGroup.GET("/:id", handler.GetOrganizationHandler)
+		orgGroup.PUT("/:id", handler.UpdateOrganizationHandler)
+		orgGroup.DELETE("/:id", handler.DeleteOrganizationHandler)
+		orgGroup.GET("/:id/workspaces", handler.ListWorkspacesHandler)
+		orgGroup.POST("/:id/workspaces", handler.ProvisionWorkspaceHandler)
+		orgGroup.POST("/:id/workspaces/assign", handler.AssignWorkspaceHandler)
+		orgGroup.DELETE("/:id/workspaces/:workspaceId", handler.RemoveWorkspaceHandler)
+		orgGroup.GET("/:id/rollup", handler.RollupHandler)
+	}
+
+	// Project API routes
+	projectGroup := r.Group("/api/projects")
+	{
+		handler := project_http.NewProjectHandler(projectService)
+		projectGroup.GET("", handler.ListProjectsHandler)
+		projectGroup.POST("", handler.CreateProjectHandler)
+		projectGroup.GET("/:id", handler.GetProjectHandler)
+		projectGroup.PUT("/:id", handler.UpdateProjectHandler)
+		projectGroup.DELETE("/:id", handler.DeleteProjectHandler)
+	}
+
+	// Prompt marketplace API routes
+	marketplaceGroup := r.Group("/api/marketplace")
+	{
+		handler := marketplace_http.NewMarketplaceHandler(marketplace_app.NewMarketplaceService(
+			marketplace_infra.NewJSONPackageRepository("config/prompt_packages.json"),
+			appConfigService,
+			workspaceService,
+		))
+		marketplaceGroup.GET("/packages", handler.ListPackagesHandler)
+		marketplaceGroup.POST("/packages", handler.PublishHandler)
+		marketplaceGroup.GET("/packages/:name", handler.ListVersionsHandler)
+		marketplaceGroup.POST("/packages/:name/import", handler.ImportHandler)
 	}
 
+	// Notification API routes
+	{
+		handler := notification_http.NewNotificationHandler(notificationService)
+		r.GET("/api/notifications/preferences", handler.GetPreferencesHandler)
+		r.PUT("/api/notifications/preferences", handler.SavePreferencesHandler)
+	}
+
+	// Backlog API routes
+	backlogGroup := r.Group("/api/backlogs")
+	{
+		handler := backlog_http.NewBacklogHandler(backlogService, asyncRunner)
+		backlogGroup.GET("", handler.ListBacklogsHandler)
+		backlogGroup.POST("", handler.CreateBacklogHandler)
+		backlogGroup.POST("/import", offline.Middleware(), handler.ImportBaselineHandler)
+		backlogGroup.GET("/:id", handler.GetBacklogHandler)
+		backlogGroup.PUT("/:id", handler.UpdateBacklogHandler)
+		backlogGroup.DELETE("/:id", handler.DeleteBacklogHandler)
+		backlogGroup.POST("/:id/sessions", handler.AddSessionsHandler)
+		backlogGroup.DELETE("/:id/sessions/:sessionId", handler.RemoveSessionHandler)
+		backlogGroup.PUT("/:id/order", handler.ReorderHandler)
+		backlogGroup.POST("/:id/export", handler.ExportHandler)
+	}
+
+	// Retrospective API routes
+	retrospectiveGroup := r.Group("/api/retrospectives")
+	{
+		handler := retrospective_http.NewRetrospectiveHandler(retrospectiveService)
+		retrospectiveGroup.GET("", handler.ListHandler)
+		retrospectiveGroup.POST("", offline.Middleware(), handler.GenerateHandler)
+		retrospectiveGroup.GET("/latest", handler.LatestHandler)
+	}
+
+	// Usage API routes
+	usageGroup := r.Group("/api/usage")
+	{
+		usageGroup.GET("/attributions", usage_http.NewUsageHandler(usageService).ListAttributionsHandler)
+	}
+
+	// Telemetry API routes
+	r.GET("/api/telemetry", telemetry_http.NewTelemetryHandler(telemetryService).PreviewHandler)
+
+	// Workload API routes
+	r.GET("/api/users/me/pending", workload_http.NewWorkloadHandler(workloadService).PendingHandler)
+
 	r.Run(":8080") // listen and serve on 0.0.0.0:8080
 }